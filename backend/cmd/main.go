@@ -27,6 +27,7 @@ import (
 	"ai-models-backend/internal/database"
 	"ai-models-backend/internal/handlers"
 	"ai-models-backend/internal/middleware"
+	"ai-models-backend/internal/models"
 	"ai-models-backend/pkg/response"
 	"log"
 	"net/http"
@@ -99,6 +100,9 @@ func setupRouter(c *container.Container) *gin.Engine {
 	// 监控指标端点
 	r.GET("/metrics/redis", c.MetricsHandler.RedisMetrics)
 
+	// JWT 验证公钥，供其他服务验证 token
+	r.GET("/.well-known/jwks.json", c.UserHandler.JWKS)
+
 	// Swagger文档路由 (仅在开发环境)
 	if !c.Config.IsProd {
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -110,22 +114,81 @@ func setupRouter(c *container.Container) *gin.Engine {
 		{
 			// 公开接口
 			users.POST("/register", c.UserHandler.Register)
-			users.POST("/login", c.UserHandler.Login)
+			users.POST("/login", middleware.RateLimitMid(), c.UserHandler.Login)
+			users.POST("/login/2fa", middleware.RateLimitMid(), c.UserHandler.LoginTwoFactor)
+			users.POST("/refresh", c.UserHandler.RefreshToken)
 			users.GET("/check-field", c.UserHandler.CheckUserField) // 检查字段是否存在
 
+			// 邮箱验证和找回密码，会发送邮件，严格限流
+			users.POST("/verify-email", middleware.RateLimitHigh(), c.UserHandler.VerifyEmail)
+			users.POST("/forgot-password", middleware.RateLimitHigh(), c.UserHandler.ForgotPassword)
+			users.POST("/reset-password", middleware.RateLimitHigh(), c.UserHandler.ResetPassword)
+
+			// 第三方登录
+			users.GET("/oauth/providers", c.UserHandler.OAuthProviders)
+			users.GET("/oauth/:provider/authorize", middleware.RateLimitMid(), c.UserHandler.OAuthAuthorize)
+			users.POST("/oauth/:provider/callback", middleware.RateLimitMid(), c.UserHandler.OAuthCallback)
+
 			// 用户自己的接口
 			users.POST("/logout", middleware.AuthRequired(c.AuthService), c.UserHandler.Logout)
+			users.POST("/logout-all", middleware.AuthRequired(c.AuthService), c.UserHandler.LogoutAll)
 			users.GET("/profile", middleware.AuthRequired(c.AuthService), c.UserHandler.GetProfile)
 			users.PUT("/profile", middleware.AuthRequired(c.AuthService), c.UserHandler.UpdateProfile)
 			users.POST("/change-password", middleware.AuthRequired(c.AuthService), c.UserHandler.ChangePassword)
+			users.POST("/resend-verification", middleware.AuthRequired(c.AuthService), middleware.RateLimitHigh(), c.UserHandler.ResendVerification)
+
+			// 注销账号和导出个人数据
+			users.POST("/account/deletion", middleware.AuthRequired(c.AuthService), middleware.RateLimitMid(), c.UserHandler.ScheduleAccountDeletion)
+			users.DELETE("/account/deletion", middleware.AuthRequired(c.AuthService), c.UserHandler.CancelAccountDeletion)
+			users.GET("/export", middleware.AuthRequired(c.AuthService), middleware.RateLimitHigh(), c.UserHandler.ExportUserData)
+
+			// 登录设备（会话）
+			users.GET("/sessions", middleware.AuthRequired(c.AuthService), c.UserHandler.ListSessions)
+			users.DELETE("/sessions/:id", middleware.AuthRequired(c.AuthService), c.UserHandler.RevokeSession)
+
+			// 个人 API Key，用于调用 /ai/v1 接口
+			users.GET("/api-keys", middleware.AuthRequired(c.AuthService), c.UserHandler.ListAPIKeys)
+			users.POST("/api-keys", middleware.AuthRequired(c.AuthService), c.UserHandler.CreateAPIKey)
+			users.DELETE("/api-keys/:id", middleware.AuthRequired(c.AuthService), c.UserHandler.RevokeAPIKey)
+
+			// 两步验证
+			users.GET("/2fa", middleware.AuthRequired(c.AuthService), c.UserHandler.GetTwoFactorStatus)
+			users.POST("/2fa/setup", middleware.AuthRequired(c.AuthService), c.UserHandler.SetupTwoFactor)
+			users.POST("/2fa/enable", middleware.AuthRequired(c.AuthService), middleware.RateLimitMid(), c.UserHandler.EnableTwoFactor)
+			users.POST("/2fa/disable", middleware.AuthRequired(c.AuthService), middleware.RateLimitMid(), c.UserHandler.DisableTwoFactor)
+			users.POST("/2fa/recovery-codes", middleware.AuthRequired(c.AuthService), middleware.RateLimitMid(), c.UserHandler.RegenerateRecoveryCodes)
+
+			// 绑定的第三方账号
+			users.GET("/identities", middleware.AuthRequired(c.AuthService), c.UserHandler.ListIdentities)
+			users.GET("/identities/:provider/authorize", middleware.AuthRequired(c.AuthService), c.UserHandler.LinkIdentityAuthorize)
+			users.POST("/identities/:provider", middleware.AuthRequired(c.AuthService), c.UserHandler.LinkIdentity)
+			users.DELETE("/identities/:provider", middleware.AuthRequired(c.AuthService), c.UserHandler.UnlinkIdentity)
 		}
 
 		// 很多 openai 都是带有 v1 前缀的，模拟一下
 		ai := api.Group("/ai/v1")
-		ai.Use(middleware.RateLimitMid())
+		ai.Use(middleware.AIAuth(c.AuthService), middleware.RateLimitMid())
 		{
 			ai.POST("/chat/completions", c.AIHandler.OpenAIChatCompletion)
 			ai.POST("/images/generations", c.AIHandler.GenerateImages)
+			ai.POST("/images/edits", c.AIHandler.EditImages)
+			ai.POST("/images/variations", c.AIHandler.CreateImageVariations)
+			ai.GET("/models", c.AIHandler.OpenAIListModels)
+		}
+
+		// 会话式聊天接口，历史按用户和会话持久化
+		aiChat := api.Group("/ai")
+		aiChat.Use(middleware.AuthRequired(c.AuthService), middleware.RateLimitMid())
+		{
+			aiChat.POST("/chat", c.AIHandler.Chat)                             // 聊天（支持流式）
+			aiChat.GET("/chat/history", c.AIHandler.GetChatHistory)            // 获取会话消息
+			aiChat.DELETE("/chat/history", c.AIHandler.ClearChatHistory)       // 清空会话消息
+			aiChat.GET("/sessions", c.AIHandler.ListSessions)                  // 会话列表
+			aiChat.PUT("/sessions/:session_id", c.AIHandler.RenameSession)     // 重命名会话
+			aiChat.POST("/sessions/:session_id/fork", c.AIHandler.ForkSession) // 复制会话
+			aiChat.DELETE("/sessions/:session_id", c.AIHandler.DeleteSession)  // 删除会话
+			aiChat.GET("/quota", c.AIHandler.GetQuota)                         // 剩余用量配额
+			aiChat.GET("/models", c.AIHandler.GetModels)                       // 可用模型列表
 		}
 
 		oss := api.Group("/oss")
@@ -166,22 +229,50 @@ func setupRouter(c *container.Container) *gin.Engine {
 			todos.DELETE("/:id", c.TodoHandler.DeleteTodo)               // 删除TODO
 		}
 
-		// 管理员接口
+		// 管理员接口，默认拒绝：需要 admin.access 权限，各分组再按操作要求具体权限
 		admin := api.Group("/admin")
-		admin.Use(middleware.AdminRequired(c.AuthService, c.UserService))
+		admin.Use(middleware.AuthRequired(c.AuthService), middleware.RequirePermission(c.RBACService, models.PermAdminAccess))
 		{
 			// 系统管理
 			admin.GET("/status", c.AdminHandler.GetSystemStatus) // 获取系统状态
 
 			// 用户管理
+			adminUsersRead := admin.Group("/users")
+			adminUsersRead.Use(middleware.RequirePermission(c.RBACService, models.PermUsersRead))
+			{
+				adminUsersRead.GET("", c.UserHandler.GetUsers)        // 获取用户列表
+				adminUsersRead.GET("/:id", c.UserHandler.GetUserByID) // 根据ID获取用户
+			}
 			adminUsers := admin.Group("/users")
+			adminUsers.Use(middleware.RequirePermission(c.RBACService, models.PermUsersWrite))
 			{
-				adminUsers.GET("", c.UserHandler.GetUsers)                               // 获取用户列表
-				adminUsers.GET("/:id", c.UserHandler.GetUserByID)                        // 根据ID获取用户
 				adminUsers.DELETE("/:id", c.UserHandler.DeleteUser)                      // 删除用户
 				adminUsers.POST("/:id/activate", c.UserHandler.ActivateUser)             // 激活用户
 				adminUsers.POST("/:id/deactivate", c.UserHandler.DeactivateUser)         // 停用用户
 				adminUsers.POST("/:id/reset-password", c.AdminHandler.ResetUserPassword) // 重置用户密码
+				adminUsers.POST("/:id/unlock", c.AdminHandler.UnlockUser)                // 解除登录锁定
+				adminUsers.DELETE("/:id/2fa", c.AdminHandler.ResetUserTwoFactor)         // 重置两步验证
+			}
+
+			// 角色权限管理
+			adminRoles := admin.Group("")
+			adminRoles.Use(middleware.RequirePermission(c.RBACService, models.PermRolesManage))
+			{
+				adminRoles.GET("/permissions", c.AdminHandler.ListPermissions)             // 全部权限
+				adminRoles.GET("/roles", c.AdminHandler.ListRoles)                         // 角色列表
+				adminRoles.PUT("/roles/:name", c.AdminHandler.SaveRole)                    // 创建或更新角色
+				adminRoles.DELETE("/roles/:name", c.AdminHandler.DeleteRole)               // 删除角色
+				adminRoles.PUT("/roles/:name/two-factor", c.AdminHandler.SetRoleTwoFactor) // 角色是否要求两步验证
+				adminRoles.PUT("/users/:id/role", c.AdminHandler.AssignRole)               // 分配用户角色
+			}
+
+			// AI 模型管理
+			adminAI := admin.Group("/ai")
+			adminAI.Use(middleware.RequirePermission(c.RBACService, models.PermAIManage))
+			{
+				adminAI.GET("/models", c.AIHandler.ListModelCatalog)       // 模型目录（含已禁用）
+				adminAI.PUT("/models", c.AIHandler.UpdateModelOverride)    // 启用/禁用模型、设置别名
+				adminAI.POST("/models/refresh", c.AIHandler.RefreshModels) // 刷新模型缓存
 			}
 		}
 
@@ -189,18 +280,36 @@ func setupRouter(c *container.Container) *gin.Engine {
 		feed := api.Group("/feed")
 		{
 			// 公开接口
-			feed.GET("/posts", c.FeedHandler.GetFeedPosts)                      // 获取信息流帖子列表
-			feed.GET("/posts/:post_id", c.FeedHandler.GetFeedPostDetail)        // 获取帖子详情
-			feed.GET("/posts/:post_id/comments", c.FeedHandler.GetFeedComments) // 获取帖子评论列表
+			feed.GET("/posts", c.FeedHandler.GetFeedPosts)                             // 获取信息流帖子列表
+			feed.GET("/posts/:post_id", c.FeedHandler.GetFeedPostDetail)               // 获取帖子详情
+			feed.GET("/posts/:post_id/comments", c.FeedHandler.GetFeedComments)        // 获取帖子评论列表
+			feed.GET("/comments/:comment_id/replies", c.FeedHandler.GetCommentReplies) // 获取评论回复列表
+			feed.GET("/users/:user_id/followers", c.FeedHandler.GetFollowers)          // 获取粉丝列表
+			feed.GET("/users/:user_id/following", c.FeedHandler.GetFollowing)          // 获取关注列表
 
 			// 需要认证的接口
 			feedAuth := feed.Group("")
 			feedAuth.Use(middleware.AuthRequired(c.AuthService))
 			{
-				feedAuth.POST("/posts", c.FeedHandler.CreateFeedPost)                      // 创建信息流帖子
 				feedAuth.POST("/posts/:post_id/like", c.FeedHandler.SetLikePost)           // 设置帖子点赞状态
-				feedAuth.POST("/posts/:post_id/comments", c.FeedHandler.CreateFeedComment) // 创建帖子评论
+				feedAuth.DELETE("/posts/:post_id", c.FeedHandler.DeleteFeedPost)           // 删除帖子
+				feedAuth.GET("/timeline", c.FeedHandler.GetTimeline)                       // 获取首页时间线
+				feedAuth.POST("/users/:user_id/follow", c.FeedHandler.FollowUser)          // 关注用户
+				feedAuth.DELETE("/users/:user_id/follow", c.FeedHandler.UnfollowUser)      // 取消关注用户
+				feedAuth.GET("/users/:user_id/follow-stats", c.FeedHandler.GetFollowStats) // 获取关注统计
 				feedAuth.POST("/comments/:comment_id/like", c.FeedHandler.SetCommentLike)  // 设置评论点赞状态
+				feedAuth.DELETE("/comments/:comment_id", c.FeedHandler.DeleteFeedComment)  // 删除评论
+
+				// 发布内容需要先验证邮箱
+				verified := feedAuth.Group("")
+				verified.Use(middleware.VerifiedEmailRequired(c.UserService))
+				{
+					verified.POST("/posts", c.FeedHandler.CreateFeedPost)                      // 创建信息流帖子
+					verified.POST("/media/upload-url", c.FeedHandler.SignFeedMediaUpload)      // 申请上传帖子图片
+					verified.PUT("/posts/:post_id", c.FeedHandler.EditFeedPost)                // 编辑帖子
+					verified.POST("/posts/:post_id/comments", c.FeedHandler.CreateFeedComment) // 创建帖子评论
+					verified.PUT("/comments/:comment_id", c.FeedHandler.EditFeedComment)       // 编辑评论
+				}
 			}
 		}
 
@@ -1,5 +1,4 @@
-// Code generated by swaggo/swag. DO NOT EDIT.
-
+// Package docs Code generated by swaggo/swag. DO NOT EDIT
 package docs
 
 import "github.com/swaggo/swag"
@@ -24,13 +23,30 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/status": {
+        "/.well-known/jwks.json": {
             "get": {
-                "description": "获取系统运行状态信息，包括用户统计数据和系统基本信息，用于管理员监控",
+                "description": "以 JWK Set 格式返回验证 token 的全部公钥，按 token header 中的 kid 选择",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JWT 公钥",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.JWKSet"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/ai/models": {
+            "get": {
+                "description": "获取全部模型，包括已禁用的模型",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "获取系统状态",
+                "summary": "模型目录（管理员）",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -43,8 +59,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.AIModel"
+                                            }
                                         }
                                     }
                                 }
@@ -52,30 +70,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/users": {
-            "get": {
-                "description": "管理员分页获取系统中所有用户的列表，支持分页查询",
+            },
+            "put": {
+                "description": "启用/禁用模型或设置别名，禁用后调用该模型会被拒绝",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "获取用户列表",
-                "operationId": "getUsers",
+                "summary": "更新模型设置（管理员）",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
+                        "description": "模型设置",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.AIModelOverrideRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -90,7 +100,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.PaginationResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.AIModelOverride"
                                         }
                                     }
                                 }
@@ -100,23 +110,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}": {
-            "get": {
-                "description": "管理员根据用户ID获取指定用户的详细信息，用于用户管理",
+        "/admin/ai/models/refresh": {
+            "post": {
+                "description": "清除各平台模型列表缓存，下次查询时重新拉取",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "根据ID获取用户信息",
-                "operationId": "getUserByID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                "summary": "刷新模型目录（管理员）",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
                     }
+                }
+            }
+        },
+        "/admin/permissions": {
+            "get": {
+                "description": "获取可以分配给角色的全部权限及说明",
+                "tags": [
+                    "Admin"
                 ],
+                "summary": "权限列表",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -129,7 +146,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.PermissionInfo"
+                                            }
                                         }
                                     }
                                 }
@@ -137,23 +157,15 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "管理员删除指定用户账户，此操作将永久删除用户数据，请谨慎使用",
+            }
+        },
+        "/admin/roles": {
+            "get": {
+                "description": "获取全部角色及其权限",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "删除用户",
-                "operationId": "deleteUser",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
+                "summary": "角色列表",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -166,8 +178,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.Role"
+                                            }
                                         }
                                     }
                                 }
@@ -177,21 +191,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}/activate": {
-            "post": {
-                "description": "管理员激活指定用户账户，激活后用户可以正常登录和使用系统",
+        "/admin/roles/{name}": {
+            "put": {
+                "description": "创建角色或更新角色的说明和权限，admin 角色始终拥有全部权限，不能修改",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "激活用户",
-                "operationId": "activateUser",
+                "summary": "创建或更新角色",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
+                        "description": "角色名",
+                        "name": "name",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "角色权限",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.RoleRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -206,8 +228,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.Role"
                                         }
                                     }
                                 }
@@ -215,20 +236,18 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/admin/users/{id}/deactivate": {
-            "post": {
-                "description": "管理员停用指定用户账户，停用后用户无法登录和使用系统功能",
+            },
+            "delete": {
+                "description": "删除自定义角色，内置角色和仍有用户的角色不能删除",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "停用用户",
+                "summary": "删除角色",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
+                        "description": "角色名",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     }
@@ -256,33 +275,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/admin/users/{id}/reset-password": {
-            "post": {
-                "description": "管理员重置指定用户的密码，通常用于用户忘记密码或管理员主动重置的场景",
+        "/admin/roles/{name}/two-factor": {
+            "put": {
+                "description": "开启后该角色的用户必须通过两步验证登录才能使用角色的权限，未启用两步验证的用户需要启用后重新登录",
                 "tags": [
                     "Admin"
                 ],
-                "summary": "重置用户密码",
+                "summary": "设置角色是否要求两步验证",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
+                        "description": "角色名",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "新密码",
+                        "description": "是否要求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object",
-                            "properties": {
-                                "newPassword": {
-                                    "type": "string"
-                                }
-                            }
+                            "$ref": "#/definitions/ai-models-backend_internal_models.RoleTwoFactorRequest"
                         }
                     }
                 ],
@@ -298,8 +312,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.Role"
                                         }
                                     }
                                 }
@@ -309,24 +322,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/ai/chat": {
-            "post": {
-                "description": "与AI模型进行对话聊天，支持流式和非流式响应，可以指定使用的AI模型",
+        "/admin/status": {
+            "get": {
+                "description": "获取系统运行状态信息，包括用户统计数据和系统基本信息，用于管理员监控",
                 "tags": [
-                    "AI"
-                ],
-                "summary": "AI聊天",
-                "parameters": [
-                    {
-                        "description": "聊天请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatRequest"
-                        }
-                    }
+                    "Admin"
                 ],
+                "summary": "获取系统状态",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -339,7 +341,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -349,18 +352,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/ai/chat/history": {
+        "/admin/users": {
             "get": {
-                "description": "获取用户的历史聊天记录，支持分页和过滤",
+                "description": "管理员分页获取系统中所有用户的列表，支持分页查询",
                 "tags": [
-                    "AI"
+                    "Admin"
                 ],
-                "summary": "获取聊天历史",
+                "summary": "获取用户列表",
+                "operationId": "getUsers",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "会话ID",
-                        "name": "session_id",
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "limit",
                         "in": "query",
                         "required": true
                     }
@@ -377,10 +388,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/ai-models-backend_internal_models.ChatMessage"
-                                            }
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.PaginationResponse"
                                         }
                                     }
                                 }
@@ -388,19 +396,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "清除用户的历史聊天记录，支持按会话ID清除",
+            }
+        },
+        "/admin/users/{id}": {
+            "get": {
+                "description": "管理员根据用户ID获取指定用户的详细信息，用于用户管理",
                 "tags": [
-                    "AI"
+                    "Admin"
                 ],
-                "summary": "清除聊天历史",
+                "summary": "根据ID获取用户信息",
+                "operationId": "getUserByID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "会话ID",
-                        "name": "session_id",
-                        "in": "query",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -408,28 +419,37 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
-            }
-        },
-        "/ai/generate": {
-            "post": {
-                "description": "基于提示词生成文本内容，支持自定义参数和不同的AI模型",
+            },
+            "delete": {
+                "description": "管理员删除指定用户账户，此操作将永久删除用户数据，请谨慎使用",
                 "tags": [
-                    "AI"
+                    "Admin"
                 ],
-                "summary": "文本生成",
+                "summary": "删除用户",
+                "operationId": "deleteUser",
                 "parameters": [
                     {
-                        "description": "生成请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.GenerateRequest"
-                        }
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -444,7 +464,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.GenerateResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -454,13 +475,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/ai/models": {
-            "get": {
-                "description": "获取可用的AI模型列表，支持不同的AI平台和模型类型",
+        "/admin/users/{id}/2fa": {
+            "delete": {
+                "description": "为丢失身份验证器的用户关闭两步验证，用户可以重新绑定",
                 "tags": [
-                    "AI"
+                    "Admin"
+                ],
+                "summary": "重置两步验证",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取模型列表",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -473,10 +503,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/ai-models-backend_internal_models.AIModel"
-                                            }
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -486,70 +514,60 @@ const docTemplate = `{
                 }
             }
         },
-        "/ai/v1/chat/completions": {
+        "/admin/users/{id}/activate": {
             "post": {
-                "description": "OpenAI兼容聊天接口，支持流式和非流式响应，可以指定使用的AI模型",
+                "description": "管理员激活指定用户账户，激活后用户可以正常登录和使用系统",
                 "tags": [
-                    "AI"
+                    "Admin"
                 ],
-                "summary": "OpenAI兼容聊天接口",
+                "summary": "激活用户",
+                "operationId": "activateUser",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "平台",
-                        "name": "platform",
-                        "in": "query"
-                    },
-                    {
-                        "description": "OpenAI聊天请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChatCompletionRequest"
-                        }
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChatCompletionResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     }
                 }
             }
         },
-        "/ai/v1/images/generations": {
+        "/admin/users/{id}/deactivate": {
             "post": {
-                "description": "基于提示词生成图片，支持自定义参数和不同的AI模型",
+                "description": "管理员停用指定用户账户，停用后用户无法登录和使用系统功能",
                 "tags": [
-                    "AI"
+                    "Admin"
                 ],
-                "summary": "图片生成",
+                "summary": "停用用户",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "平台",
-                        "name": "platform",
-                        "in": "query"
-                    },
-                    {
-                        "description": "图片生成请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "properties": {
-                                "model": {
-                                    "type": "string"
-                                },
-                                "prompt": {
-                                    "type": "string"
-                                }
-                            }
-                        }
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -564,10 +582,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "type": "string"
-                                            }
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -577,29 +593,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/feed/comments/{comment_id}/like": {
+        "/admin/users/{id}/reset-password": {
             "post": {
-                "description": "设置评论点赞或取消点赞状态，需要登录",
+                "description": "管理员重置指定用户的密码，通常用于用户忘记密码或管理员主动重置的场景",
                 "tags": [
-                    "Feed"
+                    "Admin"
                 ],
-                "summary": "设置评论点赞状态",
-                "operationId": "setFeedCommentLike",
+                "summary": "重置用户密码",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "评论ID",
-                        "name": "comment_id",
+                        "description": "用户ID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "点赞状态",
+                        "description": "新密码",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.SetFeedCommentLikeRequest"
+                            "type": "object",
+                            "properties": {
+                                "newPassword": {
+                                    "type": "string"
+                                }
+                            }
                         }
                     }
                 ],
@@ -615,7 +635,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.LikeResult"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -625,47 +646,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/feed/posts": {
-            "get": {
-                "description": "支持多种排序方式和cursor分页",
+        "/admin/users/{id}/role": {
+            "put": {
+                "description": "修改指定用户的角色，不能撤销最后一个管理员",
                 "tags": [
-                    "Feed"
+                    "Admin"
                 ],
-                "summary": "获取信息流帖子列表",
-                "operationId": "getFeedPosts",
+                "summary": "分配用户角色",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "cursor分页的after_id",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 20,
-                        "minimum": 0,
-                        "type": "integer",
-                        "description": "预载评论数量，0表示不预载，最多20条",
-                        "name": "comment_count",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 50,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页数量，最多50",
-                        "name": "limit",
-                        "in": "query"
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "enum": [
-                            "time",
-                            "like",
-                            "comment"
-                        ],
-                        "type": "string",
-                        "description": "排序类型：time, like, comment",
-                        "name": "sort",
-                        "in": "query"
+                        "description": "角色",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.AssignRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -680,7 +683,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPostResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -688,23 +692,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/unlock": {
             "post": {
-                "description": "创建新的信息流帖子，需要登录",
+                "description": "管理员解除指定用户因连续登录失败导致的临时锁定，并清空失败计数",
                 "tags": [
-                    "Feed"
+                    "Admin"
                 ],
-                "summary": "创建信息流帖子",
-                "operationId": "createFeedPost",
+                "summary": "解除登录锁定",
                 "parameters": [
                     {
-                        "description": "帖子内容",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.CreateFeedPostRequest"
-                        }
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -719,7 +722,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPost"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -729,21 +733,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/feed/posts/{post_id}": {
-            "get": {
-                "description": "获取指定帖子的详细信息",
+        "/ai/chat": {
+            "post": {
+                "description": "与AI模型进行对话聊天，支持流式和非流式响应，可以指定使用的AI模型",
                 "tags": [
-                    "Feed"
+                    "AI"
                 ],
-                "summary": "获取帖子详情",
-                "operationId": "getFeedPostDetail",
+                "summary": "AI聊天",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "帖子ID",
-                        "name": "post_id",
-                        "in": "path",
-                        "required": true
+                        "description": "聊天请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -758,7 +763,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPost"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatResponse"
                                         }
                                     }
                                 }
@@ -768,39 +773,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/feed/posts/{post_id}/comments": {
+        "/ai/chat/history": {
             "get": {
-                "description": "获取指定帖子的评论列表，支持cursor分页",
+                "description": "获取用户的历史聊天记录，支持分页和过滤",
                 "tags": [
-                    "Feed"
+                    "AI"
                 ],
-                "summary": "获取帖子评论列表",
-                "operationId": "getFeedComments",
+                "summary": "获取聊天历史",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "帖子ID",
-                        "name": "post_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "cursor分页的after_id",
-                        "name": "after_id",
-                        "in": "query"
-                    },
-                    {
-                        "maximum": 50,
-                        "minimum": 1,
-                        "type": "integer",
-                        "description": "每页数量，最多50",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "name": "post_id",
+                        "description": "会话ID",
+                        "name": "session_id",
                         "in": "query",
                         "required": true
                     }
@@ -817,7 +801,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedCommentResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.ConversationHistory"
+                                            }
                                         }
                                     }
                                 }
@@ -826,76 +813,46 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "为指定帖子创建评论，需要登录",
+            "delete": {
+                "description": "清除用户的历史聊天记录，支持按会话ID清除",
                 "tags": [
-                    "Feed"
+                    "AI"
                 ],
-                "summary": "创建帖子评论",
-                "operationId": "createFeedComment",
+                "summary": "清除聊天历史",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "帖子ID",
-                        "name": "post_id",
-                        "in": "path",
+                        "description": "会话ID",
+                        "name": "session_id",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "description": "评论内容",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.CreateFeedCommentRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
                         }
                     }
                 }
             }
         },
-        "/api/feed/posts/{post_id}/like": {
+        "/ai/generate": {
             "post": {
-                "description": "设置帖子点赞或取消点赞状态，需要登录",
+                "description": "基于提示词生成文本内容，支持自定义参数和不同的AI模型",
                 "tags": [
-                    "Feed"
+                    "AI"
                 ],
-                "summary": "设置帖子点赞状态",
-                "operationId": "setFeedPostLike",
+                "summary": "文本生成",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "帖子ID",
-                        "name": "post_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "点赞状态",
+                        "description": "生成请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.SetFeedPostLikeRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.GenerateRequest"
                         }
                     }
                 ],
@@ -911,7 +868,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.LikeResult"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.GenerateResponse"
                                         }
                                     }
                                 }
@@ -921,34 +878,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/crud": {
+        "/ai/models": {
             "get": {
-                "description": "分页获取数据记录列表，支持按分类筛选，返回分页信息和记录数据",
+                "description": "获取可用的AI模型列表，支持不同的AI平台和模型类型",
                 "tags": [
-                    "CRUD"
-                ],
-                "summary": "获取记录列表",
-                "operationId": "getList",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页数量",
-                        "name": "limit",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "分类",
-                        "name": "category",
-                        "in": "query"
-                    }
+                    "AI"
                 ],
+                "summary": "获取模型列表",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -961,8 +897,10 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.AIModel"
+                                            }
                                         }
                                     }
                                 }
@@ -970,25 +908,15 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "创建新的数据记录，支持分类，数据是字符串，前端自行管理决定是否要json parse",
+            }
+        },
+        "/ai/quota": {
+            "get": {
+                "description": "获取当前用户当日和当月的 token 配额使用情况",
                 "tags": [
-                    "CRUD"
-                ],
-                "summary": "创建记录",
-                "operationId": "create",
-                "parameters": [
-                    {
-                        "description": "创建请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudCreateRequest"
-                        }
-                    }
+                    "AI"
                 ],
+                "summary": "剩余用量配额",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1001,7 +929,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.AIQuotaResponse"
                                         }
                                     }
                                 }
@@ -1011,21 +939,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/crud/{id}": {
+        "/ai/sessions": {
             "get": {
-                "description": "根据唯一标识符获取指定的数据记录详细信息",
+                "description": "获取当前用户的聊天会话列表，按最近消息时间倒序",
                 "tags": [
-                    "CRUD"
+                    "AI"
                 ],
-                "summary": "根据ID获取记录",
-                "operationId": "getByID",
+                "summary": "获取会话列表",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "记录ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1040,7 +972,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.PaginationResponse"
                                         }
                                     }
                                 }
@@ -1048,29 +980,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/ai/sessions/{session_id}": {
             "put": {
-                "description": "根据ID更新现有数据记录的内容，支持更新数据和分类信息",
+                "description": "修改指定会话的标题",
                 "tags": [
-                    "CRUD"
+                    "AI"
                 ],
-                "summary": "更新记录",
-                "operationId": "update",
+                "summary": "重命名会话",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "记录ID",
-                        "name": "id",
+                        "description": "会话ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "更新请求",
+                        "description": "重命名请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudUpdateRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.RenameChatSessionRequest"
                         }
                     }
                 ],
@@ -1086,7 +1019,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatSession"
                                         }
                                     }
                                 }
@@ -1096,17 +1029,16 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "根据ID永久删除指定的数据记录，操作不可逆，请谨慎使用",
+                "description": "删除指定会话及其全部消息",
                 "tags": [
-                    "CRUD"
+                    "AI"
                 ],
-                "summary": "删除记录",
-                "operationId": "delete",
+                "summary": "删除会话",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "记录ID",
-                        "name": "id",
+                        "description": "会话ID",
+                        "name": "session_id",
                         "in": "path",
                         "required": true
                     }
@@ -1115,38 +1047,36 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
                         }
                     }
                 }
             }
         },
-        "/metrics/redis": {
-            "get": {
-                "description": "获取Redis内存使用、缓存命中率等监控指标",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
+        "/ai/sessions/{session_id}/fork": {
+            "post": {
+                "description": "复制指定会话及其消息，可指定复制到某条消息为止，用于从中间分叉继续对话",
                 "tags": [
-                    "metrics"
+                    "AI"
+                ],
+                "summary": "复制会话",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "复制请求",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.ForkChatSessionRequest"
+                        }
+                    }
                 ],
-                "summary": "获取Redis监控指标",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1159,7 +1089,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/internal_handlers.RedisMetricsResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.ChatSession"
                                         }
                                     }
                                 }
@@ -1169,22 +1099,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/oss/delete": {
-            "delete": {
-                "description": "删除OSS文件",
+        "/ai/v1/chat/completions": {
+            "post": {
+                "description": "OpenAI兼容聊天接口，支持流式和非流式响应，可以指定使用的AI模型",
                 "tags": [
-                    "OSS"
+                    "AI"
                 ],
-                "summary": "删除",
-                "operationId": "delete",
+                "summary": "OpenAI兼容聊天接口",
                 "parameters": [
                     {
-                        "description": "删除请求",
+                        "type": "string",
+                        "description": "平台",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "description": "OpenAI聊天请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.DeleteFileRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChatCompletionRequest"
                         }
                     }
                 ],
@@ -1192,85 +1127,229 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChatCompletionResponse"
                         }
                     }
                 }
             }
         },
-        "/oss/files": {
-            "get": {
-                "description": "获取OSS文件列表",
+        "/ai/v1/images/edits": {
+            "post": {
+                "description": "OpenAI兼容的图片编辑接口，multipart/form-data 上传原图和可选的蒙版",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "tags": [
-                    "OSS"
+                    "AI"
                 ],
-                "summary": "获取文件列表",
-                "operationId": "getFileList",
+                "summary": "图片编辑",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "前缀",
-                        "name": "prefix",
+                        "description": "平台",
+                        "name": "platform",
                         "in": "query"
                     },
                     {
-                        "type": "integer",
-                        "description": "最大数量",
-                        "name": "maxKeys",
+                        "type": "file",
+                        "description": "原图",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "蒙版，透明区域为需要编辑的部分",
+                        "name": "mask",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "提示词",
+                        "name": "prompt",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型",
+                        "name": "model",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "生成数量",
+                        "name": "n",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "尺寸",
+                        "name": "size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "url 或 b64_json",
+                        "name": "response_format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否转存到 OSS",
+                        "name": "persist",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIImageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ai/v1/images/generations": {
+            "post": {
+                "description": "OpenAI兼容的图片生成接口，支持 n、size、quality、style、response_format，persist 为 true 时转存到 OSS",
+                "tags": [
+                    "AI"
+                ],
+                "summary": "图片生成",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "平台",
+                        "name": "platform",
                         "in": "query"
+                    },
+                    {
+                        "description": "图片生成请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIImageRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FileListResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIImageResponse"
                         }
                     }
                 }
             }
         },
-        "/oss/hashify": {
+        "/ai/v1/images/variations": {
             "post": {
-                "description": "生成哈希文件名，防止重名",
+                "description": "OpenAI兼容的图片变体接口，multipart/form-data 上传原图",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "tags": [
-                    "OSS"
+                    "AI"
                 ],
-                "summary": "生成哈希文件名",
-                "operationId": "hashifyName",
+                "summary": "图片变体",
                 "parameters": [
                     {
-                        "description": "文件名请求",
+                        "type": "string",
+                        "description": "平台",
+                        "name": "platform",
+                        "in": "query"
+                    },
+                    {
+                        "type": "file",
+                        "description": "原图",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "模型",
+                        "name": "model",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "生成数量",
+                        "name": "n",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "尺寸",
+                        "name": "size",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "url 或 b64_json",
+                        "name": "response_format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "是否转存到 OSS",
+                        "name": "persist",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIImageResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ai/v1/models": {
+            "get": {
+                "description": "OpenAI兼容的模型列表，汇总各平台可用模型，管理员设置的别名也作为模型ID返回",
+                "tags": [
+                    "AI"
+                ],
+                "summary": "OpenAI兼容模型列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIModelList"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/feed/comments/{comment_id}": {
+            "put": {
+                "description": "只能编辑自己发布的评论，编辑后记录编辑时间",
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "编辑评论",
+                "operationId": "editFeedComment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "评论ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "评论内容",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.HashifyNameRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.UpdateFeedCommentRequest"
                         }
                     }
                 ],
@@ -1286,7 +1365,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.HashifyNameResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
                                         }
                                     }
                                 }
@@ -1294,24 +1373,56 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "delete": {
+                "description": "作者或拥有信息流管理权限的用户可以删除评论，有回复的评论保留占位",
+                "tags": [
+                    "Feed"
+                ],
+                "summary": "删除评论",
+                "operationId": "deleteFeedComment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "评论ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
             }
         },
-        "/oss/sign-to-fetch": {
+        "/api/feed/comments/{comment_id}/like": {
             "post": {
-                "description": "生成获取签名，用于下载文件",
+                "description": "设置评论点赞或取消点赞状态，需要登录",
                 "tags": [
-                    "OSS"
+                    "Feed"
                 ],
-                "summary": "生成获取签名",
-                "operationId": "signToFetch",
+                "summary": "设置评论点赞状态",
+                "operationId": "setFeedCommentLike",
                 "parameters": [
                     {
-                        "description": "签名请求",
+                        "type": "string",
+                        "description": "评论ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "点赞状态",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.SignRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.SetFeedCommentLikeRequest"
                         }
                     }
                 ],
@@ -1327,7 +1438,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.SignResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.LikeResult"
                                         }
                                     }
                                 }
@@ -1337,23 +1448,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/oss/sign-to-upload": {
-            "post": {
-                "description": "生成上传签名，用于上传文件",
+        "/api/feed/comments/{comment_id}/replies": {
+            "get": {
+                "description": "获取指定评论的直接回复，按时间正序，支持cursor分页",
                 "tags": [
-                    "OSS"
+                    "Feed"
                 ],
-                "summary": "生成上传签名",
-                "operationId": "signToUpload",
+                "summary": "获取评论回复列表",
+                "operationId": "getFeedCommentReplies",
                 "parameters": [
                     {
-                        "description": "签名请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.SignRequest"
-                        }
+                        "type": "string",
+                        "description": "评论ID",
+                        "name": "comment_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1368,7 +1491,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.SignResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedCommentResponse"
                                         }
                                     }
                                 }
@@ -1378,14 +1501,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/oss/sts": {
-            "get": {
-                "description": "获取STS临时凭证，用于上传文件，或者浏览",
+        "/api/feed/media/upload-url": {
+            "post": {
+                "description": "服务端在当前用户的目录下生成对象键并返回上传签名，用签名地址 PUT 上传后，发帖时在 media 中传返回的 object_key",
                 "tags": [
-                    "OSS"
+                    "Feed"
+                ],
+                "summary": "申请上传帖子图片",
+                "operationId": "signFeedMediaUpload",
+                "parameters": [
+                    {
+                        "description": "文件信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedMediaUploadRequest"
+                        }
+                    }
                 ],
-                "summary": "STS临时凭证",
-                "operationId": "getStsCredentials",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1398,7 +1532,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.STSResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.SignResponse"
                                         }
                                     }
                                 }
@@ -1408,42 +1542,48 @@ const docTemplate = `{
                 }
             }
         },
-        "/oss/upload": {
-            "post": {
-                "description": "直接上传文件到OSS",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+        "/api/feed/posts": {
+            "get": {
+                "description": "支持多种排序方式和cursor分页",
                 "tags": [
-                    "OSS"
+                    "Feed"
                 ],
-                "summary": "直接上传文件",
-                "operationId": "uploadFile",
+                "summary": "获取信息流帖子列表",
+                "operationId": "getFeedPosts",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "文件",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "文件名",
-                        "name": "fileName",
-                        "in": "formData"
+                        "maximum": 20,
+                        "minimum": 0,
+                        "type": "integer",
+                        "description": "预载评论数量，0表示不预载，最多20条",
+                        "name": "comment_count",
+                        "in": "query"
                     },
                     {
-                        "type": "string",
-                        "description": "前缀",
-                        "name": "prefix",
-                        "in": "formData"
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
+                        "enum": [
+                            "time",
+                            "like",
+                            "comment",
+                            "hot"
+                        ],
                         "type": "string",
-                        "description": "不预览",
-                        "name": "noPreview",
-                        "in": "formData"
+                        "description": "排序类型：time, like, comment, hot（按时间衰减的热度）",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1458,7 +1598,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.FileUploadResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPostResponse"
                                         }
                                     }
                                 }
@@ -1466,23 +1606,22 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/oss/url": {
+            },
             "post": {
+                "description": "创建新的信息流帖子，需要登录",
                 "tags": [
-                    "OSS"
+                    "Feed"
                 ],
-                "summary": "获取文件URL",
-                "operationId": "getFileURL",
+                "summary": "创建信息流帖子",
+                "operationId": "createFeedPost",
                 "parameters": [
                     {
-                        "description": "URL请求",
+                        "description": "帖子内容",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.GetURLRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.CreateFeedPostRequest"
                         }
                     }
                 ],
@@ -1498,7 +1637,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.GetURLResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPost"
                                         }
                                     }
                                 }
@@ -1508,39 +1647,21 @@ const docTemplate = `{
                 }
             }
         },
-        "/test/err": {
+        "/api/feed/posts/{post_id}": {
             "get": {
-                "description": "用于测试前端对各种HTTP状态码和错误响应的处理，支持通过参数指定错误类型",
+                "description": "获取指定帖子的详细信息",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试各种错误码",
+                "summary": "获取帖子详情",
+                "operationId": "getFeedPostDetail",
                 "parameters": [
-                    {
-                        "enum": [
-                            "400",
-                            "401",
-                            "403",
-                            "404",
-                            "405",
-                            "409",
-                            "413",
-                            "429",
-                            "500",
-                            "503",
-                            "validation",
-                            "success"
-                        ],
-                        "type": "string",
-                        "description": "错误类型",
-                        "name": "type",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "自定义错误消息",
-                        "name": "message",
-                        "in": "query"
+                        "description": "帖子ID",
+                        "name": "post_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1555,8 +1676,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPost"
                                         }
                                     }
                                 }
@@ -1565,20 +1685,28 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "测试POST请求的各种错误情况，包括JSON解析错误、参数验证等",
+            "put": {
+                "description": "只能编辑自己发布的帖子，整体替换文字、图片和媒体附件，编辑后记录编辑时间",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试POST请求错误处理",
+                "summary": "编辑帖子",
+                "operationId": "editFeedPost",
                 "parameters": [
                     {
-                        "description": "测试请求体",
+                        "type": "string",
+                        "description": "帖子ID",
+                        "name": "post_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "帖子内容",
                         "name": "request",
                         "in": "body",
+                        "required": true,
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/ai-models-backend_internal_models.UpdateFeedPostRequest"
                         }
                     }
                 ],
@@ -1594,8 +1722,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPost"
                                         }
                                     }
                                 }
@@ -1603,27 +1730,19 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/test/err/business/{type}": {
-            "get": {
-                "description": "模拟各种业务逻辑错误",
+            },
+            "delete": {
+                "description": "作者或拥有信息流管理权限的用户可以删除帖子，评论、点赞和媒体附件一并删除",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试业务错误",
+                "summary": "删除帖子",
+                "operationId": "deleteFeedPost",
                 "parameters": [
                     {
-                        "enum": [
-                            "validation",
-                            "auth",
-                            "permission",
-                            "quota",
-                            "maintenance"
-                        ],
                         "type": "string",
-                        "description": "业务错误类型",
-                        "name": "type",
+                        "description": "帖子ID",
+                        "name": "post_id",
                         "in": "path",
                         "required": true
                     }
@@ -1632,51 +1751,47 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
                         }
                     }
                 }
             }
         },
-        "/test/err/code/{code}": {
+        "/api/feed/posts/{post_id}/comments": {
             "get": {
-                "description": "根据路径参数返回对应的错误码，用于测试前端错误处理",
+                "description": "获取指定帖子的评论列表，支持cursor分页",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试特定错误码",
+                "summary": "获取帖子评论列表",
+                "operationId": "getFeedComments",
                 "parameters": [
                     {
-                        "enum": [
-                            "400",
-                            "401",
-                            "403",
-                            "404",
-                            "405",
-                            "409",
-                            "413",
-                            "429",
-                            "500",
-                            "503"
-                        ],
                         "type": "string",
-                        "description": "错误码",
-                        "name": "code",
+                        "description": "帖子ID",
+                        "name": "post_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "帖子ID，取自路径参数",
+                        "name": "-",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -1691,8 +1806,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedCommentResponse"
                                         }
                                     }
                                 }
@@ -1700,28 +1814,30 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/test/err/network/{type}": {
-            "get": {
-                "description": "模拟各种网络错误情况，如超时、连接失败等",
+            },
+            "post": {
+                "description": "为指定帖子创建评论，需要登录",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试网络错误模拟",
+                "summary": "创建帖子评论",
+                "operationId": "createFeedComment",
                 "parameters": [
                     {
-                        "enum": [
-                            "timeout",
-                            "connection",
-                            "dns",
-                            "ssl"
-                        ],
                         "type": "string",
-                        "description": "网络错误类型",
-                        "name": "type",
+                        "description": "帖子ID",
+                        "name": "post_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "评论内容",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.CreateFeedCommentRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1736,8 +1852,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
                                         }
                                     }
                                 }
@@ -1747,20 +1862,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/test/err/param/{id}": {
-            "get": {
-                "description": "测试带路径参数的请求错误处理",
+        "/api/feed/posts/{post_id}/like": {
+            "post": {
+                "description": "设置帖子点赞或取消点赞状态，需要登录",
                 "tags": [
-                    "Test"
+                    "Feed"
                 ],
-                "summary": "测试路径参数错误",
+                "summary": "设置帖子点赞状态",
+                "operationId": "setFeedPostLike",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "测试ID",
-                        "name": "id",
+                        "description": "帖子ID",
+                        "name": "post_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "点赞状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.SetFeedPostLikeRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -1775,8 +1900,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.LikeResult"
                                         }
                                     }
                                 }
@@ -1786,31 +1910,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos": {
+        "/api/feed/timeline": {
             "get": {
-                "description": "分页获取TODO列表，按position升序排列，支持按完成状态筛选",
+                "description": "获取关注的人和自己发布的帖子，按发布时间倒序，cursor分页与帖子列表相同，需要登录",
                 "tags": [
-                    "TODO"
+                    "Feed"
                 ],
-                "summary": "获取TODO列表",
-                "operationId": "getTodoList",
+                "summary": "获取首页时间线",
+                "operationId": "getFeedTimeline",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "页码",
-                        "name": "page",
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
                         "in": "query"
                     },
                     {
+                        "maximum": 20,
+                        "minimum": 0,
                         "type": "integer",
-                        "description": "每页数量",
-                        "name": "limit",
+                        "description": "预载评论数量，0表示不预载，最多20条",
+                        "name": "comment_count",
                         "in": "query"
                     },
                     {
-                        "type": "boolean",
-                        "description": "完成状态",
-                        "name": "completed",
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
                         "in": "query"
                     }
                 ],
@@ -1826,8 +1954,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FeedPostResponse"
                                         }
                                     }
                                 }
@@ -1835,23 +1962,23 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/feed/users/{user_id}/follow": {
             "post": {
-                "description": "创建新的TODO项，支持设置标题、描述、优先级、位置和截止日期。不指定position时自动分配到最后",
+                "description": "关注指定用户，重复关注不报错，需要登录",
                 "tags": [
-                    "TODO"
+                    "Feed"
                 ],
-                "summary": "创建TODO",
-                "operationId": "createTodo",
+                "summary": "关注用户",
+                "operationId": "followUser",
                 "parameters": [
                     {
-                        "description": "创建请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoCreateRequest"
-                        }
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1866,7 +1993,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FollowResult"
                                         }
                                     }
                                 }
@@ -1874,25 +2001,21 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/todos/positions": {
-            "put": {
-                "description": "批量更新TODO项的位置，支持拖拽排序功能",
+            },
+            "delete": {
+                "description": "取消关注指定用户，未关注时不报错，需要登录",
                 "tags": [
-                    "TODO"
+                    "Feed"
                 ],
-                "summary": "批量更新TODO位置",
-                "operationId": "updateTodoPositions",
+                "summary": "取消关注用户",
+                "operationId": "unfollowUser",
                 "parameters": [
                     {
-                        "description": "位置更新请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoPositionUpdateRequest"
-                        }
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
@@ -1907,8 +2030,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FollowResult"
                                         }
                                     }
                                 }
@@ -1918,14 +2040,23 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/rebalance": {
-            "post": {
-                "description": "重新平衡所有TODO项的位置值，当位置值变得过小时使用",
+        "/api/feed/users/{user_id}/follow-stats": {
+            "get": {
+                "description": "获取指定用户的关注数、粉丝数以及与当前用户的关注关系，需要登录",
                 "tags": [
-                    "TODO"
+                    "Feed"
+                ],
+                "summary": "获取关注统计",
+                "operationId": "getFollowStats",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "重新平衡TODO位置",
-                "operationId": "rebalanceTodoPositions",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1938,8 +2069,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FollowStats"
                                         }
                                     }
                                 }
@@ -1949,14 +2079,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/stats": {
+        "/api/feed/users/{user_id}/followers": {
             "get": {
-                "description": "获取TODO的统计信息，包括总数、完成数、待完成数",
+                "description": "获取关注了指定用户的用户，按关注时间倒序，支持cursor分页",
                 "tags": [
-                    "TODO"
+                    "Feed"
+                ],
+                "summary": "获取粉丝列表",
+                "operationId": "getFollowers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
+                        "in": "query"
+                    }
                 ],
-                "summary": "获取TODO统计信息",
-                "operationId": "getTodoStats",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -1969,8 +2122,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FollowListResponse"
                                         }
                                     }
                                 }
@@ -1980,21 +2132,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/{id}": {
+        "/api/feed/users/{user_id}/following": {
             "get": {
-                "description": "根据唯一标识符获取指定的TODO项详细信息",
+                "description": "获取指定用户关注的用户，按关注时间倒序，支持cursor分页",
                 "tags": [
-                    "TODO"
+                    "Feed"
                 ],
-                "summary": "根据ID获取TODO",
-                "operationId": "getTodoByID",
+                "summary": "获取关注列表",
+                "operationId": "getFollowing",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "TODO ID",
-                        "name": "id",
+                        "description": "用户ID",
+                        "name": "user_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "上一页返回的 next_cursor",
+                        "name": "after_id",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "description": "每页数量，最多50",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2009,7 +2175,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FollowListResponse"
                                         }
                                     }
                                 }
@@ -2017,30 +2183,34 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "根据ID更新现有TODO项的内容，支持更新标题、描述、完成状态、优先级、位置等",
+            }
+        },
+        "/crud": {
+            "get": {
+                "description": "分页获取数据记录列表，支持按分类筛选，返回分页信息和记录数据",
                 "tags": [
-                    "TODO"
+                    "CRUD"
                 ],
-                "summary": "更新TODO",
-                "operationId": "updateTodo",
+                "summary": "获取记录列表",
+                "operationId": "getList",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "TODO ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "description": "更新请求",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoUpdateRequest"
-                        }
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "分类",
+                        "name": "category",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -2055,7 +2225,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -2064,20 +2235,22 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
-                "description": "根据ID永久删除指定的TODO项，操作不可逆",
+            "post": {
+                "description": "创建新的数据记录，支持分类，数据是字符串，前端自行管理决定是否要json parse",
                 "tags": [
-                    "TODO"
+                    "CRUD"
                 ],
-                "summary": "删除TODO",
-                "operationId": "deleteTodo",
+                "summary": "创建记录",
+                "operationId": "create",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "TODO ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -2092,8 +2265,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
                                         }
                                     }
                                 }
@@ -2103,18 +2275,18 @@ const docTemplate = `{
                 }
             }
         },
-        "/todos/{id}/toggle": {
-            "patch": {
-                "description": "快速切换TODO项的完成状态（完成/未完成）",
+        "/crud/{id}": {
+            "get": {
+                "description": "根据唯一标识符获取指定的数据记录详细信息",
                 "tags": [
-                    "TODO"
+                    "CRUD"
                 ],
-                "summary": "切换TODO完成状态",
-                "operationId": "toggleTodoComplete",
+                "summary": "根据ID获取记录",
+                "operationId": "getByID",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "TODO ID",
+                        "description": "记录ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -2132,7 +2304,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
                                         }
                                     }
                                 }
@@ -2140,23 +2312,29 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/change-password": {
-            "post": {
-                "description": "用户修改自己的登录密码，需要提供旧密码进行验证",
+            },
+            "put": {
+                "description": "根据ID更新现有数据记录的内容，支持更新数据和分类信息",
                 "tags": [
-                    "User"
+                    "CRUD"
                 ],
-                "summary": "修改密码",
+                "summary": "更新记录",
+                "operationId": "update",
                 "parameters": [
                     {
-                        "description": "修改密码请求",
-                        "name": "request",
+                        "type": "string",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新请求",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.ChangePasswordRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudUpdateRequest"
                         }
                     }
                 ],
@@ -2172,8 +2350,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.CrudResponse"
                                         }
                                     }
                                 }
@@ -2181,28 +2358,20 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/check-field": {
-            "get": {
-                "description": "检查用户对应的字段是否存在（通常是email和username）",
+            },
+            "delete": {
+                "description": "根据ID永久删除指定的数据记录，操作不可逆，请谨慎使用",
                 "tags": [
-                    "Auth"
+                    "CRUD"
                 ],
-                "summary": "检查用户字段是否存在",
+                "summary": "删除记录",
+                "operationId": "delete",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "字段名",
-                        "name": "field",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "字段值",
-                        "name": "value",
-                        "in": "query",
+                        "description": "记录ID",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -2218,7 +2387,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "boolean"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -2228,22 +2398,57 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "用户使用用户名和密码登录系统，验证成功后返回JWT token和用户信息",
+        "/metrics/redis": {
+            "get": {
+                "description": "获取Redis内存使用、缓存命中率等监控指标",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Auth"
+                    "metrics"
                 ],
-                "summary": "用户登录",
-                "operationId": "login",
+                "summary": "获取Redis监控指标",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/internal_handlers.RedisMetricsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/oss/delete": {
+            "delete": {
+                "description": "删除OSS文件",
+                "tags": [
+                    "OSS"
+                ],
+                "summary": "删除",
+                "operationId": "delete",
                 "parameters": [
                     {
-                        "description": "登录请求",
+                        "description": "删除请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.DeleteFileRequest"
                         }
                     }
                 ],
@@ -2259,7 +2464,8 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginResponse"
+                                            "type": "object",
+                                            "additionalProperties": true
                                         }
                                     }
                                 }
@@ -2269,13 +2475,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/logout": {
-            "post": {
-                "description": "用户主动退出登录，清除服务端的登录状态和token",
+        "/oss/files": {
+            "get": {
+                "description": "获取OSS文件列表",
                 "tags": [
-                    "Auth"
+                    "OSS"
+                ],
+                "summary": "获取文件列表",
+                "operationId": "getFileList",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "前缀",
+                        "name": "prefix",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "最大数量",
+                        "name": "maxKeys",
+                        "in": "query"
+                    }
                 ],
-                "summary": "用户退出登录",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -2288,8 +2509,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "object",
-                                            "additionalProperties": true
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FileListResponse"
                                         }
                                     }
                                 }
@@ -2299,14 +2519,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/profile": {
-            "get": {
-                "description": "获取当前登录用户的个人资料信息，包括基本信息、角色等",
+        "/oss/hashify": {
+            "post": {
+                "description": "生成哈希文件名，防止重名",
                 "tags": [
-                    "User"
+                    "OSS"
+                ],
+                "summary": "生成哈希文件名",
+                "operationId": "hashifyName",
+                "parameters": [
+                    {
+                        "description": "文件名请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.HashifyNameRequest"
+                        }
+                    }
                 ],
-                "summary": "获取用户信息",
-                "operationId": "getProfile",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -2319,42 +2550,32 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.HashifyNameResponse"
                                         }
                                     }
                                 }
                             ]
                         }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
-                        }
                     }
                 }
-            },
-            "put": {
-                "description": "更新当前登录用户的个人资料，如用户名、邮箱、头像等信息",
+            }
+        },
+        "/oss/sign-to-fetch": {
+            "post": {
+                "description": "生成获取签名，用于下载文件",
                 "tags": [
-                    "User"
+                    "OSS"
                 ],
-                "summary": "更新用户信息",
-                "operationId": "updateProfile",
+                "summary": "生成获取签名",
+                "operationId": "signToFetch",
                 "parameters": [
                     {
-                        "description": "更新请求",
+                        "description": "签名请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.UserUpdateRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.SignRequest"
                         }
                     }
                 ],
@@ -2370,7 +2591,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.SignResponse"
                                         }
                                     }
                                 }
@@ -2380,22 +2601,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/register": {
+        "/oss/sign-to-upload": {
             "post": {
-                "description": "新用户注册账号，创建用户账户并返回JWT token，用于后续身份认证",
+                "description": "生成上传签名，用于上传文件",
                 "tags": [
-                    "Auth"
+                    "OSS"
                 ],
-                "summary": "用户注册",
-                "operationId": "register",
+                "summary": "生成上传签名",
+                "operationId": "signToUpload",
                 "parameters": [
                     {
-                        "description": "注册请求",
+                        "description": "签名请求",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/ai-models-backend_internal_models.UserCreateRequest"
+                            "$ref": "#/definitions/ai-models-backend_internal_models.SignRequest"
                         }
                     }
                 ],
@@ -2411,7 +2632,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserCreateResponse"
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.SignResponse"
                                         }
                                     }
                                 }
@@ -2420,101 +2641,2986 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "ai-models-backend_internal_models.AIModel": {
-            "type": "object",
-            "properties": {
-                "capabilities": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+        },
+        "/oss/sts": {
+            "get": {
+                "description": "获取STS临时凭证，用于上传文件，或者浏览",
+                "tags": [
+                    "OSS"
+                ],
+                "summary": "STS临时凭证",
+                "operationId": "getStsCredentials",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.STSResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
                     }
-                },
-                "description": {
-                    "type": "string"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "is_active": {
-                    "type": "boolean"
-                },
-                "max_tokens": {
-                    "type": "integer"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "provider": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.ChangePasswordRequest": {
-            "type": "object",
-            "required": [
+        "/oss/upload": {
+            "post": {
+                "description": "直接上传文件到OSS",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "tags": [
+                    "OSS"
+                ],
+                "summary": "直接上传文件",
+                "operationId": "uploadFile",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "文件名",
+                        "name": "fileName",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "前缀",
+                        "name": "prefix",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "不预览",
+                        "name": "noPreview",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.FileUploadResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/oss/url": {
+            "post": {
+                "tags": [
+                    "OSS"
+                ],
+                "summary": "获取文件URL",
+                "operationId": "getFileURL",
+                "parameters": [
+                    {
+                        "description": "URL请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.GetURLRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.GetURLResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/test/err": {
+            "get": {
+                "description": "用于测试前端对各种HTTP状态码和错误响应的处理，支持通过参数指定错误类型",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试各种错误码",
+                "parameters": [
+                    {
+                        "enum": [
+                            "400",
+                            "401",
+                            "403",
+                            "404",
+                            "405",
+                            "409",
+                            "413",
+                            "429",
+                            "500",
+                            "503",
+                            "validation",
+                            "success"
+                        ],
+                        "type": "string",
+                        "description": "错误类型",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "自定义错误消息",
+                        "name": "message",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "测试POST请求的各种错误情况，包括JSON解析错误、参数验证等",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试POST请求错误处理",
+                "parameters": [
+                    {
+                        "description": "测试请求体",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/test/err/business/{type}": {
+            "get": {
+                "description": "模拟各种业务逻辑错误",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试业务错误",
+                "parameters": [
+                    {
+                        "enum": [
+                            "validation",
+                            "auth",
+                            "permission",
+                            "quota",
+                            "maintenance"
+                        ],
+                        "type": "string",
+                        "description": "业务错误类型",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/test/err/code/{code}": {
+            "get": {
+                "description": "根据路径参数返回对应的错误码，用于测试前端错误处理",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试特定错误码",
+                "parameters": [
+                    {
+                        "enum": [
+                            "400",
+                            "401",
+                            "403",
+                            "404",
+                            "405",
+                            "409",
+                            "413",
+                            "429",
+                            "500",
+                            "503"
+                        ],
+                        "type": "string",
+                        "description": "错误码",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/test/err/network/{type}": {
+            "get": {
+                "description": "模拟各种网络错误情况，如超时、连接失败等",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试网络错误模拟",
+                "parameters": [
+                    {
+                        "enum": [
+                            "timeout",
+                            "connection",
+                            "dns",
+                            "ssl"
+                        ],
+                        "type": "string",
+                        "description": "网络错误类型",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/test/err/param/{id}": {
+            "get": {
+                "description": "测试带路径参数的请求错误处理",
+                "tags": [
+                    "Test"
+                ],
+                "summary": "测试路径参数错误",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "测试ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos": {
+            "get": {
+                "description": "分页获取TODO列表，按position升序排列，支持按完成状态筛选",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "获取TODO列表",
+                "operationId": "getTodoList",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页数量",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "完成状态",
+                        "name": "completed",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建新的TODO项，支持设置标题、描述、优先级、位置和截止日期。不指定position时自动分配到最后",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "创建TODO",
+                "operationId": "createTodo",
+                "parameters": [
+                    {
+                        "description": "创建请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/positions": {
+            "put": {
+                "description": "批量更新TODO项的位置，支持拖拽排序功能",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "批量更新TODO位置",
+                "operationId": "updateTodoPositions",
+                "parameters": [
+                    {
+                        "description": "位置更新请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoPositionUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/rebalance": {
+            "post": {
+                "description": "重新平衡所有TODO项的位置值，当位置值变得过小时使用",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "重新平衡TODO位置",
+                "operationId": "rebalanceTodoPositions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/stats": {
+            "get": {
+                "description": "获取TODO的统计信息，包括总数、完成数、待完成数",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "获取TODO统计信息",
+                "operationId": "getTodoStats",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}": {
+            "get": {
+                "description": "根据唯一标识符获取指定的TODO项详细信息",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "根据ID获取TODO",
+                "operationId": "getTodoByID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TODO ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "根据ID更新现有TODO项的内容，支持更新标题、描述、完成状态、优先级、位置等",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "更新TODO",
+                "operationId": "updateTodo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TODO ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "更新请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "根据ID永久删除指定的TODO项，操作不可逆",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "删除TODO",
+                "operationId": "deleteTodo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TODO ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/toggle": {
+            "patch": {
+                "description": "快速切换TODO项的完成状态（完成/未完成）",
+                "tags": [
+                    "TODO"
+                ],
+                "summary": "切换TODO完成状态",
+                "operationId": "toggleTodoComplete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "TODO ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TodoResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa": {
+            "get": {
+                "description": "获取当前用户是否启用了两步验证、剩余恢复码数量，以及角色是否要求两步验证",
+                "tags": [
+                    "User"
+                ],
+                "summary": "两步验证状态",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorStatus"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/disable": {
+            "post": {
+                "description": "需要密码和验证码（或恢复码），角色要求两步验证时不能关闭",
+                "tags": [
+                    "User"
+                ],
+                "summary": "关闭两步验证",
+                "parameters": [
+                    {
+                        "description": "关闭请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorDisableRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/enable": {
+            "post": {
+                "description": "输入身份验证器中的验证码确认绑定，返回只显示一次的恢复码；重新登录后会话才视为已通过两步验证",
+                "tags": [
+                    "User"
+                ],
+                "summary": "启用两步验证",
+                "parameters": [
+                    {
+                        "description": "验证码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorRecoveryCodesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/recovery-codes": {
+            "post": {
+                "description": "输入身份验证器中的验证码，生成新的恢复码，旧的恢复码全部失效",
+                "tags": [
+                    "User"
+                ],
+                "summary": "重新生成恢复码",
+                "parameters": [
+                    {
+                        "description": "验证码",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorCodeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorRecoveryCodesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/2fa/setup": {
+            "post": {
+                "description": "生成新的 TOTP 密钥和 otpauth:// 地址（用于生成二维码），之后需要调用启用接口确认",
+                "tags": [
+                    "User"
+                ],
+                "summary": "绑定身份验证器",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorSetupResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/account/deletion": {
+            "post": {
+                "description": "需要确认身份：设置过密码的账号传密码；第三方登录创建、没有密码的账号（password_generated 为 true）传两步验证码，未启用两步验证时需在重新登录后10分钟内申请。全部设备立即退出登录；宽限期内重新登录可以撤销，到期后账号及全部数据被永久删除",
+                "tags": [
+                    "User"
+                ],
+                "summary": "申请注销账号",
+                "parameters": [
+                    {
+                        "description": "注销请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.AccountDeletionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.AccountDeletionResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "在宽限期内撤销注销申请",
+                "tags": [
+                    "User"
+                ],
+                "summary": "撤销注销账号",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/api-keys": {
+            "get": {
+                "description": "获取当前用户的全部 API Key（不含明文）",
+                "tags": [
+                    "User"
+                ],
+                "summary": "API Key 列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.APIKey"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "创建个人 API Key，用于以 Bearer 方式调用 /ai/v1 接口，明文只返回一次",
+                "tags": [
+                    "User"
+                ],
+                "summary": "创建 API Key",
+                "parameters": [
+                    {
+                        "description": "创建 API Key 请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.APIKeyCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.APIKeyCreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/api-keys/{id}": {
+            "delete": {
+                "description": "吊销指定的 API Key，立即失效",
+                "tags": [
+                    "User"
+                ],
+                "summary": "吊销 API Key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/change-password": {
+            "post": {
+                "description": "用户修改自己的登录密码，需要提供旧密码进行验证，新密码需符合密码策略",
+                "tags": [
+                    "User"
+                ],
+                "summary": "修改密码",
+                "parameters": [
+                    {
+                        "description": "修改密码请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/check-field": {
+            "get": {
+                "description": "检查用户对应的字段是否存在（通常是email和username）",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "检查用户字段是否存在",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "字段名",
+                        "name": "field",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "字段值",
+                        "name": "value",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "boolean"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/export": {
+            "get": {
+                "description": "下载 ZIP 文件，包含个人资料、待办、帖子、评论和 AI 对话记录，每类数据一个 JSON 文件",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "导出个人数据",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/forgot-password": {
+            "post": {
+                "description": "向邮箱发送重置密码链接，无论邮箱是否注册都返回成功",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "忘记密码",
+                "operationId": "forgotPassword",
+                "parameters": [
+                    {
+                        "description": "忘记密码请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities": {
+            "get": {
+                "description": "获取当前用户绑定的第三方账号",
+                "tags": [
+                    "User"
+                ],
+                "summary": "已绑定的第三方账号",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.UserIdentity"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities/{provider}": {
+            "post": {
+                "description": "用第三方回调的 code 和 state 为当前用户绑定第三方账号",
+                "tags": [
+                    "User"
+                ],
+                "summary": "绑定第三方账号",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "第三方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "回调参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OAuthCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserIdentity"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "解绑当前用户的第三方账号；邮箱未验证时不能解绑最后一个第三方账号",
+                "tags": [
+                    "User"
+                ],
+                "summary": "解绑第三方账号",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "第三方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/identities/{provider}/authorize": {
+            "get": {
+                "description": "为当前用户绑定第三方账号，生成的 state 只能用于绑定接口",
+                "tags": [
+                    "User"
+                ],
+                "summary": "绑定第三方账号授权地址",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "第三方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.OAuthAuthorizeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "用户使用用户名和密码登录系统，验证成功后返回 access token、refresh token 和用户信息；启用了两步验证时返回 two_factor_required 和 challenge_token，需要再调用 /users/login/2fa；连续失败后按账号和 IP 延迟或临时锁定，返回 429",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户登录",
+                "operationId": "login",
+                "parameters": [
+                    {
+                        "description": "登录请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/2fa": {
+            "post": {
+                "description": "密码登录返回 two_factor_required 时，用 challenge_token 和身份验证器的验证码（或恢复码）换取令牌",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "两步验证登录",
+                "operationId": "loginTwoFactor",
+                "parameters": [
+                    {
+                        "description": "两步验证请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.TwoFactorLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/logout": {
+            "post": {
+                "description": "用户主动退出当前设备的登录，当前 access token 和 refresh token 立即失效",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户退出登录",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/logout-all": {
+            "post": {
+                "description": "退出当前用户在所有设备上的登录，全部 access token 和 refresh token 立即失效",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "退出所有设备",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oauth/providers": {
+            "get": {
+                "description": "获取已启用的第三方登录名称，如 github、oidc",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "第三方登录列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "type": "string"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oauth/{provider}/authorize": {
+            "get": {
+                "description": "生成第三方授权页地址，前端跳转后第三方回调到 OAUTH_REDIRECT_URL/{provider}，再将 code 和 state 提交到回调接口",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "第三方登录授权地址",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "第三方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.OAuthAuthorizeResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/oauth/{provider}/callback": {
+            "post": {
+                "description": "用第三方回调的 code 和 state 登录，首次登录时自动创建账号，返回与密码登录相同的令牌；启用了两步验证时返回 two_factor_required",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "第三方登录回调",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "第三方名称",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "回调参数",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.OAuthCallbackRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserLoginResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/profile": {
+            "get": {
+                "description": "获取当前登录用户的个人资料信息，包括基本信息、角色等",
+                "tags": [
+                    "User"
+                ],
+                "summary": "获取用户信息",
+                "operationId": "getProfile",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "更新当前登录用户的个人资料，如用户名、邮箱、头像等信息",
+                "tags": [
+                    "User"
+                ],
+                "summary": "更新用户信息",
+                "operationId": "updateProfile",
+                "parameters": [
+                    {
+                        "description": "更新请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.UserUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/refresh": {
+            "post": {
+                "description": "使用 refresh token 换取新的 access token 和 refresh token，旧的 refresh token 随即失效；重复使用已失效的 refresh token 会吊销该登录",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "刷新令牌",
+                "operationId": "refreshToken",
+                "parameters": [
+                    {
+                        "description": "刷新令牌请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.AuthTokens"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "新用户注册账号，创建用户账户并返回 access token 和 refresh token，用于后续身份认证",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "用户注册",
+                "operationId": "register",
+                "parameters": [
+                    {
+                        "description": "注册请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.UserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserCreateResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/resend-verification": {
+            "post": {
+                "description": "向当前用户的邮箱重新发送验证邮件，旧的验证链接仍然有效",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "重新发送验证邮件",
+                "operationId": "resendVerification",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/reset-password": {
+            "post": {
+                "description": "使用重置密码邮件中的令牌设置新密码，令牌只能使用一次，成功后所有设备需要重新登录",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "重置密码",
+                "operationId": "resetPassword",
+                "parameters": [
+                    {
+                        "description": "重置密码请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "object",
+                                            "additionalProperties": true
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions": {
+            "get": {
+                "description": "获取当前用户有效的登录会话，包括设备、User-Agent、登录 IP 和最近活跃时间，current 标记当前会话",
+                "tags": [
+                    "User"
+                ],
+                "summary": "登录设备列表",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/ai-models-backend_internal_models.UserSession"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/users/sessions/{id}": {
+            "delete": {
+                "description": "吊销当前用户的某个登录会话，该设备上的 access token 和 refresh token 立即失效",
+                "tags": [
+                    "User"
+                ],
+                "summary": "退出指定设备",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "会话ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/verify-email": {
+            "post": {
+                "description": "使用验证邮件中的令牌完成邮箱验证，令牌只能使用一次",
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "验证邮箱",
+                "operationId": "verifyEmail",
+                "parameters": [
+                    {
+                        "description": "邮箱验证请求",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ai-models-backend_internal_models.VerifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/ai-models-backend_pkg_response.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/ai-models-backend_internal_models.UserResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "ai-models-backend_internal_models.AIModel": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "context_length": {
+                    "description": "上下文长度，未知时为 0",
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "max_tokens": {
+                    "description": "最大输出 token，未知时为 0",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "platforms": {
+                    "description": "提供该模型的平台",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "pricing": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.AIModelPricing"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "chat, embedding, image, audio, rerank",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AIModelOverride": {
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "description": "对外的别名，请求时解析为 ModelID",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "disabled": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model_id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AIModelOverrideRequest": {
+            "type": "object",
+            "required": [
+                "model_id"
+            ],
+            "properties": {
+                "alias": {
+                    "description": "传空字符串清除别名",
+                    "type": "string",
+                    "maxLength": 128
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "model_id": {
+                    "type": "string",
+                    "maxLength": 128
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AIModelPricing": {
+            "type": "object",
+            "properties": {
+                "currency": {
+                    "description": "USD, CNY",
+                    "type": "string"
+                },
+                "input": {
+                    "type": "number"
+                },
+                "output": {
+                    "type": "number"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AIQuotaResponse": {
+            "type": "object",
+            "properties": {
+                "daily": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.AIQuotaStatus"
+                },
+                "monthly": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.AIQuotaStatus"
+                },
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AIQuotaStatus": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "0 表示不限",
+                    "type": "integer"
+                },
+                "remaining": {
+                    "description": "不限时为 -1",
+                    "type": "integer"
+                },
+                "reset_at": {
+                    "type": "string"
+                },
+                "used": {
+                    "description": "已用 token",
+                    "type": "integer"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "为空表示永不过期",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "明文前几位，便于用户辨认",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.APIKeyCreateRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "expires_in_days": {
+                    "description": "为空表示永不过期",
+                    "type": "integer",
+                    "maximum": 3650,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "ai-models-backend_internal_models.APIKeyCreateResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "为空表示永不过期",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "description": "明文前几位，便于用户辨认",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AccountDeletionRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "没有密码的第三方登录账号可以用两步验证码确认，未启用两步验证时需要重新登录后再申请",
+                    "type": "string"
+                },
+                "password": {
+                    "description": "设置过密码的账号必填",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AccountDeletionResponse": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "description": "到期后账号及全部数据被删除，之前可以撤销",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AssignRoleRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.AuthTokens": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
                 "new_password",
                 "old_password"
             ],
             "properties": {
-                "new_password": {
-                    "type": "string",
-                    "minLength": 6
-                },
-                "old_password": {
+                "new_password": {
+                    "description": "长度和强度由密码策略校验",
+                    "type": "string"
+                },
+                "old_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ChatRequest": {
+            "type": "object",
+            "required": [
+                "message"
+            ],
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "description": "会话ID，为空时自动创建新会话",
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "stream": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ChatResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "platform": {
+                    "description": "实际提供服务的平台",
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "usage": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.Usage"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ChatSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "forked_from": {
+                    "description": "来源会话ID",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "last_message_at": {
+                    "type": "string"
+                },
+                "message_count": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ConversationHistory": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "session_id": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.CreateFeedCommentRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "description": "评论内容，最多500字符",
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "parent_id": {
+                    "description": "回复的评论ID，为空表示直接评论帖子",
+                    "type": "string"
+                },
+                "reply_to": {
+                    "description": "回复的用户名，回复评论时自动设置为被回复评论的作者",
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "ai-models-backend_internal_models.CreateFeedPostRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "最多2000字符",
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "image_url": {
+                    "description": "图片URL（旧版单图）",
+                    "type": "string"
+                },
+                "media": {
+                    "description": "媒体附件，最多9个",
+                    "type": "array",
+                    "maxItems": 9,
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedMediaRequest"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.CrudCreateRequest": {
+            "type": "object",
+            "required": [
+                "data"
+            ],
+            "properties": {
+                "category": {
+                    "description": "业务分类，默认为general",
+                    "type": "string"
+                },
+                "data": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.CrudResponse": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "data": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.CrudUpdateRequest": {
+            "type": "object",
+            "required": [
+                "data"
+            ],
+            "properties": {
+                "category": {
+                    "description": "业务分类",
+                    "type": "string"
+                },
+                "data": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.DeleteFileRequest": {
+            "type": "object",
+            "required": [
+                "object_key"
+            ],
+            "properties": {
+                "object_key": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedComment": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "description": "冗余头像",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deleted": {
+                    "description": "已删除但仍有回复，保留占位，内容和作者已清空",
+                    "type": "boolean"
+                },
+                "edited_at": {
+                    "description": "最后编辑时间，为空表示未编辑过",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "parent_id": {
+                    "description": "回复的评论ID，0 表示直接评论帖子",
+                    "type": "string"
+                },
+                "post_id": {
+                    "type": "string"
+                },
+                "reply_count": {
+                    "description": "直接回复数，包括仍有回复的已删除评论",
+                    "type": "integer"
+                },
+                "reply_to": {
+                    "description": "回复的用户名",
+                    "type": "string"
+                },
+                "root_id": {
+                    "description": "所在楼层的顶层评论ID，顶层评论为 0",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_profile_version": {
+                    "description": "用户信息版本号",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "冗余字段",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedCommentResponse": {
+            "type": "object",
+            "properties": {
+                "comments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
+                    }
+                },
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedMedia": {
+            "type": "object",
+            "properties": {
+                "blurhash": {
+                    "description": "加载前显示的模糊占位图",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "oss_key": {
+                    "description": "OSS对象键",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "在帖子中的顺序，从0开始",
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "访问地址",
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedMediaRequest": {
+            "type": "object",
+            "required": [
+                "height",
+                "oss_key",
+                "width"
+            ],
+            "properties": {
+                "blurhash": {
+                    "type": "string",
+                    "maxLength": 100
+                },
+                "height": {
+                    "description": "高度（像素）",
+                    "type": "integer",
+                    "maximum": 20000,
+                    "minimum": 1
+                },
+                "oss_key": {
+                    "description": "OSS对象键，申请上传时返回的 object_key",
+                    "type": "string",
+                    "maxLength": 500
+                },
+                "width": {
+                    "description": "宽度（像素）",
+                    "type": "integer",
+                    "maximum": 20000,
+                    "minimum": 1
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedMediaUploadRequest": {
+            "type": "object",
+            "required": [
+                "file_name",
+                "file_type"
+            ],
+            "properties": {
+                "file_name": {
+                    "description": "原始文件名，用于生成对象键",
+                    "type": "string",
+                    "maxLength": 200
+                },
+                "file_type": {
+                    "description": "文件类型，只能上传图片",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedPost": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "description": "冗余头像",
+                    "type": "string"
+                },
+                "comment_count": {
+                    "type": "integer"
+                },
+                "content": {
+                    "description": "文字内容（可选）",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "description": "最后编辑时间，为空表示未编辑过",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "== from BaseModel ==",
+                    "type": "string"
+                },
+                "image_url": {
+                    "description": "图片URL（可选，旧版单图，新帖子使用 Media）",
+                    "type": "string"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "media": {
+                    "description": "媒体附件，按 Position 排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedMedia"
+                    }
+                },
+                "status": {
+                    "description": "用户状态emoji",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_profile_version": {
+                    "description": "用户信息版本号",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "冗余字段",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedPostResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedPostResponseItem"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FeedPostResponseItem": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "description": "冗余头像",
+                    "type": "string"
+                },
+                "comment_count": {
+                    "type": "integer"
+                },
+                "comment_preview_count": {
+                    "description": "预载评论数量",
+                    "type": "integer"
+                },
+                "content": {
+                    "description": "文字内容（可选）",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "edited_at": {
+                    "description": "最后编辑时间，为空表示未编辑过",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "== from BaseModel ==",
+                    "type": "string"
+                },
+                "image_url": {
+                    "description": "图片URL（可选，旧版单图，新帖子使用 Media）",
+                    "type": "string"
+                },
+                "like_count": {
+                    "type": "integer"
+                },
+                "media": {
+                    "description": "媒体附件，按 Position 排序",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedMedia"
+                    }
+                },
+                "preloaded_comments": {
+                    "description": "预载评论列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
+                    }
+                },
+                "status": {
+                    "description": "用户状态emoji",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "user_profile_version": {
+                    "description": "用户信息版本号",
+                    "type": "integer"
+                },
+                "username": {
+                    "description": "冗余字段",
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FileInfo": {
+            "type": "object",
+            "properties": {
+                "last_modified": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "object_key": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FileListResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FileInfo"
+                    }
+                },
+                "is_truncated": {
+                    "type": "boolean"
+                },
+                "next_marker": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FileUploadResponse": {
+            "type": "object",
+            "properties": {
+                "hashify_name": {
+                    "description": "哈希化文件名",
+                    "type": "string"
+                },
+                "object_key": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "upload_time": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FollowListResponse": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FollowUser"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FollowResult": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "状态是否改变",
+                    "type": "boolean"
+                },
+                "is_following": {
+                    "description": "当前关注状态",
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FollowStats": {
+            "type": "object",
+            "properties": {
+                "follower_count": {
+                    "type": "integer"
+                },
+                "following_count": {
+                    "type": "integer"
+                },
+                "is_followed_by": {
+                    "description": "是否关注了当前用户",
+                    "type": "boolean"
+                },
+                "is_following": {
+                    "description": "当前用户是否已关注",
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.FollowUser": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "type": "string"
+                },
+                "followed_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ForgotPasswordRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.ChatMessage": {
+        "ai-models-backend_internal_models.ForkChatSessionRequest": {
             "type": "object",
             "properties": {
-                "content": {
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
-                "model": {
+                "message_id": {
+                    "description": "复制到该消息为止（包含），为空复制全部",
                     "type": "string"
                 },
-                "role": {
-                    "type": "string"
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
                 }
             }
         },
-        "ai-models-backend_internal_models.ChatRequest": {
+        "ai-models-backend_internal_models.GenerateRequest": {
             "type": "object",
             "required": [
-                "message"
+                "prompt"
             ],
             "properties": {
-                "message": {
-                    "type": "string"
-                },
                 "model": {
                     "type": "string"
                 },
-                "stream": {
-                    "type": "boolean"
+                "parameters": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "prompt": {
+                    "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.ChatResponse": {
+        "ai-models-backend_internal_models.GenerateResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "content": {
                     "type": "string"
                 },
-                "id": {
+                "created_at": {
                     "type": "string"
                 },
-                "message": {
+                "id": {
                     "type": "string"
                 },
                 "model": {
@@ -2525,522 +5631,571 @@ const docTemplate = `{
                 }
             }
         },
-        "ai-models-backend_internal_models.CreateFeedCommentRequest": {
+        "ai-models-backend_internal_models.GetURLRequest": {
             "type": "object",
             "required": [
-                "content"
+                "object_key"
             ],
             "properties": {
-                "content": {
-                    "description": "评论内容，最多500字符",
-                    "type": "string",
-                    "maxLength": 500
-                },
-                "reply_to": {
-                    "description": "回复的用户名",
-                    "type": "string",
-                    "maxLength": 100
+                "object_key": {
+                    "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.CreateFeedPostRequest": {
+        "ai-models-backend_internal_models.GetURLResponse": {
             "type": "object",
             "properties": {
-                "content": {
-                    "description": "最多2000字符",
-                    "type": "string",
-                    "maxLength": 2000
+                "object_key": {
+                    "type": "string"
                 },
-                "image_url": {
-                    "description": "图片URL",
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.CrudCreateRequest": {
+        "ai-models-backend_internal_models.HashifyNameRequest": {
             "type": "object",
             "required": [
-                "data"
+                "file_name"
             ],
             "properties": {
-                "category": {
-                    "description": "业务分类，默认为general",
+                "file_name": {
                     "type": "string"
-                },
-                "data": {
+                }
+            }
+        },
+        "ai-models-backend_internal_models.HashifyNameResponse": {
+            "type": "object",
+            "properties": {
+                "hashify_name": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.CrudResponse": {
+        "ai-models-backend_internal_models.JWK": {
             "type": "object",
             "properties": {
-                "category": {
+                "alg": {
                     "type": "string"
                 },
-                "created_at": {
+                "crv": {
                     "type": "string"
                 },
-                "data": {
+                "e": {
                     "type": "string"
                 },
-                "id": {
+                "kid": {
                     "type": "string"
                 },
-                "updated_at": {
+                "kty": {
+                    "type": "string"
+                },
+                "n": {
+                    "type": "string"
+                },
+                "use": {
+                    "type": "string"
+                },
+                "x": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.CrudUpdateRequest": {
+        "ai-models-backend_internal_models.JWKSet": {
             "type": "object",
-            "required": [
-                "data"
-            ],
             "properties": {
-                "category": {
-                    "description": "业务分类",
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.JWK"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.LikeResult": {
+            "type": "object",
+            "properties": {
+                "changed": {
+                    "description": "状态是否改变",
+                    "type": "boolean"
+                },
+                "is_liked": {
+                    "description": "当前点赞状态",
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.OAuthAuthorizeResponse": {
+            "type": "object",
+            "properties": {
+                "state": {
                     "type": "string"
                 },
-                "data": {
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.DeleteFileRequest": {
+        "ai-models-backend_internal_models.OAuthCallbackRequest": {
             "type": "object",
             "required": [
-                "object_key"
+                "code",
+                "state"
             ],
             "properties": {
-                "object_key": {
+                "code": {
+                    "type": "string"
+                },
+                "state": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.FeedComment": {
+        "ai-models-backend_internal_models.OpenAIChatCompletionRequest": {
             "type": "object",
+            "required": [
+                "messages",
+                "model"
+            ],
             "properties": {
-                "avatar": {
-                    "description": "冗余头像",
-                    "type": "string"
+                "frequency_penalty": {
+                    "type": "number"
                 },
-                "content": {
-                    "type": "string"
+                "logit_bias": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 },
-                "created_at": {
-                    "type": "string"
+                "logprobs": {
+                    "type": "boolean"
                 },
-                "id": {
-                    "type": "string"
+                "max_completion_tokens": {
+                    "type": "integer"
                 },
-                "like_count": {
+                "max_tokens": {
                     "type": "integer"
                 },
-                "post_id": {
-                    "type": "string"
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIMessage"
+                    }
                 },
-                "reply_to": {
-                    "description": "回复的用户名",
+                "model": {
                     "type": "string"
                 },
-                "updated_at": {
+                "n": {
+                    "type": "integer"
+                },
+                "parallel_tool_calls": {
+                    "type": "boolean"
+                },
+                "platform": {
                     "type": "string"
                 },
-                "user_id": {
+                "presence_penalty": {
+                    "type": "number"
+                },
+                "reasoning_effort": {
                     "type": "string"
                 },
-                "user_profile_version": {
-                    "description": "用户信息版本号",
+                "response_format": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIResponseFormat"
+                },
+                "seed": {
                     "type": "integer"
                 },
-                "username": {
-                    "description": "冗余字段",
-                    "type": "string"
-                }
-            }
-        },
-        "ai-models-backend_internal_models.FeedCommentResponse": {
-            "type": "object",
-            "properties": {
-                "comments": {
+                "stop": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
+                        "type": "string"
                     }
                 },
-                "has_more": {
+                "stream": {
                     "type": "boolean"
                 },
-                "next_cursor": {
-                    "type": "string"
+                "stream_options": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIStreamOptions"
                 },
-                "total": {
+                "temperature": {
+                    "description": "指针区分未传和显式传 0",
+                    "type": "number"
+                },
+                "tool_choice": {
+                    "description": "none, auto, required 或 {\"type\":\"function\",\"function\":{\"name\":\"...\"}}",
+                    "type": "object"
+                },
+                "tools": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAITool"
+                    }
+                },
+                "top_logprobs": {
                     "type": "integer"
+                },
+                "top_p": {
+                    "type": "number"
+                },
+                "user": {
+                    "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.FeedPost": {
+        "ai-models-backend_internal_models.OpenAIChatCompletionResponse": {
             "type": "object",
             "properties": {
-                "avatar": {
-                    "description": "冗余头像",
-                    "type": "string"
+                "choices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChoice"
+                    }
                 },
-                "comment_count": {
+                "created": {
                     "type": "integer"
                 },
-                "content": {
-                    "description": "文字内容（可选）",
-                    "type": "string"
-                },
-                "created_at": {
-                    "type": "string"
-                },
                 "id": {
-                    "description": "== from BaseModel ==",
                     "type": "string"
                 },
-                "image_url": {
-                    "description": "图片URL（可选）",
+                "model": {
                     "type": "string"
                 },
-                "like_count": {
-                    "type": "integer"
-                },
-                "status": {
-                    "description": "用户状态emoji",
+                "object": {
                     "type": "string"
                 },
-                "updated_at": {
+                "platform": {
+                    "description": "实际提供服务的平台（扩展字段）",
                     "type": "string"
                 },
-                "user_id": {
+                "system_fingerprint": {
                     "type": "string"
                 },
-                "user_profile_version": {
-                    "description": "用户信息版本号",
-                    "type": "integer"
-                },
-                "username": {
-                    "description": "冗余字段",
-                    "type": "string"
+                "usage": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIUsage"
                 }
             }
         },
-        "ai-models-backend_internal_models.FeedPostResponse": {
+        "ai-models-backend_internal_models.OpenAIChoice": {
             "type": "object",
             "properties": {
-                "has_more": {
-                    "type": "boolean"
-                },
-                "next_cursor": {
+                "finish_reason": {
                     "type": "string"
                 },
-                "posts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedPostResponseItem"
-                    }
+                "index": {
+                    "type": "integer"
+                },
+                "logprobs": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAILogprobs"
+                },
+                "message": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIMessage"
                 }
             }
         },
-        "ai-models-backend_internal_models.FeedPostResponseItem": {
+        "ai-models-backend_internal_models.OpenAICompletionTokensDetails": {
             "type": "object",
             "properties": {
-                "avatar": {
-                    "description": "冗余头像",
-                    "type": "string"
+                "accepted_prediction_tokens": {
+                    "type": "integer"
                 },
-                "comment_count": {
+                "audio_tokens": {
                     "type": "integer"
                 },
-                "comment_preview_count": {
-                    "description": "预载评论数量",
+                "reasoning_tokens": {
                     "type": "integer"
                 },
-                "content": {
-                    "description": "文字内容（可选）",
+                "rejected_prediction_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.OpenAIFunctionCall": {
+            "type": "object",
+            "properties": {
+                "arguments": {
+                    "description": "JSON 字符串",
                     "type": "string"
                 },
-                "created_at": {
+                "name": {
                     "type": "string"
-                },
-                "id": {
-                    "description": "== from BaseModel ==",
+                }
+            }
+        },
+        "ai-models-backend_internal_models.OpenAIFunctionDefinition": {
+            "type": "object",
+            "properties": {
+                "description": {
                     "type": "string"
                 },
-                "image_url": {
-                    "description": "图片URL（可选）",
+                "name": {
                     "type": "string"
                 },
-                "like_count": {
-                    "type": "integer"
-                },
-                "preloaded_comments": {
-                    "description": "预载评论列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedComment"
-                    }
+                "parameters": {
+                    "description": "JSON Schema",
+                    "type": "object"
                 },
-                "status": {
-                    "description": "用户状态emoji",
+                "strict": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.OpenAIImageData": {
+            "type": "object",
+            "properties": {
+                "b64_json": {
                     "type": "string"
                 },
-                "updated_at": {
+                "object_key": {
+                    "description": "转存到 OSS 后的 objectKey",
                     "type": "string"
                 },
-                "user_id": {
+                "revised_prompt": {
                     "type": "string"
                 },
-                "user_profile_version": {
-                    "description": "用户信息版本号",
-                    "type": "integer"
-                },
-                "username": {
-                    "description": "冗余字段",
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.FileInfo": {
+        "ai-models-backend_internal_models.OpenAIImageRequest": {
             "type": "object",
+            "required": [
+                "prompt"
+            ],
             "properties": {
-                "last_modified": {
+                "model": {
                     "type": "string"
                 },
-                "name": {
+                "n": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "persist": {
+                    "description": "转存到 OSS，返回长期有效的地址",
+                    "type": "boolean"
+                },
+                "prompt": {
                     "type": "string"
                 },
-                "object_key": {
+                "quality": {
                     "type": "string"
                 },
+                "response_format": {
+                    "type": "string",
+                    "enum": [
+                        "url",
+                        "b64_json"
+                    ]
+                },
                 "size": {
-                    "type": "integer"
+                    "type": "string"
                 },
-                "url": {
+                "style": {
+                    "type": "string"
+                },
+                "user": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.FileListResponse": {
+        "ai-models-backend_internal_models.OpenAIImageResponse": {
             "type": "object",
             "properties": {
-                "files": {
+                "created": {
+                    "type": "integer"
+                },
+                "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.FileInfo"
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIImageData"
                     }
                 },
-                "is_truncated": {
-                    "type": "boolean"
-                },
-                "next_marker": {
+                "platform": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.FileUploadResponse": {
+        "ai-models-backend_internal_models.OpenAIJSONSchema": {
             "type": "object",
             "properties": {
-                "hashify_name": {
-                    "description": "哈希化文件名",
-                    "type": "string"
-                },
-                "object_key": {
+                "description": {
                     "type": "string"
                 },
-                "size": {
-                    "type": "integer"
-                },
-                "type": {
+                "name": {
                     "type": "string"
                 },
-                "upload_time": {
-                    "type": "string"
+                "schema": {
+                    "type": "object"
                 },
-                "url": {
-                    "type": "string"
+                "strict": {
+                    "type": "boolean"
                 }
             }
         },
-        "ai-models-backend_internal_models.GenerateRequest": {
+        "ai-models-backend_internal_models.OpenAILogprobs": {
             "type": "object",
-            "required": [
-                "prompt"
-            ],
             "properties": {
-                "model": {
-                    "type": "string"
-                },
-                "parameters": {
-                    "type": "object",
-                    "additionalProperties": {}
+                "content": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAITokenLogprob"
+                    }
                 },
-                "prompt": {
-                    "type": "string"
+                "refusal": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAITokenLogprob"
+                    }
                 }
             }
         },
-        "ai-models-backend_internal_models.GenerateResponse": {
+        "ai-models-backend_internal_models.OpenAIMessage": {
             "type": "object",
+            "required": [
+                "role"
+            ],
             "properties": {
                 "content": {
+                    "description": "字符串或内容片段数组",
                     "type": "string"
                 },
-                "created_at": {
+                "name": {
                     "type": "string"
                 },
-                "id": {
+                "reasoning_content": {
+                    "description": "推理内容（deepseek 等扩展字段）",
                     "type": "string"
                 },
-                "model": {
+                "refusal": {
                     "type": "string"
                 },
-                "usage": {
-                    "$ref": "#/definitions/ai-models-backend_internal_models.Usage"
+                "role": {
+                    "type": "string"
+                },
+                "tool_call_id": {
+                    "description": "tool 消息对应的调用ID",
+                    "type": "string"
+                },
+                "tool_calls": {
+                    "description": "assistant 消息的工具调用",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIToolCall"
+                    }
                 }
             }
         },
-        "ai-models-backend_internal_models.GetURLRequest": {
+        "ai-models-backend_internal_models.OpenAIModel": {
             "type": "object",
-            "required": [
-                "object_key"
-            ],
             "properties": {
-                "object_key": {
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "object": {
+                    "type": "string"
+                },
+                "owned_by": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.GetURLResponse": {
+        "ai-models-backend_internal_models.OpenAIModelList": {
             "type": "object",
             "properties": {
-                "object_key": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIModel"
+                    }
                 },
-                "url": {
+                "object": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.HashifyNameRequest": {
+        "ai-models-backend_internal_models.OpenAIPromptTokensDetails": {
             "type": "object",
-            "required": [
-                "file_name"
-            ],
             "properties": {
-                "file_name": {
-                    "type": "string"
+                "audio_tokens": {
+                    "type": "integer"
+                },
+                "cached_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "ai-models-backend_internal_models.HashifyNameResponse": {
+        "ai-models-backend_internal_models.OpenAIResponseFormat": {
             "type": "object",
             "properties": {
-                "hashify_name": {
+                "json_schema": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIJSONSchema"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.LikeResult": {
+        "ai-models-backend_internal_models.OpenAIStreamOptions": {
             "type": "object",
             "properties": {
-                "changed": {
-                    "description": "状态是否改变",
-                    "type": "boolean"
-                },
-                "is_liked": {
-                    "description": "当前点赞状态",
+                "include_usage": {
                     "type": "boolean"
                 }
             }
         },
-        "ai-models-backend_internal_models.OpenAIChatCompletionRequest": {
+        "ai-models-backend_internal_models.OpenAITokenLogprob": {
             "type": "object",
-            "required": [
-                "messages",
-                "model"
-            ],
             "properties": {
-                "max_tokens": {
-                    "type": "integer"
-                },
-                "messages": {
+                "bytes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIMessage"
+                        "type": "integer"
                     }
                 },
-                "model": {
-                    "type": "string"
+                "logprob": {
+                    "type": "number"
                 },
-                "platform": {
+                "token": {
                     "type": "string"
                 },
-                "stream": {
-                    "type": "boolean"
-                },
-                "temperature": {
-                    "type": "number"
-                },
-                "top_p": {
-                    "type": "number"
-                }
-            }
-        },
-        "ai-models-backend_internal_models.OpenAIChatCompletionResponse": {
-            "type": "object",
-            "properties": {
-                "choices": {
+                "top_logprobs": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIChoice"
+                        "$ref": "#/definitions/ai-models-backend_internal_models.OpenAITokenLogprob"
                     }
-                },
-                "created": {
-                    "type": "integer"
-                },
-                "id": {
-                    "type": "string"
-                },
-                "model": {
-                    "type": "string"
-                },
-                "object": {
-                    "type": "string"
-                },
-                "usage": {
-                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIUsage"
                 }
             }
         },
-        "ai-models-backend_internal_models.OpenAIChoice": {
+        "ai-models-backend_internal_models.OpenAITool": {
             "type": "object",
             "properties": {
-                "finish_reason": {
-                    "type": "string"
-                },
-                "index": {
-                    "type": "integer"
+                "function": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIFunctionDefinition"
                 },
-                "message": {
-                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIMessage"
+                "type": {
+                    "description": "目前只有 function",
+                    "type": "string"
                 }
             }
         },
-        "ai-models-backend_internal_models.OpenAIMessage": {
+        "ai-models-backend_internal_models.OpenAIToolCall": {
             "type": "object",
-            "required": [
-                "content",
-                "role"
-            ],
             "properties": {
-                "content": {
+                "function": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIFunctionCall"
+                },
+                "id": {
                     "type": "string"
                 },
-                "role": {
+                "index": {
+                    "type": "integer"
+                },
+                "type": {
                     "type": "string"
                 }
             }
@@ -3051,9 +6206,15 @@ const docTemplate = `{
                 "completion_tokens": {
                     "type": "integer"
                 },
+                "completion_tokens_details": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAICompletionTokensDetails"
+                },
                 "prompt_tokens": {
                     "type": "integer"
                 },
+                "prompt_tokens_details": {
+                    "$ref": "#/definitions/ai-models-backend_internal_models.OpenAIPromptTokensDetails"
+                },
                 "total_tokens": {
                     "type": "integer"
                 }
@@ -3085,6 +6246,113 @@ const docTemplate = `{
                 }
             }
         },
+        "ai-models-backend_internal_models.PermissionInfo": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.RefreshTokenRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.RenameChatSessionRequest": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "title": {
+                    "type": "string",
+                    "maxLength": 100
+                }
+            }
+        },
+        "ai-models-backend_internal_models.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
+                    "description": "长度和强度由密码策略校验",
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.Role": {
+            "type": "object",
+            "properties": {
+                "built_in": {
+                    "description": "内置角色不能删除，admin 的权限不能修改",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "require_two_factor": {
+                    "description": "该角色的用户必须通过两步验证登录才能使用角色的权限",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.RoleRequest": {
+            "type": "object",
+            "required": [
+                "permissions"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.RoleTwoFactorRequest": {
+            "type": "object",
+            "properties": {
+                "required": {
+                    "type": "boolean"
+                }
+            }
+        },
         "ai-models-backend_internal_models.STSCredentials": {
             "type": "object",
             "properties": {
@@ -3118,7 +6386,7 @@ const docTemplate = `{
             ],
             "properties": {
                 "is_like": {
-                    "description": "是否点赞",
+                    "description": "是否点赞，指针区分 false 和未传",
                     "type": "boolean"
                 }
             }
@@ -3130,7 +6398,7 @@ const docTemplate = `{
             ],
             "properties": {
                 "is_like": {
-                    "description": "是否点赞",
+                    "description": "是否点赞，指针区分 false 和未传",
                     "type": "boolean"
                 }
             }
@@ -3288,6 +6556,121 @@ const docTemplate = `{
                 }
             }
         },
+        "ai-models-backend_internal_models.TwoFactorCodeRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "ai-models-backend_internal_models.TwoFactorDisableRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.TwoFactorLoginRequest": {
+            "type": "object",
+            "required": [
+                "challenge_token",
+                "code"
+            ],
+            "properties": {
+                "challenge_token": {
+                    "type": "string"
+                },
+                "code": {
+                    "type": "string",
+                    "maxLength": 32
+                }
+            }
+        },
+        "ai-models-backend_internal_models.TwoFactorRecoveryCodesResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "ai-models-backend_internal_models.TwoFactorSetupResponse": {
+            "type": "object",
+            "properties": {
+                "provisioning_uri": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.TwoFactorStatus": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
+                },
+                "enabled_at": {
+                    "type": "string"
+                },
+                "recovery_codes_remaining": {
+                    "type": "integer"
+                },
+                "required": {
+                    "description": "用户的角色要求两步验证",
+                    "type": "boolean"
+                }
+            }
+        },
+        "ai-models-backend_internal_models.UpdateFeedCommentRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 500
+                }
+            }
+        },
+        "ai-models-backend_internal_models.UpdateFeedPostRequest": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string",
+                    "maxLength": 2000
+                },
+                "image_url": {
+                    "type": "string"
+                },
+                "media": {
+                    "type": "array",
+                    "maxItems": 9,
+                    "items": {
+                        "$ref": "#/definitions/ai-models-backend_internal_models.FeedMediaRequest"
+                    }
+                }
+            }
+        },
         "ai-models-backend_internal_models.Usage": {
             "type": "object",
             "properties": {
@@ -3314,8 +6697,8 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "password": {
-                    "type": "string",
-                    "minLength": 6
+                    "description": "长度和强度由密码策略校验",
+                    "type": "string"
                 },
                 "username": {
                     "type": "string",
@@ -3327,6 +6710,15 @@ const docTemplate = `{
         "ai-models-backend_internal_models.UserCreateResponse": {
             "type": "object",
             "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
                 },
@@ -3335,6 +6727,30 @@ const docTemplate = `{
                 }
             }
         },
+        "ai-models-backend_internal_models.UserIdentity": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "email": {
+                    "description": "绑定时第三方返回的邮箱，仅用于展示",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "provider": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
         "ai-models-backend_internal_models.UserLoginRequest": {
             "type": "object",
             "required": [
@@ -3353,6 +6769,15 @@ const docTemplate = `{
         "ai-models-backend_internal_models.UserLoginResponse": {
             "type": "object",
             "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "refresh_expires_at": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                },
                 "token": {
                     "type": "string"
                 },
@@ -3373,9 +6798,15 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
+                "email_verified": {
+                    "type": "boolean"
+                },
                 "extra": {
                     "type": "string"
                 },
@@ -3385,6 +6816,10 @@ const docTemplate = `{
                 "is_active": {
                     "type": "boolean"
                 },
+                "password_generated": {
+                    "description": "没有设置过密码，需要确认身份时不能验证密码",
+                    "type": "boolean"
+                },
                 "profile_version": {
                     "type": "integer"
                 },
@@ -3402,6 +6837,38 @@ const docTemplate = `{
                 }
             }
         },
+        "ai-models-backend_internal_models.UserSession": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "是否为发起请求的会话",
+                    "type": "boolean"
+                },
+                "device": {
+                    "description": "根据 User-Agent 识别的浏览器和系统，如 Chrome on macOS",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "登录时的 IP",
+                    "type": "string"
+                },
+                "last_seen_at": {
+                    "type": "string"
+                },
+                "two_factor": {
+                    "type": "boolean"
+                },
+                "user_agent": {
+                    "type": "string"
+                }
+            }
+        },
         "ai-models-backend_internal_models.UserUpdateRequest": {
             "type": "object",
             "properties": {
@@ -3427,6 +6894,17 @@ const docTemplate = `{
                 }
             }
         },
+        "ai-models-backend_internal_models.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "ai-models-backend_pkg_response.Response": {
             "type": "object",
             "properties": {
@@ -3537,6 +7015,8 @@ var SwaggerInfo = &swag.Spec{
 	Description:      "AI Models Backend API documentation",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
+	LeftDelim:        "{{",
+	RightDelim:       "}}",
 }
 
 func init() {
@@ -0,0 +1,110 @@
+package config
+
+import "time"
+
+// AI 会话相关配置
+var (
+	AIHistoryMaxMessages = 20  // 每次请求携带的最大历史消息数
+	AISessionTitleMaxLen = 30  // 自动生成会话标题的最大长度（按字符）
+	AISessionPageLimit   = 100 // 会话列表单页最大数量
+)
+
+// AIRouteTarget 路由目标
+type AIRouteTarget struct {
+	Platform string // 平台: silicon, openrouter, dashscope, mock
+	Model    string // 平台侧模型名，为空时沿用请求中的模型名
+}
+
+// AI 平台路由配置
+var (
+	// 按模型配置平台路由，按顺序尝试，遇到 5xx、超时、429 时切到下一个
+	// 不同平台同一模型的名字往往不一样，可以通过 Model 做映射
+	AIModelRoutes = map[string][]AIRouteTarget{
+		"deepseek-chat": {
+			{Platform: "silicon", Model: "deepseek-ai/DeepSeek-V3"},
+			{Platform: "openrouter", Model: "deepseek/deepseek-chat"},
+			{Platform: "dashscope", Model: "deepseek-v3"},
+		},
+		"qwen-plus": {
+			{Platform: "dashscope", Model: "qwen-plus"},
+			{Platform: "openrouter", Model: "qwen/qwen-plus"},
+		},
+	}
+
+	// 未配置路由的模型使用的默认平台顺序
+	AIDefaultRoute = []string{"silicon", "openrouter", "dashscope"}
+)
+
+// AITimeout 平台调用超时，超时后按可重试错误切换到下一个平台
+type AITimeout struct {
+	Request time.Duration // 非流式请求（含图片生成）的整体超时
+	Stream  time.Duration // 流式请求从建立连接到读完的整体超时
+}
+
+// AI 平台超时配置
+var (
+	AIDefaultTimeout = AITimeout{Request: 60 * time.Second, Stream: 5 * time.Minute}
+
+	// 按平台覆盖默认超时，未配置的字段使用默认值
+	AIPlatformTimeouts = map[string]AITimeout{
+		"openrouter": {Request: 90 * time.Second, Stream: 10 * time.Minute},
+		"dashscope":  {Request: 120 * time.Second},
+	}
+)
+
+// AIModelMeta 平台 /models 接口未提供的模型信息，按模型ID手动维护
+type AIModelMeta struct {
+	ContextLength int
+	MaxTokens     int
+	InputPrice    float64 // 每百万 token
+	OutputPrice   float64
+	Currency      string
+	Capabilities  []string // 额外能力，如 vision, tools, reasoning
+}
+
+// Mock 平台配置，脚本文件中的配置优先
+var (
+	AIMockDefaultSeed = int64(1)               // 未命中脚本时选择默认回复的随机种子
+	AIMockDelay       = 300 * time.Millisecond // 非流式响应（含图片生成）的模拟延迟
+	AIMockChunkDelay  = 30 * time.Millisecond  // 流式数据块之间的模拟间隔
+)
+
+// AI 图片配置
+var (
+	AIImageOSSPrefix       = "assets/ai-images/" // 转存生成图片的路径前缀，其下按用户ID分目录
+	AIImageMaxBytes        = int64(20 << 20)     // 上传和下载图片的大小上限
+	AIImageDownloadTimeout = 30 * time.Second    // 下载平台生成图片的超时时间
+
+	AIContentOSSPrefixes = []string{"assets/images/"} // 对话消息可以引用的上传目录，此外只能引用自己的生成图片目录
+)
+
+// AI 模型目录配置
+var (
+	AIModelCacheTTL     = time.Hour        // 各平台模型列表在 Redis 中的缓存时间
+	AIModelFetchTimeout = 10 * time.Second // 拉取平台模型列表的超时时间
+
+	AIModelMetadata = map[string]AIModelMeta{
+		"deepseek-ai/DeepSeek-V3": {ContextLength: 65536, MaxTokens: 8192, InputPrice: 2, OutputPrice: 8, Currency: "CNY", Capabilities: []string{"tools"}},
+		"deepseek-ai/DeepSeek-R1": {ContextLength: 65536, MaxTokens: 16384, InputPrice: 4, OutputPrice: 16, Currency: "CNY", Capabilities: []string{"reasoning"}},
+		"deepseek-v3":             {ContextLength: 65536, MaxTokens: 8192, InputPrice: 2, OutputPrice: 8, Currency: "CNY", Capabilities: []string{"tools"}},
+		"qwen-plus":               {ContextLength: 131072, MaxTokens: 8192, InputPrice: 0.8, OutputPrice: 2, Currency: "CNY", Capabilities: []string{"tools"}},
+		"qwen-vl-plus":            {ContextLength: 131072, MaxTokens: 8192, InputPrice: 1.5, OutputPrice: 4.5, Currency: "CNY", Capabilities: []string{"vision"}},
+	}
+)
+
+// AIQuota 用量配额（按 token 计），0 表示不限
+type AIQuota struct {
+	Daily   int64
+	Monthly int64
+}
+
+// AI 用量配额配置
+var (
+	AIQuotaEnabled = true
+
+	// 按用户角色配置配额，未配置的角色使用 user 的配额
+	AIRoleQuotas = map[string]AIQuota{
+		"admin": {},
+		"user":  {Daily: 200_000, Monthly: 3_000_000},
+	}
+)
@@ -1,6 +1,59 @@
 package config
 
+import "time"
+
+var (
+	RBACCacheTTL = 30 * time.Second // 角色权限的内存缓存时间，多实例部署时修改角色最长在此时间后生效
+)
+
+// API Key 相关配置
+var (
+	APIKeyMaxPerUser       = 20          // 每个用户最多持有的 API Key 数量
+	APIKeyTouchInterval    = time.Minute // 最近使用时间的更新间隔，避免每次请求都写库
+	APIKeyRandomByteLength = 32          // 随机部分的字节数
+)
+
+// 登录令牌相关配置
+var (
+	RefreshTokenByteLength = 32 // refresh token 随机部分的字节数
+	SessionIDByteLength    = 16 // 登录会话ID的字节数
+
+	SessionTouchInterval = time.Minute // 会话最近活跃时间的更新间隔，避免每次请求都写 Redis
+)
+
+// 邮件相关配置
+var (
+	MailSendTimeout = 10 * time.Second
+
+	EmailVerifyTokenTTL   = 24 * time.Hour
+	PasswordResetTokenTTL = 30 * time.Minute
+	EmailResendInterval   = time.Minute // 同一用户重新发送验证邮件的最小间隔
+	PasswordResetInterval = time.Minute // 同一邮箱请求重置密码的最小间隔
+)
+
+// 登录防暴力破解，失败次数分别按账号和 IP 统计
+var (
+	LoginFailureWindow     = 15 * time.Minute // 失败计数的统计窗口，最后一次失败后重新计时
+	LoginDelayBase         = time.Second      // 开始延迟后的首次等待时间，之后每次失败翻倍
+	LoginDelayMax          = time.Minute
+	LoginLockDuration      = 15 * time.Minute
+	LoginAccountDelayAfter = 3  // 同一账号连续失败多少次后开始延迟
+	LoginAccountLockAfter  = 10 // 同一账号连续失败多少次后锁定
+	LoginIPDelayAfter      = 10 // 同一 IP 失败多少次后开始延迟
+	LoginIPLockAfter       = 50 // 同一 IP 失败多少次后锁定
+)
+
+// 第三方登录相关配置
+var (
+	OAuthStateTTL           = 10 * time.Minute // 跳转第三方授权到回调的最长时间
+	OAuthHTTPTimeout        = 10 * time.Second
+	OIDCJWKSRefreshInterval = time.Minute // 遇到未知 kid 时重新拉取 JWKS 的最小间隔
+)
+
+// 两步验证相关配置
 var (
-	// 启用管理员权限检查，现在有了真正的角色系统
-	AdminCheck = false // 是否检查管理员权限
+	TwoFactorIssuer            = "AI Models"     // 身份验证器 App 中显示的服务名称
+	TwoFactorChallengeTTL      = 5 * time.Minute // 密码验证通过后输入验证码的最长时间
+	TwoFactorChallengeAttempts = 5               // 同一次登录最多尝试验证码的次数
+	TwoFactorRecoveryCodes     = 10              // 恢复码数量，每个只能使用一次
 )
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -26,13 +28,38 @@ type Config struct {
 	RedisPort     string
 	RedisPassword string
 
-	JWTSecret     string
-	JWTExpiration time.Duration
+	JWTSecret            string
+	JWTExpiration        time.Duration // access token 有效期，应尽量短
+	JWTRefreshExpiration time.Duration // refresh token 有效期，每次刷新后重新计算
+	JWTKeysDir           string        // 非对称签名密钥目录，为空时使用 JWTSecret（HS256）
+	JWTSigningKID        string        // 签名使用的密钥ID，为空时使用目录中最新的私钥
+
+	AppBaseURL       string // 前端地址，用于生成邮件中的链接
+	EmailTokenSecret string // 邮箱验证和重置密码链接的签名密钥，为空时使用 JWTSecret
+	FeedCursorSecret string // 信息流分页游标的签名密钥，为空时使用 JWTSecret
+
+	MailFrom     string
+	SMTPHost     string // 为空时不发送真实邮件，写入 MailDir 或输出到日志
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	MailDir      string
+
+	OAuthRedirectURL   string // 第三方登录回调地址前缀，实际回调地址为 OAuthRedirectURL/<provider>
+	GitHubClientID     string // 为空时不启用 GitHub 登录
+	GitHubClientSecret string
+	OIDCName           string // OIDC 登录的名称，用于接口路径，默认为 oidc
+	OIDCIssuer         string // 为空时不启用 OIDC 登录
+	OIDCClientID       string
+	OIDCClientSecret   string
 
 	SiliconAPIKey    string
 	OpenRouterAPIKey string
 	DashscopeAPIKey  string
 
+	AIMockFixtures string // Mock 平台脚本文件路径
+	AIMockSeed     int64
+
 	OSSAccessKeyID     string
 	OSSAccessKeySecret string
 	OSSBucket          string
@@ -83,13 +110,38 @@ func New() *Config {
 		RedisPort:     os.Getenv("REDIS_PORT"),
 		RedisPassword: os.Getenv("REDIS_PASSWORD"),
 
-		JWTSecret:     os.Getenv("JWT_SECRET"),
-		JWTExpiration: getEnvDuration("JWT_EXPIRATION", "24h"),
+		JWTSecret:            os.Getenv("JWT_SECRET"),
+		JWTExpiration:        getEnvDuration("JWT_EXPIRATION", "15m"),
+		JWTRefreshExpiration: getEnvDuration("JWT_REFRESH_EXPIRATION", "720h"),
+		JWTKeysDir:           os.Getenv("JWT_KEYS_DIR"),
+		JWTSigningKID:        os.Getenv("JWT_SIGNING_KID"),
+
+		AppBaseURL:       getEnv("APP_BASE_URL", "http://localhost:5173"),
+		EmailTokenSecret: getEnv("EMAIL_TOKEN_SECRET", os.Getenv("JWT_SECRET")),
+		FeedCursorSecret: getEnv("FEED_CURSOR_SECRET", os.Getenv("JWT_SECRET")),
+
+		MailFrom:     getEnv("MAIL_FROM", "AI Models <no-reply@localhost>"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPPort:     os.Getenv("SMTP_PORT"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		MailDir:      os.Getenv("MAIL_DIR"),
+
+		OAuthRedirectURL:   getEnv("OAUTH_REDIRECT_URL", strings.TrimRight(getEnv("APP_BASE_URL", "http://localhost:5173"), "/")+"/oauth/callback"),
+		GitHubClientID:     os.Getenv("GITHUB_CLIENT_ID"),
+		GitHubClientSecret: os.Getenv("GITHUB_CLIENT_SECRET"),
+		OIDCName:           getEnv("OIDC_NAME", "oidc"),
+		OIDCIssuer:         os.Getenv("OIDC_ISSUER"),
+		OIDCClientID:       os.Getenv("OIDC_CLIENT_ID"),
+		OIDCClientSecret:   os.Getenv("OIDC_CLIENT_SECRET"),
 
 		SiliconAPIKey:    os.Getenv("SILICON_API_KEY"),
 		OpenRouterAPIKey: os.Getenv("OPENROUTER_API_KEY"),
 		DashscopeAPIKey:  os.Getenv("DASHSCOPE_API_KEY"),
 
+		AIMockFixtures: os.Getenv("AI_MOCK_FIXTURES"),
+		AIMockSeed:     getEnvInt64("AI_MOCK_SEED", 0),
+
 		OSSAccessKeyID:     os.Getenv("OSS_ACCESS_KEY_ID"),
 		OSSAccessKeySecret: os.Getenv("OSS_ACCESS_KEY_SECRET"),
 		OSSBucket:          os.Getenv("OSS_BUCKET"),
@@ -106,6 +158,22 @@ func New() *Config {
 	return config
 }
 
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt64(key string, defaultValue int64) int64 {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key, defaultValue string) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
@@ -7,4 +7,18 @@ var (
 	FeedMaxCachedComments     = 20               // 最大缓存评论数量
 	FeedCommentCacheTTL       = 30 * time.Minute // 评论缓存过期时间
 	FeedCommentCacheKeyPrefix = "feed:comments:" // 评论缓存键前缀
+	FeedMediaKeyPrefix        = "assets/images/feed/" // 帖子媒体附件的OSS目录，其下按用户ID分目录，对象键由申请上传接口生成
+
+	// 时间线：粉丝数少于阈值的用户发帖时写入粉丝的时间线（推模式），达到阈值的用户在读取时查询（拉模式）
+	FeedFanoutFollowerThreshold = 1000               // 推模式的粉丝数上限
+	FeedFanoutBatchSize         = 500                // 推送时每批处理的粉丝数量
+	FeedTimelineKeyPrefix       = "feed:timeline:"   // 时间线缓存键前缀，有序集合，成员和分数都是帖子ID
+	FeedTimelineMaxLen          = 800                // 时间线缓存保留的帖子数量
+	FeedTimelineTTL             = 7 * 24 * time.Hour // 时间线缓存过期时间，读取时续期
+
+	// 热门排序：热度 = log10(点赞数 + 评论权重 × 评论数) + 发布时间 / 衰减周期，晚发布一个周期的帖子需要10倍的互动才能排在同样位置
+	FeedHotKey           = "feed:hot" // 热门排行缓存键，有序集合，成员为帖子ID，分数为热度
+	FeedHotCommentWeight = 2          // 一条评论相当于几个点赞
+	FeedHotDecaySeconds  = 45000      // 衰减周期（秒），即12.5小时
+	FeedHotMaxLen        = 1000       // 热门排行保留的帖子数量
 )
\ No newline at end of file
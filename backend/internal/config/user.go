@@ -1,5 +1,29 @@
 package config
 
+import "time"
+
+// 密码哈希配置，修改算法或参数后，旧哈希会在用户下次登录成功时自动升级
+var (
+	PasswordHashAlgorithm = "argon2id" // 新密码使用的算法: argon2id, bcrypt
+	PasswordBcryptCost    = 12
+
+	// argon2id 参数，参考 RFC 9106 的推荐值
+	PasswordArgon2Time    uint32 = 3
+	PasswordArgon2Memory  uint32 = 64 * 1024 // KiB
+	PasswordArgon2Threads uint8  = 2
+	PasswordArgon2KeyLen  uint32 = 32
+	PasswordArgon2SaltLen        = 16
+)
+
+// 密码策略
+var (
+	PasswordMinLength = 8
+	PasswordMaxLength = 72 // bcrypt 只处理前 72 字节，超出部分会被拒绝
+)
+
+// 账号注销
 var (
-	UserStorePlainPassword = true // 用户存储的密码是否是明文
+	AccountDeletionGracePeriod = 14 * 24 * time.Hour // 申请注销后的宽限期，期间可以登录并撤销
+	AccountPurgeBatchSize      = 100                 // 定时任务每次最多删除的账号数
+	AccountReauthMaxAge        = 10 * time.Minute    // 没有密码的第三方登录账号申请注销时，当前会话需在此时间内登录
 )
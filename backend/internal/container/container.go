@@ -20,6 +20,7 @@ type Container struct {
 	// 服务层
 	AuthService     *auth.AuthService
 	UserService     *services.UserService
+	RBACService     *services.RBACService
 	AIService       *ai.AIService
 	OSSService      *services.OSSService
 	CrudService     *services.CrudService
@@ -45,8 +46,9 @@ func New(cfg *config.Config) *Container {
 	// 初始化服务层
 	userService := services.NewUserService(cfg)
 	authService := auth.NewAuthService(cfg)
-	aiService := ai.NewAIService(cfg)
+	rbacService := services.NewRBACService()
 	ossService := services.NewOSSService(cfg)
+	aiService := ai.NewAIService(cfg, ossService, rbacService)
 	crudService := services.NewCrudService()
 	todoService := services.NewTodoService()
 	feedService := services.NewFeedService(database.GetDB(), userService)
@@ -54,20 +56,21 @@ func New(cfg *config.Config) *Container {
 
 	// 初始化处理器层
 	userHandler := handlers.NewUserHandler(userService, authService)
-	adminHandler := handlers.NewAdminHandler(userService, authService)
+	adminHandler := handlers.NewAdminHandler(userService, authService, rbacService)
 	aiHandler := handlers.NewAIHandler(aiService)
 	ossHandler := handlers.NewOSSHandler(ossService)
 	healthHandler := handlers.NewHealthHandler()
 	crudHandler := handlers.NewCrudHandler(crudService)
 	todoHandler := handlers.NewTodoHandler(todoService)
 	testHandler := handlers.NewTestHandler()
-	feedHandler := handlers.NewFeedHandler(feedService)
+	feedHandler := handlers.NewFeedHandler(feedService, rbacService)
 	metricsHandler := handlers.NewMetricsHandler()
 
 	return &Container{
 		Config:          cfg,
 		AuthService:     authService,
 		UserService:     userService,
+		RBACService:     rbacService,
 		AIService:       aiService,
 		OSSService:      ossService,
 		CrudService:     crudService,
@@ -89,6 +92,11 @@ func New(cfg *config.Config) *Container {
 
 /* 初始化应用启动时需要执行的操作 */
 func (c *Container) Initialize() error {
+	// 创建内置角色，默认管理员依赖 admin 角色
+	if err := c.RBACService.EnsureBuiltInRoles(); err != nil {
+		return err
+	}
+
 	// 创建默认管理员用户
 	if err := c.AuthService.CreateDefaultAdmin(); err != nil {
 		return err
@@ -63,25 +63,71 @@ func Initialize(cfg *config.Config) error {
 func autoMigrate() error {
 	logrus.Info("开始数据库迁移...")
 
+	// 邮箱验证上线前注册的用户视为已验证，需要在 AutoMigrate 加列之前判断
+	backfillVerified := DB.Migrator().HasTable(&models.User{}) &&
+		!DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
 	err := DB.AutoMigrate(
 		&models.User{},
+		&models.APIKey{},
+		&models.UserIdentity{},
+		&models.UserTwoFactor{},
+		&models.AuditLog{},
+		&models.Role{},
 		&models.ConversationHistory{},
+		&models.ChatSession{},
+		&models.AIUsageLog{},
+		&models.AIModelOverride{},
 		&models.Crud{},
 		&models.Todo{},
 		&models.FeedPost{},
+		&models.FeedMedia{},
+		&models.FeedUpload{},
 		&models.FeedComment{},
 		&models.PostLike{},
 		&models.FeedCommentLike{},
+		&models.UserFollow{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	// AutoMigrate 不会删除列，删除字段的迁移单独处理，需要可重复执行
+	if err := dropPlainPassword(); err != nil {
+		return fmt.Errorf("删除明文密码列失败: %w", err)
+	}
+
+	if backfillVerified {
+		if err := DB.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error; err != nil {
+			return fmt.Errorf("回填邮箱验证状态失败: %w", err)
+		}
+		logrus.Info("已将现有用户标记为邮箱已验证")
+	}
+
 	logrus.Info("数据库迁移完成")
 	return nil
 }
 
+// dropPlainPassword 早期版本会在 users.plain_password 中保存明文密码
+// PostgreSQL 的 DROP COLUMN 只是把列标记为删除，旧数据仍留在磁盘上，所以先清空再删除
+func dropPlainPassword() error {
+	if !DB.Migrator().HasColumn(&models.User{}, "plain_password") {
+		return nil
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("UPDATE users SET plain_password = NULL").Error; err != nil {
+			return err
+		}
+		if err := tx.Migrator().DropColumn(&models.User{}, "plain_password"); err != nil {
+			return err
+		}
+		logrus.Info("已清空并删除 users.plain_password 列")
+		return nil
+	})
+}
+
 // Close 关闭数据库连接
 func Close() error {
 	if DB != nil {
@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/auth"
+	"ai-models-backend/pkg/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary 申请注销账号
+// @Description 需要确认身份：设置过密码的账号传密码；第三方登录创建、没有密码的账号（password_generated 为 true）传两步验证码，未启用两步验证时需在重新登录后10分钟内申请。全部设备立即退出登录；宽限期内重新登录可以撤销，到期后账号及全部数据被永久删除
+// @Tags User
+// @Param request body models.AccountDeletionRequest true "注销请求"
+// @Success 200 {object} response.Response{data=models.AccountDeletionResponse}
+// @Router /users/account/deletion [post]
+func (h *UserHandler) ScheduleAccountDeletion(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.AccountDeletionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	scheduledAt, err := h.authService.ScheduleAccountDeletion(c.Request.Context(), userID, c.GetString("session_id"), req, h.GetClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrPasswordIncorrect), errors.Is(err, auth.ErrTwoFactorCodeInvalid),
+			errors.Is(err, auth.ErrTwoFactorNotEnabled), errors.Is(err, services.ErrLastAdministrator):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, auth.ErrReauthRequired):
+			response.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, auth.ErrDeletionScheduled):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			logrus.Error("Failed to schedule account deletion:", err)
+			response.Error(c, http.StatusInternalServerError, "注销账号失败")
+		}
+		return
+	}
+
+	response.Success(c, models.AccountDeletionResponse{DeletionScheduledAt: scheduledAt})
+}
+
+// @Summary 撤销注销账号
+// @Description 在宽限期内撤销注销申请
+// @Tags User
+// @Success 200 {object} response.Response
+// @Router /users/account/deletion [delete]
+func (h *UserHandler) CancelAccountDeletion(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.CancelAccountDeletion(userID, h.GetClientInfo(c)); err != nil {
+		if errors.Is(err, auth.ErrDeletionNotScheduled) {
+			response.Error(c, http.StatusConflict, err.Error())
+			return
+		}
+		logrus.Error("Failed to cancel account deletion:", err)
+		response.Error(c, http.StatusInternalServerError, "撤销注销失败")
+		return
+	}
+
+	response.SuccessMsg(c, "已撤销注销")
+}
+
+// @Summary 导出个人数据
+// @Description 下载 ZIP 文件，包含个人资料、待办、帖子、评论和 AI 对话记录，每类数据一个 JSON 文件
+// @Tags User
+// @Produce application/zip
+// @Success 200 {file} file
+// @Router /users/export [get]
+func (h *UserHandler) ExportUserData(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("export-%d-%s.zip", userID, time.Now().Format("20060102"))
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Cache-Control", "no-store")
+
+	// 数据在写入前全部查出，查询失败时还能返回错误；写入过程中失败只能中断下载
+	if err := h.userService.ExportUserData(userID, c.Writer); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error("Failed to export user data")
+		if !c.Writer.Written() {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			c.Header("Content-Disposition", "")
+			response.Error(c, http.StatusInternalServerError, "导出数据失败")
+			return
+		}
+		c.Abort()
+	}
+}
@@ -4,23 +4,30 @@ import (
 	"ai-models-backend/internal/services"
 	"ai-models-backend/internal/services/auth"
 	"ai-models-backend/pkg/response"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"ai-models-backend/internal/models"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type AdminHandler struct {
+	BaseHandler
 	userService *services.UserService
 	authService *auth.AuthService
+	rbacService *services.RBACService
 }
 
-func NewAdminHandler(userService *services.UserService, authService *auth.AuthService) *AdminHandler {
+func NewAdminHandler(userService *services.UserService, authService *auth.AuthService, rbacService *services.RBACService) *AdminHandler {
 	return &AdminHandler{
+		BaseHandler: BaseHandler{},
 		userService: userService,
 		authService: authService,
+		rbacService: rbacService,
 	}
 }
 
@@ -68,7 +75,7 @@ func (h *AdminHandler) ResetUserPassword(c *gin.Context) {
 	userID := c.Param("id")
 
 	var req struct {
-		NewPassword string `binding:"required,min=6"`
+		NewPassword string `binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -79,9 +86,207 @@ func (h *AdminHandler) ResetUserPassword(c *gin.Context) {
 	err := h.userService.ResetPassword(userID, req.NewPassword)
 	if err != nil {
 		logrus.Error("Failed to reset password:", err)
+		if errors.Is(err, services.ErrWeakPassword) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response.SuccessMsg(c, "密码重置成功")
 }
+
+// @Summary 解除登录锁定
+// @Description 管理员解除指定用户因连续登录失败导致的临时锁定，并清空失败计数
+// @Tags Admin
+// @Param id path string true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /admin/users/{id}/unlock [post]
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.authService.UnlockAccount(c.Request.Context(), userID, h.GetClientInfo(c), c.GetUint64("user_id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response.Error(c, http.StatusNotFound, "用户不存在")
+			return
+		}
+		logrus.Error("Failed to unlock user:", err)
+		response.Error(c, http.StatusInternalServerError, "解除锁定失败")
+		return
+	}
+
+	response.SuccessMsg(c, "已解除登录锁定")
+}
+
+// @Summary 重置两步验证
+// @Description 为丢失身份验证器的用户关闭两步验证，用户可以重新绑定
+// @Tags Admin
+// @Param id path string true "用户ID"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /admin/users/{id}/2fa [delete]
+func (h *AdminHandler) ResetUserTwoFactor(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.authService.ResetTwoFactor(userID, h.GetClientInfo(c), c.GetUint64("user_id")); err != nil {
+		if errors.Is(err, auth.ErrTwoFactorNotEnabled) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logrus.Error("Failed to reset two-factor:", err)
+		response.Error(c, http.StatusInternalServerError, "重置两步验证失败")
+		return
+	}
+
+	response.SuccessMsg(c, "两步验证已重置")
+}
+
+// @Summary 角色列表
+// @Description 获取全部角色及其权限
+// @Tags Admin
+// @Success 200 {object} response.Response{data=[]models.Role}
+// @Router /admin/roles [get]
+func (h *AdminHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles()
+	if err != nil {
+		logrus.Error("Failed to list roles:", err)
+		response.Error(c, http.StatusInternalServerError, "获取角色列表失败")
+		return
+	}
+
+	response.Success(c, roles)
+}
+
+// @Summary 权限列表
+// @Description 获取可以分配给角色的全部权限及说明
+// @Tags Admin
+// @Success 200 {object} response.Response{data=[]models.PermissionInfo}
+// @Router /admin/permissions [get]
+func (h *AdminHandler) ListPermissions(c *gin.Context) {
+	response.Success(c, h.rbacService.ListPermissions())
+}
+
+// @Summary 创建或更新角色
+// @Description 创建角色或更新角色的说明和权限，admin 角色始终拥有全部权限，不能修改
+// @Tags Admin
+// @Param name path string true "角色名"
+// @Param request body models.RoleRequest true "角色权限"
+// @Success 200 {object} response.Response{data=models.Role}
+// @Router /admin/roles/{name} [put]
+func (h *AdminHandler) SaveRole(c *gin.Context) {
+	var req models.RoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数无效")
+		return
+	}
+
+	role, err := h.rbacService.SaveRole(c.Param("name"), req, h.GetClientInfo(c), c.GetUint64("user_id"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRole) || errors.Is(err, services.ErrRoleBuiltIn) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.Error("Failed to save role:", err)
+		response.Error(c, http.StatusInternalServerError, "保存角色失败")
+		return
+	}
+
+	response.Success(c, role)
+}
+
+// @Summary 设置角色是否要求两步验证
+// @Description 开启后该角色的用户必须通过两步验证登录才能使用角色的权限，未启用两步验证的用户需要启用后重新登录
+// @Tags Admin
+// @Param name path string true "角色名"
+// @Param request body models.RoleTwoFactorRequest true "是否要求"
+// @Success 200 {object} response.Response{data=models.Role}
+// @Router /admin/roles/{name}/two-factor [put]
+func (h *AdminHandler) SetRoleTwoFactor(c *gin.Context) {
+	var req models.RoleTwoFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数无效")
+		return
+	}
+
+	role, err := h.rbacService.SetTwoFactorRequired(c.Param("name"), req.Required, h.GetClientInfo(c), c.GetUint64("user_id"))
+	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logrus.Error("Failed to update role two-factor requirement:", err)
+		response.Error(c, http.StatusInternalServerError, "保存角色失败")
+		return
+	}
+
+	response.Success(c, role)
+}
+
+// @Summary 删除角色
+// @Description 删除自定义角色，内置角色和仍有用户的角色不能删除
+// @Tags Admin
+// @Param name path string true "角色名"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /admin/roles/{name} [delete]
+func (h *AdminHandler) DeleteRole(c *gin.Context) {
+	err := h.rbacService.DeleteRole(c.Param("name"), h.GetClientInfo(c), c.GetUint64("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoleNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, services.ErrRoleBuiltIn), errors.Is(err, services.ErrRoleInUse):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			logrus.Error("Failed to delete role:", err)
+			response.Error(c, http.StatusInternalServerError, "删除角色失败")
+		}
+		return
+	}
+
+	response.SuccessMsg(c, "角色已删除")
+}
+
+// @Summary 分配用户角色
+// @Description 修改指定用户的角色，不能撤销最后一个管理员
+// @Tags Admin
+// @Param id path string true "用户ID"
+// @Param request body models.AssignRoleRequest true "角色"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) AssignRole(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.AssignRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数无效")
+		return
+	}
+
+	err = h.rbacService.AssignRole(userID, req.Role, h.GetClientInfo(c), c.GetUint64("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			response.Error(c, http.StatusNotFound, "用户不存在")
+		case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrLastAdministrator):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			logrus.Error("Failed to assign role:", err)
+			response.Error(c, http.StatusInternalServerError, "分配角色失败")
+		}
+		return
+	}
+
+	response.SuccessMsg(c, "角色已更新")
+}
@@ -4,9 +4,15 @@ import (
 	"ai-models-backend/internal/models"
 	"ai-models-backend/internal/services/ai"
 	"ai-models-backend/pkg/response"
+	"ai-models-backend/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
@@ -45,49 +51,85 @@ func (h *AIHandler) Chat(c *gin.Context) {
 		h.handleStreamingChat(c, userID, req)
 		return
 	}
-	chatResponse, err := h.aiService.Chat(userID, req)
+	chatResponse, err := h.aiService.Chat(c.Request.Context(), userID, req)
 	if err != nil {
+		if errors.Is(err, ai.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		if errors.Is(err, ai.ErrQuotaExceeded) {
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		if errors.Is(err, ai.ErrModelDisabled) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		logrus.Error("Failed to process chat:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to process chat request")
 		return
 	}
 
+	c.Header(ai.PlatformHeader, chatResponse.Platform)
+
 	response.Success(c, chatResponse)
 }
 
 func (h *AIHandler) handleStreamingChat(c *gin.Context, userID uint64, req models.ChatRequest) {
+	// 开始推流后无法再返回状态码，先检查会话
+	if err := h.aiService.CheckSession(userID, req.SessionID); err != nil {
+		if errors.Is(err, ai.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logrus.Error("Failed to check session:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to process chat request")
+		return
+	}
+
+	// 流式响应无法在 body 中返回会话ID，提前生成并放在响应头中
+	if req.SessionID == "" {
+		req.SessionID = utils.GenerateSessionID()
+	}
+	c.Header("X-Session-ID", req.SessionID)
+
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
 	c.Header("Access-Control-Allow-Origin", "*")
 
+	// 客户端断开时 ctx 被取消，服务层随之停止读取上游
+	// 错误在关闭 responseChan 之前写入带缓冲的 errChan，responseChan 关闭后再读取，不会把失败当作完成
+	ctx := c.Request.Context()
 	responseChan := make(chan string, 100)
 	errChan := make(chan error, 1)
 	go func() {
 		defer close(responseChan)
-		defer close(errChan)
 
-		err := h.aiService.StreamChat(userID, req, responseChan)
-		if err != nil {
+		if err := h.aiService.StreamChat(ctx, userID, req, responseChan); err != nil {
 			errChan <- err
 		}
 	}()
 
 	for {
 		select {
+		case <-ctx.Done():
+			logrus.WithField("user_id", userID).Info("Client disconnected, chat stream aborted")
+			return
 		case chunk, ok := <-responseChan:
-			if !ok {
-				c.SSEvent("done", "")
-				return
+			if ok {
+				c.SSEvent("data", chunk)
+				c.Writer.Flush()
+				continue
 			}
-			c.SSEvent("data", chunk)
-			c.Writer.Flush()
-		case err := <-errChan:
-			if err != nil {
+			select {
+			case err := <-errChan:
 				logrus.Error("Streaming error:", err)
 				c.SSEvent("error", err.Error())
-				return
+			default:
+				c.SSEvent("done", "")
 			}
+			return
 		}
 	}
 }
@@ -111,7 +153,7 @@ func (h *AIHandler) Generate(c *gin.Context) {
 		return
 	}
 
-	generateResponse, err := h.aiService.Generate(userID, req)
+	generateResponse, err := h.aiService.Generate(c.Request.Context(), userID, req)
 	if err != nil {
 		logrus.Error("Failed to generate content:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to generate content")
@@ -127,7 +169,7 @@ func (h *AIHandler) Generate(c *gin.Context) {
 // @Success 200 {object} response.Response{data=[]models.AIModel}
 // @Router /ai/models [get]
 func (h *AIHandler) GetModels(c *gin.Context) {
-	models, err := h.aiService.GetAvailableModels()
+	models, err := h.aiService.GetAvailableModels(c.Request.Context())
 	if err != nil {
 		logrus.Error("Failed to get models:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to get available models")
@@ -137,11 +179,86 @@ func (h *AIHandler) GetModels(c *gin.Context) {
 	response.Success(c, models)
 }
 
+// @Summary OpenAI兼容模型列表
+// @Description OpenAI兼容的模型列表，汇总各平台可用模型，管理员设置的别名也作为模型ID返回
+// @Tags AI
+// @Success 200 {object} models.OpenAIModelList
+// @Router /ai/v1/models [get]
+func (h *AIHandler) OpenAIListModels(c *gin.Context) {
+	list, err := h.aiService.OpenAIModels(c.Request.Context())
+	if err != nil {
+		logrus.WithError(err).Error("Failed to list models")
+		writeOpenAIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// @Summary 模型目录（管理员）
+// @Description 获取全部模型，包括已禁用的模型
+// @Tags Admin
+// @Success 200 {object} response.Response{data=[]models.AIModel}
+// @Router /admin/ai/models [get]
+func (h *AIHandler) ListModelCatalog(c *gin.Context) {
+	catalog, err := h.aiService.ListModelCatalog(c.Request.Context())
+	if err != nil {
+		logrus.Error("Failed to list model catalog:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to list model catalog")
+		return
+	}
+
+	response.Success(c, catalog)
+}
+
+// @Summary 更新模型设置（管理员）
+// @Description 启用/禁用模型或设置别名，禁用后调用该模型会被拒绝
+// @Tags Admin
+// @Param request body models.AIModelOverrideRequest true "模型设置"
+// @Success 200 {object} response.Response{data=models.AIModelOverride}
+// @Router /admin/ai/models [put]
+func (h *AIHandler) UpdateModelOverride(c *gin.Context) {
+	var req models.AIModelOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	override, err := h.aiService.UpdateModelOverride(req)
+	if err != nil {
+		if errors.Is(err, ai.ErrAliasConflict) {
+			response.Error(c, http.StatusConflict, err.Error())
+			return
+		}
+		logrus.Error("Failed to update model override:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to update model")
+		return
+	}
+
+	response.Success(c, override)
+}
+
+// @Summary 刷新模型目录（管理员）
+// @Description 清除各平台模型列表缓存，下次查询时重新拉取
+// @Tags Admin
+// @Success 200 {object} response.Response
+// @Router /admin/ai/models/refresh [post]
+func (h *AIHandler) RefreshModels(c *gin.Context) {
+	if err := h.aiService.RefreshModels(c.Request.Context()); err != nil {
+		logrus.Error("Failed to refresh models:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to refresh models")
+		return
+	}
+
+	response.SuccessMsg(c, "模型目录已刷新")
+}
+
 // @Summary 获取聊天历史
 // @Description 获取用户的历史聊天记录，支持分页和过滤
 // @Tags AI
 // @Param session_id query string true "会话ID"
-// @Success 200 {object} response.Response{data=[]models.ChatMessage}
+// @Success 200 {object} response.Response{data=[]models.ConversationHistory}
 // @Router /ai/chat/history [get]
 func (h *AIHandler) GetChatHistory(c *gin.Context) {
 	userID, ok := h.GetUserID(c)
@@ -157,6 +274,10 @@ func (h *AIHandler) GetChatHistory(c *gin.Context) {
 
 	history, err := h.aiService.GetChatHistory(userID, sessionID)
 	if err != nil {
+		if errors.Is(err, ai.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
 		logrus.Error("Failed to get chat history:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to get chat history")
 		return
@@ -185,6 +306,10 @@ func (h *AIHandler) ClearChatHistory(c *gin.Context) {
 
 	err := h.aiService.ClearChatHistory(userID, sessionID)
 	if err != nil {
+		if errors.Is(err, ai.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
 		logrus.Error("Failed to clear chat history:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to clear chat history")
 		return
@@ -193,6 +318,141 @@ func (h *AIHandler) ClearChatHistory(c *gin.Context) {
 	response.Success(c, nil)
 }
 
+// @Summary 获取会话列表
+// @Description 获取当前用户的聊天会话列表，按最近消息时间倒序
+// @Tags AI
+// @Param page query int false "页码"
+// @Param limit query int false "每页数量"
+// @Success 200 {object} response.Response{data=models.PaginationResponse}
+// @Router /ai/sessions [get]
+func (h *AIHandler) ListSessions(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	data, err := h.aiService.ListSessions(userID, page, limit)
+	if err != nil {
+		logrus.Error("Failed to list sessions:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// @Summary 重命名会话
+// @Description 修改指定会话的标题
+// @Tags AI
+// @Param session_id path string true "会话ID"
+// @Param request body models.RenameChatSessionRequest true "重命名请求"
+// @Success 200 {object} response.Response{data=models.ChatSession}
+// @Router /ai/sessions/{session_id} [put]
+func (h *AIHandler) RenameSession(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.RenameChatSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	session, err := h.aiService.RenameSession(userID, c.Param("session_id"), req.Title)
+	if err != nil {
+		h.handleSessionError(c, err, "Failed to rename session")
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// @Summary 复制会话
+// @Description 复制指定会话及其消息，可指定复制到某条消息为止，用于从中间分叉继续对话
+// @Tags AI
+// @Param session_id path string true "会话ID"
+// @Param request body models.ForkChatSessionRequest false "复制请求"
+// @Success 200 {object} response.Response{data=models.ChatSession}
+// @Router /ai/sessions/{session_id}/fork [post]
+func (h *AIHandler) ForkSession(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.ForkChatSessionRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.Error(c, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+	}
+
+	session, err := h.aiService.ForkSession(userID, c.Param("session_id"), req)
+	if err != nil {
+		h.handleSessionError(c, err, "Failed to fork session")
+		return
+	}
+
+	response.Success(c, session)
+}
+
+// @Summary 删除会话
+// @Description 删除指定会话及其全部消息
+// @Tags AI
+// @Param session_id path string true "会话ID"
+// @Success 200 {object} response.Response
+// @Router /ai/sessions/{session_id} [delete]
+func (h *AIHandler) DeleteSession(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.aiService.DeleteSession(userID, c.Param("session_id")); err != nil {
+		h.handleSessionError(c, err, "Failed to delete session")
+		return
+	}
+
+	response.SuccessMsg(c, "Session deleted successfully")
+}
+
+// handleSessionError 会话不存在返回404，其余返回500
+func (h *AIHandler) handleSessionError(c *gin.Context, err error, message string) {
+	if errors.Is(err, ai.ErrSessionNotFound) {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	logrus.WithError(err).Error(message)
+	response.Error(c, http.StatusInternalServerError, message)
+}
+
+// @Summary 剩余用量配额
+// @Description 获取当前用户当日和当月的 token 配额使用情况
+// @Tags AI
+// @Success 200 {object} response.Response{data=models.AIQuotaResponse}
+// @Router /ai/quota [get]
+func (h *AIHandler) GetQuota(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	quota, err := h.aiService.GetQuota(userID)
+	if err != nil {
+		logrus.Error("Failed to get quota:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to get quota")
+		return
+	}
+
+	response.Success(c, quota)
+}
+
 // @Summary OpenAI兼容聊天接口
 // @Description OpenAI兼容聊天接口，支持流式和非流式响应，可以指定使用的AI模型
 // @Tags AI
@@ -204,12 +464,7 @@ func (h *AIHandler) OpenAIChatCompletion(c *gin.Context) {
 	var req models.OpenAIChatCompletionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logrus.Error("Invalid OpenAI request body:", err)
-		c.JSON(http.StatusBadRequest, models.OpenAIErrorResponse{
-			Error: models.OpenAIError{
-				Message: "Invalid request body",
-				Type:    "invalid_request_error",
-			},
-		})
+		writeOpenAIBadRequest(c, "Invalid request body: "+err.Error())
 		return
 	}
 
@@ -221,18 +476,15 @@ func (h *AIHandler) OpenAIChatCompletion(c *gin.Context) {
 		return
 	}
 
-	resp, err := h.aiService.ChatCompletion(platform, req)
+	resp, err := h.aiService.ChatCompletion(c.Request.Context(), c.GetUint64("user_id"), platform, req)
 	if err != nil {
 		logrus.WithError(err).Error("Failed to call AI service")
-		c.JSON(http.StatusInternalServerError, models.OpenAIErrorResponse{
-			Error: models.OpenAIError{
-				Message: "Failed to process request",
-				Type:    "internal_error",
-			},
-		})
+		writeOpenAIError(c, err)
 		return
 	}
 
+	c.Header(ai.PlatformHeader, resp.Platform)
+
 	c.JSON(http.StatusOK, resp)
 }
 
@@ -242,44 +494,212 @@ func (h *AIHandler) handleOpenAIStreamingChat(c *gin.Context, platform ai.Platfo
 	c.Header("Connection", "keep-alive")
 	c.Header("Access-Control-Allow-Origin", "*")
 
-	err := h.aiService.ChatCompletionStream(platform, req, c.Writer)
+	err := h.aiService.ChatCompletionStream(c.Request.Context(), c.GetUint64("user_id"), platform, req, c.Writer)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			logrus.WithField("user_id", c.GetUint64("user_id")).Info("Client disconnected, chat stream aborted")
+			return
+		}
 		logrus.WithError(err).Error("Failed to stream chat")
+		// 流尚未开始时按普通 JSON 返回错误，客户端才能拿到正确的状态码
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Type")
+			writeOpenAIError(c, err)
+			return
+		}
 		c.SSEvent("error", err.Error())
 		return
 	}
 }
 
+// writeOpenAIBadRequest 以 OpenAI 错误格式返回 400
+func writeOpenAIBadRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, models.OpenAIErrorResponse{
+		Error: models.OpenAIError{
+			Message: message,
+			Type:    "invalid_request_error",
+		},
+	})
+}
+
+// writeOpenAIError 以 OpenAI 错误格式返回，上游平台的错误原样透传
+func writeOpenAIError(c *gin.Context, err error) {
+	if errors.Is(err, ai.ErrQuotaExceeded) {
+		c.JSON(http.StatusTooManyRequests, models.OpenAIErrorResponse{
+			Error: models.OpenAIError{
+				Message: err.Error(),
+				Type:    "insufficient_quota",
+				Code:    "insufficient_quota",
+			},
+		})
+		return
+	}
+
+	if errors.Is(err, ai.ErrModelDisabled) {
+		c.JSON(http.StatusNotFound, models.OpenAIErrorResponse{
+			Error: models.OpenAIError{
+				Message: err.Error(),
+				Type:    "invalid_request_error",
+				Code:    "model_not_found",
+			},
+		})
+		return
+	}
+
+	if errors.Is(err, ai.ErrInvalidContent) || errors.Is(err, ai.ErrImageStorageUnavailable) || errors.Is(err, ai.ErrPlatformUnavailable) {
+		writeOpenAIBadRequest(c, err.Error())
+		return
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode >= http.StatusBadRequest {
+		errType := apiErr.Type
+		if errType == "" {
+			errType = "upstream_error"
+		}
+		c.JSON(apiErr.HTTPStatusCode, models.OpenAIErrorResponse{
+			Error: models.OpenAIError{
+				Message: apiErr.Message,
+				Type:    errType,
+				Param:   apiErr.Param,
+				Code:    apiErr.Code,
+			},
+		})
+		return
+	}
+
+	c.JSON(http.StatusInternalServerError, models.OpenAIErrorResponse{
+		Error: models.OpenAIError{
+			Message: "Failed to process request",
+			Type:    "internal_error",
+		},
+	})
+}
+
 // @Summary 图片生成
-// @Description 基于提示词生成图片，支持自定义参数和不同的AI模型
+// @Description OpenAI兼容的图片生成接口，支持 n、size、quality、style、response_format，persist 为 true 时转存到 OSS
 // @Tags AI
 // @Param platform query string false "平台"
-// @Param request body object{prompt=string,model=string} true "图片生成请求"
-// @Success 200 {object} response.Response{data=[]string}
+// @Param request body models.OpenAIImageRequest true "图片生成请求"
+// @Success 200 {object} models.OpenAIImageResponse
 // @Router /ai/v1/images/generations [post]
 func (h *AIHandler) GenerateImages(c *gin.Context) {
-	var req struct {
-		Prompt string `binding:"required"`
-		Model  string
-	}
-
+	var req models.OpenAIImageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		logrus.Error("Invalid image generation request:", err)
-		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		writeOpenAIBadRequest(c, "Invalid request body: "+err.Error())
 		return
 	}
 
 	// 获取平台参数
 	platform := ai.Platform(c.Query("platform"))
 
-	urls, err := h.aiService.GenerateImages(platform, req.Prompt, req.Model)
+	resp, err := h.aiService.GenerateImages(c.Request.Context(), c.GetUint64("user_id"), platform, req)
+	h.writeImageResponse(c, resp, err)
+}
+
+// @Summary 图片编辑
+// @Description OpenAI兼容的图片编辑接口，multipart/form-data 上传原图和可选的蒙版
+// @Tags AI
+// @Accept multipart/form-data
+// @Param platform query string false "平台"
+// @Param image formData file true "原图"
+// @Param mask formData file false "蒙版，透明区域为需要编辑的部分"
+// @Param prompt formData string true "提示词"
+// @Param model formData string false "模型"
+// @Param n formData int false "生成数量"
+// @Param size formData string false "尺寸"
+// @Param response_format formData string false "url 或 b64_json"
+// @Param persist formData bool false "是否转存到 OSS"
+// @Success 200 {object} models.OpenAIImageResponse
+// @Router /ai/v1/images/edits [post]
+func (h *AIHandler) EditImages(c *gin.Context) {
+	var req models.OpenAIImageEditRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logrus.Error("Invalid image edit request:", err)
+		writeOpenAIBadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	image, ok := readImageFormFile(c, "image", true)
+	if !ok {
+		return
+	}
+	mask, ok := readImageFormFile(c, "mask", false)
+	if !ok {
+		return
+	}
+
+	platform := ai.Platform(c.Query("platform"))
+
+	resp, err := h.aiService.EditImages(c.Request.Context(), c.GetUint64("user_id"), platform, req, *image, mask)
+	h.writeImageResponse(c, resp, err)
+}
+
+// @Summary 图片变体
+// @Description OpenAI兼容的图片变体接口，multipart/form-data 上传原图
+// @Tags AI
+// @Accept multipart/form-data
+// @Param platform query string false "平台"
+// @Param image formData file true "原图"
+// @Param model formData string false "模型"
+// @Param n formData int false "生成数量"
+// @Param size formData string false "尺寸"
+// @Param response_format formData string false "url 或 b64_json"
+// @Param persist formData bool false "是否转存到 OSS"
+// @Success 200 {object} models.OpenAIImageResponse
+// @Router /ai/v1/images/variations [post]
+func (h *AIHandler) CreateImageVariations(c *gin.Context) {
+	var req models.OpenAIImageVariationRequest
+	if err := c.ShouldBind(&req); err != nil {
+		logrus.Error("Invalid image variation request:", err)
+		writeOpenAIBadRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+
+	image, ok := readImageFormFile(c, "image", true)
+	if !ok {
+		return
+	}
+
+	platform := ai.Platform(c.Query("platform"))
+
+	resp, err := h.aiService.CreateImageVariations(c.Request.Context(), c.GetUint64("user_id"), platform, req, *image)
+	h.writeImageResponse(c, resp, err)
+}
+
+func (h *AIHandler) writeImageResponse(c *gin.Context, resp *models.OpenAIImageResponse, err error) {
 	if err != nil {
-		logrus.WithError(err).Error("Failed to generate images")
-		response.Error(c, http.StatusInternalServerError, "Failed to generate images")
+		logrus.WithError(err).Error("Failed to process image request")
+		writeOpenAIError(c, err)
 		return
 	}
 
-	response.Success(c, models.ImageGenerationResponse{
-		Data: urls,
-	})
+	c.Header(ai.PlatformHeader, resp.Platform)
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// readImageFormFile 读取表单中的图片文件，出错时已写入响应
+func readImageFormFile(c *gin.Context, field string, required bool) (*ai.ImageFile, bool) {
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		if !required && errors.Is(err, http.ErrMissingFile) {
+			return nil, true
+		}
+		writeOpenAIBadRequest(c, fmt.Sprintf("Invalid %s file: %s", field, err.Error()))
+		return nil, false
+	}
+
+	image, err := ai.ReadImageFile(fileHeader)
+	if err != nil {
+		if errors.Is(err, ai.ErrImageTooLarge) {
+			writeOpenAIBadRequest(c, fmt.Sprintf("%s: %s", field, err.Error()))
+			return nil, false
+		}
+		logrus.WithError(err).Error("Failed to read uploaded image")
+		writeOpenAIError(c, err)
+		return nil, false
+	}
+	return &image, true
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"ai-models-backend/internal/models"
 	"ai-models-backend/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -21,3 +22,11 @@ func (h *BaseHandler) GetUserID(c *gin.Context) (uint64, bool) {
 	}
 	return userID.(uint64), true
 }
+
+// GetClientInfo returns the client IP and user agent of the request
+func (h *BaseHandler) GetClientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
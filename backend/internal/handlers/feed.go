@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"ai-models-backend/internal/models"
@@ -14,12 +15,14 @@ import (
 type FeedHandler struct {
 	BaseHandler
 	feedService *services.FeedService
+	rbacService *services.RBACService
 }
 
 // NewFeedHandler 创建信息流处理器
-func NewFeedHandler(feedService *services.FeedService) *FeedHandler {
+func NewFeedHandler(feedService *services.FeedService, rbacService *services.RBACService) *FeedHandler {
 	return &FeedHandler{
 		feedService: feedService,
+		rbacService: rbacService,
 	}
 }
 
@@ -47,6 +50,10 @@ func (h *FeedHandler) GetFeedPosts(c *gin.Context) {
 
 	posts, nextCursor, hasMore, err := h.feedService.GetFeedPosts(params)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "获取信息流失败")
 		return
 	}
@@ -77,21 +84,131 @@ func (h *FeedHandler) CreateFeedPost(c *gin.Context) {
 		return
 	}
 
-	// 验证内容不能为空（内容或图片至少有一个）
-	if req.Content == "" && req.ImageURL == "" {
-		response.Error(c, http.StatusBadRequest, "内容和图片不能同时为空")
+	// 内容、图片和媒体附件至少有一个，媒体附件必须是已上传的图片
+	post, err := h.feedService.CreateFeedPost(userID, req)
+	if err != nil {
+		if errors.Is(err, services.ErrFeedEmptyPost) || errors.Is(err, services.ErrFeedInvalidMedia) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "创建帖子失败")
 		return
 	}
 
-	post, err := h.feedService.CreateFeedPost(userID, req)
+	response.Success(c, post)
+}
+
+// @Summary 申请上传帖子图片
+// @Description 服务端在当前用户的目录下生成对象键并返回上传签名，用签名地址 PUT 上传后，发帖时在 media 中传返回的 object_key
+// @ID signFeedMediaUpload
+// @Tags Feed
+// @Param request body models.FeedMediaUploadRequest true "文件信息"
+// @Success 200 {object} response.Response{data=models.SignResponse}
+// @Router /api/feed/media/upload-url [post]
+func (h *FeedHandler) SignFeedMediaUpload(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.FeedMediaUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	result, err := h.feedService.SignFeedMediaUpload(userID, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, "创建帖子失败")
+		if errors.Is(err, services.ErrFeedMediaUnavailable) {
+			response.Error(c, http.StatusServiceUnavailable, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "申请上传失败")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// @Summary 编辑帖子
+// @Description 只能编辑自己发布的帖子，整体替换文字、图片和媒体附件，编辑后记录编辑时间
+// @ID editFeedPost
+// @Tags Feed
+// @Param post_id path string true "帖子ID"
+// @Param request body models.UpdateFeedPostRequest true "帖子内容"
+// @Success 200 {object} response.Response{data=models.FeedPost}
+// @Router /api/feed/posts/{post_id} [put]
+func (h *FeedHandler) EditFeedPost(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	postID := c.Param("post_id")
+	if postID == "" {
+		response.Error(c, http.StatusBadRequest, "帖子ID不能为空")
+		return
+	}
+
+	var req models.UpdateFeedPostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	post, err := h.feedService.EditFeedPost(userID, postID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFeedPostNotFound):
+			response.Error(c, http.StatusNotFound, "帖子不存在")
+		case errors.Is(err, services.ErrFeedForbidden):
+			response.Error(c, http.StatusForbidden, err.Error())
+		case errors.Is(err, services.ErrFeedEmptyPost), errors.Is(err, services.ErrFeedInvalidMedia):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "编辑帖子失败")
+		}
 		return
 	}
 
 	response.Success(c, post)
 }
 
+// @Summary 删除帖子
+// @Description 作者或拥有信息流管理权限的用户可以删除帖子，评论、点赞和媒体附件一并删除
+// @ID deleteFeedPost
+// @Tags Feed
+// @Param post_id path string true "帖子ID"
+// @Success 200 {object} response.Response
+// @Router /api/feed/posts/{post_id} [delete]
+func (h *FeedHandler) DeleteFeedPost(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	postID := c.Param("post_id")
+	if postID == "" {
+		response.Error(c, http.StatusBadRequest, "帖子ID不能为空")
+		return
+	}
+
+	moderator := h.rbacService.Authorize(userID, c.GetBool("two_factor"), models.PermFeedModerate) == nil
+	if err := h.feedService.DeleteFeedPost(userID, postID, moderator); err != nil {
+		switch {
+		case errors.Is(err, services.ErrFeedPostNotFound):
+			response.Error(c, http.StatusNotFound, "帖子不存在")
+		case errors.Is(err, services.ErrFeedForbidden):
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "删除帖子失败")
+		}
+		return
+	}
+
+	response.SuccessMsg(c, "帖子已删除")
+}
+
 // @Summary 设置帖子点赞状态
 // @Description 设置帖子点赞或取消点赞状态，需要登录
 // @ID setFeedPostLike
@@ -118,7 +235,7 @@ func (h *FeedHandler) SetLikePost(c *gin.Context) {
 		return
 	}
 
-	result, err := h.feedService.SetFeedPostLike(userID, postID, req.IsLike)
+	result, err := h.feedService.SetFeedPostLike(userID, postID, *req.IsLike)
 	if err != nil {
 		if err.Error() == "post not found" {
 			response.Error(c, http.StatusNotFound, "帖子不存在")
@@ -160,6 +277,10 @@ func (h *FeedHandler) GetFeedComments(c *gin.Context) {
 
 	comments, nextCursor, hasMore, total, err := h.feedService.GetFeedComments(params)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, "获取评论失败")
 		return
 	}
@@ -200,17 +321,144 @@ func (h *FeedHandler) CreateFeedComment(c *gin.Context) {
 
 	comment, err := h.feedService.CreateFeedComment(userID, postID, req)
 	if err != nil {
-		if err.Error() == "post not found" {
+		switch {
+		case errors.Is(err, services.ErrFeedPostNotFound):
 			response.Error(c, http.StatusNotFound, "帖子不存在")
-			return
+		case errors.Is(err, services.ErrFeedCommentNotFound):
+			response.Error(c, http.StatusNotFound, "回复的评论不存在")
+		case errors.Is(err, services.ErrFeedParentMismatch):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "创建评论失败")
 		}
-		response.Error(c, http.StatusInternalServerError, "创建评论失败")
 		return
 	}
 
 	response.Success(c, comment)
 }
 
+// @Summary 获取评论回复列表
+// @Description 获取指定评论的直接回复，按时间正序，支持cursor分页
+// @ID getFeedCommentReplies
+// @Tags Feed
+// @Param comment_id path string true "评论ID"
+// @Param params query models.ReplyQueryParams false "查询参数"
+// @Success 200 {object} response.Response{data=models.FeedCommentResponse}
+// @Router /api/feed/comments/{comment_id}/replies [get]
+func (h *FeedHandler) GetCommentReplies(c *gin.Context) {
+	commentID := c.Param("comment_id")
+	if commentID == "" {
+		response.Error(c, http.StatusBadRequest, "评论ID不能为空")
+		return
+	}
+
+	var params models.ReplyQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+
+	replies, nextCursor, hasMore, total, err := h.feedService.GetFeedCommentReplies(commentID, params)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFeedCommentNotFound):
+			response.Error(c, http.StatusNotFound, "评论不存在")
+		case errors.Is(err, services.ErrInvalidCursor):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "获取回复失败")
+		}
+		return
+	}
+
+	response.Success(c, models.FeedCommentResponse{
+		Comments:   replies,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+		Total:      total,
+	})
+}
+
+// @Summary 编辑评论
+// @Description 只能编辑自己发布的评论，编辑后记录编辑时间
+// @ID editFeedComment
+// @Tags Feed
+// @Param comment_id path string true "评论ID"
+// @Param request body models.UpdateFeedCommentRequest true "评论内容"
+// @Success 200 {object} response.Response{data=models.FeedComment}
+// @Router /api/feed/comments/{comment_id} [put]
+func (h *FeedHandler) EditFeedComment(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID := c.Param("comment_id")
+	if commentID == "" {
+		response.Error(c, http.StatusBadRequest, "评论ID不能为空")
+		return
+	}
+
+	var req models.UpdateFeedCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+
+	comment, err := h.feedService.EditFeedComment(userID, commentID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFeedCommentNotFound):
+			response.Error(c, http.StatusNotFound, "评论不存在")
+		case errors.Is(err, services.ErrFeedForbidden):
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "编辑评论失败")
+		}
+		return
+	}
+
+	response.Success(c, comment)
+}
+
+// @Summary 删除评论
+// @Description 作者或拥有信息流管理权限的用户可以删除评论，有回复的评论保留占位
+// @ID deleteFeedComment
+// @Tags Feed
+// @Param comment_id path string true "评论ID"
+// @Success 200 {object} response.Response
+// @Router /api/feed/comments/{comment_id} [delete]
+func (h *FeedHandler) DeleteFeedComment(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	commentID := c.Param("comment_id")
+	if commentID == "" {
+		response.Error(c, http.StatusBadRequest, "评论ID不能为空")
+		return
+	}
+
+	moderator := h.rbacService.Authorize(userID, c.GetBool("two_factor"), models.PermFeedModerate) == nil
+	if err := h.feedService.DeleteFeedComment(userID, commentID, moderator); err != nil {
+		switch {
+		case errors.Is(err, services.ErrFeedCommentNotFound):
+			response.Error(c, http.StatusNotFound, "评论不存在")
+		case errors.Is(err, services.ErrFeedForbidden):
+			response.Error(c, http.StatusForbidden, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "删除评论失败")
+		}
+		return
+	}
+
+	response.SuccessMsg(c, "评论已删除")
+}
+
 // @Summary 设置评论点赞状态
 // @Description 设置评论点赞或取消点赞状态，需要登录
 // @ID setFeedCommentLike
@@ -237,9 +485,9 @@ func (h *FeedHandler) SetCommentLike(c *gin.Context) {
 		return
 	}
 
-	result, err := h.feedService.SetFeedCommentLike(userID, commentID, req.IsLike)
+	result, err := h.feedService.SetFeedCommentLike(userID, commentID, *req.IsLike)
 	if err != nil {
-		if err.Error() == "comment not found" {
+		if errors.Is(err, services.ErrFeedCommentNotFound) {
 			response.Error(c, http.StatusNotFound, "评论不存在")
 			return
 		}
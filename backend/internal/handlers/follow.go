@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary 关注用户
+// @Description 关注指定用户，重复关注不报错，需要登录
+// @ID followUser
+// @Tags Feed
+// @Param user_id path string true "用户ID"
+// @Success 200 {object} response.Response{data=models.FollowResult}
+// @Router /api/feed/users/{user_id}/follow [post]
+func (h *FeedHandler) FollowUser(c *gin.Context) {
+	h.setFollow(c, true)
+}
+
+// @Summary 取消关注用户
+// @Description 取消关注指定用户，未关注时不报错，需要登录
+// @ID unfollowUser
+// @Tags Feed
+// @Param user_id path string true "用户ID"
+// @Success 200 {object} response.Response{data=models.FollowResult}
+// @Router /api/feed/users/{user_id}/follow [delete]
+func (h *FeedHandler) UnfollowUser(c *gin.Context) {
+	h.setFollow(c, false)
+}
+
+func (h *FeedHandler) setFollow(c *gin.Context, follow bool) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	followeeID, err := h.feedService.ParseStringToUint64(c.Param("user_id"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var changed bool
+	if follow {
+		changed, err = h.feedService.Follow(userID, followeeID)
+	} else {
+		changed, err = h.feedService.Unfollow(userID, followeeID)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrFollowSelf):
+			response.Error(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, services.ErrFollowUserAbsent):
+			response.Error(c, http.StatusNotFound, err.Error())
+		default:
+			response.Error(c, http.StatusInternalServerError, "操作失败")
+		}
+		return
+	}
+
+	response.Success(c, models.FollowResult{Changed: changed, IsFollowing: follow})
+}
+
+// @Summary 获取粉丝列表
+// @Description 获取关注了指定用户的用户，按关注时间倒序，支持cursor分页
+// @ID getFollowers
+// @Tags Feed
+// @Param user_id path string true "用户ID"
+// @Param params query models.FollowQueryParams false "查询参数"
+// @Success 200 {object} response.Response{data=models.FollowListResponse}
+// @Router /api/feed/users/{user_id}/followers [get]
+func (h *FeedHandler) GetFollowers(c *gin.Context) {
+	h.getFollowList(c, h.feedService.GetFollowers)
+}
+
+// @Summary 获取关注列表
+// @Description 获取指定用户关注的用户，按关注时间倒序，支持cursor分页
+// @ID getFollowing
+// @Tags Feed
+// @Param user_id path string true "用户ID"
+// @Param params query models.FollowQueryParams false "查询参数"
+// @Success 200 {object} response.Response{data=models.FollowListResponse}
+// @Router /api/feed/users/{user_id}/following [get]
+func (h *FeedHandler) GetFollowing(c *gin.Context) {
+	h.getFollowList(c, h.feedService.GetFollowing)
+}
+
+func (h *FeedHandler) getFollowList(c *gin.Context, list func(string, models.FollowQueryParams) (*models.FollowListResponse, error)) {
+	userID := c.Param("user_id")
+	if userID == "" {
+		response.Error(c, http.StatusBadRequest, "用户ID不能为空")
+		return
+	}
+
+	var params models.FollowQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+
+	result, err := list(userID, params)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取列表失败")
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// @Summary 获取关注统计
+// @Description 获取指定用户的关注数、粉丝数以及与当前用户的关注关系，需要登录
+// @ID getFollowStats
+// @Tags Feed
+// @Param user_id path string true "用户ID"
+// @Success 200 {object} response.Response{data=models.FollowStats}
+// @Router /api/feed/users/{user_id}/follow-stats [get]
+func (h *FeedHandler) GetFollowStats(c *gin.Context) {
+	viewerID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.feedService.GetFollowStats(c.Param("user_id"), viewerID)
+	if err != nil {
+		if errors.Is(err, services.ErrFollowUserAbsent) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取关注统计失败")
+		return
+	}
+
+	response.Success(c, stats)
+}
+
+// @Summary 获取首页时间线
+// @Description 获取关注的人和自己发布的帖子，按发布时间倒序，cursor分页与帖子列表相同，需要登录
+// @ID getFeedTimeline
+// @Tags Feed
+// @Param params query models.TimelineQueryParams false "查询参数"
+// @Success 200 {object} response.Response{data=models.FeedPostResponse}
+// @Router /api/feed/timeline [get]
+func (h *FeedHandler) GetTimeline(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var params models.TimelineQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		response.Error(c, http.StatusBadRequest, "参数错误: "+err.Error())
+		return
+	}
+	if params.Limit == 0 {
+		params.Limit = 20
+	}
+
+	posts, nextCursor, hasMore, err := h.feedService.GetTimeline(userID, params)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "获取时间线失败")
+		return
+	}
+
+	response.Success(c, models.FeedPostResponse{
+		Posts:      posts,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	})
+}
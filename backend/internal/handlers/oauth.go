@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services/auth"
+	"ai-models-backend/internal/services/auth/oauth"
+	"ai-models-backend/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary 第三方登录列表
+// @Description 获取已启用的第三方登录名称，如 github、oidc
+// @Tags Auth
+// @Success 200 {object} response.Response{data=[]string}
+// @Router /users/oauth/providers [get]
+func (h *UserHandler) OAuthProviders(c *gin.Context) {
+	response.Success(c, h.authService.OAuthProviders())
+}
+
+// @Summary 第三方登录授权地址
+// @Description 生成第三方授权页地址，前端跳转后第三方回调到 OAUTH_REDIRECT_URL/{provider}，再将 code 和 state 提交到回调接口
+// @Tags Auth
+// @Param provider path string true "第三方名称"
+// @Success 200 {object} response.Response{data=models.OAuthAuthorizeResponse}
+// @Router /users/oauth/{provider}/authorize [get]
+func (h *UserHandler) OAuthAuthorize(c *gin.Context) {
+	h.oauthAuthorize(c, 0)
+}
+
+// @Summary 第三方登录回调
+// @Description 用第三方回调的 code 和 state 登录，首次登录时自动创建账号，返回与密码登录相同的令牌；启用了两步验证时返回 two_factor_required
+// @Tags Auth
+// @Param provider path string true "第三方名称"
+// @Param request body models.OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} response.Response{data=models.UserLoginResponse}
+// @Router /users/oauth/{provider}/callback [post]
+func (h *UserHandler) OAuthCallback(c *gin.Context) {
+	var req models.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, tokens, err := h.authService.OAuthLogin(c.Request.Context(), c.Param("provider"), req, h.GetClientInfo(c))
+	if err != nil {
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			response.Error(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		h.handleOAuthError(c, err, "第三方登录失败")
+		return
+	}
+
+	response.Success(c, models.UserLoginResponse{
+		User:       user.ToResponse(),
+		AuthTokens: *tokens,
+	})
+}
+
+// @Summary 已绑定的第三方账号
+// @Description 获取当前用户绑定的第三方账号
+// @Tags User
+// @Success 200 {object} response.Response{data=[]models.UserIdentity}
+// @Router /users/identities [get]
+func (h *UserHandler) ListIdentities(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	identities, err := h.authService.ListIdentities(userID)
+	if err != nil {
+		logrus.Error("Failed to list identities:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to list identities")
+		return
+	}
+
+	response.Success(c, identities)
+}
+
+// @Summary 绑定第三方账号授权地址
+// @Description 为当前用户绑定第三方账号，生成的 state 只能用于绑定接口
+// @Tags User
+// @Param provider path string true "第三方名称"
+// @Success 200 {object} response.Response{data=models.OAuthAuthorizeResponse}
+// @Router /users/identities/{provider}/authorize [get]
+func (h *UserHandler) LinkIdentityAuthorize(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+	h.oauthAuthorize(c, userID)
+}
+
+// @Summary 绑定第三方账号
+// @Description 用第三方回调的 code 和 state 为当前用户绑定第三方账号
+// @Tags User
+// @Param provider path string true "第三方名称"
+// @Param request body models.OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} response.Response{data=models.UserIdentity}
+// @Router /users/identities/{provider} [post]
+func (h *UserHandler) LinkIdentity(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	identity, err := h.authService.LinkIdentity(c.Request.Context(), userID, c.Param("provider"), req, h.GetClientInfo(c))
+	if err != nil {
+		h.handleOAuthError(c, err, "绑定第三方账号失败")
+		return
+	}
+
+	response.Success(c, identity)
+}
+
+// @Summary 解绑第三方账号
+// @Description 解绑当前用户的第三方账号；邮箱未验证时不能解绑最后一个第三方账号
+// @Tags User
+// @Param provider path string true "第三方名称"
+// @Success 200 {object} response.Response
+// @Router /users/identities/{provider} [delete]
+func (h *UserHandler) UnlinkIdentity(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	err := h.authService.UnlinkIdentity(c.Request.Context(), userID, c.Param("provider"), h.GetClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrIdentityNotFound):
+			response.Error(c, http.StatusNotFound, err.Error())
+		case errors.Is(err, auth.ErrIdentityLastLogin):
+			response.Error(c, http.StatusConflict, err.Error())
+		default:
+			logrus.Error("Failed to unlink identity:", err)
+			response.Error(c, http.StatusInternalServerError, "解绑第三方账号失败")
+		}
+		return
+	}
+
+	response.SuccessMsg(c, "已解绑")
+}
+
+func (h *UserHandler) oauthAuthorize(c *gin.Context, userID uint64) {
+	data, err := h.authService.OAuthAuthorize(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		h.handleOAuthError(c, err, "获取授权地址失败")
+		return
+	}
+	response.Success(c, data)
+}
+
+// handleOAuthError 第三方登录和绑定的通用错误处理
+func (h *UserHandler) handleOAuthError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, auth.ErrOAuthProviderUnknown):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, auth.ErrOAuthStateInvalid),
+		errors.Is(err, auth.ErrOAuthEmailRequired):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, auth.ErrOAuthEmailConflict),
+		errors.Is(err, auth.ErrIdentityLinked):
+		response.Error(c, http.StatusConflict, err.Error())
+	case errors.Is(err, oauth.ErrExchangeFailed),
+		errors.Is(err, oauth.ErrInvalidIDToken):
+		// 授权码无效或第三方返回异常，详细原因只记录日志
+		logrus.Warn("OAuth exchange failed:", err)
+		response.Error(c, http.StatusBadRequest, message)
+	default:
+		logrus.Error("OAuth request failed:", err)
+		response.Error(c, http.StatusInternalServerError, message)
+	}
+}
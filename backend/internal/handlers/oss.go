@@ -93,6 +93,11 @@ func (h *OSSHandler) SignToUpload(c *gin.Context) {
 		}
 	}
 
+	if services.ReservedObjectKey(finalObjectKey) {
+		response.Error(c, http.StatusForbidden, "帖子图片请通过信息流接口上传")
+		return
+	}
+
 	fileType := req.FileType
 	if fileType == "" {
 		fileType = "application/octet-stream"
@@ -243,6 +248,11 @@ func (h *OSSHandler) UploadFile(c *gin.Context) {
 		}
 	}
 
+	if services.ReservedObjectKey(objectKey) {
+		response.Error(c, http.StatusForbidden, "帖子图片请通过信息流接口上传")
+		return
+	}
+
 	// 打开文件
 	file, err := fileHeader.Open()
 	if err != nil {
@@ -309,6 +319,10 @@ func (h *OSSHandler) DeleteFile(c *gin.Context) {
 		response.Error(c, http.StatusBadRequest, "objectKey不能为空")
 		return
 	}
+	if services.ReservedObjectKey(req.ObjectKey) {
+		response.Error(c, http.StatusForbidden, "帖子图片随帖子删除")
+		return
+	}
 
 	if err := h.ossService.DeleteFile(req.ObjectKey); err != nil {
 		logrus.WithError(err).Error("删除文件失败")
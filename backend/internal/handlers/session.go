@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services/auth"
+	"ai-models-backend/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary 登录设备列表
+// @Description 获取当前用户有效的登录会话，包括设备、User-Agent、登录 IP 和最近活跃时间，current 标记当前会话
+// @Tags User
+// @Success 200 {object} response.Response{data=[]models.UserSession}
+// @Router /users/sessions [get]
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var sessions []models.UserSession
+	sessions, err := h.authService.ListSessions(c.Request.Context(), userID, c.GetString("session_id"))
+	if err != nil {
+		logrus.Error("Failed to list sessions:", err)
+		response.Error(c, http.StatusInternalServerError, "获取登录设备失败")
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// @Summary 退出指定设备
+// @Description 吊销当前用户的某个登录会话，该设备上的 access token 和 refresh token 立即失效
+// @Tags User
+// @Param id path string true "会话ID"
+// @Success 200 {object} response.Response
+// @Router /users/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		if errors.Is(err, auth.ErrSessionNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logrus.Error("Failed to revoke session:", err)
+		response.Error(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
+
+	response.SuccessMsg(c, "已退出该设备")
+}
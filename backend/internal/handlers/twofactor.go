@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services/auth"
+	"ai-models-backend/pkg/response"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// @Summary 两步验证登录
+// @Description 密码登录返回 two_factor_required 时，用 challenge_token 和身份验证器的验证码（或恢复码）换取令牌
+// @ID loginTwoFactor
+// @Tags Auth
+// @Param request body models.TwoFactorLoginRequest true "两步验证请求"
+// @Success 200 {object} response.Response{data=models.UserLoginResponse}
+// @Router /users/login/2fa [post]
+func (h *UserHandler) LoginTwoFactor(c *gin.Context) {
+	var req models.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, tokens, err := h.authService.LoginTwoFactor(c.Request.Context(), req.ChallengeToken, req.Code, h.GetClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrTwoFactorChallengeInvalid),
+			errors.Is(err, auth.ErrTwoFactorCodeInvalid),
+			errors.Is(err, auth.ErrInvalidCredentials):
+			logrus.Warn("Two-factor login failed:", err)
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		default:
+			h.handleTwoFactorError(c, err, "登录失败")
+		}
+		return
+	}
+
+	response.Success(c, models.UserLoginResponse{
+		User:       user.ToResponse(),
+		AuthTokens: *tokens,
+	})
+}
+
+// @Summary 两步验证状态
+// @Description 获取当前用户是否启用了两步验证、剩余恢复码数量，以及角色是否要求两步验证
+// @Tags User
+// @Success 200 {object} response.Response{data=models.TwoFactorStatus}
+// @Router /users/2fa [get]
+func (h *UserHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		h.handleTwoFactorError(c, err, "获取两步验证状态失败")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// @Summary 绑定身份验证器
+// @Description 生成新的 TOTP 密钥和 otpauth:// 地址（用于生成二维码），之后需要调用启用接口确认
+// @Tags User
+// @Success 200 {object} response.Response{data=models.TwoFactorSetupResponse}
+// @Router /users/2fa/setup [post]
+func (h *UserHandler) SetupTwoFactor(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	data, err := h.authService.SetupTwoFactor(userID)
+	if err != nil {
+		h.handleTwoFactorError(c, err, "绑定身份验证器失败")
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// @Summary 启用两步验证
+// @Description 输入身份验证器中的验证码确认绑定，返回只显示一次的恢复码；重新登录后会话才视为已通过两步验证
+// @Tags User
+// @Param request body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=models.TwoFactorRecoveryCodesResponse}
+// @Router /users/2fa/enable [post]
+func (h *UserHandler) EnableTwoFactor(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.authService.EnableTwoFactor(userID, req.Code, h.GetClientInfo(c))
+	if err != nil {
+		h.handleTwoFactorError(c, err, "启用两步验证失败")
+		return
+	}
+
+	response.Success(c, models.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary 关闭两步验证
+// @Description 需要密码和验证码（或恢复码），角色要求两步验证时不能关闭
+// @Tags User
+// @Param request body models.TwoFactorDisableRequest true "关闭请求"
+// @Success 200 {object} response.Response
+// @Router /users/2fa/disable [post]
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, req, h.GetClientInfo(c)); err != nil {
+		h.handleTwoFactorError(c, err, "关闭两步验证失败")
+		return
+	}
+
+	response.SuccessMsg(c, "两步验证已关闭")
+}
+
+// @Summary 重新生成恢复码
+// @Description 输入身份验证器中的验证码，生成新的恢复码，旧的恢复码全部失效
+// @Tags User
+// @Param request body models.TwoFactorCodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=models.TwoFactorRecoveryCodesResponse}
+// @Router /users/2fa/recovery-codes [post]
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, req.Code, h.GetClientInfo(c))
+	if err != nil {
+		h.handleTwoFactorError(c, err, "生成恢复码失败")
+		return
+	}
+
+	response.Success(c, models.TwoFactorRecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// handleTwoFactorError 两步验证接口的通用错误处理
+func (h *UserHandler) handleTwoFactorError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, auth.ErrTwoFactorCodeInvalid),
+		errors.Is(err, auth.ErrPasswordIncorrect),
+		errors.Is(err, auth.ErrTwoFactorSetupRequired):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	case errors.Is(err, auth.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, auth.ErrTwoFactorNotEnabled),
+		errors.Is(err, auth.ErrTwoFactorEnforced):
+		response.Error(c, http.StatusConflict, err.Error())
+	default:
+		logrus.Error("Two-factor request failed:", err)
+		response.Error(c, http.StatusInternalServerError, message)
+	}
+}
+
+// respondTwoFactorChallenge 密码或第三方登录需要两步验证时返回 challenge
+func respondTwoFactorChallenge(c *gin.Context, err error) bool {
+	var challenge *auth.TwoFactorChallengeError
+	if !errors.As(err, &challenge) {
+		return false
+	}
+	response.Success(c, models.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge.Token,
+		ExpiresAt:         challenge.ExpiresAt,
+	})
+	return true
+}
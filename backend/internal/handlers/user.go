@@ -5,9 +5,10 @@ import (
 	"ai-models-backend/internal/services"
 	"ai-models-backend/internal/services/auth"
 	"ai-models-backend/pkg/response"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
-	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -28,7 +29,7 @@ func NewUserHandler(userService *services.UserService, authService *auth.AuthSer
 }
 
 // @Summary 用户注册
-// @Description 新用户注册账号，创建用户账户并返回JWT token，用于后续身份认证
+// @Description 新用户注册账号，创建用户账户并返回 access token 和 refresh token，用于后续身份认证
 // @ID register
 // @Tags Auth
 // @Param request body models.UserCreateRequest true "注册请求"
@@ -42,23 +43,27 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.Register(req)
+	user, tokens, err := h.authService.Register(c.Request.Context(), req, h.GetClientInfo(c))
 	if err != nil {
 		logrus.Error("Failed to register user:", err)
+		if errors.Is(err, services.ErrWeakPassword) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	data := models.UserCreateResponse{
-		User:  user.ToResponse(),
-		Token: token,
+		User:       user.ToResponse(),
+		AuthTokens: *tokens,
 	}
 
 	response.Success(c, data)
 }
 
 // @Summary 用户登录
-// @Description 用户使用用户名和密码登录系统，验证成功后返回JWT token和用户信息
+// @Description 用户使用用户名和密码登录系统，验证成功后返回 access token、refresh token 和用户信息；启用了两步验证时返回 two_factor_required 和 challenge_token，需要再调用 /users/login/2fa；连续失败后按账号和 IP 延迟或临时锁定，返回 429
 // @ID login
 // @Tags Auth
 // @Param request body models.UserLoginRequest true "登录请求"
@@ -72,21 +77,176 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, token, err := h.authService.Login(req.Username, req.Password)
+	user, tokens, err := h.authService.Login(c.Request.Context(), req.Username, req.Password, h.GetClientInfo(c))
 	if err != nil {
-		logrus.Error("Authentication failed:", err)
-		response.Error(c, http.StatusUnauthorized, err.Error())
+		if respondTwoFactorChallenge(c, err) {
+			return
+		}
+
+		var throttled *auth.LoginThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			logrus.Warn("Login throttled:", err)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			logrus.Warn("Authentication failed:", err)
+			response.Error(c, http.StatusUnauthorized, err.Error())
+		default:
+			logrus.Error("Failed to login:", err)
+			response.Error(c, http.StatusInternalServerError, "登录失败")
+		}
 		return
 	}
 
 	data := models.UserLoginResponse{
-		User:  user.ToResponse(),
-		Token: token,
+		User:       user.ToResponse(),
+		AuthTokens: *tokens,
 	}
 
 	response.Success(c, data)
 }
 
+// @Summary 刷新令牌
+// @Description 使用 refresh token 换取新的 access token 和 refresh token，旧的 refresh token 随即失效；重复使用已失效的 refresh token 会吊销该登录
+// @ID refreshToken
+// @Tags Auth
+// @Param request body models.RefreshTokenRequest true "刷新令牌请求"
+// @Success 200 {object} response.Response{data=models.AuthTokens}
+// @Router /users/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenStoreUnavailable) {
+			logrus.Error("Failed to refresh token:", err)
+			response.Error(c, http.StatusInternalServerError, "Failed to refresh token")
+			return
+		}
+		logrus.Warn("Refresh token rejected:", err)
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.Success(c, tokens)
+}
+
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证，令牌只能使用一次
+// @ID verifyEmail
+// @Tags Auth
+// @Param request body models.VerifyEmailRequest true "邮箱验证请求"
+// @Success 200 {object} response.Response{data=models.UserResponse}
+// @Router /users/verify-email [post]
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	user, err := h.authService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		if errors.Is(err, auth.ErrEmailTokenInvalid) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.Error("Failed to verify email:", err)
+		response.Error(c, http.StatusInternalServerError, "邮箱验证失败")
+		return
+	}
+
+	response.Success(c, user.ToResponse())
+}
+
+// @Summary 重新发送验证邮件
+// @Description 向当前用户的邮箱重新发送验证邮件，旧的验证链接仍然有效
+// @ID resendVerification
+// @Tags Auth
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /users/resend-verification [post]
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(c.Request.Context(), userID); err != nil {
+		if errors.Is(err, auth.ErrEmailAlreadyVerified) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, auth.ErrMailTooFrequent) {
+			response.Error(c, http.StatusTooManyRequests, err.Error())
+			return
+		}
+		logrus.Error("Failed to send verification email:", err)
+		response.Error(c, http.StatusInternalServerError, "发送验证邮件失败")
+		return
+	}
+
+	response.SuccessMsg(c, "验证邮件已发送")
+}
+
+// @Summary 忘记密码
+// @Description 向邮箱发送重置密码链接，无论邮箱是否注册都返回成功
+// @ID forgotPassword
+// @Tags Auth
+// @Param request body models.ForgotPasswordRequest true "忘记密码请求"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /users/forgot-password [post]
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		logrus.Error("Failed to request password reset:", err)
+		response.Error(c, http.StatusInternalServerError, "请求重置密码失败")
+		return
+	}
+
+	response.SuccessMsg(c, "如果该邮箱已注册，重置密码邮件已发送")
+}
+
+// @Summary 重置密码
+// @Description 使用重置密码邮件中的令牌设置新密码，令牌只能使用一次，成功后所有设备需要重新登录
+// @ID resetPassword
+// @Tags Auth
+// @Param request body models.ResetPasswordRequest true "重置密码请求"
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /users/reset-password [post]
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.authService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		if errors.Is(err, auth.ErrEmailTokenInvalid) || errors.Is(err, services.ErrWeakPassword) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.Error("Failed to reset password:", err)
+		response.Error(c, http.StatusInternalServerError, "重置密码失败")
+		return
+	}
+
+	response.SuccessMsg(c, "密码已重置，请重新登录")
+}
+
 // GetProfile 获取当前用户信息
 // @Summary 获取用户信息
 // @Description 获取当前登录用户的个人资料信息，包括基本信息、角色等
@@ -179,6 +339,10 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	if err := h.userService.DeleteUser(id); err != nil {
+		if errors.Is(err, services.ErrLastAdministrator) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		logrus.Error("Failed to delete user:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to delete user")
 		return
@@ -252,6 +416,10 @@ func (h *UserHandler) DeactivateUser(c *gin.Context) {
 	}
 
 	if err := h.userService.DeactivateUser(userID); err != nil {
+		if errors.Is(err, services.ErrLastAdministrator) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		logrus.Error("Failed to deactivate user:", err)
 		response.Error(c, http.StatusInternalServerError, "Failed to deactivate user")
 		return
@@ -261,7 +429,7 @@ func (h *UserHandler) DeactivateUser(c *gin.Context) {
 }
 
 // @Summary 修改密码
-// @Description 用户修改自己的登录密码，需要提供旧密码进行验证
+// @Description 用户修改自己的登录密码，需要提供旧密码进行验证，新密码需符合密码策略
 // @Tags User
 // @Param request body models.ChangePasswordRequest true "修改密码请求"
 // @Success 200 {object} response.Response{data=map[string]any}
@@ -285,6 +453,8 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 		// 根据错误信息返回不同的状态码
 		if err.Error() == "原密码错误" {
 			response.Error(c, http.StatusBadRequest, "原密码错误")
+		} else if errors.Is(err, services.ErrWeakPassword) {
+			response.Error(c, http.StatusBadRequest, err.Error())
 		} else {
 			response.Error(c, http.StatusInternalServerError, "修改密码失败")
 		}
@@ -315,26 +485,131 @@ func (h *UserHandler) CheckUserField(c *gin.Context) {
 }
 
 // @Summary 用户退出登录
-// @Description 用户主动退出登录，清除服务端的登录状态和token
+// @Description 用户主动退出当前设备的登录，当前 access token 和 refresh token 立即失效
 // @Tags Auth
 // @Success 200 {object} response.Response{data=map[string]any}
 // @Router /users/logout [post]
 func (h *UserHandler) Logout(c *gin.Context) {
-	// 从请求头中提取token
-	authHeader := c.GetHeader("Authorization")
-	if authHeader == "" {
-		response.Error(c, http.StatusBadRequest, "缺少认证头")
+	if err := h.authService.Logout(c.Request.Context(), c.GetString("session_id")); err != nil {
+		logrus.Error("Failed to logout:", err)
+		response.Error(c, http.StatusInternalServerError, "退出登录失败")
 		return
 	}
 
-	token := strings.TrimPrefix(authHeader, "Bearer ")
-	if token == "" {
-		response.Error(c, http.StatusBadRequest, "无效的token格式")
+	response.SuccessMsg(c, "退出登录成功")
+}
+
+// @Summary 退出所有设备
+// @Description 退出当前用户在所有设备上的登录，全部 access token 和 refresh token 立即失效
+// @Tags Auth
+// @Success 200 {object} response.Response{data=map[string]any}
+// @Router /users/logout-all [post]
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
 		return
 	}
 
-	// 将token添加到黑名单
-	h.authService.Logout(token)
+	if err := h.authService.LogoutAll(c.Request.Context(), userID); err != nil {
+		logrus.Error("Failed to logout all sessions:", err)
+		response.Error(c, http.StatusInternalServerError, "退出登录失败")
+		return
+	}
 
-	response.SuccessMsg(c, "退出登录成功")
+	response.SuccessMsg(c, "已退出所有设备")
+}
+
+// @Summary JWT 公钥
+// @Description 以 JWK Set 格式返回验证 token 的全部公钥，按 token header 中的 kid 选择
+// @Tags Auth
+// @Success 200 {object} models.JWKSet
+// @Router /.well-known/jwks.json [get]
+func (h *UserHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.JWKS())
+}
+
+// @Summary 创建 API Key
+// @Description 创建个人 API Key，用于以 Bearer 方式调用 /ai/v1 接口，明文只返回一次
+// @Tags User
+// @Param request body models.APIKeyCreateRequest true "创建 API Key 请求"
+// @Success 200 {object} response.Response{data=models.APIKeyCreateResponse}
+// @Router /users/api-keys [post]
+func (h *UserHandler) CreateAPIKey(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logrus.Error("Invalid request body:", err)
+		response.Error(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	key, err := h.authService.CreateAPIKey(userID, req)
+	if err != nil {
+		if errors.Is(err, auth.ErrAPIKeyLimit) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.Error("Failed to create API key:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to create API key")
+		return
+	}
+
+	response.Success(c, key)
+}
+
+// @Summary API Key 列表
+// @Description 获取当前用户的全部 API Key（不含明文）
+// @Tags User
+// @Success 200 {object} response.Response{data=[]models.APIKey}
+// @Router /users/api-keys [get]
+func (h *UserHandler) ListAPIKeys(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	keys, err := h.authService.ListAPIKeys(userID)
+	if err != nil {
+		logrus.Error("Failed to list API keys:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to list API keys")
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// @Summary 吊销 API Key
+// @Description 吊销指定的 API Key，立即失效
+// @Tags User
+// @Param id path string true "API Key ID"
+// @Success 200 {object} response.Response
+// @Router /users/api-keys/{id} [delete]
+func (h *UserHandler) RevokeAPIKey(c *gin.Context) {
+	userID, ok := h.GetUserID(c)
+	if !ok {
+		return
+	}
+
+	keyID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.authService.RevokeAPIKey(userID, keyID); err != nil {
+		if errors.Is(err, auth.ErrAPIKeyNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		logrus.Error("Failed to revoke API key:", err)
+		response.Error(c, http.StatusInternalServerError, "Failed to revoke API key")
+		return
+	}
+
+	response.SuccessMsg(c, "API Key 已吊销")
 }
@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
 	"ai-models-backend/internal/services"
 	"ai-models-backend/internal/services/auth"
 	"ai-models-backend/pkg/response"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -24,7 +25,7 @@ func AuthRequired(authService *auth.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := authService.ValidateToken(token)
+		claims, err := authService.ValidateToken(c.Request.Context(), token)
 		if err != nil {
 			logrus.Error("Invalid token:", err)
 			response.Error(c, http.StatusUnauthorized, "Invalid token")
@@ -32,6 +33,53 @@ func AuthRequired(authService *auth.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
+		c.Set("two_factor", claims.TwoFactor)
+		c.Next()
+	}
+}
+
+// AIAuth AI 代理接口认证，Bearer 凭证可以是 API Key（sk-...）或 JWT
+// 错误按 OpenAI 格式返回，便于 OpenAI SDK 直接使用
+func AIAuth(authService *auth.AuthService) gin.HandlerFunc {
+	abort := func(c *gin.Context, message string) {
+		c.JSON(http.StatusUnauthorized, models.OpenAIErrorResponse{
+			Error: models.OpenAIError{
+				Message: message,
+				Type:    "invalid_request_error",
+				Code:    "invalid_api_key",
+			},
+		})
+		c.Abort()
+	}
+
+	return func(c *gin.Context) {
+		token, err := extractToken(c)
+		if err != nil {
+			abort(c, "Missing bearer authentication in header")
+			return
+		}
+
+		if auth.IsAPIKey(token) {
+			userID, err := authService.ValidateAPIKey(token)
+			if err != nil {
+				logrus.Error("Invalid API key:", err)
+				abort(c, "Incorrect API key provided")
+				return
+			}
+			c.Set("user_id", userID)
+			c.Next()
+			return
+		}
+
+		claims, err := authService.ValidateToken(c.Request.Context(), token)
+		if err != nil {
+			logrus.Error("Invalid token:", err)
+			abort(c, "Invalid token")
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Next()
 	}
@@ -56,46 +104,63 @@ func extractToken(c *gin.Context) (string, error) {
 	return token, nil
 }
 
-// AdminRequired 管理员权限中间件
-func AdminRequired(authService *auth.AuthService, userService *services.UserService) gin.HandlerFunc {
-	return gin.HandlerFunc(func(c *gin.Context) {
-		if !config.AdminCheck {
-			c.Next()
+// RequirePermission 要求当前用户拥有全部指定的权限，需要放在 AuthRequired 之后
+// 没有认证信息时直接拒绝，路由漏配 AuthRequired 也不会放行；角色要求两步验证时会话必须通过两步验证
+func RequirePermission(rbacService *services.RBACService, permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("user_id")
+		if !exists {
+			response.Error(c, http.StatusUnauthorized, "Authentication required")
+			c.Abort()
 			return
 		}
 
-		// 先执行用户认证
-		authMiddleware := AuthRequired(authService)
-		authMiddleware(c)
-
-		// 如果认证失败，AuthRequired 已经处理了响应和 Abort
-		if c.IsAborted() {
+		err := rbacService.Authorize(userID.(uint64), c.GetBool("two_factor"), permissions...)
+		switch {
+		case err == nil:
+		case errors.Is(err, services.ErrPermissionDenied):
+			response.Error(c, http.StatusForbidden, "Permission denied")
+			c.Abort()
+			return
+		case errors.Is(err, services.ErrRoleTwoFactorRequired):
+			response.Error(c, http.StatusForbidden, err.Error())
+			c.Abort()
+			return
+		default:
+			logrus.Error("Failed to check permissions:", err)
+			response.Error(c, http.StatusInternalServerError, "Failed to verify permissions")
+			c.Abort()
 			return
 		}
 
-		// 从上下文中获取用户ID (这里其实一定存在)
+		c.Next()
+	}
+}
+
+// VerifiedEmailRequired 邮箱已验证的用户才能访问，需要放在 AuthRequired 之后
+func VerifiedEmailRequired(userService *services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
-			response.Error(c, http.StatusInternalServerError, "User ID not found in context")
+			response.Error(c, http.StatusUnauthorized, "Authentication required")
 			c.Abort()
 			return
 		}
 
-		// 认证成功，检查管理员权限
-		isAdmin, err := userService.IsAdmin(userID.(uint64))
+		verified, err := userService.IsEmailVerified(userID.(uint64))
 		if err != nil {
-			logrus.Error("Failed to check admin status:", err)
-			response.Error(c, http.StatusInternalServerError, "Failed to verify admin status")
+			logrus.Error("Failed to check email verification:", err)
+			response.Error(c, http.StatusInternalServerError, "Failed to verify email status")
 			c.Abort()
 			return
 		}
 
-		if !isAdmin {
-			response.Error(c, http.StatusForbidden, "Admin access required")
+		if !verified {
+			response.Error(c, http.StatusForbidden, "请先验证邮箱")
 			c.Abort()
 			return
 		}
 
 		c.Next()
-	})
+	}
 }
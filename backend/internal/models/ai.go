@@ -1,23 +1,30 @@
 package models
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
 // 聊天请求
 type ChatRequest struct {
-	Message string `json:"message" binding:"required"`
-	Model   string `json:"model"`
-	Stream  bool `json:"stream,omitempty"`
+	Message   string `json:"message" binding:"required"`
+	Model     string `json:"model"`
+	Stream    bool `json:"stream,omitempty"`
+	SessionID string `json:"session_id,omitempty" binding:"omitempty,max=64"` // 会话ID，为空时自动创建新会话
 }
 
 // 聊天响应
 type ChatResponse struct {
 	ID        string `json:"id"`
+	SessionID string `json:"session_id"`
 	Message   string `json:"message"`
 	Model     string `json:"model"`
 	CreatedAt time.Time `json:"created_at"`
 	Usage     Usage `json:"usage"`
+	Platform  string `json:"platform,omitempty"` // 实际提供服务的平台
 }
 
 // 生成请求
@@ -45,14 +52,54 @@ type Usage struct {
 
 // AI 模型
 type AIModel struct {
-	ID           string `json:"id"`
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Provider     string `json:"provider"`
-	Type         string `json:"type"`
-	Capabilities []string `json:"capabilities"`
-	MaxTokens    int `json:"max_tokens"`
-	IsActive     bool `json:"is_active"`
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Provider      string          `json:"provider"`
+	Type          string          `json:"type"` // chat, embedding, image, audio, rerank
+	Capabilities  []string        `json:"capabilities"`
+	MaxTokens     int             `json:"max_tokens"`     // 最大输出 token，未知时为 0
+	ContextLength int             `json:"context_length"` // 上下文长度，未知时为 0
+	Pricing       *AIModelPricing `json:"pricing,omitempty"`
+	Platforms     []string        `json:"platforms"` // 提供该模型的平台
+	Aliases       []string        `json:"aliases,omitempty"`
+	Created       int64           `json:"created,omitempty"`
+	IsActive      bool            `json:"is_active"`
+}
+
+// 模型价格（每百万 token）
+type AIModelPricing struct {
+	Input    float64 `json:"input"`
+	Output   float64 `json:"output"`
+	Currency string  `json:"currency"` // USD, CNY
+}
+
+// 管理员对模型的启用/禁用及别名设置
+type AIModelOverride struct {
+	BaseModel
+	ModelID  string `json:"model_id" gorm:"type:varchar(128);uniqueIndex;not null"`
+	Disabled bool   `json:"disabled" gorm:"default:false"`
+	Alias    string `json:"alias,omitempty" gorm:"type:varchar(128);index"` // 对外的别名，请求时解析为 ModelID
+}
+
+// 更新模型设置请求，字段为空表示不修改
+type AIModelOverrideRequest struct {
+	ModelID string  `json:"model_id" binding:"required,max=128"`
+	Enabled *bool   `json:"enabled,omitempty"`
+	Alias   *string `json:"alias,omitempty" binding:"omitempty,max=128"` // 传空字符串清除别名
+}
+
+// OpenAI 兼容模型列表
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type OpenAIModelList struct {
+	Object string        `json:"object"`
+	Data   []OpenAIModel `json:"data"`
 }
 
 // 聊天消息
@@ -65,68 +112,347 @@ type ChatMessage struct {
 
 // 对话历史
 type ConversationHistory struct {
-	ID        uint64 `json:"id" gorm:"primaryKey"`
-	UserID    uint64 `json:"user_id" gorm:"not null"`
-	SessionID string `json:"session_id" gorm:"not null"`
+	ID        uint64 `json:"id" gorm:"primaryKey" swaggertype:"string"`
+	UserID    uint64 `json:"user_id" gorm:"not null;index" swaggertype:"string"`
+	SessionID string `json:"session_id" gorm:"not null;index:idx_session_created"`
 	Role      string `json:"role" gorm:"not null"`
 	Content   string `json:"content" gorm:"type:text;not null"`
 	Model     string `json:"model"`
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_session_created"`
 	User      User `json:"-" gorm:"foreignKey:UserID"`
 }
 
+// AI 调用用量流水，每次调用（含流式）记录一条
+type AIUsageLog struct {
+	ID               uint64    `json:"id" gorm:"primaryKey" swaggertype:"string"`
+	UserID           uint64    `json:"user_id" gorm:"not null;index:idx_usage_user_created" swaggertype:"string"` // 0 表示匿名调用
+	Endpoint         string    `json:"endpoint" gorm:"type:varchar(32)"`                                        // chat, chat_completions, images
+	Platform         string    `json:"platform" gorm:"type:varchar(32)"`
+	Model            string    `json:"model" gorm:"type:varchar(128)"`
+	Stream           bool      `json:"stream"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	TotalTokens      int       `json:"total_tokens"`
+	LatencyMs        int64     `json:"latency_ms"`
+	Status           string    `json:"status" gorm:"type:varchar(16)"` // success, error, canceled
+	Error            string    `json:"error,omitempty" gorm:"type:varchar(255)"`
+	CreatedAt        time.Time `json:"created_at" gorm:"index:idx_usage_user_created"`
+}
+
+// 用量配额状态
+type AIQuotaStatus struct {
+	Limit     int64     `json:"limit"`     // 0 表示不限
+	Used      int64     `json:"used"`      // 已用 token
+	Remaining int64     `json:"remaining"` // 不限时为 -1
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// 剩余配额响应
+type AIQuotaResponse struct {
+	Role    string        `json:"role"`
+	Daily   AIQuotaStatus `json:"daily"`
+	Monthly AIQuotaStatus `json:"monthly"`
+}
+
+// 对话会话，一个会话下挂多条 ConversationHistory
+type ChatSession struct {
+	BaseModel
+	UserID        uint64    `json:"user_id" gorm:"not null;index:idx_user_last_message" swaggertype:"string"`
+	SessionID     string    `json:"session_id" gorm:"type:varchar(64);uniqueIndex;not null"`
+	Title         string    `json:"title" gorm:"type:varchar(100)"`
+	Model         string    `json:"model" gorm:"type:varchar(100)"`
+	ForkedFrom    string    `json:"forked_from,omitempty" gorm:"type:varchar(64)"` // 来源会话ID
+	MessageCount  int       `json:"message_count" gorm:"default:0"`
+	LastMessageAt time.Time `json:"last_message_at" gorm:"index:idx_user_last_message"`
+}
+
+// 重命名会话请求
+type RenameChatSessionRequest struct {
+	Title string `json:"title" binding:"required,max=100"`
+}
+
+// 复制会话请求
+type ForkChatSessionRequest struct {
+	MessageID uint64 `json:"message_id,omitempty" swaggertype:"string"` // 复制到该消息为止（包含），为空复制全部
+	Title     string `json:"title,omitempty" binding:"max=100"`
+}
+
 // OpenAI 兼容模型
 type OpenAIMessage struct {
-	Role    string `json:"role" binding:"required"`
-	Content string `json:"content" binding:"required"`
+	Role             string           `json:"role" binding:"required"`
+	Content          OpenAIContent    `json:"content" swaggertype:"string"` // 字符串或内容片段数组
+	Name             string           `json:"name,omitempty"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"` // 推理内容（deepseek 等扩展字段）
+	Refusal          string           `json:"refusal,omitempty"`
+	ToolCalls        []OpenAIToolCall `json:"tool_calls,omitempty"`   // assistant 消息的工具调用
+	ToolCallID       string           `json:"tool_call_id,omitempty"` // tool 消息对应的调用ID
+}
+
+// OpenAIContent 消息内容，兼容字符串和内容片段数组两种写法
+type OpenAIContent struct {
+	Text  string
+	Parts []OpenAIContentPart // 不为空时按数组序列化
+}
+
+// 内容片段类型
+const (
+	ContentPartText       = "text"
+	ContentPartImageURL   = "image_url"
+	ContentPartInputAudio = "input_audio"
+)
+
+// OpenAI 内容片段: text, image_url, input_audio
+type OpenAIContentPart struct {
+	Type       string            `json:"type"`
+	Text       string            `json:"text,omitempty"`
+	ImageURL   *OpenAIImageURL   `json:"image_url,omitempty"`
+	InputAudio *OpenAIInputAudio `json:"input_audio,omitempty"`
+}
+
+type OpenAIImageURL struct {
+	URL    string `json:"url"`              // http(s) 链接、data URI 或 oss://objectKey
+	Detail string `json:"detail,omitempty"` // auto, low, high
+}
+
+type OpenAIInputAudio struct {
+	Data   string `json:"data"`   // base64 编码的音频
+	Format string `json:"format"` // wav, mp3
+}
+
+func NewTextContent(text string) OpenAIContent {
+	return OpenAIContent{Text: text}
+}
+
+// String 返回内容中的全部文本，多个文本片段按换行拼接
+func (c OpenAIContent) String() string {
+	if len(c.Parts) == 0 {
+		return c.Text
+	}
+
+	texts := make([]string, 0, len(c.Parts))
+	for _, part := range c.Parts {
+		if part.Type == ContentPartText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+func (c OpenAIContent) MarshalJSON() ([]byte, error) {
+	if len(c.Parts) > 0 {
+		return json.Marshal(c.Parts)
+	}
+	return json.Marshal(c.Text)
+}
+
+func (c *OpenAIContent) UnmarshalJSON(data []byte) error {
+	*c = OpenAIContent{}
+	if string(data) == "null" {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &c.Text); err == nil {
+		return nil
+	}
+
+	var parts []OpenAIContentPart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return errors.New("content must be a string or an array of content parts")
+	}
+	for i, part := range parts {
+		if err := part.validate(); err != nil {
+			return fmt.Errorf("content[%d]: %w", i, err)
+		}
+	}
+	c.Parts = parts
+	return nil
+}
+
+func (p OpenAIContentPart) validate() error {
+	switch p.Type {
+	case ContentPartText:
+		return nil
+	case ContentPartImageURL:
+		if p.ImageURL == nil || p.ImageURL.URL == "" {
+			return errors.New("image_url.url is required")
+		}
+		return nil
+	case ContentPartInputAudio:
+		if p.InputAudio == nil || p.InputAudio.Data == "" || p.InputAudio.Format == "" {
+			return errors.New("input_audio.data and input_audio.format are required")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported content part type %q", p.Type)
+	}
+}
+
+// OpenAI 工具定义
+type OpenAITool struct {
+	Type     string                    `json:"type"` // 目前只有 function
+	Function *OpenAIFunctionDefinition `json:"function,omitempty"`
+}
+
+type OpenAIFunctionDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Strict      bool            `json:"strict,omitempty"`
+	Parameters  json.RawMessage `json:"parameters,omitempty" swaggertype:"object"` // JSON Schema
+}
+
+// OpenAI 工具调用，流式时 Index 不为空
+type OpenAIToolCall struct {
+	Index    *int               `json:"index,omitempty"`
+	ID       string             `json:"id,omitempty"`
+	Type     string             `json:"type,omitempty"`
+	Function OpenAIFunctionCall `json:"function"`
+}
+
+type OpenAIFunctionCall struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"` // JSON 字符串
+}
+
+// OpenAI 响应格式: text, json_object, json_schema
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type OpenAIJSONSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema,omitempty" swaggertype:"object"`
+	Strict      bool            `json:"strict,omitempty"`
+}
+
+type OpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// OpenAIStop stop 参数，兼容字符串和字符串数组两种写法
+type OpenAIStop []string
+
+func (s *OpenAIStop) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		if single == "" {
+			*s = nil
+		} else {
+			*s = OpenAIStop{single}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
 }
 
 type OpenAIChatCompletionRequest struct {
-	Model       string          `json:"model" binding:"required"`
-	Messages    []OpenAIMessage `json:"messages" binding:"required"`
-	Stream      bool `json:"stream"`
-	MaxTokens   int `json:"max_tokens"`
-	Temperature float64 `json:"temperature"`
-	TopP        float64 `json:"top_p"`
-	Platform    string `json:"platform"`
+	Model               string                `json:"model" binding:"required"`
+	Messages            []OpenAIMessage       `json:"messages" binding:"required"`
+	Stream              bool                  `json:"stream"`
+	StreamOptions       *OpenAIStreamOptions  `json:"stream_options,omitempty"`
+	MaxTokens           int                   `json:"max_tokens"`
+	MaxCompletionTokens int                   `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64              `json:"temperature,omitempty"` // 指针区分未传和显式传 0
+	TopP                *float64              `json:"top_p,omitempty"`
+	N                   int                   `json:"n,omitempty"`
+	Stop                OpenAIStop            `json:"stop,omitempty" swaggertype:"array,string"`
+	Seed                *int                  `json:"seed,omitempty"`
+	PresencePenalty     *float64              `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64              `json:"frequency_penalty,omitempty"`
+	LogitBias           map[string]int        `json:"logit_bias,omitempty"`
+	Logprobs            bool                  `json:"logprobs,omitempty"`
+	TopLogprobs         int                   `json:"top_logprobs,omitempty"`
+	ResponseFormat      *OpenAIResponseFormat `json:"response_format,omitempty"`
+	Tools               []OpenAITool          `json:"tools,omitempty"`
+	ToolChoice          any                   `json:"tool_choice,omitempty" swaggertype:"object"` // none, auto, required 或 {"type":"function","function":{"name":"..."}}
+	ParallelToolCalls   *bool                 `json:"parallel_tool_calls,omitempty"`
+	ReasoningEffort     string                `json:"reasoning_effort,omitempty"`
+	User                string                `json:"user,omitempty"`
+	Platform            string                `json:"platform"`
 }
 
 type OpenAIChoice struct {
-	Index        int `json:"index"`
-	Message      OpenAIMessage `json:"message"`
-	FinishReason string `json:"finish_reason"`
+	Index        int             `json:"index"`
+	Message      OpenAIMessage   `json:"message"`
+	Logprobs     *OpenAILogprobs `json:"logprobs,omitempty"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// OpenAI token 概率信息
+type OpenAILogprobs struct {
+	Content []OpenAITokenLogprob `json:"content"`
+	Refusal []OpenAITokenLogprob `json:"refusal,omitempty"`
+}
+
+type OpenAITokenLogprob struct {
+	Token       string               `json:"token"`
+	Logprob     float64              `json:"logprob"`
+	Bytes       []int                `json:"bytes,omitempty"`
+	TopLogprobs []OpenAITokenLogprob `json:"top_logprobs,omitempty"`
 }
 
 type OpenAIUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                            `json:"prompt_tokens"`
+	CompletionTokens        int                            `json:"completion_tokens"`
+	TotalTokens             int                            `json:"total_tokens"`
+	PromptTokensDetails     *OpenAIPromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *OpenAICompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type OpenAIPromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+	AudioTokens  int `json:"audio_tokens"`
+}
+
+type OpenAICompletionTokensDetails struct {
+	ReasoningTokens          int `json:"reasoning_tokens"`
+	AudioTokens              int `json:"audio_tokens"`
+	AcceptedPredictionTokens int `json:"accepted_prediction_tokens"`
+	RejectedPredictionTokens int `json:"rejected_prediction_tokens"`
 }
 
 type OpenAIChatCompletionResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64 `json:"created"`
-	Model   string `json:"model"`
-	Choices []OpenAIChoice `json:"choices"`
-	Usage   OpenAIUsage `json:"usage"`
+	ID                string         `json:"id"`
+	Object            string         `json:"object"`
+	Created           int64          `json:"created"`
+	Model             string         `json:"model"`
+	SystemFingerprint string         `json:"system_fingerprint,omitempty"`
+	Choices           []OpenAIChoice `json:"choices"`
+	Usage             OpenAIUsage    `json:"usage"`
+	Platform          string         `json:"platform,omitempty"` // 实际提供服务的平台（扩展字段）
+}
+
+type OpenAIStreamDelta struct {
+	Role             string           `json:"role,omitempty"`
+	Content          string           `json:"content,omitempty"`
+	ReasoningContent string           `json:"reasoning_content,omitempty"`
+	Refusal          string           `json:"refusal,omitempty"`
+	ToolCalls        []OpenAIToolCall `json:"tool_calls,omitempty"`
 }
 
 type OpenAIStreamChoice struct {
-	Index int `json:"index"`
-	Delta struct {
-		Role    string `json:"role,omitempty"`
-		Content string `json:"content"`
-	} `json:"delta"`
-	FinishReason *string `json:"finish_reason"`
+	Index        int               `json:"index"`
+	Delta        OpenAIStreamDelta `json:"delta"`
+	Logprobs     *OpenAILogprobs   `json:"logprobs,omitempty"`
+	FinishReason *string           `json:"finish_reason"`
 }
 
 type OpenAIChatCompletionStreamResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64 `json:"created"`
-	Model   string `json:"model"`
-	Choices []OpenAIStreamChoice `json:"choices"`
+	ID                string               `json:"id"`
+	Object            string               `json:"object"`
+	Created           int64                `json:"created"`
+	Model             string               `json:"model"`
+	SystemFingerprint string               `json:"system_fingerprint,omitempty"`
+	Choices           []OpenAIStreamChoice `json:"choices"`
+	Usage             *OpenAIUsage         `json:"usage,omitempty"` // 仅 stream_options.include_usage 时最后一块携带
+	Platform          string               `json:"platform,omitempty"`
 }
 
 // OpenAI错误响应
@@ -137,9 +463,61 @@ type OpenAIErrorResponse struct {
 type OpenAIError struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
+	Param   *string `json:"param,omitempty"`
+	Code    any `json:"code,omitempty"`
+}
+
+// 图片返回格式
+const (
+	ImageResponseFormatURL     = "url"
+	ImageResponseFormatB64JSON = "b64_json"
+)
+
+// OpenAIImageRequest OpenAI兼容的图片生成请求，persist 为扩展字段
+type OpenAIImageRequest struct {
+	Prompt         string `json:"prompt" binding:"required"`
+	Model          string `json:"model"`
+	N              int    `json:"n" binding:"omitempty,min=1,max=10"`
+	Size           string `json:"size"`
+	Quality        string `json:"quality"`
+	Style          string `json:"style"`
+	ResponseFormat string `json:"response_format" binding:"omitempty,oneof=url b64_json"`
+	User           string `json:"user"`
+	Persist        bool   `json:"persist"` // 转存到 OSS，返回长期有效的地址
+}
+
+// OpenAIImageEditRequest 图片编辑请求（multipart/form-data），图片文件在 image 和 mask 字段
+type OpenAIImageEditRequest struct {
+	Prompt         string `form:"prompt" binding:"required"`
+	Model          string `form:"model"`
+	N              int    `form:"n" binding:"omitempty,min=1,max=10"`
+	Size           string `form:"size"`
+	Quality        string `form:"quality"`
+	ResponseFormat string `form:"response_format" binding:"omitempty,oneof=url b64_json"`
+	User           string `form:"user"`
+	Persist        bool   `form:"persist"`
+}
+
+// OpenAIImageVariationRequest 图片变体请求（multipart/form-data），图片文件在 image 字段
+type OpenAIImageVariationRequest struct {
+	Model          string `form:"model"`
+	N              int    `form:"n" binding:"omitempty,min=1,max=10"`
+	Size           string `form:"size"`
+	ResponseFormat string `form:"response_format" binding:"omitempty,oneof=url b64_json"`
+	User           string `form:"user"`
+	Persist        bool   `form:"persist"`
+}
+
+type OpenAIImageData struct {
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+	ObjectKey     string `json:"object_key,omitempty"` // 转存到 OSS 后的 objectKey
 }
 
-// 图片生成响应
-type ImageGenerationResponse struct {
-	Data []string `json:"data"`
+// OpenAIImageResponse OpenAI兼容的图片响应
+type OpenAIImageResponse struct {
+	Created  int64             `json:"created"`
+	Data     []OpenAIImageData `json:"data"`
+	Platform string            `json:"platform,omitempty"`
 }
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// APIKeyPrefix API Key 前缀，与 OpenAI 保持一致
+const APIKeyPrefix = "sk-"
+
+// 用户个人 API Key，只保存哈希，明文仅在创建时返回一次
+type APIKey struct {
+	BaseModel
+	UserID     uint64     `json:"user_id" gorm:"not null;index" swaggertype:"string"`
+	Name       string     `json:"name" gorm:"type:varchar(64)"`
+	Prefix     string     `json:"prefix" gorm:"type:varchar(16)"` // 明文前几位，便于用户辨认
+	KeyHash    string     `json:"-" gorm:"type:char(64);uniqueIndex;not null"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"` // 为空表示永不过期
+	User       User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// 创建 API Key 请求
+type APIKeyCreateRequest struct {
+	Name          string `json:"name" binding:"required,max=64"`
+	ExpiresInDays int    `json:"expires_in_days,omitempty" binding:"omitempty,min=1,max=3650"` // 为空表示永不过期
+}
+
+// 创建 API Key 响应，Key 只返回这一次
+type APIKeyCreateResponse struct {
+	APIKey
+	Key string `json:"key"`
+}
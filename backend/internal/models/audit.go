@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// 审计事件类型
+const (
+	AuditLoginLockout     = "login.lockout"   // 登录失败次数过多，账号或 IP 被临时锁定
+	AuditAccountUnlock    = "account.unlock"  // 管理员解除账号锁定
+	AuditRoleUpdate       = "role.update"     // 创建、修改或删除角色
+	AuditRoleAssign       = "role.assign"     // 修改用户的角色
+	AuditIdentityLink     = "identity.link"   // 绑定第三方账号（包括首次第三方登录自动创建账号）
+	AuditIdentityUnlink   = "identity.unlink" // 解绑第三方账号
+	AuditTwoFactorEnable  = "2fa.enable"      // 启用两步验证
+	AuditTwoFactorDisable = "2fa.disable"     // 关闭两步验证（用户自己关闭或管理员重置）
+	AuditRecoveryCodeUsed = "2fa.recovery"    // 使用恢复码登录
+	AuditAccountDeletion  = "account.delete"  // 用户申请注销账号，宽限期后彻底删除
+	AuditDeletionCancel   = "account.restore" // 用户在宽限期内撤销注销
+	AuditAccountPurge     = "account.purge"   // 账号及其全部数据已被删除
+)
+
+// AuditLog 安全相关的审计记录，只追加不修改
+type AuditLog struct {
+	ID        uint64    `json:"id" gorm:"primaryKey" swaggertype:"string"`
+	Action    string    `json:"action" gorm:"type:varchar(64);not null;index"`
+	UserID    uint64    `json:"user_id" gorm:"index" swaggertype:"string"` // 涉及的用户，0 表示无（如按 IP 锁定或用户不存在）
+	ActorID   uint64    `json:"actor_id" swaggertype:"string"`             // 操作人，0 表示系统
+	IP        string    `json:"ip" gorm:"type:varchar(64)"`
+	UserAgent string    `json:"user_agent" gorm:"type:varchar(255)"`
+	Detail    string    `json:"detail" gorm:"type:varchar(255)"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+// ClientInfo 发起请求的客户端信息
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
@@ -14,12 +14,33 @@ type FeedPost struct {
 	Avatar             string `json:"avatar" gorm:"type:varchar(500)"`            // 冗余头像
 	Status             string `json:"status" gorm:"type:varchar(50)"`             // 用户状态emoji
 	Content            string `json:"content" gorm:"type:text"`                   // 文字内容（可选）
-	ImageURL           string `json:"image_url" gorm:"type:varchar(500)"`         // 图片URL（可选）
+	ImageURL           string `json:"image_url" gorm:"type:varchar(500)"`         // 图片URL（可选，旧版单图，新帖子使用 Media）
+	Media              []FeedMedia `json:"media" gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE"` // 媒体附件，按 Position 排序
 	LikeCount          int    `json:"like_count" gorm:"default:0;index:idx_like_id"`
 	CommentCount       int    `json:"comment_count" gorm:"default:0;index:idx_comment_id"`
+	EditedAt           *time.Time `json:"edited_at,omitempty"` // 最后编辑时间，为空表示未编辑过
 	UserProfileVersion int64  `json:"user_profile_version"` // 用户信息版本号
 }
 
+// FeedMedia 帖子媒体附件，文件存储在OSS
+type FeedMedia struct {
+	BaseModel
+	PostID   uint64 `json:"-" gorm:"not null;index"`
+	Position int    `json:"position" gorm:"not null;default:0"`           // 在帖子中的顺序，从0开始
+	OssKey   string `json:"oss_key" gorm:"type:varchar(500);not null"`    // OSS对象键
+	URL      string `json:"url" gorm:"type:varchar(500)"`                 // 访问地址
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	Blurhash string `json:"blurhash,omitempty" gorm:"type:varchar(100)"` // 加载前显示的模糊占位图
+}
+
+// FeedUpload 用户申请上传的帖子图片，对象键由服务端生成，发帖时只能使用自己申请过的对象键
+type FeedUpload struct {
+	BaseModel
+	UserID uint64 `json:"-" gorm:"not null;index"`
+	OssKey string `json:"oss_key" gorm:"type:varchar(500);not null;uniqueIndex"` // OSS对象键
+}
+
 // FeedComment 信息流评论模型
 type FeedComment struct {
 	BaseModel                 // 嵌入基础模型，获得uint64 ID + 时间戳
@@ -29,7 +50,12 @@ type FeedComment struct {
 	Avatar             string `json:"avatar" gorm:"type:varchar(500)"`            // 冗余头像
 	Content            string `json:"content" gorm:"type:text;not null"`
 	ReplyTo            string `json:"reply_to" gorm:"type:varchar(100)"` // 回复的用户名
+	ParentID           uint64 `json:"parent_id" gorm:"default:0;index" swaggertype:"string"` // 回复的评论ID，0 表示直接评论帖子
+	RootID             uint64 `json:"root_id" gorm:"default:0;index" swaggertype:"string"`   // 所在楼层的顶层评论ID，顶层评论为 0
+	ReplyCount         int    `json:"reply_count" gorm:"default:0"`                          // 直接回复数，包括仍有回复的已删除评论
 	LikeCount          int    `json:"like_count" gorm:"default:0"`
+	EditedAt           *time.Time `json:"edited_at,omitempty"`        // 最后编辑时间，为空表示未编辑过
+	Deleted            bool   `json:"deleted" gorm:"default:false"` // 已删除但仍有回复，保留占位，内容和作者已清空
 	UserProfileVersion int64  `json:"user_profile_version"` // 用户信息版本号
 }
 
@@ -57,29 +83,52 @@ type FeedCommentResponse struct {
 
 // CreateFeedPostRequest 创建帖子请求
 type CreateFeedPostRequest struct {
-	Content  string `json:"content,omitempty" validate:"max=2000"` // 最多2000字符
-	ImageURL string `json:"image_url,omitempty" validate:"url"`    // 图片URL
+	Content  string             `json:"content,omitempty" binding:"omitempty,max=2000"`   // 最多2000字符
+	ImageURL string             `json:"image_url,omitempty" binding:"omitempty,url"`      // 图片URL（旧版单图）
+	Media    []FeedMediaRequest `json:"media,omitempty" binding:"omitempty,max=9,dive"` // 媒体附件，最多9个
+}
+
+// UpdateFeedPostRequest 编辑帖子请求，整体替换内容和媒体附件
+type UpdateFeedPostRequest struct {
+	Content  string             `json:"content,omitempty" binding:"omitempty,max=2000"`
+	ImageURL string             `json:"image_url,omitempty" binding:"omitempty,url"`
+	Media    []FeedMediaRequest `json:"media,omitempty" binding:"omitempty,max=9,dive"`
+}
+
+// FeedMediaRequest 媒体附件，文件需先通过 /api/feed/media/upload-url 申请上传
+type FeedMediaRequest struct {
+	OssKey   string `json:"oss_key" binding:"required,max=500"`        // OSS对象键，申请上传时返回的 object_key
+	Width    int    `json:"width" binding:"required,min=1,max=20000"`  // 宽度（像素）
+	Height   int    `json:"height" binding:"required,min=1,max=20000"` // 高度（像素）
+	Blurhash string `json:"blurhash,omitempty" binding:"omitempty,max=100"`
+}
+
+// FeedMediaUploadRequest 申请上传帖子图片请求
+type FeedMediaUploadRequest struct {
+	FileName string `json:"file_name" binding:"required,max=200"`              // 原始文件名，用于生成对象键
+	FileType string `json:"file_type" binding:"required,startswith=image/"` // 文件类型，只能上传图片
 }
 
 // CreateFeedCommentRequest 创建评论请求
 type CreateFeedCommentRequest struct {
-	Content string `json:"content" validate:"required,max=500"`   // 评论内容，最多500字符
-	ReplyTo string `json:"reply_to,omitempty" validate:"max=100"` // 回复的用户名
+	Content  string `json:"content" binding:"required,max=500"`              // 评论内容，最多500字符
+	ReplyTo  string `json:"reply_to,omitempty" binding:"omitempty,max=100"` // 回复的用户名，回复评论时自动设置为被回复评论的作者
+	ParentID uint64 `json:"parent_id,omitempty" swaggertype:"string"` // 回复的评论ID，为空表示直接评论帖子
 }
 
-// ToggleLikeRequest 切换点赞请求
-type ToggleLikeRequest struct {
-	PostID string `json:"post_id" validate:"required"`
+// UpdateFeedCommentRequest 编辑评论请求
+type UpdateFeedCommentRequest struct {
+	Content string `json:"content" binding:"required,max=500"`
 }
 
 // SetFeedCommentLikeRequest 设置评论点赞请求
 type SetFeedCommentLikeRequest struct {
-	IsLike bool `json:"is_like" binding:"required"` // 是否点赞
+	IsLike *bool `json:"is_like" binding:"required"` // 是否点赞，指针区分 false 和未传
 }
 
 // SetFeedPostLikeRequest 设置帖子点赞请求
 type SetFeedPostLikeRequest struct {
-	IsLike bool `json:"is_like" binding:"required"` // 是否点赞
+	IsLike *bool `json:"is_like" binding:"required"` // 是否点赞，指针区分 false 和未传
 }
 
 // LikeResult 点赞操作结果
@@ -90,10 +139,10 @@ type LikeResult struct {
 
 // FeedQueryParams 信息流查询参数
 type FeedQueryParams struct {
-	Sort         string `form:"sort" validate:"oneof=time like comment"` // 排序类型：time, like, comment
-	AfterID      string `form:"after_id"`                                // cursor分页的after_id
-	Limit        int    `form:"limit" validate:"min=1,max=50"`           // 每页数量，最多50
-	CommentCount int    `form:"comment_count" validate:"min=0,max=20"`   // 预载评论数量，0表示不预载，最多20条
+	Sort         string `form:"sort" binding:"omitempty,oneof=time like comment hot"` // 排序类型：time, like, comment, hot（按时间衰减的热度）
+	AfterID      string `form:"after_id"`                                         // 上一页返回的 next_cursor
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=50"`           // 每页数量，最多50
+	CommentCount int    `form:"comment_count" binding:"omitempty,min=0,max=20"`   // 预载评论数量，0表示不预载，最多20条
 }
 
 // PostLike 帖子点赞模型
@@ -116,9 +165,15 @@ type FeedCommentLike struct {
 	// UNIQUE KEY idx_comment_user_unique (comment_id, user_id)
 }
 
+// ReplyQueryParams 评论回复查询参数，按时间正序
+type ReplyQueryParams struct {
+	AfterID string `form:"after_id"`                               // 上一页返回的 next_cursor
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50"` // 每页数量，最多50
+}
+
 // CommentQueryParams 评论查询参数
 type CommentQueryParams struct {
-	PostID  string `form:"post_id" binding:"required"`
-	AfterID string `form:"after_id"`                     // cursor分页的after_id
-	Limit   int    `form:"limit" binding:"min=1,max=50"` // 每页数量，最多50
+	PostID  string `form:"-"`                                      // 帖子ID，取自路径参数
+	AfterID string `form:"after_id"`                               // 上一页返回的 next_cursor
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50"` // 每页数量，最多50
 }
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// UserFollow 关注关系，FollowerID 关注了 FolloweeID
+type UserFollow struct {
+	BaseModel
+	FollowerID uint64 `json:"follower_id" gorm:"not null;uniqueIndex:idx_follow_unique" swaggertype:"string"`
+	FolloweeID uint64 `json:"followee_id" gorm:"not null;uniqueIndex:idx_follow_unique;index" swaggertype:"string"`
+	Follower   User   `json:"-" gorm:"foreignKey:FollowerID;constraint:OnDelete:CASCADE"`
+	Followee   User   `json:"-" gorm:"foreignKey:FolloweeID;constraint:OnDelete:CASCADE"`
+}
+
+// FollowUser 关注列表中的用户
+type FollowUser struct {
+	ID         uint64    `json:"id" swaggertype:"string"`
+	Username   string    `json:"username"`
+	Avatar     string    `json:"avatar"`
+	Status     string    `json:"status"`
+	FollowedAt time.Time `json:"followed_at"`
+	FollowID   uint64    `json:"-"` // 关注记录ID，用作分页cursor
+}
+
+// FollowListResponse 关注列表响应，按关注时间倒序
+type FollowListResponse struct {
+	Users      []FollowUser `json:"users"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+	HasMore    bool         `json:"has_more"`
+	Total      int64        `json:"total"`
+}
+
+// FollowQueryParams 关注列表查询参数
+type FollowQueryParams struct {
+	AfterID string `form:"after_id"`                               // 上一页返回的 next_cursor
+	Limit   int    `form:"limit" binding:"omitempty,min=1,max=50"` // 每页数量，最多50
+}
+
+// FollowResult 关注操作结果
+type FollowResult struct {
+	Changed     bool `json:"changed"`      // 状态是否改变
+	IsFollowing bool `json:"is_following"` // 当前关注状态
+}
+
+// FollowStats 用户的关注数和粉丝数
+type FollowStats struct {
+	FollowerCount  int64 `json:"follower_count"`
+	FollowingCount int64 `json:"following_count"`
+	IsFollowing    bool  `json:"is_following"`   // 当前用户是否已关注
+	IsFollowedBy   bool  `json:"is_followed_by"` // 是否关注了当前用户
+}
+
+// TimelineQueryParams 首页时间线查询参数
+type TimelineQueryParams struct {
+	AfterID      string `form:"after_id"`                                       // 上一页返回的 next_cursor
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=50"`         // 每页数量，最多50
+	CommentCount int    `form:"comment_count" binding:"omitempty,min=0,max=20"` // 预载评论数量，0表示不预载，最多20条
+}
@@ -0,0 +1,23 @@
+package models
+
+// 用户绑定的第三方账号，同一第三方账号只能绑定一个用户，每个用户在同一第三方只能绑定一个账号
+type UserIdentity struct {
+	BaseModel
+	UserID   uint64 `json:"user_id" gorm:"not null;uniqueIndex:idx_user_identity_user_provider" swaggertype:"string"`
+	Provider string `json:"provider" gorm:"type:varchar(32);not null;uniqueIndex:idx_user_identity_provider_subject;uniqueIndex:idx_user_identity_user_provider"`
+	Subject  string `json:"-" gorm:"type:varchar(255);not null;uniqueIndex:idx_user_identity_provider_subject"` // 第三方账号的唯一ID
+	Email    string `json:"email" gorm:"type:varchar(255)"`                                                     // 绑定时第三方返回的邮箱，仅用于展示
+	User     User   `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+// 第三方授权地址响应，前端跳转到 URL，回调时原样带回 state
+type OAuthAuthorizeResponse struct {
+	URL   string `json:"url"`
+	State string `json:"state"`
+}
+
+// 第三方登录回调请求，code 和 state 来自第三方回调地址的查询参数
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+}
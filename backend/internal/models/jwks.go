@@ -0,0 +1,18 @@
+package models
+
+// JWK 公钥，格式见 RFC 7517，RSA 使用 n/e，Ed25519 使用 crv/x
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet /.well-known/jwks.json 的响应，其他服务据此验证 token
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
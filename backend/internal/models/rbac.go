@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// 权限名称，按 <资源>.<操作> 命名
+const (
+	PermAdminAccess  = "admin.access"  // 访问管理后台，/admin 下的所有接口都需要
+	PermUsersRead    = "users.read"    // 查看用户列表和详情
+	PermUsersWrite   = "users.write"   // 删除、启用、停用用户，重置密码，解除登录锁定
+	PermRolesManage  = "roles.manage"  // 管理角色权限和用户的角色
+	PermFeedModerate = "feed.moderate" // 管理他人发布的信息流内容
+	PermAIManage     = "ai.manage"     // 管理 AI 模型目录
+	PermAIUnlimited  = "ai.unlimited"  // 不受 AI 用量配额限制
+
+	PermAll = "*" // 拥有全部权限，新增的权限自动包含在内
+)
+
+// Permissions 全部权限及说明，角色只能包含这里列出的权限
+var Permissions = map[string]string{
+	PermAdminAccess:  "访问管理后台",
+	PermUsersRead:    "查看用户",
+	PermUsersWrite:   "管理用户",
+	PermRolesManage:  "管理角色",
+	PermFeedModerate: "管理信息流内容",
+	PermAIManage:     "管理 AI 模型",
+	PermAIUnlimited:  "不限 AI 用量",
+	PermAll:          "全部权限",
+}
+
+// Role 角色及其权限，用户通过 User.Role 关联角色名
+type Role struct {
+	Name             string    `json:"name" gorm:"primaryKey;type:varchar(32)"`
+	Description      string    `json:"description" gorm:"type:varchar(255)"`
+	Permissions      []string  `json:"permissions" gorm:"serializer:json;type:jsonb;not null"`
+	BuiltIn          bool      `json:"built_in"`           // 内置角色不能删除，admin 的权限不能修改
+	RequireTwoFactor bool      `json:"require_two_factor"` // 该角色的用户必须通过两步验证登录才能使用角色的权限
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// HasPermission 角色是否拥有指定权限
+func (r *Role) HasPermission(permission string) bool {
+	for _, p := range r.Permissions {
+		if p == PermAll || p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * 创建或更新角色请求结构体
+ */
+type RoleRequest struct {
+	Description string   `json:"description" binding:"max=255"`
+	Permissions []string `json:"permissions" binding:"required"`
+}
+
+/**
+ * 设置角色是否要求两步验证请求结构体
+ */
+type RoleTwoFactorRequest struct {
+	Required bool `json:"required"`
+}
+
+/**
+ * 分配用户角色请求结构体
+ */
+type AssignRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+/**
+ * 权限说明
+ */
+type PermissionInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
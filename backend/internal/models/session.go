@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// 用户的登录会话（设备），保存在 Redis 中，会话ID同时写入该会话签发的 token
+type UserSession struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"` // 根据 User-Agent 识别的浏览器和系统，如 Chrome on macOS
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"` // 登录时的 IP
+	TwoFactor  bool      `json:"two_factor"`
+	Current    bool      `json:"current"` // 是否为发起请求的会话
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
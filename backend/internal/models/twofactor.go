@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// 用户的 TOTP 两步验证，EnabledAt 为空表示已生成密钥但尚未确认启用
+type UserTwoFactor struct {
+	UserID        uint64     `json:"user_id" gorm:"primaryKey;autoIncrement:false" swaggertype:"string"`
+	Secret        string     `json:"-" gorm:"type:varchar(64);not null"` // base32 编码的 TOTP 密钥
+	EnabledAt     *time.Time `json:"enabled_at"`
+	LastUsedStep  int64      `json:"-" gorm:"not null;default:0"`         // 最近一次使用的时间步，同一验证码不能重复使用
+	RecoveryCodes []string   `json:"-" gorm:"serializer:json;type:jsonb"` // 未使用的恢复码哈希
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	User          User       `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+}
+
+func (t *UserTwoFactor) IsEnabled() bool {
+	return t.EnabledAt != nil
+}
+
+// 两步验证状态
+type TwoFactorStatus struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+	Required               bool       `json:"required"` // 用户的角色要求两步验证
+}
+
+// 开始绑定身份验证器的响应，ProvisioningURI 用于生成二维码
+type TwoFactorSetupResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// 恢复码，只在生成时返回一次
+type TwoFactorRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// 验证码请求，code 为身份验证器中的 6 位数字，部分接口也接受恢复码
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// 关闭两步验证请求，需要同时验证密码和验证码（或恢复码）
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"`
+}
+
+// 登录第二步请求，code 可以是验证码或恢复码
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"`
+}
+
+// 密码验证通过但需要两步验证时的登录响应，用 challenge_token 和验证码调用 /users/login/2fa 获取令牌
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
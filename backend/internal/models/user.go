@@ -11,15 +11,17 @@ type User struct {
 	BaseModel             // 继承基础字段
 	Username       string `json:"username" gorm:"uniqueIndex;not null"`           // 用户名，系统内唯一标识
 	Email          string `json:"email" gorm:"uniqueIndex;not null"`           // 邮箱地址，用于登录和通知
-	Password       string `json:"-" gorm:"not null"`              // 密码，存储加密后的值
-	PlainPassword  string `json:"-" gorm:"column:plain_password"` // 明文密码，可选存储
+	Password       string `json:"-" gorm:"not null"`              // 密码哈希（argon2id 或 bcrypt），不保存明文
 	Avatar         string `json:"avatar,omitempty"`                     // 用户头像URL
 	AvatarOssKey   string `json:"avatar_oss_key,omitempty"`       // 用户头像OSS对象键
 	Status         string `json:"status,omitempty"`                     // 用户状态emoji
 	Extra          string `json:"extra,omitempty"`                     // 扩展字段，JSON格式存储额外信息
 	IsActive       bool   `json:"is_active" gorm:"default:true"`                   // 用户激活状态
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`         // 邮箱验证时间，为空表示未验证
 	Role           string `json:"role" gorm:"default:'user'"`                 // 用户角色: admin, user
 	ProfileVersion int64  `json:"profile_version" gorm:"default:1"`                      // 用户信息版本号
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"` // 用户申请注销后计划彻底删除的时间，为空表示未申请
+	PasswordGenerated bool `json:"password_generated" gorm:"not null;default:false"` // 密码是第三方登录创建账号时随机生成的，用户不知道，设置密码后为 false
 }
 
 // 用户角色常量
@@ -32,23 +34,81 @@ func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
 }
 
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
 /**
  * 用户创建请求结构体
  */
 type UserCreateRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Password string `json:"password" binding:"required"` // 长度和强度由密码策略校验
 }
 
 type UserCreateResponse struct {
-	User  UserResponse `json:"user"`
-	Token string `json:"token"`
+	User UserResponse `json:"user"`
+	AuthTokens
 }
 
 type UserLoginResponse struct {
-	User  UserResponse `json:"user"`
-	Token string `json:"token"`
+	User UserResponse `json:"user"`
+	AuthTokens
+}
+
+/**
+ * 登录令牌：短期的 access token 用于请求认证，refresh token 用于换取新的令牌
+ */
+type AuthTokens struct {
+	Token            string    `json:"token"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+/**
+ * 刷新令牌请求结构体
+ */
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+/**
+ * 邮箱验证请求结构体
+ */
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+/**
+ * 忘记密码请求结构体
+ */
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+/**
+ * 通过邮件链接重置密码请求结构体
+ */
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // 长度和强度由密码策略校验
+}
+
+/**
+ * 申请注销账号请求结构体
+ */
+type AccountDeletionRequest struct {
+	Password string `json:"password,omitempty"` // 设置过密码的账号必填
+	Code     string `json:"code,omitempty"`     // 没有密码的第三方登录账号可以用两步验证码确认，未启用两步验证时需要重新登录后再申请
+}
+
+/**
+ * 申请注销账号响应结构体
+ */
+type AccountDeletionResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"` // 到期后账号及全部数据被删除，之前可以撤销
 }
 
 /**
@@ -76,7 +136,7 @@ type UserUpdateRequest struct {
  */
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6"`
+	NewPassword string `json:"new_password" binding:"required"` // 长度和强度由密码策略校验
 }
 
 /**
@@ -92,7 +152,10 @@ type UserResponse struct {
 	Extra          string `json:"extra,omitempty"`
 	Role           string `json:"role"`
 	IsActive       bool `json:"is_active"`
+	EmailVerified  bool `json:"email_verified"`
 	ProfileVersion int64 `json:"profile_version"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	PasswordGenerated bool `json:"password_generated"` // 没有设置过密码，需要确认身份时不能验证密码
 	CreatedAt      time.Time `json:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at"`
 }
@@ -109,7 +172,10 @@ func (u *User) ToResponse() UserResponse {
 		Extra:          u.Extra,
 		Role:           u.Role,
 		IsActive:       u.IsActive,
+		EmailVerified:  u.IsEmailVerified(),
 		ProfileVersion: u.ProfileVersion,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+		PasswordGenerated: u.PasswordGenerated,
 		CreatedAt:      u.CreatedAt,
 		UpdatedAt:      u.UpdatedAt,
 	}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errDeletionCanceled 定时删除前用户已撤销注销
+var errDeletionCanceled = errors.New("account deletion canceled")
+
+// PurgeUser 删除用户及其全部数据：待办、帖子和评论（包括收到的评论和点赞）、点过的赞、对话记录和OSS文件
+// 有其他用户回复的评论只清空内容和作者，保留占位
+// 其他用户帖子和评论上的点赞数、评论数同步扣减；审计记录保留，AI 用量流水匿名化保留用于统计
+func (s *UserService) PurgeUser(id uint64, detail string) error {
+	return s.purgeUser(id, detail, false)
+}
+
+// purgeUser scheduledOnly 为 true 时只在用户仍处于注销状态且宽限期已过时删除，避免与撤销注销并发
+func (s *UserService) purgeUser(id uint64, detail string, scheduledOnly bool) error {
+	var user models.User
+	// 该用户评论过的帖子，删除后需要清理评论缓存
+	var commentedPostIDs []uint64
+	// 该用户关注的人，关注关系删除后粉丝数可能降到推模式阈值以下
+	var followeeIDs []uint64
+	var media []models.FeedMedia
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// 至少保留一个管理员，最后一个管理员的注销申请保留到有其他管理员后再删除
+		if err := EnsureOtherAdministrator(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("用户不存在")
+			}
+			return err
+		}
+		if scheduledOnly && (user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(time.Now())) {
+			return errDeletionCanceled
+		}
+
+		if err := tx.Model(&models.FeedComment{}).Where("user_id = ?", id).
+			Distinct().Pluck("post_id", &commentedPostIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.UserFollow{}).Where("follower_id = ?", id).
+			Pluck("followee_id", &followeeIDs).Error; err != nil {
+			return err
+		}
+		purged, err := purgeUserData(tx, id)
+		media = purged
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	RecordAudit(s.DB, models.AuditLog{Action: models.AuditAccountPurge, UserID: id, Detail: detail})
+
+	if s.Redis != nil {
+		for _, postID := range commentedPostIDs {
+			s.Redis.Del(context.Background(), config.FeedCommentCacheKeyPrefix+strconv.FormatUint(postID, 10))
+		}
+		s.Redis.Del(context.Background(), timelineKey(id))
+		invalidateDemotedTimelines(s.DB, s.Redis, followeeIDs)
+	}
+	if user.AvatarOssKey != "" {
+		go s.deleteOSSFileAsync(user.AvatarOssKey)
+	}
+	for _, m := range media {
+		go s.deleteOSSFileAsync(m.OssKey)
+	}
+	return nil
+}
+
+// purgeUserData 删除用户的全部数据，返回被删除的帖子媒体附件，提交后再删除OSS文件
+func purgeUserData(tx *gorm.DB, id uint64) ([]models.FeedMedia, error) {
+	var media []models.FeedMedia
+	steps := []func() error{
+		// 点过的赞：对应帖子和评论的点赞数减一
+		func() error {
+			return tx.Exec("UPDATE feed_posts SET like_count = like_count - 1 WHERE id IN (SELECT post_id FROM post_likes WHERE user_id = ?)", id).Error
+		},
+		func() error { return tx.Where("user_id = ?", id).Delete(&models.PostLike{}).Error },
+		func() error {
+			return tx.Exec("UPDATE feed_comments SET like_count = like_count - 1 WHERE id IN (SELECT comment_id FROM feed_comment_likes WHERE user_id = ?)", id).Error
+		},
+		func() error { return tx.Where("user_id = ?", id).Delete(&models.FeedCommentLike{}).Error },
+
+		// 发表的评论与用户自己删除评论的处理相同：有回复的保留匿名占位，帖子评论数和回复数同步扣减
+		func() error {
+			var commentIDs []uint64
+			if err := tx.Model(&models.FeedComment{}).Where("user_id = ? AND deleted = ?", id, false).
+				Order("id DESC").Pluck("id", &commentIDs).Error; err != nil {
+				return err
+			}
+			// 从最新的评论开始，删除回复后再处理被回复的评论，每次重新读取回复数
+			for _, commentID := range commentIDs {
+				var comment models.FeedComment
+				err := lockCommentWithPost(tx, commentID, &comment)
+				if errors.Is(err, ErrFeedCommentNotFound) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if err := removeFeedComment(tx, &comment); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+
+		// 发布的帖子，以及帖子下其他用户的评论、点赞和媒体附件
+		func() error {
+			var postIDs []uint64
+			if err := tx.Model(&models.FeedPost{}).Where("user_id = ?", id).Pluck("id", &postIDs).Error; err != nil {
+				return err
+			}
+			var err error
+			media, err = deleteFeedPosts(tx, postIDs)
+			return err
+		},
+
+		// 申请上传过但没有发布的帖子图片，提交后与帖子媒体附件一起删除OSS文件
+		func() error {
+			var keys []string
+			if err := tx.Model(&models.FeedUpload{}).Where("user_id = ?", id).Pluck("oss_key", &keys).Error; err != nil {
+				return err
+			}
+			attached := make(map[string]bool, len(media))
+			for _, m := range media {
+				attached[m.OssKey] = true
+			}
+			for _, key := range keys {
+				if !attached[key] {
+					media = append(media, models.FeedMedia{OssKey: key})
+				}
+			}
+			return tx.Where("user_id = ?", id).Delete(&models.FeedUpload{}).Error
+		},
+
+		func() error { return tx.Where("user_id = ?", id).Delete(&models.Todo{}).Error },
+		func() error { return tx.Where("user_id = ?", id).Delete(&models.ConversationHistory{}).Error },
+		func() error { return tx.Where("user_id = ?", id).Delete(&models.ChatSession{}).Error },
+		func() error {
+			return tx.Model(&models.AIUsageLog{}).Where("user_id = ?", id).Update("user_id", 0).Error
+		},
+
+		// API Key、第三方账号、两步验证和关注关系通过外键级联删除
+		func() error { return tx.Unscoped().Delete(&models.User{}, id).Error },
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	return media, nil
+}
+
+// PurgeScheduledDeletions 删除注销宽限期已过的账号，返回删除的数量
+func (s *UserService) PurgeScheduledDeletions() (int, error) {
+	var ids []uint64
+	err := s.DB.Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Order("deletion_scheduled_at").
+		Limit(config.AccountPurgeBatchSize).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, id := range ids {
+		// 单个账号失败不影响其他账号，下次执行时重试
+		err := s.purgeUser(id, "scheduled deletion", true)
+		if errors.Is(err, errDeletionCanceled) {
+			continue
+		}
+		if errors.Is(err, ErrLastAdministrator) {
+			logrus.WithField("user_id", id).Warn("最后一个管理员暂不删除")
+			continue
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("user_id", id).Error("Failed to purge user")
+			continue
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+// ExportUserData 将用户的个人资料、待办、帖子、评论和对话记录以 JSON 文件打包为 ZIP 写入 w
+func (s *UserService) ExportUserData(userID uint64, w io.Writer) error {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	var todos []models.Todo
+	if err := s.DB.Where("user_id = ?", userID).Order("position, id").Find(&todos).Error; err != nil {
+		return err
+	}
+	todoResponses := make([]models.TodoResponse, len(todos))
+	for i := range todos {
+		todoResponses[i] = todos[i].ToResponse()
+	}
+
+	var posts []models.FeedPost
+	if err := s.DB.Preload("Media", orderFeedMedia).Where("user_id = ?", userID).Order("id").Find(&posts).Error; err != nil {
+		return err
+	}
+	var comments []models.FeedComment
+	if err := s.DB.Where("user_id = ?", userID).Order("id").Find(&comments).Error; err != nil {
+		return err
+	}
+	var chatSessions []models.ChatSession
+	if err := s.DB.Where("user_id = ?", userID).Order("id").Find(&chatSessions).Error; err != nil {
+		return err
+	}
+	var chatMessages []models.ConversationHistory
+	if err := s.DB.Where("user_id = ?", userID).Order("created_at, id").Find(&chatMessages).Error; err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", user.ToResponse()},
+		{"todos.json", todoResponses},
+		{"posts.json", posts},
+		{"comments.json", comments},
+		{"chat_sessions.json", chatSessions},
+		{"chat_messages.json", chatMessages},
+	}
+	for _, file := range files {
+		fw, err := zw.Create(file.name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(fw)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_PurgeUser(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		leaving, err := userService.CreateUser(getTestUser1("_purge"))
+		require.NoError(t, err)
+		staying, err := userService.CreateUser(getTestUser2("_purge"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(staying.ID)
+		}()
+
+		// 留下的用户的帖子上有即将删除的用户的点赞和评论
+		stayingPost, err := feedService.CreateFeedPost(staying.ID, models.CreateFeedPostRequest{Content: "staying"})
+		require.NoError(t, err)
+		stayingPostID := strconv.FormatUint(stayingPost.ID, 10)
+		_, err = feedService.SetFeedPostLike(leaving.ID, stayingPostID, true)
+		require.NoError(t, err)
+		_, err = feedService.CreateFeedComment(leaving.ID, stayingPostID, models.CreateFeedCommentRequest{Content: "bye"})
+		require.NoError(t, err)
+		stayingComment, err := feedService.CreateFeedComment(staying.ID, stayingPostID, models.CreateFeedCommentRequest{Content: "hi"})
+		require.NoError(t, err)
+		_, err = feedService.SetFeedCommentLike(leaving.ID, strconv.FormatUint(stayingComment.ID, 10), true)
+		require.NoError(t, err)
+
+		// 即将删除的用户的帖子下有其他用户的评论和点赞
+		leavingPost, err := feedService.CreateFeedPost(leaving.ID, models.CreateFeedPostRequest{Content: "leaving"})
+		require.NoError(t, err)
+		leavingPostID := strconv.FormatUint(leavingPost.ID, 10)
+		_, err = feedService.SetFeedPostLike(staying.ID, leavingPostID, true)
+		require.NoError(t, err)
+		_, err = feedService.CreateFeedComment(staying.ID, leavingPostID, models.CreateFeedCommentRequest{Content: "nice"})
+		require.NoError(t, err)
+
+		_, err = NewTodoService().CreateTodo(leaving.ID, models.TodoCreateRequest{Title: "todo"})
+		require.NoError(t, err)
+		require.NoError(t, testutil.TestDB.Create(&models.ConversationHistory{
+			UserID: leaving.ID, SessionID: "purge_" + leavingPostID, Role: "user", Content: "hello",
+		}).Error)
+
+		// 导出的 ZIP 中每类数据一个 JSON 文件
+		var buf bytes.Buffer
+		require.NoError(t, userService.ExportUserData(leaving.ID, &buf))
+		archive, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		files := map[string][]byte{}
+		for _, file := range archive.File {
+			rc, err := file.Open()
+			require.NoError(t, err)
+			var content bytes.Buffer
+			_, err = content.ReadFrom(rc)
+			require.NoError(t, err)
+			rc.Close()
+			files[file.Name] = content.Bytes()
+		}
+		assert.Len(t, files, 6)
+		var profile models.UserResponse
+		require.NoError(t, json.Unmarshal(files["profile.json"], &profile))
+		assert.Equal(t, leaving.Email, profile.Email)
+		var comments []models.FeedComment
+		require.NoError(t, json.Unmarshal(files["comments.json"], &comments))
+		assert.Len(t, comments, 1)
+		var messages []models.ConversationHistory
+		require.NoError(t, json.Unmarshal(files["chat_messages.json"], &messages))
+		require.Len(t, messages, 1)
+		assert.Equal(t, "hello", messages[0].Content)
+
+		require.NoError(t, userService.PurgeUser(leaving.ID, "test"))
+
+		// 计数同步扣减
+		post, err := feedService.GetFeedPostByID(stayingPostID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, post.LikeCount)
+		assert.Equal(t, 1, post.CommentCount)
+		var comment models.FeedComment
+		require.NoError(t, testutil.TestDB.First(&comment, stayingComment.ID).Error)
+		assert.Equal(t, 0, comment.LikeCount)
+
+		// 数据全部删除
+		for _, model := range []any{&models.Todo{}, &models.FeedPost{}, &models.FeedComment{}, &models.PostLike{}, &models.FeedCommentLike{}, &models.ConversationHistory{}} {
+			var count int64
+			require.NoError(t, testutil.TestDB.Model(model).Where("user_id = ?", leaving.ID).Count(&count).Error)
+			assert.Zero(t, count, "%T", model)
+		}
+		var count int64
+		require.NoError(t, testutil.TestDB.Model(&models.FeedComment{}).Where("post_id = ?", leavingPost.ID).Count(&count).Error)
+		assert.Zero(t, count)
+		_, err = userService.GetUserByID(leaving.ID)
+		assert.Error(t, err)
+		testutil.TestDB.Where("user_id = ?", leaving.ID).Delete(&models.AuditLog{})
+	})
+}
+
+func TestUserService_PurgeScheduledDeletions(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+
+		expired, err := userService.CreateUser(getTestUser1("_expired"))
+		require.NoError(t, err)
+		pending, err := userService.CreateUser(getTestUser2("_pending"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(pending.ID)
+			testutil.TestDB.Where("user_id = ?", expired.ID).Delete(&models.AuditLog{})
+		}()
+
+		require.NoError(t, testutil.TestDB.Model(expired).Update("deletion_scheduled_at", time.Now().Add(-time.Minute)).Error)
+		require.NoError(t, testutil.TestDB.Model(pending).Update("deletion_scheduled_at", time.Now().Add(time.Hour)).Error)
+
+		purged, err := userService.PurgeScheduledDeletions()
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+
+		_, err = userService.GetUserByID(expired.ID)
+		assert.Error(t, err)
+		_, err = userService.GetUserByID(pending.ID)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,478 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrModelDisabled = errors.New("模型已被禁用")
+	ErrAliasConflict = errors.New("别名已被其他模型使用")
+)
+
+const modelCacheKeyPrefix = "ai:models:"
+
+// providerModel 平台 /models 接口返回的模型，兼容 OpenAI 标准字段和 OpenRouter 的扩展字段
+type providerModel struct {
+	ID            string `json:"id"`
+	Created       int64  `json:"created"`
+	OwnedBy       string `json:"owned_by"`
+	Name          string `json:"name"`
+	Description   string `json:"description"`
+	ContextLength int    `json:"context_length"`
+	Pricing       *struct {
+		Prompt     string `json:"prompt"`     // 美元/token
+		Completion string `json:"completion"` // 美元/token
+	} `json:"pricing"`
+	Architecture *struct {
+		InputModalities  []string `json:"input_modalities"`
+		OutputModalities []string `json:"output_modalities"`
+	} `json:"architecture"`
+	TopProvider *struct {
+		MaxCompletionTokens int `json:"max_completion_tokens"`
+	} `json:"top_provider"`
+}
+
+// GetAvailableModels 获取可用模型（已启用）
+func (s *AIService) GetAvailableModels(ctx context.Context) ([]models.AIModel, error) {
+	catalog, err := s.ListModelCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	available := make([]models.AIModel, 0, len(catalog))
+	for _, m := range catalog {
+		if m.IsActive {
+			available = append(available, m)
+		}
+	}
+	return available, nil
+}
+
+// OpenAIModels OpenAI 兼容的模型列表，别名作为独立的模型ID返回
+func (s *AIService) OpenAIModels(ctx context.Context) (*models.OpenAIModelList, error) {
+	available, err := s.GetAvailableModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.OpenAIModelList{Object: "list", Data: make([]models.OpenAIModel, 0, len(available))}
+	for _, m := range available {
+		for _, id := range append([]string{m.ID}, m.Aliases...) {
+			list.Data = append(list.Data, models.OpenAIModel{
+				ID:      id,
+				Object:  "model",
+				Created: m.Created,
+				OwnedBy: m.Provider,
+			})
+		}
+	}
+	return list, nil
+}
+
+// ListModelCatalog 汇总各平台模型列表，包含已禁用的模型
+func (s *AIService) ListModelCatalog(ctx context.Context) ([]models.AIModel, error) {
+	merged := make(map[string]*models.AIModel)
+
+	for _, platform := range s.catalogPlatforms() {
+		platformModels, err := s.platformModels(ctx, platform)
+		if err != nil {
+			// 单个平台失败不影响其他平台
+			logrus.WithError(err).WithField("platform", platform).Warn("Failed to fetch platform models")
+			continue
+		}
+		for _, m := range platformModels {
+			mergeModel(merged, m)
+		}
+	}
+
+	for _, m := range merged {
+		applyModelMeta(m)
+	}
+
+	// 路由中配置的逻辑模型，由对应平台提供
+	for id, route := range config.AIModelRoutes {
+		model := models.AIModel{ID: id, Name: id, Type: "chat", Capabilities: []string{"chat", "text-generation"}}
+		for _, target := range s.resolveRoute("", id) {
+			model.Platforms = append(model.Platforms, string(target.Platform))
+		}
+		// 沿用第一个目标模型的信息
+		if len(route) > 0 {
+			if upstream, ok := merged[route[0].Model]; ok {
+				model.Description = upstream.Description
+				model.Provider = upstream.Provider
+				model.Type = upstream.Type
+				model.Capabilities = slices.Clone(upstream.Capabilities)
+				model.ContextLength = upstream.ContextLength
+				model.MaxTokens = upstream.MaxTokens
+				model.Pricing = upstream.Pricing
+				model.Created = upstream.Created
+			}
+		}
+		applyModelMeta(&model)
+		mergeModel(merged, model)
+	}
+
+	catalog := make([]models.AIModel, 0, len(merged))
+	for _, m := range merged {
+		m.IsActive = true
+		catalog = append(catalog, *m)
+	}
+
+	if err := s.applyModelOverrides(catalog); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(catalog, func(i, j int) bool { return catalog[i].ID < catalog[j].ID })
+	return catalog, nil
+}
+
+// RefreshModels 清除各平台模型列表缓存，下次查询时重新拉取
+func (s *AIService) RefreshModels(ctx context.Context) error {
+	if s.Redis == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(s.endpoints))
+	for platform := range s.endpoints {
+		keys = append(keys, modelCacheKeyPrefix+string(platform))
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return s.Redis.Del(ctx, keys...).Err()
+}
+
+// UpdateModelOverride 管理员启用/禁用模型或设置别名
+func (s *AIService) UpdateModelOverride(req models.AIModelOverrideRequest) (*models.AIModelOverride, error) {
+	var override models.AIModelOverride
+	err := s.DB.Where("model_id = ?", req.ModelID).First(&override).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	override.ModelID = req.ModelID
+
+	if req.Enabled != nil {
+		override.Disabled = !*req.Enabled
+	}
+
+	if req.Alias != nil {
+		alias := strings.TrimSpace(*req.Alias)
+		if alias != "" {
+			if alias == req.ModelID {
+				return nil, fmt.Errorf("%w: 别名不能与模型ID相同", ErrAliasConflict)
+			}
+			taken := s.ExistsByConditionExcludeID(&models.AIModelOverride{}, map[string]any{"alias": alias}, override.ID) ||
+				s.ExistsByCondition(&models.AIModelOverride{}, map[string]any{"model_id": alias})
+			if taken {
+				return nil, ErrAliasConflict
+			}
+		}
+		override.Alias = alias
+	}
+
+	if err := s.DB.Save(&override).Error; err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+// resolveModel 解析请求中的模型名：别名转换为实际模型ID，已禁用的模型拒绝调用
+func (s *AIService) resolveModel(model string) (string, error) {
+	if s.DB == nil || model == "" {
+		return model, nil
+	}
+
+	var overrides []models.AIModelOverride
+	if err := s.DB.Where("model_id = ? OR alias = ?", model, model).Find(&overrides).Error; err != nil {
+		return "", err
+	}
+
+	// 模型ID精确匹配优先于别名
+	var matched *models.AIModelOverride
+	for i := range overrides {
+		if overrides[i].ModelID == model {
+			matched = &overrides[i]
+			break
+		}
+		matched = &overrides[i]
+	}
+	if matched == nil {
+		return model, nil
+	}
+
+	if matched.Disabled {
+		return "", fmt.Errorf("%w: %s", ErrModelDisabled, model)
+	}
+	return matched.ModelID, nil
+}
+
+// applyModelOverrides 将管理员设置应用到模型目录
+func (s *AIService) applyModelOverrides(catalog []models.AIModel) error {
+	if s.DB == nil {
+		return nil
+	}
+
+	var overrides []models.AIModelOverride
+	if err := s.DB.Find(&overrides).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[string]models.AIModelOverride, len(overrides))
+	for _, o := range overrides {
+		byID[o.ModelID] = o
+	}
+	for i := range catalog {
+		o, ok := byID[catalog[i].ID]
+		if !ok {
+			continue
+		}
+		catalog[i].IsActive = !o.Disabled
+		if o.Alias != "" {
+			catalog[i].Aliases = append(catalog[i].Aliases, o.Alias)
+		}
+	}
+	return nil
+}
+
+// catalogPlatforms 参与模型目录的平台，按默认路由顺序
+func (s *AIService) catalogPlatforms() []Platform {
+	var platforms []Platform
+	for _, p := range config.AIDefaultRoute {
+		if _, ok := s.endpoints[Platform(p)]; ok {
+			platforms = append(platforms, Platform(p))
+		}
+	}
+	for p := range s.endpoints {
+		if !slices.Contains(platforms, p) {
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms
+}
+
+// platformModels 获取平台模型列表，优先读取 Redis 缓存
+func (s *AIService) platformModels(ctx context.Context, platform Platform) ([]models.AIModel, error) {
+	key := modelCacheKeyPrefix + string(platform)
+
+	if s.Redis != nil {
+		cached, err := s.Redis.Get(ctx, key).Bytes()
+		if err == nil {
+			var list []models.AIModel
+			if err := json.Unmarshal(cached, &list); err == nil {
+				return list, nil
+			}
+		} else if !errors.Is(err, redis.Nil) {
+			logrus.WithError(err).Warn("Failed to read model cache")
+		}
+	}
+
+	list, err := s.fetchPlatformModels(ctx, platform)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Redis != nil {
+		if data, err := json.Marshal(list); err == nil {
+			// 缓存失败不影响主流程
+			s.Redis.SetEx(ctx, key, data, config.AIModelCacheTTL)
+		}
+	}
+	return list, nil
+}
+
+// fetchPlatformModels 请求平台的 /models 接口
+func (s *AIService) fetchPlatformModels(ctx context.Context, platform Platform) ([]models.AIModel, error) {
+	endpoint, ok := s.endpoints[platform]
+	if !ok {
+		return nil, fmt.Errorf("platform %s not available", platform)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AIModelFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint.BaseURL, "/")+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+endpoint.APIKey)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list models failed, status code: %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data []providerModel `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	list := make([]models.AIModel, 0, len(body.Data))
+	for _, pm := range body.Data {
+		if pm.ID == "" {
+			continue
+		}
+		list = append(list, toCatalogModel(platform, pm))
+	}
+	return list, nil
+}
+
+// toCatalogModel 将平台模型转换为目录条目
+func toCatalogModel(platform Platform, pm providerModel) models.AIModel {
+	model := models.AIModel{
+		ID:            pm.ID,
+		Name:          pm.Name,
+		Description:   pm.Description,
+		Provider:      pm.OwnedBy,
+		ContextLength: pm.ContextLength,
+		Platforms:     []string{string(platform)},
+		Created:       pm.Created,
+	}
+
+	if owner, name, ok := strings.Cut(pm.ID, "/"); ok {
+		model.Provider = owner
+		if model.Name == "" {
+			model.Name = name
+		}
+	}
+	if model.Name == "" {
+		model.Name = pm.ID
+	}
+	if model.Provider == "" {
+		model.Provider = string(platform)
+	}
+
+	if pm.TopProvider != nil {
+		model.MaxTokens = pm.TopProvider.MaxCompletionTokens
+	}
+
+	// OpenRouter 按美元/token 计价，换算为每百万 token
+	if pm.Pricing != nil {
+		input, inErr := strconv.ParseFloat(pm.Pricing.Prompt, 64)
+		output, outErr := strconv.ParseFloat(pm.Pricing.Completion, 64)
+		if inErr == nil && outErr == nil && input >= 0 && output >= 0 {
+			model.Pricing = &models.AIModelPricing{Input: input * 1e6, Output: output * 1e6, Currency: "USD"}
+		}
+	}
+
+	var inputModalities []string
+	if pm.Architecture != nil {
+		inputModalities = pm.Architecture.InputModalities
+	}
+	model.Type, model.Capabilities = inferModelType(pm.ID, inputModalities)
+
+	return model
+}
+
+var (
+	visionModelPattern    = regexp.MustCompile(`(^|[-_/.])(vl\d*|vision)([-_/.]|$)`)
+	reasoningModelPattern = regexp.MustCompile(`(^|[-_/.])(r1|reasoner|qwq|thinking)([-_/.]|$)`)
+)
+
+// inferModelType 根据模型ID和输入模态推断模型类型和能力
+func inferModelType(id string, inputModalities []string) (string, []string) {
+	lower := strings.ToLower(id)
+	containsAny := func(keywords ...string) bool {
+		for _, k := range keywords {
+			if strings.Contains(lower, k) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case containsAny("rerank"):
+		return "rerank", []string{"rerank"}
+	case containsAny("embed", "bge-"):
+		return "embedding", []string{"embedding"}
+	case containsAny("flux", "stable-diffusion", "sdxl", "kolors", "dall-e", "wanx", "qwen-image"):
+		return "image", []string{"image-generation"}
+	case containsAny("whisper", "sensevoice", "cosyvoice", "fish-speech", "tts"):
+		return "audio", []string{"audio"}
+	}
+
+	capabilities := []string{"chat", "text-generation"}
+	if slices.Contains(inputModalities, "image") || visionModelPattern.MatchString(lower) {
+		capabilities = append(capabilities, "vision")
+	}
+	if slices.Contains(inputModalities, "audio") {
+		capabilities = append(capabilities, "audio")
+	}
+	if reasoningModelPattern.MatchString(lower) {
+		capabilities = append(capabilities, "reasoning")
+	}
+	return "chat", capabilities
+}
+
+// mergeModel 合并不同平台提供的同一模型
+func mergeModel(merged map[string]*models.AIModel, m models.AIModel) {
+	existing, ok := merged[m.ID]
+	if !ok {
+		merged[m.ID] = &m
+		return
+	}
+
+	for _, p := range m.Platforms {
+		if !slices.Contains(existing.Platforms, p) {
+			existing.Platforms = append(existing.Platforms, p)
+		}
+	}
+	for _, c := range m.Capabilities {
+		if !slices.Contains(existing.Capabilities, c) {
+			existing.Capabilities = append(existing.Capabilities, c)
+		}
+	}
+	existing.ContextLength = max(existing.ContextLength, m.ContextLength)
+	existing.MaxTokens = max(existing.MaxTokens, m.MaxTokens)
+	if existing.Pricing == nil {
+		existing.Pricing = m.Pricing
+	}
+	if existing.Description == "" {
+		existing.Description = m.Description
+	}
+}
+
+// applyModelMeta 补充平台未提供的模型信息
+func applyModelMeta(m *models.AIModel) {
+	meta, ok := config.AIModelMetadata[m.ID]
+	if !ok {
+		return
+	}
+
+	if m.ContextLength == 0 {
+		m.ContextLength = meta.ContextLength
+	}
+	if m.MaxTokens == 0 {
+		m.MaxTokens = meta.MaxTokens
+	}
+	if m.Pricing == nil && meta.Currency != "" {
+		m.Pricing = &models.AIModelPricing{Input: meta.InputPrice, Output: meta.OutputPrice, Currency: meta.Currency}
+	}
+	for _, c := range meta.Capabilities {
+		if !slices.Contains(m.Capabilities, c) {
+			m.Capabilities = append(m.Capabilities, c)
+		}
+	}
+}
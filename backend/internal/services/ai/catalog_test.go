@@ -0,0 +1,156 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const siliconModelsBody = `{"object":"list","data":[
+	{"id":"deepseek-ai/DeepSeek-V3","object":"model","created":1,"owned_by":""},
+	{"id":"Qwen/Qwen2.5-VL-72B-Instruct","object":"model","created":2,"owned_by":""},
+	{"id":"BAAI/bge-m3","object":"model","created":3,"owned_by":""},
+	{"id":"black-forest-labs/FLUX.1-schnell","object":"model","created":4,"owned_by":""}
+]}`
+
+const openRouterModelsBody = `{"data":[
+	{"id":"deepseek/deepseek-chat","name":"DeepSeek V3","description":"DeepSeek chat model","context_length":163840,
+	 "pricing":{"prompt":"0.0000003","completion":"0.00000088"},
+	 "architecture":{"input_modalities":["text"],"output_modalities":["text"]},
+	 "top_provider":{"max_completion_tokens":16384}},
+	{"id":"deepseek/deepseek-r1","name":"DeepSeek R1","context_length":163840,"pricing":{"prompt":"-1","completion":"-1"}},
+	{"id":"openai/gpt-4o","name":"GPT-4o","context_length":128000,"architecture":{"input_modalities":["text","image"]}}
+]}`
+
+// modelsHandler 以固定状态码返回模型列表
+func modelsHandler(t *testing.T, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/models", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}
+}
+
+func findModel(t *testing.T, catalog []models.AIModel, id string) models.AIModel {
+	for _, m := range catalog {
+		if m.ID == id {
+			return m
+		}
+	}
+	require.Failf(t, "model not found", id)
+	return models.AIModel{}
+}
+
+func TestModelCatalog_Merge(t *testing.T) {
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    newTestServer(t, modelsHandler(t, http.StatusOK, siliconModelsBody)),
+		PlatformOpenRouter: newTestServer(t, modelsHandler(t, http.StatusOK, openRouterModelsBody)),
+		PlatformDashScope:  newTestServer(t, modelsHandler(t, http.StatusInternalServerError, `{}`)),
+	})
+
+	catalog, err := s.ListModelCatalog(context.Background())
+	require.NoError(t, err)
+
+	v3 := findModel(t, catalog, "deepseek-ai/DeepSeek-V3")
+	assert.Equal(t, "deepseek-ai", v3.Provider)
+	assert.Equal(t, []string{"silicon"}, v3.Platforms)
+	// 平台未提供的信息来自配置
+	assert.Equal(t, 65536, v3.ContextLength)
+	require.NotNil(t, v3.Pricing)
+	assert.Equal(t, "CNY", v3.Pricing.Currency)
+	assert.Contains(t, v3.Capabilities, "tools")
+
+	assert.Contains(t, findModel(t, catalog, "Qwen/Qwen2.5-VL-72B-Instruct").Capabilities, "vision")
+	assert.Equal(t, "embedding", findModel(t, catalog, "BAAI/bge-m3").Type)
+	assert.Equal(t, "image", findModel(t, catalog, "black-forest-labs/FLUX.1-schnell").Type)
+
+	chat := findModel(t, catalog, "deepseek/deepseek-chat")
+	assert.Equal(t, "DeepSeek V3", chat.Name)
+	assert.Equal(t, 163840, chat.ContextLength)
+	assert.Equal(t, 16384, chat.MaxTokens)
+	require.NotNil(t, chat.Pricing)
+	assert.InDelta(t, 0.3, chat.Pricing.Input, 1e-9)
+	assert.InDelta(t, 0.88, chat.Pricing.Output, 1e-9)
+	assert.Equal(t, "USD", chat.Pricing.Currency)
+
+	r1 := findModel(t, catalog, "deepseek/deepseek-r1")
+	assert.Nil(t, r1.Pricing)
+	assert.Contains(t, r1.Capabilities, "reasoning")
+	assert.Contains(t, findModel(t, catalog, "openai/gpt-4o").Capabilities, "vision")
+
+	// 路由中的逻辑模型，dashscope 不可用时仍列出已配置的平台
+	logical := findModel(t, catalog, "deepseek-chat")
+	assert.Equal(t, []string{"silicon", "openrouter", "dashscope"}, logical.Platforms)
+	assert.Equal(t, 65536, logical.ContextLength)
+
+	for _, m := range catalog {
+		assert.True(t, m.IsActive, m.ID)
+	}
+}
+
+func TestModelCatalog_OpenAIList(t *testing.T) {
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon: newTestServer(t, modelsHandler(t, http.StatusOK, siliconModelsBody)),
+	})
+
+	list, err := s.OpenAIModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "list", list.Object)
+
+	ids := make([]string, len(list.Data))
+	for i, m := range list.Data {
+		assert.Equal(t, "model", m.Object)
+		ids[i] = m.ID
+	}
+	assert.Contains(t, ids, "deepseek-ai/DeepSeek-V3")
+	for id := range config.AIModelRoutes {
+		assert.Contains(t, ids, id)
+	}
+}
+
+func TestModelCatalog_MockOnly(t *testing.T) {
+	s := newStubService(nil)
+
+	catalog, err := s.ListModelCatalog(context.Background())
+	require.NoError(t, err)
+	require.Len(t, catalog, len(config.AIModelRoutes))
+	for _, m := range catalog {
+		assert.Equal(t, []string{string(PlatformMock)}, m.Platforms)
+	}
+}
+
+func TestInferModelType(t *testing.T) {
+	cases := []struct {
+		id   string
+		typ  string
+		caps []string
+	}{
+		{id: "BAAI/bge-reranker-v2-m3", typ: "rerank"},
+		{id: "text-embedding-v3", typ: "embedding"},
+		{id: "FunAudioLLM/SenseVoiceSmall", typ: "audio"},
+		{id: "deepseek-ai/deepseek-vl2", typ: "chat", caps: []string{"vision"}},
+		{id: "Qwen/QwQ-32B", typ: "chat", caps: []string{"reasoning"}},
+		{id: "deepseek-reasoner", typ: "chat", caps: []string{"reasoning"}},
+		{id: "qwen-plus", typ: "chat"},
+	}
+	for _, tc := range cases {
+		typ, caps := inferModelType(tc.id, nil)
+		assert.Equal(t, tc.typ, typ, tc.id)
+		for _, c := range tc.caps {
+			assert.Contains(t, caps, c, tc.id)
+		}
+	}
+
+	// 平台声明的模态优先
+	_, caps := inferModelType("some/model", []string{"text", "image", "audio"})
+	assert.Contains(t, caps, "vision")
+	assert.Contains(t, caps, "audio")
+}
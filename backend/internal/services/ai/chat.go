@@ -6,217 +6,409 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
-// Chat 聊天（非流式）
-func (s *AIService) Chat(userID uint64, req models.ChatRequest) (*models.ChatResponse, error) {
-	platform := s.getDefaultPlatform()
+// Chat 聊天（非流式），携带会话历史并持久化本轮对话
+func (s *AIService) Chat(ctx context.Context, userID uint64, req models.ChatRequest) (*models.ChatResponse, error) {
+	if err := s.CheckQuota(userID); err != nil {
+		return nil, err
+	}
 
-	// 检查是否是 mock 平台
-	if platform == PlatformMock {
-		return s.mockChat(userID, req)
+	model, err := s.resolveModel(s.getModelName(req.Model))
+	if err != nil {
+		return nil, err
 	}
+	req.Model = model
 
-	client, err := s.getClient(platform)
+	session, err := s.prepareSession(userID, req.SessionID, model, req.Message)
 	if err != nil {
 		return nil, err
 	}
 
-	chatReq := openai.ChatCompletionRequest{
-		Model: s.getModelName(req.Model),
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    "user",
-				Content: req.Message,
-			},
-		},
-		Stream: false,
+	messages, err := s.buildSessionMessages(session, req.Message)
+	if err != nil {
+		return nil, err
 	}
 
-	ctx := context.Background()
-	resp, err := client.CreateChatCompletion(ctx, chatReq)
+	resp, err := s.complete(ctx, userID, req, messages)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to create chat completion")
 		return nil, err
 	}
 
-	var content string
-	if len(resp.Choices) > 0 {
-		content = resp.Choices[0].Message.Content
+	if err := s.saveSessionTurn(session, req.Message, resp.Message, resp.Model); err != nil {
+		logrus.WithError(err).WithField("session_id", session.SessionID).Error("Failed to save chat history")
 	}
 
-	return &models.ChatResponse{
-		ID:        resp.ID,
-		Message:   content,
-		Model:     resp.Model,
-		CreatedAt: time.Unix(resp.Created, 0),
-		Usage: models.Usage{
-			PromptTokens:     resp.Usage.PromptTokens,
-			CompletionTokens: resp.Usage.CompletionTokens,
-			TotalTokens:      resp.Usage.TotalTokens,
-		},
-	}, nil
+	resp.SessionID = session.SessionID
+	return resp, nil
 }
 
-// StreamChat 聊天（流式）
-func (s *AIService) StreamChat(userID uint64, req models.ChatRequest, responseChan chan<- string) error {
-	platform := s.getDefaultPlatform()
+// complete 单次聊天补全，不涉及会话
+func (s *AIService) complete(ctx context.Context, userID uint64, req models.ChatRequest, messages []openai.ChatCompletionMessage) (*models.ChatResponse, error) {
+	model := s.getModelName(req.Model)
+	targets := s.resolveRoute("", model)
+	meter := s.startUsage(userID, EndpointChat, model, false)
+
+	var result *models.ChatResponse
+	platform, err := s.routeCall(ctx, targets, func(target routeTarget) error {
+		// 检查是否是 mock 平台
+		if target.Platform == PlatformMock {
+			resp, err := s.mockChat(ctx, req, messages)
+			result = resp
+			return err
+		}
 
-	// 检查是否是 mock 平台
-	if platform == PlatformMock {
-		return s.mockStreamChat(userID, req, responseChan)
+		client, err := s.getClient(target.Platform)
+		if err != nil {
+			return err
+		}
+
+		chatReq := openai.ChatCompletionRequest{
+			Model:    target.Model,
+			Messages: messages,
+			Stream:   false,
+		}
+
+		callCtx, cancel := withPlatformTimeout(ctx, target.Platform, false)
+		defer cancel()
+
+		resp, err := client.CreateChatCompletion(callCtx, chatReq)
+		if err != nil {
+			return err
+		}
+
+		var content string
+		if len(resp.Choices) > 0 {
+			content = resp.Choices[0].Message.Content
+		}
+
+		result = &models.ChatResponse{
+			ID:        resp.ID,
+			Message:   content,
+			Model:     resp.Model,
+			CreatedAt: time.Unix(resp.Created, 0),
+			Usage: models.Usage{
+				PromptTokens:     resp.Usage.PromptTokens,
+				CompletionTokens: resp.Usage.CompletionTokens,
+				TotalTokens:      resp.Usage.TotalTokens,
+			},
+		}
+		return nil
+	})
+	if err != nil {
+		meter.finish(platform, models.Usage{}, err)
+		logrus.WithError(err).Error("Failed to create chat completion")
+		return nil, err
 	}
+	meter.finish(platform, result.Usage, nil)
 
-	client, err := s.getClient(platform)
+	result.Platform = string(platform)
+	return result, nil
+}
+
+// StreamChat 聊天（流式），结束后持久化完整回复
+// 客户端断开（ctx 取消）后停止读取上游，不保存未完成的回复
+func (s *AIService) StreamChat(ctx context.Context, userID uint64, req models.ChatRequest, responseChan chan<- string) error {
+	if err := s.CheckQuota(userID); err != nil {
+		return err
+	}
+
+	model, err := s.resolveModel(s.getModelName(req.Model))
 	if err != nil {
 		return err
 	}
+	req.Model = model
 
-	chatReq := openai.ChatCompletionRequest{
-		Model: s.getModelName(req.Model),
-		Messages: []openai.ChatCompletionMessage{
-			{
-				Role:    "user",
-				Content: req.Message,
-			},
-		},
-		Stream: true,
+	session, err := s.prepareSession(userID, req.SessionID, model, req.Message)
+	if err != nil {
+		return err
+	}
+
+	messages, err := s.buildSessionMessages(session, req.Message)
+	if err != nil {
+		return err
+	}
+
+	// 转发的同时收集完整回复
+	var reply strings.Builder
+	emit := func(chunk string) {
+		reply.WriteString(chunk)
+		// 接收方已退出时不再阻塞
+		select {
+		case responseChan <- chunk:
+		case <-ctx.Done():
+		}
 	}
 
-	ctx := context.Background()
-	stream, err := client.CreateChatCompletionStream(ctx, chatReq)
+	if err := s.streamComplete(ctx, userID, req, messages, emit); err != nil {
+		return err
+	}
+
+	if err := s.saveSessionTurn(session, req.Message, reply.String(), model); err != nil {
+		logrus.WithError(err).WithField("session_id", session.SessionID).Error("Failed to save chat history")
+	}
+
+	return nil
+}
+
+// streamComplete 单次流式聊天补全，不涉及会话
+func (s *AIService) streamComplete(ctx context.Context, userID uint64, req models.ChatRequest, messages []openai.ChatCompletionMessage, emit func(string)) error {
+	model := s.getModelName(req.Model)
+	targets := s.resolveRoute("", model)
+	meter := s.startUsage(userID, EndpointChat, model, true)
+
+	// 只在建立流之前切换平台，已经开始输出后不再重试
+	var stream *chatStream
+	platform, err := s.routeCall(ctx, targets, func(target routeTarget) error {
+		if target.Platform == PlatformMock {
+			return nil
+		}
+
+		chatReq := openai.ChatCompletionRequest{
+			Model:         target.Model,
+			Messages:      messages,
+			Stream:        true,
+			StreamOptions: &openai.StreamOptions{IncludeUsage: true},
+		}
+
+		var err error
+		stream, err = s.openChatStream(ctx, target.Platform, chatReq)
+		return err
+	})
 	if err != nil {
+		meter.finish(platform, models.Usage{}, err)
 		logrus.WithError(err).Error("Failed to create chat completion stream")
 		return err
 	}
-	defer stream.Close()
 
+	// 收集回复内容，上游未返回用量时据此估算
+	var reply strings.Builder
+	var usage *models.Usage
+	collect := func(chunk string) {
+		reply.WriteString(chunk)
+		emit(chunk)
+	}
+
+	// 检查是否是 mock 平台
+	if platform == PlatformMock {
+		err = s.mockStreamChat(ctx, req, collect)
+	} else {
+		defer stream.Close()
+		usage, err = recvChatStream(stream, collect)
+	}
+
+	// 中途断开时按已收到的内容估算用量
+	if usage == nil {
+		estimated := estimateUsage(messages, reply.String())
+		usage = &estimated
+	}
+	meter.finish(platform, *usage, err)
+
+	return err
+}
+
+// chatStream 上游流式响应，关闭时一并释放超时 context
+type chatStream struct {
+	*openai.ChatCompletionStream
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// openChatStream 按平台超时建立流式请求，超时覆盖整个读取过程
+func (s *AIService) openChatStream(ctx context.Context, platform Platform, chatReq openai.ChatCompletionRequest) (*chatStream, error) {
+	client, err := s.getClient(platform)
+	if err != nil {
+		return nil, err
+	}
+
+	streamCtx, cancel := withPlatformTimeout(ctx, platform, true)
+	stream, err := client.CreateChatCompletionStream(streamCtx, chatReq)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &chatStream{ChatCompletionStream: stream, ctx: streamCtx, cancel: cancel}, nil
+}
+
+// Recv 读取下一个数据块，context 结束导致的读取失败统一返回 context 错误
+func (cs *chatStream) Recv() (openai.ChatCompletionStreamResponse, error) {
+	response, err := cs.ChatCompletionStream.Recv()
+	if err != nil && err != io.EOF && cs.ctx.Err() != nil {
+		return response, cs.ctx.Err()
+	}
+	return response, err
+}
+
+func (cs *chatStream) Close() error {
+	defer cs.cancel()
+	return cs.ChatCompletionStream.Close()
+}
+
+// recvChatStream 读取流式回复，返回上游给出的用量（可能为空）
+func recvChatStream(stream *chatStream, emit func(string)) (*models.Usage, error) {
+	var usage *models.Usage
 	for {
 		response, err := stream.Recv()
 		if err != nil {
 			if err == io.EOF {
-				break
+				return usage, nil
+			}
+			return usage, err
+		}
+
+		if response.Usage != nil {
+			usage = &models.Usage{
+				PromptTokens:     response.Usage.PromptTokens,
+				CompletionTokens: response.Usage.CompletionTokens,
+				TotalTokens:      response.Usage.TotalTokens,
 			}
-			return err
 		}
 
 		if len(response.Choices) > 0 && response.Choices[0].Delta.Content != "" {
-			responseChan <- response.Choices[0].Delta.Content
+			emit(response.Choices[0].Delta.Content)
 		}
 	}
-
-	return nil
 }
 
 // ChatCompletion OpenAI兼容的聊天接口（非流式）
-func (s *AIService) ChatCompletion(platform Platform, req models.OpenAIChatCompletionRequest) (*models.OpenAIChatCompletionResponse, error) {
-	if platform == "" {
-		platform = s.getDefaultPlatform()
+func (s *AIService) ChatCompletion(ctx context.Context, userID uint64, platform Platform, req models.OpenAIChatCompletionRequest) (*models.OpenAIChatCompletionResponse, error) {
+	if err := s.CheckQuota(userID); err != nil {
+		return nil, err
 	}
 
-	// 检查是否是 mock 平台
-	if platform == PlatformMock {
-		return s.mockChatCompletion(req)
+	model, err := s.resolveModel(req.Model)
+	if err != nil {
+		return nil, err
 	}
+	req.Model = model
 
-	client, err := s.getClient(platform)
+	messages, err := s.resolveContentURLs(userID, req.Messages)
 	if err != nil {
 		return nil, err
 	}
+	req.Messages = messages
+
+	meter := s.startUsage(userID, EndpointChatCompletions, req.Model, false)
 
-	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
-	for i, msg := range req.Messages {
-		messages[i] = openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+	var result *models.OpenAIChatCompletionResponse
+	served, err := s.routeCall(ctx, s.resolveRoute(platform, req.Model), func(target routeTarget) error {
+		// 检查是否是 mock 平台
+		if target.Platform == PlatformMock {
+			resp, err := s.mockChatCompletion(ctx, req)
+			result = resp
+			return err
 		}
-	}
 
-	chatReq := openai.ChatCompletionRequest{
-		Model:    req.Model,
-		Messages: messages,
-		Stream:   false,
-	}
+		client, err := s.getClient(target.Platform)
+		if err != nil {
+			return err
+		}
+
+		chatReq := toChatCompletionRequest(req, target.Model)
+		chatReq.Stream = false
+
+		callCtx, cancel := withPlatformTimeout(ctx, target.Platform, false)
+		defer cancel()
+
+		resp, err := client.CreateChatCompletion(callCtx, chatReq)
+		if err != nil {
+			return err
+		}
 
-	ctx := context.Background()
-	resp, err := client.CreateChatCompletion(ctx, chatReq)
+		result = fromChatCompletionResponse(resp)
+		return nil
+	})
 	if err != nil {
+		meter.finish(served, models.Usage{}, err)
 		return nil, err
 	}
-
-	var content string
-	if len(resp.Choices) > 0 {
-		content = resp.Choices[0].Message.Content
-	}
-
-	return &models.OpenAIChatCompletionResponse{
-		ID:      resp.ID,
-		Object:  "chat.completion",
-		Created: resp.Created,
-		Model:   resp.Model,
-		Choices: []models.OpenAIChoice{
-			{
-				Index: 0,
-				Message: models.OpenAIMessage{
-					Role:    "assistant",
-					Content: content,
-				},
-				FinishReason: "stop",
-			},
-		},
-		Usage: models.OpenAIUsage{
-			PromptTokens:     resp.Usage.PromptTokens,
-			CompletionTokens: resp.Usage.CompletionTokens,
-			TotalTokens:      resp.Usage.TotalTokens,
-		},
-	}, nil
+	meter.finish(served, models.Usage{
+		PromptTokens:     result.Usage.PromptTokens,
+		CompletionTokens: result.Usage.CompletionTokens,
+		TotalTokens:      result.Usage.TotalTokens,
+	}, nil)
+
+	result.Platform = string(served)
+	return result, nil
 }
 
 // ChatCompletionStream OpenAI兼容的聊天接口（流式）
-func (s *AIService) ChatCompletionStream(platform Platform, req models.OpenAIChatCompletionRequest, writer io.Writer) error {
-	if platform == "" {
-		platform = s.getDefaultPlatform()
+// 客户端断开（ctx 取消）后立即停止读取上游，并按已输出的内容记录用量
+func (s *AIService) ChatCompletionStream(ctx context.Context, userID uint64, platform Platform, req models.OpenAIChatCompletionRequest, writer io.Writer) error {
+	if err := s.CheckQuota(userID); err != nil {
+		return err
 	}
 
-	// 检查是否是 mock 平台
-	if platform == PlatformMock {
-		return s.mockChatCompletionStream(req, writer)
+	model, err := s.resolveModel(req.Model)
+	if err != nil {
+		return err
 	}
+	req.Model = model
 
-	client, err := s.getClient(platform)
+	messages, err := s.resolveContentURLs(userID, req.Messages)
 	if err != nil {
 		return err
 	}
+	req.Messages = messages
+
+	meter := s.startUsage(userID, EndpointChatCompletions, req.Model, true)
+	// 始终向上游要求返回用量，客户端未要求时不转发用量块
+	clientWantsUsage := req.StreamOptions != nil && req.StreamOptions.IncludeUsage
 
-	messages := make([]openai.ChatCompletionMessage, len(req.Messages))
-	for i, msg := range req.Messages {
-		messages[i] = openai.ChatCompletionMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
+	// 只在建立流之前切换平台，已经开始输出后不再重试
+	var stream *chatStream
+	served, err := s.routeCall(ctx, s.resolveRoute(platform, req.Model), func(target routeTarget) error {
+		if target.Platform == PlatformMock {
+			return nil
 		}
+
+		chatReq := toChatCompletionRequest(req, target.Model)
+		chatReq.Stream = true
+		chatReq.StreamOptions = &openai.StreamOptions{IncludeUsage: true}
+
+		var err error
+		stream, err = s.openChatStream(ctx, target.Platform, chatReq)
+		return err
+	})
+	if err != nil {
+		meter.finish(served, models.Usage{}, err)
+		return err
 	}
 
-	chatReq := openai.ChatCompletionRequest{
-		Model:    req.Model,
-		Messages: messages,
-		Stream:   true,
+	// 写出第一个字节前记录实际服务的平台
+	if w, ok := writer.(interface{ Header() http.Header }); ok {
+		w.Header().Set(PlatformHeader, string(served))
 	}
 
-	ctx := context.Background()
-	stream, err := client.CreateChatCompletionStream(ctx, chatReq)
-	if err != nil {
+	// 检查是否是 mock 平台
+	if served == PlatformMock {
+		reply, err := s.mockChatCompletionStream(ctx, req, writer)
+		meter.finish(served, estimateUsage(toChatCompletionMessages(req.Messages), reply), err)
 		return err
 	}
 	defer stream.Close()
 
+	var usage *models.Usage
+	var reply strings.Builder
+	defer func() {
+		if usage == nil {
+			estimated := estimateUsage(toChatCompletionMessages(req.Messages), reply.String())
+			usage = &estimated
+		}
+		meter.finish(served, *usage, err)
+	}()
+
 	for {
-		response, err := stream.Recv()
+		var response openai.ChatCompletionStreamResponse
+		response, err = stream.Recv()
 		if err != nil {
 			if err == io.EOF {
+				err = nil
 				writer.Write([]byte("data: [DONE]\n\n"))
 				break
 			}
@@ -224,28 +416,55 @@ func (s *AIService) ChatCompletionStream(platform Platform, req models.OpenAICha
 		}
 
 		// 构建流式响应
-		streamResponse := map[string]any{
-			"id":      response.ID,
-			"object":  "chat.completion.chunk",
-			"created": response.Created,
-			"model":   response.Model,
-			"choices": response.Choices,
+		chunk := fromChatCompletionStreamResponse(response)
+		chunk.Platform = string(served)
+
+		for _, choice := range response.Choices {
+			reply.WriteString(choice.Delta.Content)
+		}
+		if chunk.Usage != nil {
+			usage = &models.Usage{
+				PromptTokens:     chunk.Usage.PromptTokens,
+				CompletionTokens: chunk.Usage.CompletionTokens,
+				TotalTokens:      chunk.Usage.TotalTokens,
+			}
+			if !clientWantsUsage {
+				if len(chunk.Choices) == 0 {
+					continue
+				}
+				chunk.Usage = nil
+			}
 		}
 
-		data, err := json.Marshal(streamResponse)
-		if err != nil {
+		data, marshalErr := json.Marshal(chunk)
+		if marshalErr != nil {
 			continue
 		}
 
-		writer.Write([]byte("data: "))
-		writer.Write(data)
-		writer.Write([]byte("\n\n"))
-
-		// 刷新缓冲区
-		if flusher, ok := writer.(http.Flusher); ok {
-			flusher.Flush()
+		// 写入失败说明客户端已断开
+		if err = writeSSEData(writer, data); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// writeSSEData 写出一个 SSE 数据块并立即刷新
+func writeSSEData(writer io.Writer, data []byte) error {
+	if _, err := writer.Write([]byte("data: ")); err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if _, err := writer.Write([]byte("\n\n")); err != nil {
+		return err
+	}
+
+	// 刷新缓冲区
+	if flusher, ok := writer.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+var ErrInvalidContent = errors.New("invalid message content")
+
+// OSSURLScheme image_url 中引用 OSS 对象的前缀，必须显式带上
+const OSSURLScheme = "oss://"
+
+// objectSigner 为 OSS 对象生成临时访问地址，由 OSSService 实现
+type objectSigner interface {
+	SignToFetch(objectKey string) (string, error)
+}
+
+// allowedObjectKey 消息只能引用上传目录和调用者自己的生成图片目录下的对象
+func allowedObjectKey(key string, userID uint64) bool {
+	if strings.Contains(key, "..") {
+		return false
+	}
+	prefixes := append([]string{fmt.Sprintf("%s%d/", config.AIImageOSSPrefix, userID)}, config.AIContentOSSPrefixes...)
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(key, prefix) && len(key) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveContentURLs 将消息中以 oss:// 引用的 objectKey 替换为签名下载地址，返回新的消息列表
+// http(s) 链接和 data URI 原样转发，其他地址以及无权引用的对象返回 ErrInvalidContent
+func (s *AIService) resolveContentURLs(userID uint64, messages []models.OpenAIMessage) ([]models.OpenAIMessage, error) {
+	resolved := make([]models.OpenAIMessage, len(messages))
+	copy(resolved, messages)
+
+	for i, msg := range resolved {
+		if len(msg.Content.Parts) == 0 {
+			continue
+		}
+
+		parts := make([]models.OpenAIContentPart, len(msg.Content.Parts))
+		for j, part := range msg.Content.Parts {
+			parts[j] = part
+			if part.Type != models.ContentPartImageURL || part.ImageURL == nil {
+				continue
+			}
+
+			url := part.ImageURL.URL
+			key, ok := strings.CutPrefix(url, OSSURLScheme)
+			if !ok {
+				if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") || strings.HasPrefix(url, "data:") {
+					continue
+				}
+				return nil, fmt.Errorf("%w: messages[%d].content[%d] image url must be http(s), data URI or %s", ErrInvalidContent, i, j, OSSURLScheme)
+			}
+			if !allowedObjectKey(key, userID) {
+				return nil, fmt.Errorf("%w: messages[%d].content[%d] references an object that is not accessible", ErrInvalidContent, i, j)
+			}
+			if s.signer == nil {
+				return nil, fmt.Errorf("%w: OSS object keys are not supported", ErrInvalidContent)
+			}
+
+			signed, err := s.signer.SignToFetch(key)
+			if err != nil {
+				return nil, err
+			}
+			imageURL := *part.ImageURL
+			imageURL.URL = signed
+			parts[j].ImageURL = &imageURL
+		}
+		resolved[i].Content = models.OpenAIContent{Parts: parts}
+	}
+
+	return resolved, nil
+}
+
+// toClientContentParts 转换内容片段，input_audio 暂存在 Text 中由 contentPartDoer 还原
+func toClientContentParts(parts []models.OpenAIContentPart) []openai.ChatMessagePart {
+	result := make([]openai.ChatMessagePart, len(parts))
+	for i, part := range parts {
+		result[i] = openai.ChatMessagePart{
+			Type: openai.ChatMessagePartType(part.Type),
+			Text: part.Text,
+		}
+		switch part.Type {
+		case models.ContentPartImageURL:
+			result[i].ImageURL = &openai.ChatMessageImageURL{
+				URL:    part.ImageURL.URL,
+				Detail: openai.ImageURLDetail(part.ImageURL.Detail),
+			}
+		case models.ContentPartInputAudio:
+			audio, _ := json.Marshal(part.InputAudio)
+			result[i].Text = string(audio)
+		}
+	}
+	return result
+}
+
+// contentPartDoer 补齐客户端不支持的内容片段（input_audio）后再发送请求
+type contentPartDoer struct {
+	client openai.HTTPDoer
+}
+
+func newContentPartDoer() *contentPartDoer {
+	return &contentPartDoer{client: &http.Client{}}
+}
+
+var inputAudioMarker = []byte(`"type":"input_audio"`)
+
+func (d *contentPartDoer) Do(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return d.client.Do(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if bytes.Contains(body, inputAudioMarker) {
+		if body, err = rewriteInputAudioParts(body); err != nil {
+			return nil, err
+		}
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.ContentLength = int64(len(body))
+
+	return d.client.Do(req)
+}
+
+// rewriteInputAudioParts 将 input_audio 片段的 text 改写为 input_audio 字段，其余内容原样保留
+func rewriteInputAudioParts(body []byte) ([]byte, error) {
+	var payload map[string]json.RawMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, err
+	}
+
+	var messages []map[string]json.RawMessage
+	if err := json.Unmarshal(payload["messages"], &messages); err != nil {
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		var parts []map[string]json.RawMessage
+		if err := json.Unmarshal(msg["content"], &parts); err != nil {
+			// 字符串内容
+			continue
+		}
+
+		changed := false
+		for _, part := range parts {
+			var partType string
+			if err := json.Unmarshal(part["type"], &partType); err != nil || partType != models.ContentPartInputAudio {
+				continue
+			}
+
+			var audio string
+			if err := json.Unmarshal(part["text"], &audio); err != nil {
+				return nil, err
+			}
+			part["input_audio"] = json.RawMessage(audio)
+			delete(part, "text")
+			changed = true
+		}
+
+		if changed {
+			content, err := json.Marshal(parts)
+			if err != nil {
+				return nil, err
+			}
+			msg["content"] = content
+		}
+	}
+
+	rewritten, err := json.Marshal(messages)
+	if err != nil {
+		return nil, err
+	}
+	payload["messages"] = rewritten
+
+	return json.Marshal(payload)
+}
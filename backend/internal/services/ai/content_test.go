@@ -0,0 +1,161 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSigner struct {
+	keys []string
+	err  error
+}
+
+func (s *stubSigner) SignToFetch(objectKey string) (string, error) {
+	s.keys = append(s.keys, objectKey)
+	if s.err != nil {
+		return "", s.err
+	}
+	return "https://bucket.oss.example.com/" + objectKey + "?signature=x", nil
+}
+
+const multimodalRequestBody = `{
+	"model": "stub-model",
+	"messages": [
+		{"role": "system", "content": "你是助手"},
+		{"role": "user", "content": [
+			{"type": "text", "text": "描述这两张图"},
+			{"type": "image_url", "image_url": {"url": "oss://assets/images/cat.png", "detail": "low"}},
+			{"type": "image_url", "image_url": {"url": "https://example.com/dog.png"}},
+			{"type": "input_audio", "input_audio": {"data": "UklGRg==", "format": "wav"}}
+		]}
+	]
+}`
+
+func TestOpenAIContent_JSON(t *testing.T) {
+	req := toolRequest(t, multimodalRequestBody)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, "你是助手", req.Messages[0].Content.String())
+	require.Len(t, req.Messages[1].Content.Parts, 4)
+	assert.Equal(t, "描述这两张图", req.Messages[1].Content.String())
+
+	// 字符串内容序列化后保持字符串
+	data, err := json.Marshal(req.Messages[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"content":"你是助手"`)
+
+	for _, body := range []string{
+		`{"role":"user","content":[{"type":"video"}]}`,
+		`{"role":"user","content":[{"type":"image_url"}]}`,
+		`{"role":"user","content":[{"type":"input_audio","input_audio":{"data":"x"}}]}`,
+		`{"role":"user","content":42}`,
+	} {
+		var msg models.OpenAIMessage
+		assert.Error(t, json.Unmarshal([]byte(body), &msg), body)
+	}
+}
+
+func TestChatCompletion_Multimodal(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, toolCallResponse)
+	}))
+	s := newCaptureService(server)
+	signer := &stubSigner{}
+	s.signer = signer
+
+	req := toolRequest(t, multimodalRequestBody)
+	_, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, req)
+	require.NoError(t, err)
+
+	// 只有 OSS 引用需要签名，原请求不被修改
+	assert.Equal(t, []string{"assets/images/cat.png"}, signer.keys)
+	assert.Equal(t, "oss://assets/images/cat.png", req.Messages[1].Content.Parts[1].ImageURL.URL)
+
+	messages := captured["messages"].([]any)
+	assert.Equal(t, "你是助手", messages[0].(map[string]any)["content"])
+
+	parts := messages[1].(map[string]any)["content"].([]any)
+	require.Len(t, parts, 4)
+	assert.Equal(t, map[string]any{"type": "text", "text": "描述这两张图"}, parts[0])
+	assert.Equal(t, map[string]any{
+		"type":      "image_url",
+		"image_url": map[string]any{"url": "https://bucket.oss.example.com/assets/images/cat.png?signature=x", "detail": "low"},
+	}, parts[1])
+	assert.Equal(t, "https://example.com/dog.png", parts[2].(map[string]any)["image_url"].(map[string]any)["url"])
+	assert.Equal(t, map[string]any{
+		"type":        "input_audio",
+		"input_audio": map[string]any{"data": "UklGRg==", "format": "wav"},
+	}, parts[3])
+}
+
+func TestResolveContentURLs(t *testing.T) {
+	parts := func(url string) []models.OpenAIMessage {
+		return []models.OpenAIMessage{{
+			Role: "user",
+			Content: models.OpenAIContent{Parts: []models.OpenAIContentPart{
+				{Type: models.ContentPartImageURL, ImageURL: &models.OpenAIImageURL{URL: url}},
+			}},
+		}}
+	}
+
+	t.Run("oss object key", func(t *testing.T) {
+		signer := &stubSigner{}
+		s := &AIService{signer: signer}
+		messages, err := s.resolveContentURLs(7, parts("oss://assets/images/a.png"))
+		require.NoError(t, err)
+		messages2, err := s.resolveContentURLs(7, parts("oss://assets/ai-images/7/b.png"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"assets/images/a.png", "assets/ai-images/7/b.png"}, signer.keys)
+		assert.Contains(t, messages[0].Content.Parts[0].ImageURL.URL, "signature")
+		assert.Contains(t, messages2[0].Content.Parts[0].ImageURL.URL, "signature")
+	})
+
+	t.Run("inaccessible object key", func(t *testing.T) {
+		signer := &stubSigner{}
+		s := &AIService{signer: signer}
+		for _, url := range []string{
+			"assets/images/a.png",
+			"/assets/images/a.png",
+			"oss://",
+			"oss://assets/images/",
+			"oss://assets/ai-images/8/b.png",
+			"oss://assets/ai-images/70/b.png",
+			"oss://assets/images/../ai-images/8/b.png",
+			"oss://secrets/config.json",
+		} {
+			_, err := s.resolveContentURLs(7, parts(url))
+			assert.ErrorIs(t, err, ErrInvalidContent, url)
+		}
+		assert.Empty(t, signer.keys)
+	})
+
+	t.Run("data uri untouched", func(t *testing.T) {
+		signer := &stubSigner{}
+		s := &AIService{signer: signer}
+		_, err := s.resolveContentURLs(7, parts("data:image/png;base64,AAAA"))
+		require.NoError(t, err)
+		assert.Empty(t, signer.keys)
+	})
+
+	t.Run("oss not configured", func(t *testing.T) {
+		s := &AIService{}
+		_, err := s.resolveContentURLs(7, parts("oss://assets/images/a.png"))
+		assert.ErrorIs(t, err, ErrInvalidContent)
+	})
+
+	t.Run("sign failure", func(t *testing.T) {
+		s := &AIService{signer: &stubSigner{err: errors.New("boom")}}
+		_, err := s.resolveContentURLs(7, parts("oss://assets/images/a.png"))
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidContent)
+	})
+}
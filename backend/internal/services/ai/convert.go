@@ -0,0 +1,316 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"encoding/json"
+	"math"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// 未声明参数的函数按空对象处理，部分平台不接受 null
+var emptyFunctionParameters = json.RawMessage(`{"type":"object","properties":{}}`)
+
+// toFloatParam 转换可选的浮点参数，go-openai 的字段带 omitempty 会丢弃 0
+// 显式传 0 时按 go-openai 文档的做法改为 math.SmallestNonzeroFloat32，序列化后平台仍按 0 处理
+func toFloatParam(v *float64) float32 {
+	if v == nil {
+		return 0
+	}
+	if *v == 0 {
+		return math.SmallestNonzeroFloat32
+	}
+	return float32(*v)
+}
+
+// toChatCompletionRequest 将 OpenAI 兼容请求完整转换为客户端请求，model 为平台侧模型名
+func toChatCompletionRequest(req models.OpenAIChatCompletionRequest, model string) openai.ChatCompletionRequest {
+	chatReq := openai.ChatCompletionRequest{
+		Model:               model,
+		Messages:            toChatCompletionMessages(req.Messages),
+		Stream:              req.Stream,
+		MaxTokens:           req.MaxTokens,
+		MaxCompletionTokens: req.MaxCompletionTokens,
+		Temperature:         toFloatParam(req.Temperature),
+		TopP:                toFloatParam(req.TopP),
+		N:                   req.N,
+		Stop:                req.Stop,
+		Seed:                req.Seed,
+		PresencePenalty:     toFloatParam(req.PresencePenalty),
+		FrequencyPenalty:    toFloatParam(req.FrequencyPenalty),
+		LogitBias:           req.LogitBias,
+		LogProbs:            req.Logprobs,
+		TopLogProbs:         req.TopLogprobs,
+		ReasoningEffort:     req.ReasoningEffort,
+		User:                req.User,
+		ToolChoice:          req.ToolChoice,
+	}
+
+	// NOTE: 直接赋值 nil 指针会让 any 字段序列化成 null
+	if req.ParallelToolCalls != nil {
+		chatReq.ParallelToolCalls = *req.ParallelToolCalls
+	}
+
+	if req.Stream && req.StreamOptions != nil {
+		chatReq.StreamOptions = &openai.StreamOptions{IncludeUsage: req.StreamOptions.IncludeUsage}
+	}
+
+	if req.ResponseFormat != nil {
+		format := &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatType(req.ResponseFormat.Type),
+		}
+		if schema := req.ResponseFormat.JSONSchema; schema != nil {
+			format.JSONSchema = &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:        schema.Name,
+				Description: schema.Description,
+				Schema:      schema.Schema,
+				Strict:      schema.Strict,
+			}
+		}
+		chatReq.ResponseFormat = format
+	}
+
+	for _, tool := range req.Tools {
+		t := openai.Tool{Type: openai.ToolType(tool.Type)}
+		if tool.Function != nil {
+			params := tool.Function.Parameters
+			if len(params) == 0 {
+				params = emptyFunctionParameters
+			}
+			t.Function = &openai.FunctionDefinition{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Strict:      tool.Function.Strict,
+				Parameters:  params,
+			}
+		}
+		chatReq.Tools = append(chatReq.Tools, t)
+	}
+
+	return chatReq
+}
+
+func toChatCompletionMessages(messages []models.OpenAIMessage) []openai.ChatCompletionMessage {
+	result := make([]openai.ChatCompletionMessage, len(messages))
+	for i, msg := range messages {
+		result[i] = openai.ChatCompletionMessage{
+			Role:             msg.Role,
+			Name:             msg.Name,
+			ReasoningContent: msg.ReasoningContent,
+			Refusal:          msg.Refusal,
+			ToolCalls:        toClientToolCalls(msg.ToolCalls),
+			ToolCallID:       msg.ToolCallID,
+		}
+		if len(msg.Content.Parts) > 0 {
+			result[i].MultiContent = toClientContentParts(msg.Content.Parts)
+		} else {
+			result[i].Content = msg.Content.Text
+		}
+	}
+	return result
+}
+
+func toClientToolCalls(calls []models.OpenAIToolCall) []openai.ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]openai.ToolCall, len(calls))
+	for i, call := range calls {
+		result[i] = openai.ToolCall{
+			Index: call.Index,
+			ID:    call.ID,
+			Type:  openai.ToolType(call.Type),
+			Function: openai.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		}
+	}
+	return result
+}
+
+func fromClientToolCalls(calls []openai.ToolCall) []models.OpenAIToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	result := make([]models.OpenAIToolCall, len(calls))
+	for i, call := range calls {
+		result[i] = models.OpenAIToolCall{
+			Index: call.Index,
+			ID:    call.ID,
+			Type:  string(call.Type),
+			Function: models.OpenAIFunctionCall{
+				Name:      call.Function.Name,
+				Arguments: call.Function.Arguments,
+			},
+		}
+	}
+	return result
+}
+
+// fromChatCompletionResponse 将客户端响应转换为 OpenAI 兼容响应，保留全部 choices
+func fromChatCompletionResponse(resp openai.ChatCompletionResponse) *models.OpenAIChatCompletionResponse {
+	choices := make([]models.OpenAIChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choices[i] = models.OpenAIChoice{
+			Index: choice.Index,
+			Message: models.OpenAIMessage{
+				Role:             choice.Message.Role,
+				Content:          models.NewTextContent(choice.Message.Content),
+				ReasoningContent: choice.Message.ReasoningContent,
+				Refusal:          choice.Message.Refusal,
+				ToolCalls:        fromClientToolCalls(choice.Message.ToolCalls),
+			},
+			Logprobs:     fromClientLogprobs(choice.LogProbs),
+			FinishReason: string(choice.FinishReason),
+		}
+		if choices[i].Message.Role == "" {
+			choices[i].Message.Role = openai.ChatMessageRoleAssistant
+		}
+	}
+
+	object := resp.Object
+	if object == "" {
+		object = "chat.completion"
+	}
+
+	return &models.OpenAIChatCompletionResponse{
+		ID:                resp.ID,
+		Object:            object,
+		Created:           resp.Created,
+		Model:             resp.Model,
+		SystemFingerprint: resp.SystemFingerprint,
+		Choices:           choices,
+		Usage:             fromClientUsage(resp.Usage),
+	}
+}
+
+// fromChatCompletionStreamResponse 转换流式响应块
+func fromChatCompletionStreamResponse(resp openai.ChatCompletionStreamResponse) models.OpenAIChatCompletionStreamResponse {
+	choices := make([]models.OpenAIStreamChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		choices[i] = models.OpenAIStreamChoice{
+			Index: choice.Index,
+			Delta: models.OpenAIStreamDelta{
+				Role:             choice.Delta.Role,
+				Content:          choice.Delta.Content,
+				ReasoningContent: choice.Delta.ReasoningContent,
+				Refusal:          choice.Delta.Refusal,
+				ToolCalls:        fromClientToolCalls(choice.Delta.ToolCalls),
+			},
+			Logprobs: fromClientStreamLogprobs(choice.Logprobs),
+		}
+		// 中间块的 finish_reason 为 null
+		if choice.FinishReason != "" {
+			reason := string(choice.FinishReason)
+			choices[i].FinishReason = &reason
+		}
+	}
+
+	chunk := models.OpenAIChatCompletionStreamResponse{
+		ID:                resp.ID,
+		Object:            "chat.completion.chunk",
+		Created:           resp.Created,
+		Model:             resp.Model,
+		SystemFingerprint: resp.SystemFingerprint,
+		Choices:           choices,
+	}
+	if resp.Usage != nil {
+		usage := fromClientUsage(*resp.Usage)
+		chunk.Usage = &usage
+	}
+
+	return chunk
+}
+
+func fromClientUsage(usage openai.Usage) models.OpenAIUsage {
+	result := models.OpenAIUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+	if d := usage.PromptTokensDetails; d != nil {
+		result.PromptTokensDetails = &models.OpenAIPromptTokensDetails{
+			CachedTokens: d.CachedTokens,
+			AudioTokens:  d.AudioTokens,
+		}
+	}
+	if d := usage.CompletionTokensDetails; d != nil {
+		result.CompletionTokensDetails = &models.OpenAICompletionTokensDetails{
+			ReasoningTokens:          d.ReasoningTokens,
+			AudioTokens:              d.AudioTokens,
+			AcceptedPredictionTokens: d.AcceptedPredictionTokens,
+			RejectedPredictionTokens: d.RejectedPredictionTokens,
+		}
+	}
+	return result
+}
+
+func fromClientLogprobs(logprobs *openai.LogProbs) *models.OpenAILogprobs {
+	if logprobs == nil {
+		return nil
+	}
+	content := make([]models.OpenAITokenLogprob, len(logprobs.Content))
+	for i, lp := range logprobs.Content {
+		top := make([]models.OpenAITokenLogprob, len(lp.TopLogProbs))
+		for j, t := range lp.TopLogProbs {
+			top[j] = models.OpenAITokenLogprob{Token: t.Token, Logprob: t.LogProb, Bytes: bytesToInts(t.Bytes)}
+		}
+		content[i] = models.OpenAITokenLogprob{
+			Token:       lp.Token,
+			Logprob:     lp.LogProb,
+			Bytes:       bytesToInts(lp.Bytes),
+			TopLogprobs: top,
+		}
+	}
+	return &models.OpenAILogprobs{Content: content}
+}
+
+func fromClientStreamLogprobs(logprobs *openai.ChatCompletionStreamChoiceLogprobs) *models.OpenAILogprobs {
+	if logprobs == nil {
+		return nil
+	}
+	convert := func(items []openai.ChatCompletionTokenLogprob) []models.OpenAITokenLogprob {
+		result := make([]models.OpenAITokenLogprob, len(items))
+		for i, lp := range items {
+			top := make([]models.OpenAITokenLogprob, len(lp.TopLogprobs))
+			for j, t := range lp.TopLogprobs {
+				top[j] = models.OpenAITokenLogprob{Token: t.Token, Logprob: t.Logprob, Bytes: int64sToInts(t.Bytes)}
+			}
+			result[i] = models.OpenAITokenLogprob{
+				Token:       lp.Token,
+				Logprob:     lp.Logprob,
+				Bytes:       int64sToInts(lp.Bytes),
+				TopLogprobs: top,
+			}
+		}
+		return result
+	}
+	return &models.OpenAILogprobs{
+		Content: convert(logprobs.Content),
+		Refusal: convert(logprobs.Refusal),
+	}
+}
+
+// NOTE: OpenAI 的 bytes 是整数数组，[]byte 直接序列化会变成 base64
+func bytesToInts(b []byte) []int {
+	if b == nil {
+		return nil
+	}
+	result := make([]int, len(b))
+	for i, v := range b {
+		result[i] = int(v)
+	}
+	return result
+}
+
+func int64sToInts(b []int64) []int {
+	if b == nil {
+		return nil
+	}
+	result := make([]int, len(b))
+	for i, v := range b {
+		result[i] = int(v)
+	}
+	return result
+}
@@ -0,0 +1,205 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const toolCallResponse = `{
+	"id": "chatcmpl-tool",
+	"object": "chat.completion",
+	"created": 1700000000,
+	"model": "stub-model",
+	"system_fingerprint": "fp_stub",
+	"choices": [{
+		"index": 0,
+		"message": {
+			"role": "assistant",
+			"content": "",
+			"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Hangzhou\"}"}}]
+		},
+		"finish_reason": "tool_calls"
+	}],
+	"usage": {"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15, "completion_tokens_details": {"reasoning_tokens": 2}}
+}`
+
+// captureHandler 记录收到的原始请求体并返回固定响应
+func captureHandler(t *testing.T, captured *map[string]any, handle func(w http.ResponseWriter, stream bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		*captured = body
+
+		stream, _ := body["stream"].(bool)
+		handle(w, stream)
+	}
+}
+
+func newCaptureService(server *stubServer) *AIService {
+	s := newStubService(nil)
+	s.clients[PlatformSilicon] = newPlatformClient("test-key", server.URL+"/v1")
+	return s
+}
+
+func toolRequest(t *testing.T, raw string) models.OpenAIChatCompletionRequest {
+	var req models.OpenAIChatCompletionRequest
+	require.NoError(t, json.Unmarshal([]byte(raw), &req))
+	return req
+}
+
+const toolRequestBody = `{
+	"model": "stub-model",
+	"messages": [
+		{"role": "user", "content": "杭州天气"},
+		{"role": "assistant", "tool_calls": [{"id": "call_0", "type": "function", "function": {"name": "get_weather", "arguments": "{}"}}]},
+		{"role": "tool", "tool_call_id": "call_0", "content": "晴"}
+	],
+	"max_tokens": 128,
+	"temperature": 0.3,
+	"top_p": 0.9,
+	"n": 2,
+	"stop": "END",
+	"seed": 42,
+	"logprobs": true,
+	"top_logprobs": 3,
+	"response_format": {"type": "json_object"},
+	"tools": [{"type": "function", "function": {"name": "get_weather", "parameters": {"type": "object", "properties": {"city": {"type": "string"}}}}}],
+	"tool_choice": {"type": "function", "function": {"name": "get_weather"}},
+	"parallel_tool_calls": false
+}`
+
+func TestChatCompletion_Passthrough(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, toolCallResponse)
+	}))
+	s := newCaptureService(server)
+
+	resp, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, toolRequest(t, toolRequestBody))
+	require.NoError(t, err)
+
+	// 请求参数完整转发
+	assert.EqualValues(t, 128, captured["max_tokens"])
+	assert.InDelta(t, 0.3, captured["temperature"], 1e-6)
+	assert.InDelta(t, 0.9, captured["top_p"], 1e-6)
+	assert.EqualValues(t, 2, captured["n"])
+	assert.Equal(t, []any{"END"}, captured["stop"])
+	assert.EqualValues(t, 42, captured["seed"])
+	assert.Equal(t, true, captured["logprobs"])
+	assert.EqualValues(t, 3, captured["top_logprobs"])
+	assert.Equal(t, false, captured["parallel_tool_calls"])
+	assert.Equal(t, map[string]any{"type": "json_object"}, captured["response_format"])
+	assert.Equal(t, map[string]any{"type": "function", "function": map[string]any{"name": "get_weather"}}, captured["tool_choice"])
+
+	tools := captured["tools"].([]any)
+	require.Len(t, tools, 1)
+	function := tools[0].(map[string]any)["function"].(map[string]any)
+	assert.Equal(t, "get_weather", function["name"])
+	assert.Contains(t, function["parameters"], "properties")
+
+	messages := captured["messages"].([]any)
+	require.Len(t, messages, 3)
+	assert.Len(t, messages[1].(map[string]any)["tool_calls"], 1)
+	assert.Equal(t, "call_0", messages[2].(map[string]any)["tool_call_id"])
+
+	// 响应完整回传
+	assert.Equal(t, "fp_stub", resp.SystemFingerprint)
+	require.Len(t, resp.Choices, 1)
+	assert.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	require.Len(t, resp.Choices[0].Message.ToolCalls, 1)
+	assert.Equal(t, "call_1", resp.Choices[0].Message.ToolCalls[0].ID)
+	assert.Equal(t, `{"city":"Hangzhou"}`, resp.Choices[0].Message.ToolCalls[0].Function.Arguments)
+	require.NotNil(t, resp.Usage.CompletionTokensDetails)
+	assert.Equal(t, 2, resp.Usage.CompletionTokensDetails.ReasoningTokens)
+}
+
+func TestChatCompletion_OmitsUnsetParameters(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, toolCallResponse)
+	}))
+	s := newCaptureService(server)
+
+	_, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, toolRequest(t, `{"model":"stub-model","messages":[{"role":"user","content":"hi"}]}`))
+	require.NoError(t, err)
+
+	for _, key := range []string{"tools", "tool_choice", "parallel_tool_calls", "response_format", "seed", "stop", "temperature"} {
+		assert.NotContains(t, captured, key)
+	}
+}
+
+func TestChatCompletion_ExplicitZeroParameters(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, toolCallResponse)
+	}))
+	s := newCaptureService(server)
+
+	_, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, toolRequest(t,
+		`{"model":"stub-model","messages":[{"role":"user","content":"hi"}],"temperature":0,"top_p":0,"presence_penalty":0,"frequency_penalty":0}`))
+	require.NoError(t, err)
+
+	// 显式传 0 也要转发，否则平台会使用默认值
+	for _, key := range []string{"temperature", "top_p", "presence_penalty", "frequency_penalty"} {
+		require.Contains(t, captured, key)
+		assert.InDelta(t, 0, captured[key], 1e-30, key)
+	}
+}
+
+func TestChatCompletionStream_ToolCalls(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]},"finish_reason":null}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Hangzhou\"}"}}]},"finish_reason":"tool_calls"}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	s := newCaptureService(server)
+
+	req := toolRequest(t, toolRequestBody)
+	req.Stream = true
+	req.StreamOptions = &models.OpenAIStreamOptions{IncludeUsage: true}
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ChatCompletionStream(context.Background(), 0, PlatformSilicon, req, &buf))
+	assert.Equal(t, map[string]any{"include_usage": true}, captured["stream_options"])
+
+	var chunks []models.OpenAIChatCompletionStreamResponse
+	for _, line := range bytes.Split(buf.Bytes(), []byte("\n\n")) {
+		data, ok := bytes.CutPrefix(line, []byte("data: "))
+		if !ok || string(data) == "[DONE]" {
+			continue
+		}
+		var chunk models.OpenAIChatCompletionStreamResponse
+		require.NoError(t, json.Unmarshal(data, &chunk))
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 3)
+
+	first := chunks[0].Choices[0]
+	assert.Nil(t, first.FinishReason)
+	require.Len(t, first.Delta.ToolCalls, 1)
+	require.NotNil(t, first.Delta.ToolCalls[0].Index)
+	assert.Equal(t, "get_weather", first.Delta.ToolCalls[0].Function.Name)
+
+	second := chunks[1].Choices[0]
+	require.NotNil(t, second.FinishReason)
+	assert.Equal(t, "tool_calls", *second.FinishReason)
+	assert.Equal(t, `{"city":"Hangzhou"}`, second.Delta.ToolCalls[0].Function.Arguments)
+
+	require.NotNil(t, chunks[2].Usage)
+	assert.Equal(t, 15, chunks[2].Usage.TotalTokens)
+	assert.Equal(t, string(PlatformSilicon), chunks[2].Platform)
+}
@@ -1,50 +1,422 @@
 package ai
 
 import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrImageStorageUnavailable = errors.New("OSS 未配置，无法转存图片")
+	ErrImageTooLarge           = errors.New("图片超出大小限制")
+)
+
+// imageStorage 生成图片转存的对象存储，由 OSSService 实现
+type imageStorage interface {
+	HashifyName(fileName string) string
+	UploadFile(file multipart.File, objectKey string, contentType string) error
+	GetFileURL(objectKey string) (string, error)
+}
+
+// ImageFile 上传的图片文件，切换平台时需要重新发送，所以保存完整内容
+type ImageFile struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// imageOptions 图片结果的处理方式
+type imageOptions struct {
+	ResponseFormat string
+	Persist        bool
+}
+
 // GenerateImages 图片生成
-func (s *AIService) GenerateImages(platform Platform, prompt string, model string) ([]string, error) {
-	if platform == "" {
-		platform = s.getDefaultPlatform()
+func (s *AIService) GenerateImages(ctx context.Context, userID uint64, platform Platform, req models.OpenAIImageRequest) (*models.OpenAIImageResponse, error) {
+	if req.Model == "" {
+		req.Model = openai.CreateImageModelDallE3
+	}
+	opts := imageOptions{ResponseFormat: req.ResponseFormat, Persist: req.Persist}
+
+	return s.imageCall(ctx, userID, platform, EndpointImages, req.Model, req.Prompt, req.N, opts, func(ctx context.Context, target routeTarget) (openai.ImageResponse, error) {
+		client, err := s.getClient(target.Platform)
+		if err != nil {
+			return openai.ImageResponse{}, err
+		}
+
+		return client.CreateImage(ctx, openai.ImageRequest{
+			Prompt:         req.Prompt,
+			Model:          target.Model,
+			N:              max(req.N, 1),
+			Size:           imageSize(req.Size),
+			Quality:        req.Quality,
+			Style:          req.Style,
+			ResponseFormat: req.ResponseFormat,
+			User:           req.User,
+		})
+	})
+}
+
+// EditImages 图片编辑，mask 可为空
+func (s *AIService) EditImages(ctx context.Context, userID uint64, platform Platform, req models.OpenAIImageEditRequest, image ImageFile, mask *ImageFile) (*models.OpenAIImageResponse, error) {
+	if req.Model == "" {
+		req.Model = openai.CreateImageModelDallE2
+	}
+	opts := imageOptions{ResponseFormat: req.ResponseFormat, Persist: req.Persist}
+
+	files := map[string]*ImageFile{"image": &image}
+	if mask != nil {
+		files["mask"] = mask
+	}
+
+	return s.imageCall(ctx, userID, platform, EndpointImageEdits, req.Model, req.Prompt, req.N, opts, func(ctx context.Context, target routeTarget) (openai.ImageResponse, error) {
+		fields := map[string]string{
+			"prompt":          req.Prompt,
+			"model":           target.Model,
+			"n":               strconv.Itoa(max(req.N, 1)),
+			"size":            imageSize(req.Size),
+			"quality":         req.Quality,
+			"response_format": req.ResponseFormat,
+			"user":            req.User,
+		}
+		return s.postImageForm(ctx, target.Platform, "/images/edits", fields, files)
+	})
+}
+
+// CreateImageVariations 图片变体
+func (s *AIService) CreateImageVariations(ctx context.Context, userID uint64, platform Platform, req models.OpenAIImageVariationRequest, image ImageFile) (*models.OpenAIImageResponse, error) {
+	if req.Model == "" {
+		req.Model = openai.CreateImageModelDallE2
+	}
+	opts := imageOptions{ResponseFormat: req.ResponseFormat, Persist: req.Persist}
+
+	files := map[string]*ImageFile{"image": &image}
+
+	return s.imageCall(ctx, userID, platform, EndpointImageVariations, req.Model, "", req.N, opts, func(ctx context.Context, target routeTarget) (openai.ImageResponse, error) {
+		fields := map[string]string{
+			"model":           target.Model,
+			"n":               strconv.Itoa(max(req.N, 1)),
+			"size":            imageSize(req.Size),
+			"response_format": req.ResponseFormat,
+			"user":            req.User,
+		}
+		return s.postImageForm(ctx, target.Platform, "/images/variations", fields, files)
+	})
+}
+
+// imageCall 图片接口的公共流程：检查配额、解析模型、按路由调用、记录用量并处理返回的图片
+func (s *AIService) imageCall(ctx context.Context, userID uint64, platform Platform, endpoint, model, prompt string, n int, opts imageOptions,
+	call func(ctx context.Context, target routeTarget) (openai.ImageResponse, error)) (*models.OpenAIImageResponse, error) {
+	if err := s.CheckQuota(userID); err != nil {
+		return nil, err
+	}
+
+	model, err := s.resolveModel(model)
+	if err != nil {
+		return nil, err
 	}
 
-	// 检查是否是 mock 平台
-	if platform == PlatformMock {
-		return s.mockGenerateImages(prompt, model)
+	// 转存需要 OSS，在调用平台之前检查，避免生成了图片却无法返回
+	if opts.Persist && s.storage == nil {
+		return nil, ErrImageStorageUnavailable
 	}
 
-	client, err := s.getClient(platform)
+	meter := s.startUsage(userID, endpoint, model, false)
+
+	var resp openai.ImageResponse
+	served, err := s.routeCall(ctx, s.resolveRoute(platform, model), func(target routeTarget) error {
+		// 检查是否是 mock 平台
+		if target.Platform == PlatformMock {
+			result, err := s.mockGenerateImages(ctx, prompt, target.Model, max(n, 1))
+			resp = result
+			return err
+		}
+
+		callCtx, cancel := withPlatformTimeout(ctx, target.Platform, false)
+		defer cancel()
+
+		result, err := call(callCtx, target)
+		resp = result
+		return err
+	})
+	// 只有部分模型（如 gpt-image-1）返回用量，其余只记录调用
+	meter.finish(served, models.Usage{
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}, err)
 	if err != nil {
+		logrus.WithError(err).WithField("endpoint", endpoint).Error("Failed to generate image")
 		return nil, err
 	}
 
-	if model == "" {
-		model = "dall-e-3"
+	data, err := s.processImages(ctx, userID, resp.Data, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	created := resp.Created
+	if created == 0 {
+		created = time.Now().Unix()
+	}
+	return &models.OpenAIImageResponse{
+		Created:  created,
+		Data:     data,
+		Platform: string(served),
+	}, nil
+}
+
+// processImages 按请求的返回格式转换图片，需要时转存到 OSS
+// 平台不支持 b64_json 时下载图片自行编码；转存失败只记录日志，仍返回平台地址
+func (s *AIService) processImages(ctx context.Context, userID uint64, items []openai.ImageResponseDataInner, opts imageOptions) ([]models.OpenAIImageData, error) {
+	wantB64 := opts.ResponseFormat == models.ImageResponseFormatB64JSON
+
+	result := make([]models.OpenAIImageData, len(items))
+	for i, item := range items {
+		data := models.OpenAIImageData{
+			URL:           item.URL,
+			B64JSON:       item.B64JSON,
+			RevisedPrompt: item.RevisedPrompt,
+		}
+
+		if !opts.Persist && (!wantB64 || data.B64JSON != "") {
+			result[i] = data
+			continue
+		}
+
+		content, err := s.imageContent(ctx, item)
+		if err != nil {
+			if wantB64 && data.B64JSON == "" {
+				return nil, fmt.Errorf("fetch generated image: %w", err)
+			}
+			logrus.WithError(err).WithField("user_id", userID).Warn("Failed to fetch generated image for persistence")
+			result[i] = data
+			continue
+		}
+
+		if wantB64 && data.B64JSON == "" {
+			data.B64JSON = base64.StdEncoding.EncodeToString(content)
+		}
+		if wantB64 {
+			data.URL = ""
+		}
+
+		if opts.Persist {
+			objectKey, url, err := s.persistImage(userID, content)
+			if err != nil {
+				logrus.WithError(err).WithField("user_id", userID).Warn("Failed to persist generated image")
+			} else {
+				data.ObjectKey = objectKey
+				if !wantB64 {
+					data.URL = url
+				}
+			}
+		}
+
+		result[i] = data
 	}
+	return result, nil
+}
 
-	imageReq := openai.ImageRequest{
-		Prompt: prompt,
-		Model:  model,
-		N:      1,
-		Size:   "1024x1024",
+// imageContent 获取图片内容，b64_json 直接解码，否则下载
+func (s *AIService) imageContent(ctx context.Context, item openai.ImageResponseDataInner) ([]byte, error) {
+	if item.B64JSON != "" {
+		return base64.StdEncoding.DecodeString(item.B64JSON)
 	}
+	if item.URL == "" {
+		return nil, errors.New("image has neither url nor b64_json")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.AIImageDownloadTimeout)
+	defer cancel()
 
-	ctx := context.Background()
-	resp, err := client.CreateImage(ctx, imageReq)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, item.URL, nil)
 	if err != nil {
-		logrus.WithError(err).Error("Failed to generate image")
 		return nil, err
 	}
 
-	urls := make([]string, len(resp.Data))
-	for i, img := range resp.Data {
-		urls[i] = img.URL
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	return urls, nil
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download image failed, status code: %d", resp.StatusCode)
+	}
+	return readLimited(resp.Body, config.AIImageMaxBytes)
+}
+
+// persistImage 将图片上传到用户自己的目录下，返回 objectKey 和访问地址
+func (s *AIService) persistImage(userID uint64, content []byte) (string, string, error) {
+	contentType := http.DetectContentType(content)
+	objectKey := fmt.Sprintf("%s%d/%s", config.AIImageOSSPrefix, userID, s.storage.HashifyName("image"+imageExt(contentType)))
+
+	if err := s.storage.UploadFile(memoryFile{bytes.NewReader(content)}, objectKey, contentType); err != nil {
+		return "", "", err
+	}
+
+	url, err := s.storage.GetFileURL(objectKey)
+	if err != nil {
+		return "", "", err
+	}
+	return objectKey, url, nil
+}
+
+// postImageForm 以 multipart 表单调用平台的图片接口
+// NOTE: 客户端库的编辑、变体接口不发送 model、quality 等字段，这里直接构造请求
+func (s *AIService) postImageForm(ctx context.Context, platform Platform, path string, fields map[string]string, files map[string]*ImageFile) (openai.ImageResponse, error) {
+	var response openai.ImageResponse
+
+	endpoint, ok := s.endpoints[platform]
+	if !ok {
+		return response, fmt.Errorf("platform %s not available", platform)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, file := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, quoteEscaper.Replace(file.Name)))
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = http.DetectContentType(file.Data)
+		}
+		header.Set("Content-Type", contentType)
+
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return response, err
+		}
+		if _, err := part.Write(file.Data); err != nil {
+			return response, err
+		}
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := writer.WriteField(name, value); err != nil {
+			return response, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return response, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint.BaseURL, "/")+path, &body)
+	if err != nil {
+		return response, err
+	}
+	req.Header.Set("Authorization", "Bearer "+endpoint.APIKey)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return response, err
+	}
+	defer resp.Body.Close()
+
+	// 错误与客户端库保持一致，路由才能按状态码判断是否切换平台
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &openai.APIError{
+			HTTPStatus:     resp.Status,
+			HTTPStatusCode: resp.StatusCode,
+		}
+		var errResp openai.ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error != nil {
+			apiErr.Message = errResp.Error.Message
+			apiErr.Type = errResp.Error.Type
+			apiErr.Code = errResp.Error.Code
+			apiErr.Param = errResp.Error.Param
+		}
+		if apiErr.Message == "" {
+			apiErr.Message = resp.Status
+		}
+		return response, apiErr
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	return response, err
+}
+
+// ReadImageFile 读取上传的图片，超出大小限制时返回 ErrImageTooLarge
+func ReadImageFile(fileHeader *multipart.FileHeader) (ImageFile, error) {
+	if fileHeader.Size > config.AIImageMaxBytes {
+		return ImageFile{}, ErrImageTooLarge
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ImageFile{}, err
+	}
+	defer file.Close()
+
+	data, err := readLimited(file, config.AIImageMaxBytes)
+	if err != nil {
+		return ImageFile{}, err
+	}
+
+	return ImageFile{
+		Name:        fileHeader.Filename,
+		ContentType: fileHeader.Header.Get("Content-Type"),
+		Data:        data,
+	}, nil
+}
+
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrImageTooLarge
+	}
+	return data, nil
+}
+
+var quoteEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// memoryFile 让内存中的内容满足 multipart.File，用于 OSSService.UploadFile
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error {
+	return nil
+}
+
+func imageSize(size string) string {
+	if size == "" {
+		return openai.CreateImageSize1024x1024
+	}
+	return size
+}
+
+func imageExt(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
 }
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader 足以被识别为 PNG 的图片内容
+var pngHeader = []byte("\x89PNG\r\n\x1a\n0000")
+
+// memoryStorage 记录上传内容的 imageStorage
+type memoryStorage struct {
+	objects map[string][]byte
+	err     error
+}
+
+func (m *memoryStorage) HashifyName(fileName string) string {
+	return "hashed-" + fileName
+}
+
+func (m *memoryStorage) UploadFile(file multipart.File, objectKey string, contentType string) error {
+	if m.err != nil {
+		return m.err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	m.objects[objectKey] = data
+	return nil
+}
+
+func (m *memoryStorage) GetFileURL(objectKey string) (string, error) {
+	return "https://oss.example.com/" + objectKey, nil
+}
+
+// serveImage 返回固定图片内容，模拟图片下载服务
+func serveImage(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "image/png")
+	w.Write(pngHeader)
+}
+
+// newImageService Mock 平台对 draw 返回 imageURL
+func newImageService(t *testing.T, imageURL string) *AIService {
+	s := newStubService(nil)
+	zero := 0
+	require.NoError(t, s.UseMockFixtures(&MockFixtures{
+		DelayMs: &zero,
+		Scripts: []MockScript{{Match: "^draw", Replies: []MockReply{{Images: []string{imageURL}}}}},
+	}))
+	return s
+}
+
+func TestGenerateImages_B64JSON(t *testing.T) {
+	server := newTestServer(t, serveImage)
+	s := newImageService(t, server.URL+"/cat.png")
+
+	resp, err := s.GenerateImages(context.Background(), 1, "", models.OpenAIImageRequest{
+		Prompt:         "draw a cat",
+		ResponseFormat: models.ImageResponseFormatB64JSON,
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+	assert.Empty(t, resp.Data[0].URL)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(pngHeader), resp.Data[0].B64JSON)
+	assert.Equal(t, string(PlatformMock), resp.Platform)
+
+	// 无法下载时 b64_json 无法满足，返回错误
+	s = newImageService(t, server.URL+"/missing")
+	server.Close()
+	_, err = s.GenerateImages(context.Background(), 1, "", models.OpenAIImageRequest{
+		Prompt:         "draw a cat",
+		ResponseFormat: models.ImageResponseFormatB64JSON,
+	})
+	assert.Error(t, err)
+}
+
+func TestGenerateImages_Persist(t *testing.T) {
+	server := newTestServer(t, serveImage)
+	s := newImageService(t, server.URL+"/cat.png")
+
+	storage := &memoryStorage{objects: make(map[string][]byte)}
+	s.storage = storage
+
+	resp, err := s.GenerateImages(context.Background(), 7, "", models.OpenAIImageRequest{Prompt: "draw a cat", Persist: true})
+	require.NoError(t, err)
+	require.Len(t, resp.Data, 1)
+
+	objectKey := "assets/ai-images/7/hashed-image.png"
+	assert.Equal(t, objectKey, resp.Data[0].ObjectKey)
+	assert.Equal(t, "https://oss.example.com/"+objectKey, resp.Data[0].URL)
+	assert.Equal(t, pngHeader, storage.objects[objectKey])
+
+	// 转存失败时仍返回平台地址
+	storage.err = errors.New("oss down")
+	resp, err = s.GenerateImages(context.Background(), 7, "", models.OpenAIImageRequest{Prompt: "draw a cat", Persist: true})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL+"/cat.png", resp.Data[0].URL)
+	assert.Empty(t, resp.Data[0].ObjectKey)
+}
+
+func TestGenerateImages_PersistWithoutStorage(t *testing.T) {
+	s := newImageService(t, "https://example.com/cat.png")
+
+	_, err := s.GenerateImages(context.Background(), 1, "", models.OpenAIImageRequest{Prompt: "draw a cat", Persist: true})
+	assert.ErrorIs(t, err, ErrImageStorageUnavailable)
+}
+
+func TestEditImages_Multipart(t *testing.T) {
+	var fields map[string]string
+	var files map[string][]byte
+	stub := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/images/edits", r.URL.Path)
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+
+		fields = make(map[string]string)
+		for name, values := range r.MultipartForm.Value {
+			fields[name] = values[0]
+		}
+		files = make(map[string][]byte)
+		for name, headers := range r.MultipartForm.File {
+			file, err := headers[0].Open()
+			require.NoError(t, err)
+			files[name], _ = io.ReadAll(file)
+			file.Close()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ImageResponse{
+			Created: 1,
+			Data:    []openai.ImageResponseDataInner{{URL: "https://example.com/edited.png"}},
+		})
+	})
+	s := newStubService(map[Platform]*stubServer{PlatformSilicon: stub})
+
+	image := ImageFile{Name: "cat.png", ContentType: "image/png", Data: pngHeader}
+	mask := ImageFile{Name: "mask.png", ContentType: "image/png", Data: []byte("mask")}
+	resp, err := s.EditImages(context.Background(), 1, PlatformSilicon, models.OpenAIImageEditRequest{
+		Prompt:  "add a hat",
+		N:       2,
+		Quality: "high",
+	}, image, &mask)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/edited.png", resp.Data[0].URL)
+	assert.Equal(t, string(PlatformSilicon), resp.Platform)
+
+	assert.Equal(t, "add a hat", fields["prompt"])
+	assert.Equal(t, openai.CreateImageModelDallE2, fields["model"])
+	assert.Equal(t, "2", fields["n"])
+	assert.Equal(t, "high", fields["quality"])
+	assert.Equal(t, openai.CreateImageSize1024x1024, fields["size"])
+	assert.Equal(t, pngHeader, files["image"])
+	assert.Equal(t, []byte("mask"), files["mask"])
+}
+
+func TestCreateImageVariations_APIError(t *testing.T) {
+	stub := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/images/variations", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"invalid image","type":"invalid_request_error","param":"image"}}`))
+	})
+	s := newStubService(map[Platform]*stubServer{PlatformSilicon: stub})
+
+	_, err := s.CreateImageVariations(context.Background(), 1, PlatformSilicon, models.OpenAIImageVariationRequest{},
+		ImageFile{Name: "cat.png", Data: pngHeader})
+
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.HTTPStatusCode)
+	assert.Equal(t, "invalid image", apiErr.Message)
+	assert.Equal(t, "invalid_request_error", apiErr.Type)
+}
+
+func TestReadLimited(t *testing.T) {
+	data, err := readLimited(bytes.NewReader([]byte("1234")), 4)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1234"), data)
+
+	_, err = readLimited(bytes.NewReader([]byte("12345")), 4)
+	assert.ErrorIs(t, err, ErrImageTooLarge)
+}
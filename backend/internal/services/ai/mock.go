@@ -2,15 +2,14 @@ package ai
 
 import (
 	"ai-models-backend/internal/models"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math/rand"
-	"net/http"
 	"strings"
 	"time"
 
-	"github.com/google/uuid"
+	"github.com/sashabaranov/go-openai"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,66 +18,60 @@ const (
 	MockImageURL = "https://picsum.photos/1024/1024?random="
 )
 
-// Mock 聊天（非流式）
-func (s *AIService) mockChat(userID uint64, req models.ChatRequest) (*models.ChatResponse, error) {
-	// 模拟延迟
-	delay := time.Duration(rand.Intn(500)+200) * time.Millisecond
-	time.Sleep(delay)
-
-	// 生成模拟回复
-	content := s.generateMockResponse(req.Message)
+// Mock 聊天（非流式），messages 为携带会话历史的完整消息
+func (s *AIService) mockChat(ctx context.Context, req models.ChatRequest, messages []openai.ChatCompletionMessage) (*models.ChatResponse, error) {
+	reply := s.mock.reply(req.Message)
+	if err := sleepContext(ctx, s.mock.delayFor(reply)); err != nil {
+		return nil, err
+	}
+	if reply.Error != nil {
+		return nil, reply.Error.apiError()
+	}
 
 	return &models.ChatResponse{
-		ID:        fmt.Sprintf("chatcmpl-%s", uuid.New().String()),
-		Message:   content,
+		ID:        fmt.Sprintf("chatcmpl-mock-%d", s.mock.nextID()),
+		Message:   reply.Content,
 		Model:     s.getModelName(req.Model),
 		CreatedAt: time.Now(),
-		Usage: models.Usage{
-			PromptTokens:     int(rand.Intn(50) + 20),
-			CompletionTokens: len(content),
-			TotalTokens:      int(rand.Intn(50)+20) + len(content),
-		},
+		Usage:     estimateUsage(messages, reply.completionText()),
 	}, nil
 }
 
-// Mock 流式聊天
-func (s *AIService) mockStreamChat(userID uint64, req models.ChatRequest, responseChan chan<- string) error {
-	// 生成完整回复
-	fullResponse := s.generateMockResponse(req.Message)
-	chunks := strings.Split(fullResponse, "")
+// Mock 流式聊天，按 token 切分回复逐块发送
+func (s *AIService) mockStreamChat(ctx context.Context, req models.ChatRequest, emit func(string)) error {
+	reply := s.mock.reply(req.Message)
+	if reply.Error != nil {
+		return reply.Error.apiError()
+	}
 
-	// 模拟流式发送
-	for _, chunk := range chunks {
-		if chunk == "" {
-			continue
+	for i, chunk := range splitTokens(reply.Content) {
+		if reply.CutAfter > 0 && i >= reply.CutAfter {
+			return mockStreamCut(i)
 		}
-
-		// 随机延迟
-		delay := time.Duration(rand.Intn(50)+10) * time.Millisecond
-		time.Sleep(delay)
-
-		responseChan <- chunk
+		if err := sleepContext(ctx, s.mock.chunkDelay); err != nil {
+			return err
+		}
+		emit(chunk)
 	}
 
 	return nil
 }
 
 // Mock OpenAI 聊天完成（非流式）
-func (s *AIService) mockChatCompletion(req models.OpenAIChatCompletionRequest) (*models.OpenAIChatCompletionResponse, error) {
-	// 模拟延迟
-	delay := time.Duration(rand.Intn(500)+200) * time.Millisecond
-	time.Sleep(delay)
-
-	// 获取用户消息
-	var userMessage string
-	if len(req.Messages) > 0 {
-		userMessage = req.Messages[len(req.Messages)-1].Content
+func (s *AIService) mockChatCompletion(ctx context.Context, req models.OpenAIChatCompletionRequest) (*models.OpenAIChatCompletionResponse, error) {
+	reply := s.mock.reply(lastMessageText(req.Messages))
+	if err := sleepContext(ctx, s.mock.delayFor(reply)); err != nil {
+		return nil, err
+	}
+	if reply.Error != nil {
+		return nil, reply.Error.apiError()
 	}
 
-	content := s.generateMockResponse(userMessage)
+	id := s.mock.nextID()
+	usage := estimateUsage(toChatCompletionMessages(req.Messages), reply.completionText())
 
 	return &models.OpenAIChatCompletionResponse{
-		ID:      fmt.Sprintf("chatcmpl-%s", uuid.New().String()),
+		ID:      fmt.Sprintf("chatcmpl-mock-%d", id),
 		Object:  "chat.completion",
 		Created: time.Now().Unix(),
 		Model:   req.Model,
@@ -86,118 +79,179 @@ func (s *AIService) mockChatCompletion(req models.OpenAIChatCompletionRequest) (
 			{
 				Index: 0,
 				Message: models.OpenAIMessage{
-					Role:    "assistant",
-					Content: content,
+					Role:      openai.ChatMessageRoleAssistant,
+					Content:   models.NewTextContent(reply.Content),
+					ToolCalls: mockToolCalls(id, reply.ToolCalls, false),
 				},
-				FinishReason: "stop",
+				FinishReason: reply.finishReason(),
 			},
 		},
 		Usage: models.OpenAIUsage{
-			PromptTokens:     int(rand.Intn(50) + 20),
-			CompletionTokens: len(content),
-			TotalTokens:      int(rand.Intn(50)+20) + len(content),
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
 		},
 	}, nil
 }
 
-// Mock OpenAI 流式聊天
-func (s *AIService) mockChatCompletionStream(req models.OpenAIChatCompletionRequest, writer io.Writer) error {
-	// 获取用户消息
-	var userMessage string
-	if len(req.Messages) > 0 {
-		userMessage = req.Messages[len(req.Messages)-1].Content
+// Mock OpenAI 流式聊天，返回已发送的内容用于估算用量
+func (s *AIService) mockChatCompletionStream(ctx context.Context, req models.OpenAIChatCompletionRequest, writer io.Writer) (string, error) {
+	reply := s.mock.reply(lastMessageText(req.Messages))
+	if reply.Error != nil {
+		return "", reply.Error.apiError()
 	}
 
-	// 生成完整回复
-	fullResponse := s.generateMockResponse(userMessage)
-	chunks := strings.Split(fullResponse, "")
-
-	requestId := uuid.New().String()
+	id := s.mock.nextID()
 	created := time.Now().Unix()
-
-	// 发送流式数据
-	for _, chunk := range chunks {
-		if chunk == "" {
-			continue
+	chunk := func(delta models.OpenAIStreamDelta, finishReason *string) models.OpenAIChatCompletionStreamResponse {
+		return models.OpenAIChatCompletionStreamResponse{
+			ID:       fmt.Sprintf("chatcmpl-mock-%d", id),
+			Object:   "chat.completion.chunk",
+			Created:  created,
+			Model:    req.Model,
+			Platform: string(PlatformMock),
+			Choices:  []models.OpenAIStreamChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
 		}
+	}
 
-		// 构建流式响应
-		streamResponse := map[string]any{
-			"id":      fmt.Sprintf("chatcmpl-%s", requestId),
-			"object":  "chat.completion.chunk",
-			"created": created,
-			"model":   req.Model,
-			"choices": []map[string]any{
-				{
-					"index": 0,
-					"delta": map[string]string{
-						"content": chunk,
-					},
-					"finish_reason": nil,
-				},
-			},
+	// 先发送角色，再逐块发送内容和工具调用
+	chunks := []models.OpenAIChatCompletionStreamResponse{
+		chunk(models.OpenAIStreamDelta{Role: openai.ChatMessageRoleAssistant}, nil),
+	}
+	for _, token := range splitTokens(reply.Content) {
+		chunks = append(chunks, chunk(models.OpenAIStreamDelta{Content: token}, nil))
+	}
+	for _, call := range mockToolCalls(id, reply.ToolCalls, true) {
+		chunks = append(chunks, chunk(models.OpenAIStreamDelta{ToolCalls: []models.OpenAIToolCall{call}}, nil))
+	}
+	finishReason := reply.finishReason()
+	chunks = append(chunks, chunk(models.OpenAIStreamDelta{}, &finishReason))
+
+	var sent strings.Builder
+	for i, c := range chunks {
+		if reply.CutAfter > 0 && i >= reply.CutAfter {
+			return sent.String(), mockStreamCut(i)
+		}
+		if err := sleepContext(ctx, s.mock.chunkDelay); err != nil {
+			return sent.String(), err
 		}
 
-		data, err := json.Marshal(streamResponse)
+		data, err := json.Marshal(c)
 		if err != nil {
-			continue
+			return sent.String(), err
+		}
+		if err := writeSSEData(writer, data); err != nil {
+			return sent.String(), err
 		}
 
-		writer.Write([]byte("data: "))
-		writer.Write(data)
-		writer.Write([]byte("\n\n"))
+		delta := c.Choices[0].Delta
+		sent.WriteString(delta.Content)
+		for _, call := range delta.ToolCalls {
+			sent.WriteString(call.Function.Name + call.Function.Arguments)
+		}
+	}
 
-		// 刷新缓冲区
-		if flusher, ok := writer.(http.Flusher); ok {
-			flusher.Flush()
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usage := estimateUsage(toChatCompletionMessages(req.Messages), sent.String())
+		usageChunk := chunk(models.OpenAIStreamDelta{}, nil)
+		usageChunk.Choices = []models.OpenAIStreamChoice{}
+		usageChunk.Usage = &models.OpenAIUsage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
 		}
+		data, _ := json.Marshal(usageChunk)
+		if err := writeSSEData(writer, data); err != nil {
+			return sent.String(), err
+		}
+	}
 
-		// 随机延迟
-		delay := time.Duration(rand.Intn(50)+10) * time.Millisecond
-		time.Sleep(delay)
+	if _, err := writer.Write([]byte("data: [DONE]\n\n")); err != nil {
+		return sent.String(), err
 	}
+	return sent.String(), nil
+}
 
-	// 发送结束标记
-	endChunk := map[string]any{
-		"id":      fmt.Sprintf("chatcmpl-%s", requestId),
-		"object":  "chat.completion.chunk",
-		"created": created,
-		"model":   req.Model,
-		"choices": []map[string]any{
-			{
-				"index":         0,
-				"delta":         map[string]any{},
-				"finish_reason": "stop",
-			},
-		},
+// Mock 图片生成（含编辑和变体），脚本未指定地址时按提示词生成 n 个固定地址
+func (s *AIService) mockGenerateImages(ctx context.Context, prompt string, model string, n int) (openai.ImageResponse, error) {
+	reply := s.mock.reply(prompt)
+	if err := sleepContext(ctx, s.mock.delayFor(reply)); err != nil {
+		return openai.ImageResponse{}, err
+	}
+	if reply.Error != nil {
+		return openai.ImageResponse{}, reply.Error.apiError()
 	}
 
-	data, _ := json.Marshal(endChunk)
-	writer.Write([]byte("data: "))
-	writer.Write(data)
-	writer.Write([]byte("\n\n"))
-	writer.Write([]byte("data: [DONE]\n\n"))
+	logrus.Infof("[Mock] Image generation request: %s (model: %s)", prompt, model)
 
-	return nil
+	urls := reply.Images
+	if len(urls) == 0 {
+		for i := range n {
+			urls = append(urls, fmt.Sprintf("%s%d", MockImageURL, s.mock.pick(fmt.Sprintf("%s#%d", prompt, i), 1000)))
+		}
+	}
+
+	resp := openai.ImageResponse{Created: time.Now().Unix()}
+	for _, url := range urls {
+		resp.Data = append(resp.Data, openai.ImageResponseDataInner{URL: url})
+	}
+	return resp, nil
 }
 
-// Mock 图片生成
-func (s *AIService) mockGenerateImages(prompt string, model string) ([]string, error) {
-	// 模拟延迟
-	delay := time.Duration(rand.Intn(2000)+1000) * time.Millisecond
-	time.Sleep(delay)
+// mockToolCalls 补全脚本中未填写的工具调用ID和类型，流式响应需要带上序号
+func mockToolCalls(id int, calls []models.OpenAIToolCall, stream bool) []models.OpenAIToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
 
-	logrus.Infof("[Mock] Image generation request: %s (model: %s)", prompt, model)
+	result := make([]models.OpenAIToolCall, len(calls))
+	for i, call := range calls {
+		if call.ID == "" {
+			call.ID = fmt.Sprintf("call_mock_%d_%d", id, i)
+		}
+		if call.Type == "" {
+			call.Type = string(openai.ToolTypeFunction)
+		}
+		if stream {
+			index := i
+			call.Index = &index
+		}
+		result[i] = call
+	}
+	return result
+}
+
+// mockStreamCut 模拟上游在输出中途断开
+func mockStreamCut(sent int) error {
+	return fmt.Errorf("mock: stream cut after %d chunks: %w", sent, io.ErrUnexpectedEOF)
+}
 
-	// 生成随机图片 URL
-	randomID := rand.Intn(1000)
-	imageURL := fmt.Sprintf("%s%d", MockImageURL, randomID)
+func lastMessageText(messages []models.OpenAIMessage) string {
+	if len(messages) == 0 {
+		return ""
+	}
+	return messages[len(messages)-1].Content.String()
+}
+
+// sleepContext 模拟延迟，ctx 结束时提前返回
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
 
-	return []string{imageURL}, nil
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
-// 生成模拟回复内容
-func (s *AIService) generateMockResponse(prompt string) string {
+// defaultContent 未命中脚本时的回复，由种子和输入决定
+func (m *mockPlatform) defaultContent(prompt string) string {
 	responses := []string{
 		"你好！我是AI助手，很高兴为你服务。有什么我可以帮助你的吗？",
 		"感谢你的提问！这是一个很有趣的话题。让我来为你详细解答...",
@@ -207,17 +261,16 @@ func (s *AIService) generateMockResponse(prompt string) string {
 	}
 
 	// 如果包含特定关键词，返回相应回复
-	prompt = strings.ToLower(prompt)
-	if strings.Contains(prompt, "你好") || strings.Contains(prompt, "hello") {
+	lower := strings.ToLower(prompt)
+	if strings.Contains(lower, "你好") || strings.Contains(lower, "hello") {
 		return "你好！我是AI助手，很高兴见到你！有什么我可以帮助你的吗？"
 	}
-	if strings.Contains(prompt, "测试") || strings.Contains(prompt, "test") {
+	if strings.Contains(lower, "测试") || strings.Contains(lower, "test") {
 		return "这是一个测试回复。Mock平台正在正常工作！"
 	}
-	if strings.Contains(prompt, "谢谢") || strings.Contains(prompt, "thank") {
+	if strings.Contains(lower, "谢谢") || strings.Contains(lower, "thank") {
 		return "不客气！如果还有其他问题，随时可以问我。"
 	}
 
-	// 随机选择回复
-	return responses[rand.Intn(len(responses))]
+	return responses[m.pick(prompt, len(responses))]
 }
@@ -0,0 +1,212 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// MockFixtures Mock 平台的脚本，前后端测试据此得到可复现的回复
+type MockFixtures struct {
+	Seed         int64        `json:"seed"`           // 未命中脚本时选择默认回复的随机种子
+	DelayMs      *int         `json:"delay_ms"`       // 非流式响应延迟，为空时使用默认值
+	ChunkDelayMs *int         `json:"chunk_delay_ms"` // 流式数据块间隔，为空时使用默认值
+	Scripts      []MockScript `json:"scripts"`
+}
+
+// MockScript 最后一条消息（图片生成为提示词）匹配 Match 时，依次返回 Replies，用完后重复最后一条
+type MockScript struct {
+	Match   string      `json:"match"`
+	Replies []MockReply `json:"replies"`
+}
+
+// MockReply 一次调用的脚本回复
+type MockReply struct {
+	Content      string                  `json:"content"`
+	ToolCalls    []models.OpenAIToolCall `json:"tool_calls"`
+	FinishReason string                  `json:"finish_reason"` // 为空时按是否有工具调用推断
+	Images       []string                `json:"images"`        // 图片生成返回的地址
+	Error        *MockError              `json:"error"`         // 模拟平台错误
+	CutAfter     int                     `json:"cut_after"`     // 流式输出 N 个数据块后断开，0 表示不断开
+	DelayMs      *int                    `json:"delay_ms"`
+}
+
+// MockError 模拟的平台错误，与真实平台一样以 *openai.APIError 返回
+type MockError struct {
+	Status  int    `json:"status"`
+	Type    string `json:"type"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// LoadMockFixtures 读取 JSON 格式的 Mock 脚本文件
+func LoadMockFixtures(path string) (*MockFixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures MockFixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("invalid mock fixtures %s: %w", path, err)
+	}
+	return &fixtures, nil
+}
+
+// UseMockFixtures 替换 Mock 平台的脚本，脚本的调用进度同时重置
+func (s *AIService) UseMockFixtures(fixtures *MockFixtures) error {
+	mock, err := newMockPlatform(fixtures)
+	if err != nil {
+		return err
+	}
+	s.mock = mock
+	return nil
+}
+
+// mockPlatform 按脚本回复的 Mock 平台
+type mockPlatform struct {
+	seed       int64
+	delay      time.Duration
+	chunkDelay time.Duration
+
+	mu      sync.Mutex
+	scripts []mockScript
+	calls   int
+}
+
+type mockScript struct {
+	pattern *regexp.Regexp
+	replies []MockReply
+	next    int
+}
+
+func newMockPlatform(fixtures *MockFixtures) (*mockPlatform, error) {
+	m := &mockPlatform{
+		seed:       config.AIMockDefaultSeed,
+		delay:      config.AIMockDelay,
+		chunkDelay: config.AIMockChunkDelay,
+	}
+	if fixtures == nil {
+		return m, nil
+	}
+
+	if fixtures.Seed != 0 {
+		m.seed = fixtures.Seed
+	}
+	if fixtures.DelayMs != nil {
+		m.delay = time.Duration(*fixtures.DelayMs) * time.Millisecond
+	}
+	if fixtures.ChunkDelayMs != nil {
+		m.chunkDelay = time.Duration(*fixtures.ChunkDelayMs) * time.Millisecond
+	}
+
+	for i, script := range fixtures.Scripts {
+		pattern, err := regexp.Compile(script.Match)
+		if err != nil {
+			return nil, fmt.Errorf("mock script %d: invalid match: %w", i, err)
+		}
+		if len(script.Replies) == 0 {
+			return nil, fmt.Errorf("mock script %d: no replies", i)
+		}
+		m.scripts = append(m.scripts, mockScript{pattern: pattern, replies: script.Replies})
+	}
+	return m, nil
+}
+
+// reply 按脚本顺序取第一个匹配的回复，未命中时返回默认回复
+func (m *mockPlatform) reply(prompt string) MockReply {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.scripts {
+		script := &m.scripts[i]
+		if !script.pattern.MatchString(prompt) {
+			continue
+		}
+		reply := script.replies[min(script.next, len(script.replies)-1)]
+		script.next++
+		return reply
+	}
+
+	return MockReply{Content: m.defaultContent(prompt)}
+}
+
+// nextID 调用序号，用于生成可复现的响应ID
+func (m *mockPlatform) nextID() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	return m.calls
+}
+
+func (m *mockPlatform) delayFor(reply MockReply) time.Duration {
+	if reply.DelayMs != nil {
+		return time.Duration(*reply.DelayMs) * time.Millisecond
+	}
+	return m.delay
+}
+
+// pick 由种子和输入确定地选出 [0, n) 中的一个数，与调用顺序无关
+func (m *mockPlatform) pick(input string, n int) int {
+	h := fnv.New64a()
+	h.Write([]byte(input))
+	return int((h.Sum64() ^ uint64(m.seed)) % uint64(n))
+}
+
+// apiError 转换为与真实平台一致的错误，状态码默认 500
+func (e *MockError) apiError() *openai.APIError {
+	status := e.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+
+	apiErr := &openai.APIError{
+		Message:        e.Message,
+		Type:           e.Type,
+		HTTPStatus:     fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		HTTPStatusCode: status,
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = "mock: " + http.StatusText(status)
+	}
+	if apiErr.Type == "" {
+		apiErr.Type = "server_error"
+		if status < http.StatusInternalServerError {
+			apiErr.Type = "invalid_request_error"
+		}
+	}
+	if e.Code != "" {
+		apiErr.Code = e.Code
+	}
+	return apiErr
+}
+
+// finishReason 未指定时有工具调用为 tool_calls，否则为 stop
+func (r MockReply) finishReason() string {
+	if r.FinishReason != "" {
+		return r.FinishReason
+	}
+	if len(r.ToolCalls) > 0 {
+		return string(openai.FinishReasonToolCalls)
+	}
+	return string(openai.FinishReasonStop)
+}
+
+// completionText 回复中计入输出用量的文本
+func (r MockReply) completionText() string {
+	text := r.Content
+	for _, call := range r.ToolCalls {
+		text += call.Function.Name + call.Function.Arguments
+	}
+	return text
+}
@@ -0,0 +1,219 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockService(t *testing.T) *AIService {
+	fixtures, err := LoadMockFixtures("testdata/mock_fixtures.json")
+	require.NoError(t, err)
+
+	s := newStubService(nil)
+	require.NoError(t, s.UseMockFixtures(fixtures))
+	return s
+}
+
+func mockRequest(content string, stream bool) models.OpenAIChatCompletionRequest {
+	return models.OpenAIChatCompletionRequest{
+		Model:    "mock-model",
+		Messages: []models.OpenAIMessage{{Role: "user", Content: models.NewTextContent(content)}},
+		Stream:   stream,
+	}
+}
+
+// parseStream 解析 SSE 输出，返回数据块及是否收到 [DONE]
+func parseStream(t *testing.T, body []byte) ([]models.OpenAIChatCompletionStreamResponse, bool) {
+	var chunks []models.OpenAIChatCompletionStreamResponse
+	var done bool
+	for _, line := range bytes.Split(body, []byte("\n\n")) {
+		data, ok := bytes.CutPrefix(line, []byte("data: "))
+		if !ok {
+			continue
+		}
+		if string(data) == "[DONE]" {
+			done = true
+			continue
+		}
+		var chunk models.OpenAIChatCompletionStreamResponse
+		require.NoError(t, json.Unmarshal(data, &chunk))
+		chunks = append(chunks, chunk)
+	}
+	return chunks, done
+}
+
+func TestSplitTokens(t *testing.T) {
+	assert.Nil(t, splitTokens(""))
+	assert.Equal(t, []string{"你", "好"}, splitTokens("你好"))
+	assert.Equal(t, []string{"hell", "o wo", "rld"}, splitTokens("hello world"))
+	assert.Equal(t, []string{"你", "好", " hi!"}, splitTokens("你好 hi!"))
+	assert.Equal(t, "从前有座山，abc", strings.Join(splitTokens("从前有座山，abc"), ""))
+}
+
+func TestMockPlatform_ScriptSequence(t *testing.T) {
+	s := newMockService(t)
+
+	first, err := s.ChatCompletion(context.Background(), 0, "", mockRequest("杭州天气怎么样", false))
+	require.NoError(t, err)
+	assert.Equal(t, string(PlatformMock), first.Platform)
+	assert.Equal(t, "chatcmpl-mock-1", first.ID)
+	assert.Equal(t, "tool_calls", first.Choices[0].FinishReason)
+	require.Len(t, first.Choices[0].Message.ToolCalls, 1)
+	call := first.Choices[0].Message.ToolCalls[0]
+	assert.Equal(t, "call_mock_1_0", call.ID)
+	assert.Equal(t, "function", call.Type)
+	assert.Equal(t, "get_weather", call.Function.Name)
+	assert.Equal(t, `{"city":"杭州"}`, call.Function.Arguments)
+
+	// 第二次命中同一脚本返回下一条，之后重复最后一条
+	for i := 0; i < 2; i++ {
+		resp, err := s.ChatCompletion(context.Background(), 0, "", mockRequest("天气", false))
+		require.NoError(t, err)
+		assert.Equal(t, "stop", resp.Choices[0].FinishReason)
+		assert.Equal(t, "杭州今天晴，气温 25 度。", resp.Choices[0].Message.Content.String())
+
+		// 用量按近似分词计算
+		assert.Equal(t, estimateTokens("天气"), resp.Usage.PromptTokens)
+		assert.Equal(t, estimateTokens("杭州今天晴，气温 25 度。"), resp.Usage.CompletionTokens)
+		assert.Equal(t, resp.Usage.PromptTokens+resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+	}
+}
+
+func TestMockPlatform_Errors(t *testing.T) {
+	s := newMockService(t)
+
+	_, err := s.ChatCompletion(context.Background(), 0, "", mockRequest("rate limit please", false))
+	var apiErr *openai.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusTooManyRequests, apiErr.HTTPStatusCode)
+	assert.Equal(t, "rate_limit_error", apiErr.Type)
+	assert.Equal(t, "rate_limit_exceeded", apiErr.Code)
+	assert.Equal(t, "Too many requests", apiErr.Message)
+
+	_, err = s.ChatCompletion(context.Background(), 0, "", mockRequest("server error", false))
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.HTTPStatusCode)
+	assert.Equal(t, "server_error", apiErr.Type)
+
+	// 流尚未开始时直接返回错误，不写出任何内容
+	var buf bytes.Buffer
+	err = s.ChatCompletionStream(context.Background(), 0, "", mockRequest("rate limit", true), &buf)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Zero(t, buf.Len())
+}
+
+func TestMockPlatform_StreamCut(t *testing.T) {
+	s := newMockService(t)
+
+	var buf bytes.Buffer
+	err := s.ChatCompletionStream(context.Background(), 0, "", mockRequest("tell me a long story", true), &buf)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	chunks, done := parseStream(t, buf.Bytes())
+	assert.False(t, done)
+	require.Len(t, chunks, 4)
+	assert.Equal(t, "assistant", chunks[0].Choices[0].Delta.Role)
+	var content string
+	for _, chunk := range chunks {
+		content += chunk.Choices[0].Delta.Content
+		assert.Nil(t, chunk.Choices[0].FinishReason)
+	}
+	assert.Equal(t, "从前有", content)
+}
+
+func TestMockPlatform_StreamToolCalls(t *testing.T) {
+	s := newMockService(t)
+
+	req := mockRequest("天气", true)
+	req.StreamOptions = &models.OpenAIStreamOptions{IncludeUsage: true}
+
+	var buf bytes.Buffer
+	require.NoError(t, s.ChatCompletionStream(context.Background(), 0, "", req, &buf))
+
+	chunks, done := parseStream(t, buf.Bytes())
+	assert.True(t, done)
+	require.Len(t, chunks, 4)
+
+	toolCalls := chunks[1].Choices[0].Delta.ToolCalls
+	require.Len(t, toolCalls, 1)
+	require.NotNil(t, toolCalls[0].Index)
+	assert.Equal(t, 0, *toolCalls[0].Index)
+	assert.Equal(t, "get_weather", toolCalls[0].Function.Name)
+
+	require.NotNil(t, chunks[2].Choices[0].FinishReason)
+	assert.Equal(t, "tool_calls", *chunks[2].Choices[0].FinishReason)
+
+	require.NotNil(t, chunks[3].Usage)
+	assert.Empty(t, chunks[3].Choices)
+	assert.Equal(t, estimateTokens("天气"), chunks[3].Usage.PromptTokens)
+	assert.Equal(t, estimateTokens(`get_weather{"city":"杭州"}`), chunks[3].Usage.CompletionTokens)
+}
+
+func TestMockPlatform_Deterministic(t *testing.T) {
+	prompts := []string{"介绍一下你自己", "今天学点什么", "随便聊聊"}
+
+	first, second := newMockService(t), newMockService(t)
+	for _, prompt := range prompts {
+		a, err := first.ChatCompletion(context.Background(), 0, "", mockRequest(prompt, false))
+		require.NoError(t, err)
+		b, err := second.ChatCompletion(context.Background(), 0, "", mockRequest(prompt, false))
+		require.NoError(t, err)
+
+		assert.Equal(t, a.ID, b.ID)
+		assert.Equal(t, a.Choices[0].Message.Content.String(), b.Choices[0].Message.Content.String())
+		assert.Equal(t, a.Usage, b.Usage)
+	}
+
+	// 默认回复只取决于种子和输入，与调用顺序无关
+	content := func(s *AIService, prompt string) string {
+		resp, err := s.ChatCompletion(context.Background(), 0, "", mockRequest(prompt, false))
+		require.NoError(t, err)
+		return resp.Choices[0].Message.Content.String()
+	}
+	assert.Equal(t, content(first, prompts[0]), content(newMockService(t), prompts[0]))
+}
+
+func TestMockPlatform_Images(t *testing.T) {
+	s := newMockService(t)
+	urls := func(prompt string, n int) []string {
+		resp, err := s.GenerateImages(context.Background(), 0, "", models.OpenAIImageRequest{Prompt: prompt, N: n})
+		require.NoError(t, err)
+		var result []string
+		for _, data := range resp.Data {
+			result = append(result, data.URL)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"https://example.com/cat.png"}, urls("draw a cat", 1))
+
+	first := urls("一只猫", 3)
+	assert.Len(t, first, 3)
+	assert.Equal(t, first, urls("一只猫", 3))
+	assert.True(t, strings.HasPrefix(first[0], MockImageURL))
+}
+
+func TestMockFixtures_Invalid(t *testing.T) {
+	s := newStubService(nil)
+
+	err := s.UseMockFixtures(&MockFixtures{Scripts: []MockScript{{Match: "(", Replies: []MockReply{{Content: "x"}}}}})
+	assert.ErrorContains(t, err, "invalid match")
+
+	err = s.UseMockFixtures(&MockFixtures{Scripts: []MockScript{{Match: "x"}}})
+	assert.ErrorContains(t, err, "no replies")
+
+	_, err = LoadMockFixtures("testdata/not_exist.json")
+	assert.Error(t, err)
+}
@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+// routeTarget 一次调用的目标平台及平台侧模型名
+type routeTarget struct {
+	Platform Platform
+	Model    string
+}
+
+// resolveRoute 解析请求对应的平台路由
+// 显式指定平台时只使用该平台，否则按模型路由配置，最后回退到默认顺序
+func (s *AIService) resolveRoute(platform Platform, model string) []routeTarget {
+	if platform != "" {
+		return []routeTarget{{Platform: platform, Model: model}}
+	}
+
+	var targets []routeTarget
+	if route, ok := config.AIModelRoutes[model]; ok {
+		for _, t := range route {
+			target := routeTarget{Platform: Platform(t.Platform), Model: t.Model}
+			if target.Model == "" {
+				target.Model = model
+			}
+			if s.hasPlatform(target.Platform) {
+				targets = append(targets, target)
+			}
+		}
+	} else {
+		for _, p := range config.AIDefaultRoute {
+			if s.hasPlatform(Platform(p)) {
+				targets = append(targets, routeTarget{Platform: Platform(p), Model: model})
+			}
+		}
+	}
+
+	// 如果没有可用平台，则使用 Mock 平台
+	if len(targets) == 0 {
+		targets = append(targets, routeTarget{Platform: PlatformMock, Model: model})
+	}
+
+	return targets
+}
+
+// hasPlatform 平台是否已配置客户端
+func (s *AIService) hasPlatform(platform Platform) bool {
+	if platform == PlatformMock {
+		return true
+	}
+	client, ok := s.clients[platform]
+	return ok && client != nil
+}
+
+// routeCall 按路由顺序调用，遇到可重试错误时切换到下一个平台
+// 请求被取消（客户端断开）后不再尝试后续平台。返回实际提供服务的平台
+func (s *AIService) routeCall(ctx context.Context, targets []routeTarget, call func(target routeTarget) error) (Platform, error) {
+	var lastErr error
+	for i, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return PlatformUnknown, err
+		}
+
+		err := call(target)
+		if err == nil {
+			if i > 0 {
+				logrus.WithFields(logrus.Fields{
+					"platform": target.Platform,
+					"model":    target.Model,
+					"attempt":  i + 1,
+				}).Info("AI request served by fallback platform")
+			}
+			return target.Platform, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil || !isRetryableError(err) {
+			return target.Platform, err
+		}
+
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"platform": target.Platform,
+			"model":    target.Model,
+			"attempt":  i + 1,
+		}).Warn("AI platform failed, trying next")
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no platform available")
+	}
+	return PlatformUnknown, fmt.Errorf("all platforms failed: %w", lastErr)
+}
+
+// platformTimeout 平台调用超时，未单独配置时使用默认值
+func platformTimeout(platform Platform, stream bool) time.Duration {
+	timeout := config.AIPlatformTimeouts[string(platform)]
+	if stream {
+		if timeout.Stream > 0 {
+			return timeout.Stream
+		}
+		return config.AIDefaultTimeout.Stream
+	}
+	if timeout.Request > 0 {
+		return timeout.Request
+	}
+	return config.AIDefaultTimeout.Request
+}
+
+// withPlatformTimeout 为单次平台调用派生带超时的 context
+func withPlatformTimeout(ctx context.Context, platform Platform, stream bool) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, platformTimeout(platform, stream))
+}
+
+// isRetryableError 是否应切换平台重试：5xx、429、超时
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.HTTPStatusCode)
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return isRetryableStatus(reqErr.HTTPStatusCode)
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	// 连接失败（平台不可达）
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+
+	return false
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
@@ -0,0 +1,294 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stubModel = "stub-model"
+
+// chatHandler OpenAI 兼容的对话接口，固定返回指定状态码
+func chatHandler(name string, status int, delay time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+
+		if status != http.StatusOK {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"message":"%s failed","type":"server_error"}}`, name)
+			return
+		}
+
+		var req openai.ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		if req.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "data: {\"id\":\"%s\",\"object\":\"chat.completion.chunk\",\"model\":\"%s\",\"choices\":[{\"index\":0,\"delta\":{\"content\":\"hi from %s\"}}]}\n\n", name, req.Model, name)
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(openai.ChatCompletionResponse{
+			ID:    name,
+			Model: req.Model,
+			Choices: []openai.ChatCompletionChoice{
+				{Message: openai.ChatCompletionMessage{Role: "assistant", Content: "hi from " + name}},
+			},
+			Usage: openai.Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3},
+		})
+	}
+}
+
+// newStubService 用桩服务替换各平台客户端
+func newStubService(stubs map[Platform]*stubServer) *AIService {
+	s := &AIService{
+		clients:   make(map[Platform]*openai.Client),
+		endpoints: make(map[Platform]platformEndpoint),
+		cfg:       &config.Config{},
+	}
+	zero := 0
+	_ = s.UseMockFixtures(&MockFixtures{DelayMs: &zero, ChunkDelayMs: &zero})
+	for platform, stub := range stubs {
+		clientConfig := openai.DefaultConfig("test-key")
+		clientConfig.BaseURL = stub.URL + "/v1"
+		s.clients[platform] = openai.NewClientWithConfig(clientConfig)
+		s.endpoints[platform] = platformEndpoint{BaseURL: clientConfig.BaseURL, APIKey: "test-key"}
+	}
+	return s
+}
+
+// withStubRoute 临时设置 stub-model 的路由
+func withStubRoute(t *testing.T, platforms ...Platform) {
+	route := make([]config.AIRouteTarget, len(platforms))
+	for i, p := range platforms {
+		route[i] = config.AIRouteTarget{Platform: string(p), Model: string(p) + "/" + stubModel}
+	}
+	config.AIModelRoutes[stubModel] = route
+	t.Cleanup(func() {
+		delete(config.AIModelRoutes, stubModel)
+	})
+}
+
+// withStubTimeout 临时缩短平台调用超时
+func withStubTimeout(t *testing.T, timeout time.Duration) {
+	original := config.AIDefaultTimeout
+	config.AIDefaultTimeout = config.AITimeout{Request: timeout, Stream: timeout}
+	t.Cleanup(func() {
+		config.AIDefaultTimeout = original
+	})
+}
+
+func stubRequest(stream bool) models.OpenAIChatCompletionRequest {
+	return models.OpenAIChatCompletionRequest{
+		Model:    stubModel,
+		Messages: []models.OpenAIMessage{{Role: "user", Content: models.NewTextContent("hello")}},
+		Stream:   stream,
+	}
+}
+
+func TestRouter_FailoverOnRetryableErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		delay  time.Duration
+	}{
+		{name: "5xx", status: http.StatusBadGateway},
+		{name: "429", status: http.StatusTooManyRequests},
+		{name: "timeout", status: http.StatusOK, delay: time.Second},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			primary := newTestServer(t, chatHandler("silicon", tc.status, tc.delay))
+			fallback := newTestServer(t, chatHandler("openrouter", http.StatusOK, 0))
+			s := newStubService(map[Platform]*stubServer{
+				PlatformSilicon:    primary,
+				PlatformOpenRouter: fallback,
+			})
+			withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+			withStubTimeout(t, 200*time.Millisecond)
+
+			resp, err := s.ChatCompletion(context.Background(), 0, "", stubRequest(false))
+			require.NoError(t, err)
+			assert.Equal(t, string(PlatformOpenRouter), resp.Platform)
+			assert.Equal(t, "openrouter/"+stubModel, resp.Model)
+			assert.Equal(t, "hi from openrouter", resp.Choices[0].Message.Content.String())
+			assert.Equal(t, int32(1), primary.hits.Load())
+			assert.Equal(t, int32(1), fallback.hits.Load())
+		})
+	}
+}
+
+func TestRouter_NoFailoverOnClientError(t *testing.T) {
+	primary := newTestServer(t, chatHandler("silicon", http.StatusBadRequest, 0))
+	fallback := newTestServer(t, chatHandler("openrouter", http.StatusOK, 0))
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    primary,
+		PlatformOpenRouter: fallback,
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+
+	_, err := s.ChatCompletion(context.Background(), 0, "", stubRequest(false))
+	require.Error(t, err)
+	assert.Equal(t, int32(0), fallback.hits.Load())
+}
+
+func TestRouter_AllPlatformsFailed(t *testing.T) {
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    newTestServer(t, chatHandler("silicon", http.StatusInternalServerError, 0)),
+		PlatformOpenRouter: newTestServer(t, chatHandler("openrouter", http.StatusServiceUnavailable, 0)),
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+
+	_, err := s.ChatCompletion(context.Background(), 0, "", stubRequest(false))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "all platforms failed")
+}
+
+func TestRouter_ExplicitPlatformSkipsRoute(t *testing.T) {
+	primary := newTestServer(t, chatHandler("silicon", http.StatusInternalServerError, 0))
+	fallback := newTestServer(t, chatHandler("openrouter", http.StatusOK, 0))
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    primary,
+		PlatformOpenRouter: fallback,
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+
+	_, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, stubRequest(false))
+	require.Error(t, err)
+	assert.Equal(t, int32(0), fallback.hits.Load())
+}
+
+func TestRouter_SkipsUnconfiguredPlatforms(t *testing.T) {
+	fallback := newTestServer(t, chatHandler("dashscope", http.StatusOK, 0))
+	s := newStubService(map[Platform]*stubServer{
+		PlatformDashScope: fallback,
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter, PlatformDashScope)
+
+	resp, err := s.ChatCompletion(context.Background(), 0, "", stubRequest(false))
+	require.NoError(t, err)
+	assert.Equal(t, string(PlatformDashScope), resp.Platform)
+}
+
+func TestRouter_ExplicitUnconfiguredPlatform(t *testing.T) {
+	s := newStubService(map[Platform]*stubServer{
+		PlatformDashScope: newTestServer(t, chatHandler("dashscope", http.StatusOK, 0)),
+	})
+
+	_, err := s.ChatCompletion(context.Background(), 0, PlatformSilicon, stubRequest(false))
+	assert.ErrorIs(t, err, ErrPlatformUnavailable)
+	_, err = s.GenerateImages(context.Background(), 0, PlatformSilicon, models.OpenAIImageRequest{Prompt: "cat"})
+	assert.ErrorIs(t, err, ErrPlatformUnavailable)
+}
+
+func TestRouter_StreamFailover(t *testing.T) {
+	primary := newTestServer(t, chatHandler("silicon", http.StatusServiceUnavailable, 0))
+	fallback := newTestServer(t, chatHandler("openrouter", http.StatusOK, 0))
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    primary,
+		PlatformOpenRouter: fallback,
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+
+	recorder := httptest.NewRecorder()
+	err := s.ChatCompletionStream(context.Background(), 0, "", stubRequest(true), recorder)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(PlatformOpenRouter), recorder.Header().Get(PlatformHeader))
+	body := recorder.Body.String()
+	assert.Contains(t, body, "hi from openrouter")
+	assert.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+	assert.Equal(t, 1, bytes.Count(recorder.Body.Bytes(), []byte(`"platform":"openrouter"`)))
+}
+
+func TestRouter_NoFailoverAfterCancel(t *testing.T) {
+	primary := newTestServer(t, chatHandler("silicon", http.StatusOK, 0))
+	fallback := newTestServer(t, chatHandler("openrouter", http.StatusOK, 0))
+	s := newStubService(map[Platform]*stubServer{
+		PlatformSilicon:    primary,
+		PlatformOpenRouter: fallback,
+	})
+	withStubRoute(t, PlatformSilicon, PlatformOpenRouter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.ChatCompletion(ctx, 0, "", stubRequest(false))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), primary.hits.Load())
+	assert.Equal(t, int32(0), fallback.hits.Load())
+}
+
+func TestPlatformTimeout(t *testing.T) {
+	original := config.AIPlatformTimeouts
+	config.AIPlatformTimeouts = map[string]config.AITimeout{
+		"silicon": {Request: time.Second},
+	}
+	t.Cleanup(func() {
+		config.AIPlatformTimeouts = original
+	})
+
+	assert.Equal(t, time.Second, platformTimeout(PlatformSilicon, false))
+	assert.Equal(t, config.AIDefaultTimeout.Stream, platformTimeout(PlatformSilicon, true))
+	assert.Equal(t, config.AIDefaultTimeout.Request, platformTimeout(PlatformOpenRouter, false))
+}
+
+// cancelWriter 收到第一个数据块后模拟客户端断开
+type cancelWriter struct {
+	bytes.Buffer
+	cancel context.CancelFunc
+}
+
+func (w *cancelWriter) Write(p []byte) (int, error) {
+	w.cancel()
+	return w.Buffer.Write(p)
+}
+
+func TestChatCompletionStream_ClientDisconnect(t *testing.T) {
+	upstreamClosed := make(chan struct{})
+	server := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[{"index":0,"delta":{"content":"你好"}}]}`+"\n\n")
+		w.(http.Flusher).Flush()
+
+		// 上游一直不结束，直到连接被关闭
+		select {
+		case <-r.Context().Done():
+			close(upstreamClosed)
+		case <-time.After(5 * time.Second):
+		}
+	})
+	s := newCaptureService(server)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	writer := &cancelWriter{cancel: cancel}
+
+	start := time.Now()
+	err := s.ChatCompletionStream(ctx, 0, PlatformSilicon, stubRequest(true), writer)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Contains(t, writer.String(), "你好")
+
+	select {
+	case <-upstreamClosed:
+	case <-time.After(time.Second):
+		t.Fatal("upstream connection was not closed")
+	}
+}
@@ -2,44 +2,93 @@ package ai
 
 import (
 	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/database"
 	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"context"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
 )
 
-func NewAIService(cfg *config.Config) *AIService {
+func NewAIService(cfg *config.Config, ossService *services.OSSService, rbacService *services.RBACService) *AIService {
 	service := &AIService{
-		clients: make(map[Platform]*openai.Client),
-		cfg:     cfg,
+		BaseService: services.BaseService{DB: database.DB, Redis: database.Redis},
+		clients:     make(map[Platform]*openai.Client),
+		endpoints:   make(map[Platform]platformEndpoint),
+		cfg:         cfg,
+	}
+
+	if rbacService != nil {
+		service.perms = rbacService
+	}
+
+	// 未配置 OSS 时不解析消息中的 objectKey，也不支持转存生成的图片
+	if ossService != nil && ossService.ValidateCfg() == nil {
+		service.signer = ossService
+		service.storage = ossService
 	}
 
 	// 初始化客户端
 	if cfg.SiliconAPIKey != "" {
-		clientConfig := openai.DefaultConfig(cfg.SiliconAPIKey)
-		clientConfig.BaseURL = "https://api.siliconflow.cn/v1"
-		service.clients[PlatformSilicon] = openai.NewClientWithConfig(clientConfig)
+		service.addPlatform(PlatformSilicon, cfg.SiliconAPIKey, "https://api.siliconflow.cn/v1")
 	}
 
 	if cfg.OpenRouterAPIKey != "" {
-		clientConfig := openai.DefaultConfig(cfg.OpenRouterAPIKey)
-		clientConfig.BaseURL = "https://openrouter.ai/api/v1"
-		service.clients[PlatformOpenRouter] = openai.NewClientWithConfig(clientConfig)
+		service.addPlatform(PlatformOpenRouter, cfg.OpenRouterAPIKey, "https://openrouter.ai/api/v1")
 	}
 
 	if cfg.DashscopeAPIKey != "" {
-		clientConfig := openai.DefaultConfig(cfg.DashscopeAPIKey)
-		clientConfig.BaseURL = "https://dashscope.aliyuncs.com/compatible-mode/v1"
-		service.clients[PlatformDashScope] = openai.NewClientWithConfig(clientConfig)
+		service.addPlatform(PlatformDashScope, cfg.DashscopeAPIKey, "https://dashscope.aliyuncs.com/compatible-mode/v1")
 	}
 
 	// Mock 平台不需要真实客户端，但为了统一处理，创建一个占位客户端
 	service.clients[PlatformMock] = nil
 
+	// Mock 平台按脚本回复，便于测试复现
+	fixtures := &MockFixtures{}
+	if cfg.AIMockFixtures != "" {
+		loaded, err := LoadMockFixtures(cfg.AIMockFixtures)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to load mock fixtures")
+		}
+		fixtures = loaded
+	}
+	if cfg.AIMockSeed != 0 {
+		fixtures.Seed = cfg.AIMockSeed
+	}
+	if err := service.UseMockFixtures(fixtures); err != nil {
+		logrus.WithError(err).Fatal("Invalid mock fixtures")
+	}
+
 	return service
 }
 
+// addPlatform 注册平台客户端，并记录接口地址供模型目录等直接调用
+func (s *AIService) addPlatform(platform Platform, apiKey, baseURL string) {
+	s.clients[platform] = newPlatformClient(apiKey, baseURL)
+	s.endpoints[platform] = platformEndpoint{BaseURL: baseURL, APIKey: apiKey}
+}
+
+func newPlatformClient(apiKey, baseURL string) *openai.Client {
+	clientConfig := openai.DefaultConfig(apiKey)
+	clientConfig.BaseURL = baseURL
+	clientConfig.HTTPClient = newContentPartDoer()
+	return openai.NewClientWithConfig(clientConfig)
+}
+
 // Generate 文本生成（兼容旧接口）
-func (s *AIService) Generate(userID uint64, req models.GenerateRequest) (*models.GenerateResponse, error) {
+func (s *AIService) Generate(ctx context.Context, userID uint64, req models.GenerateRequest) (*models.GenerateResponse, error) {
+	if err := s.CheckQuota(userID); err != nil {
+		return nil, err
+	}
+
+	model, err := s.resolveModel(s.getModelName(req.Model))
+	if err != nil {
+		return nil, err
+	}
+	req.Model = model
+
 	// 转换为聊天请求
 	chatReq := models.ChatRequest{
 		Message: req.Prompt,
@@ -47,7 +96,15 @@ func (s *AIService) Generate(userID uint64, req models.GenerateRequest) (*models
 		Stream:  false,
 	}
 
-	chatResp, err := s.Chat(userID, chatReq)
+	// 单次生成不需要会话上下文
+	messages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleUser,
+			Content: req.Prompt,
+		},
+	}
+
+	chatResp, err := s.complete(ctx, userID, chatReq, messages)
 	if err != nil {
 		return nil, err
 	}
@@ -60,51 +117,3 @@ func (s *AIService) Generate(userID uint64, req models.GenerateRequest) (*models
 		Usage:     chatResp.Usage,
 	}, nil
 }
-
-// GetAvailableModels 获取可用模型
-func (s *AIService) GetAvailableModels() ([]models.AIModel, error) {
-	models := []models.AIModel{
-		{
-			ID:           "gpt-3.5-turbo",
-			Name:         "GPT-3.5 Turbo",
-			Description:  "Fast and efficient language model",
-			Provider:     "OpenAI",
-			Type:         "chat",
-			Capabilities: []string{"text-generation", "chat"},
-			MaxTokens:    4096,
-			IsActive:     true,
-		},
-		{
-			ID:           "gpt-4",
-			Name:         "GPT-4",
-			Description:  "Most capable language model",
-			Provider:     "OpenAI",
-			Type:         "chat",
-			Capabilities: []string{"text-generation", "chat"},
-			MaxTokens:    8192,
-			IsActive:     true,
-		},
-		{
-			ID:           "deepseek-chat",
-			Name:         "DeepSeek Chat",
-			Description:  "Fast Chinese language model",
-			Provider:     "DeepSeek",
-			Type:         "chat",
-			Capabilities: []string{"text-generation", "chat"},
-			MaxTokens:    4096,
-			IsActive:     true,
-		},
-	}
-
-	return models, nil
-}
-
-// GetChatHistory 获取聊天历史（Mock实现）
-func (s *AIService) GetChatHistory(userID uint64, sessionID string) ([]models.ConversationHistory, error) {
-	return []models.ConversationHistory{}, nil
-}
-
-// ClearChatHistory 清除聊天历史（Mock实现）
-func (s *AIService) ClearChatHistory(userID uint64, sessionID string) error {
-	return nil
-}
@@ -0,0 +1,317 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/pkg/utils"
+	"errors"
+	"time"
+
+	"github.com/sashabaranov/go-openai"
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("会话不存在")
+
+// prepareSession 获取用户会话，sessionID 为空或不存在时返回未保存的新会话
+// 新会话在第一轮对话成功后与消息一起保存，上游调用失败时不会留下空会话
+func (s *AIService) prepareSession(userID uint64, sessionID string, model string, firstMessage string) (*models.ChatSession, error) {
+	if sessionID != "" {
+		session, err := s.getSession(userID, sessionID)
+		if err == nil {
+			return session, nil
+		}
+		if !errors.Is(err, ErrSessionNotFound) {
+			return nil, err
+		}
+
+		// 会话ID已被其他用户占用
+		if s.ExistsByCondition(&models.ChatSession{}, map[string]any{"session_id": sessionID}) {
+			return nil, ErrSessionNotFound
+		}
+	} else {
+		sessionID = utils.GenerateSessionID()
+	}
+
+	return &models.ChatSession{
+		UserID:        userID,
+		SessionID:     sessionID,
+		Title:         genSessionTitle(firstMessage),
+		Model:         model,
+		LastMessageAt: time.Now(),
+	}, nil
+}
+
+// CheckSession 检查会话ID能否由该用户使用：属于该用户或尚未被使用，被其他用户占用时返回 ErrSessionNotFound
+// 流式对话开始推流后无法再返回状态码，推流前先检查
+func (s *AIService) CheckSession(userID uint64, sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+	_, err := s.getSession(userID, sessionID)
+	if !errors.Is(err, ErrSessionNotFound) {
+		return err
+	}
+	if s.ExistsByCondition(&models.ChatSession{}, map[string]any{"session_id": sessionID}) {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// getSession 获取用户的指定会话
+func (s *AIService) getSession(userID uint64, sessionID string) (*models.ChatSession, error) {
+	var session models.ChatSession
+	err := s.DB.Where("user_id = ? AND session_id = ?", userID, sessionID).First(&session).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// buildSessionMessages 将会话历史与本轮用户消息拼装为上下文
+func (s *AIService) buildSessionMessages(session *models.ChatSession, message string) ([]openai.ChatCompletionMessage, error) {
+	var history []models.ConversationHistory
+
+	// 取最近 N 条，再按时间正序排列
+	err := s.DB.Where("session_id = ?", session.SessionID).
+		Order("created_at DESC, id DESC").
+		Limit(config.AIHistoryMaxMessages).
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]openai.ChatCompletionMessage, 0, len(history)+1)
+	for i := len(history) - 1; i >= 0; i-- {
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    history[i].Role,
+			Content: history[i].Content,
+		})
+	}
+	messages = append(messages, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: message,
+	})
+
+	return messages, nil
+}
+
+// saveSessionTurn 保存一轮对话（用户消息 + 助手回复），新会话在此时创建
+func (s *AIService) saveSessionTurn(session *models.ChatSession, userMessage, assistantMessage, model string) error {
+	now := time.Now()
+	records := []models.ConversationHistory{
+		{
+			UserID:    session.UserID,
+			SessionID: session.SessionID,
+			Role:      openai.ChatMessageRoleUser,
+			Content:   userMessage,
+			Model:     model,
+			CreatedAt: now,
+		},
+		{
+			UserID:    session.UserID,
+			SessionID: session.SessionID,
+			Role:      openai.ChatMessageRoleAssistant,
+			Content:   assistantMessage,
+			Model:     model,
+			// 保证同一轮中回复排在提问之后
+			CreatedAt: now.Add(time.Millisecond),
+		},
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if session.ID == 0 {
+			if err := tx.Create(session).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&records).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(session).Updates(map[string]any{
+			"model":           model,
+			"message_count":   gorm.Expr("message_count + ?", len(records)),
+			"last_message_at": now,
+		}).Error
+	})
+}
+
+// ListSessions 获取用户会话列表（按最近消息时间倒序）
+func (s *AIService) ListSessions(userID uint64, page, limit int) (*models.PaginationResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > config.AISessionPageLimit {
+		limit = config.AISessionPageLimit
+	}
+
+	query := s.DB.Model(&models.ChatSession{}).Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var sessions []models.ChatSession
+	err := query.Order("last_message_at DESC, id DESC").
+		Offset(utils.GetOffset(page, limit)).
+		Limit(limit).
+		Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.PaginationResponse{
+		Data: make([]any, len(sessions)),
+		Pagination: models.Pagination{
+			Current:  page,
+			PageSize: limit,
+			Total:    int(total),
+		},
+	}
+	for i, session := range sessions {
+		response.Data[i] = session
+	}
+
+	return response, nil
+}
+
+// RenameSession 重命名会话
+func (s *AIService) RenameSession(userID uint64, sessionID string, title string) (*models.ChatSession, error) {
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	session.Title = title
+	if err := s.DB.Model(session).Update("title", title).Error; err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// ForkSession 复制会话，可指定复制到某条消息为止
+func (s *AIService) ForkSession(userID uint64, sessionID string, req models.ForkChatSessionRequest) (*models.ChatSession, error) {
+	source, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Where("session_id = ?", source.SessionID)
+	if req.MessageID != 0 {
+		var pivot models.ConversationHistory
+		err := s.DB.Where("id = ? AND session_id = ?", req.MessageID, source.SessionID).First(&pivot).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errors.New("消息不存在")
+			}
+			return nil, err
+		}
+		query = query.Where("(created_at < ? OR (created_at = ? AND id <= ?))", pivot.CreatedAt, pivot.CreatedAt, pivot.ID)
+	}
+
+	var history []models.ConversationHistory
+	if err := query.Order("created_at ASC, id ASC").Find(&history).Error; err != nil {
+		return nil, err
+	}
+
+	title := req.Title
+	if title == "" {
+		title = source.Title + " (副本)"
+	}
+
+	fork := &models.ChatSession{
+		UserID:        userID,
+		SessionID:     utils.GenerateSessionID(),
+		Title:         title,
+		Model:         source.Model,
+		ForkedFrom:    source.SessionID,
+		MessageCount:  len(history),
+		LastMessageAt: source.LastMessageAt,
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(fork).Error; err != nil {
+			return err
+		}
+		if len(history) == 0 {
+			return nil
+		}
+
+		records := make([]models.ConversationHistory, len(history))
+		for i, h := range history {
+			records[i] = models.ConversationHistory{
+				UserID:    userID,
+				SessionID: fork.SessionID,
+				Role:      h.Role,
+				Content:   h.Content,
+				Model:     h.Model,
+				CreatedAt: h.CreatedAt,
+			}
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fork, nil
+}
+
+// DeleteSession 删除会话及其全部消息
+func (s *AIService) DeleteSession(userID uint64, sessionID string) error {
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", session.SessionID).Delete(&models.ConversationHistory{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(session).Error
+	})
+}
+
+// GetChatHistory 获取会话聊天历史
+func (s *AIService) GetChatHistory(userID uint64, sessionID string) ([]models.ConversationHistory, error) {
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var history []models.ConversationHistory
+	err = s.DB.Where("session_id = ?", session.SessionID).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// ClearChatHistory 清空会话消息，保留会话本身
+func (s *AIService) ClearChatHistory(userID uint64, sessionID string) error {
+	session, err := s.getSession(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", session.SessionID).Delete(&models.ConversationHistory{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(session).Update("message_count", 0).Error
+	})
+}
+
+// genSessionTitle 取首条消息的前若干字符作为会话标题
+func genSessionTitle(message string) string {
+	runes := []rune(message)
+	if len(runes) > config.AISessionTitleMaxLen {
+		return string(runes[:config.AISessionTitleMaxLen]) + "..."
+	}
+	return message
+}
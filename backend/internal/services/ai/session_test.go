@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAIService_Sessions(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "sessionuser_" + timestamp,
+			Email:    "sessionuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		// 未配置任何 API Key 时走 mock 平台
+		cfg := *testutil.TestConfig
+		cfg.SiliconAPIKey, cfg.OpenRouterAPIKey, cfg.DashscopeAPIKey = "", "", ""
+		s := NewAIService(&cfg, nil, nil)
+
+		// 首轮对话自动创建会话
+		resp, err := s.Chat(context.Background(), user.ID, models.ChatRequest{Message: "你好"})
+		require.NoError(t, err)
+		require.NotEmpty(t, resp.SessionID)
+		sessionID := resp.SessionID
+		defer func() {
+			_ = s.DeleteSession(user.ID, sessionID)
+		}()
+
+		// 上游调用失败时不创建会话
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		failedID := "failed_" + timestamp
+		err = s.StreamChat(canceled, user.ID, models.ChatRequest{Message: "你好", SessionID: failedID}, make(chan string, 100))
+		require.Error(t, err)
+		_, err = s.GetChatHistory(user.ID, failedID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+
+		// 第二轮对话沿用会话
+		_, err = s.Chat(context.Background(), user.ID, models.ChatRequest{Message: "测试", SessionID: sessionID})
+		require.NoError(t, err)
+
+		history, err := s.GetChatHistory(user.ID, sessionID)
+		require.NoError(t, err)
+		require.Len(t, history, 4)
+		assert.Equal(t, "user", history[0].Role)
+		assert.Equal(t, "你好", history[0].Content)
+		assert.Equal(t, "assistant", history[1].Role)
+		assert.Equal(t, "测试", history[2].Content)
+
+		// 上下文包含历史消息
+		messages, err := s.buildSessionMessages(&models.ChatSession{SessionID: sessionID}, "再来一次")
+		require.NoError(t, err)
+		assert.Len(t, messages, 5)
+		assert.Equal(t, "再来一次", messages[4].Content)
+
+		// 其他用户无法访问，也不能在流式对话中沿用
+		_, err = s.GetChatHistory(user.ID+1, sessionID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+		assert.ErrorIs(t, s.CheckSession(user.ID+1, sessionID), ErrSessionNotFound)
+		assert.NoError(t, s.CheckSession(user.ID, sessionID))
+		assert.NoError(t, s.CheckSession(user.ID, "unused_"+timestamp))
+
+		// 重命名
+		renamed, err := s.RenameSession(user.ID, sessionID, "新标题")
+		require.NoError(t, err)
+		assert.Equal(t, "新标题", renamed.Title)
+
+		// 从第二条消息分叉
+		fork, err := s.ForkSession(user.ID, sessionID, models.ForkChatSessionRequest{MessageID: history[1].ID})
+		require.NoError(t, err)
+		defer func() {
+			_ = s.DeleteSession(user.ID, fork.SessionID)
+		}()
+		assert.Equal(t, sessionID, fork.ForkedFrom)
+		forkHistory, err := s.GetChatHistory(user.ID, fork.SessionID)
+		require.NoError(t, err)
+		assert.Len(t, forkHistory, 2)
+
+		// 会话列表
+		list, err := s.ListSessions(user.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Pagination.Total)
+
+		// 删除
+		require.NoError(t, s.DeleteSession(user.ID, sessionID))
+		_, err = s.GetChatHistory(user.ID, sessionID)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
@@ -0,0 +1,25 @@
+package ai
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// stubServer 模拟上游平台的测试服务，记录收到的请求数
+type stubServer struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+// newTestServer 用 handler 启动测试服务，测试结束时自动关闭
+func newTestServer(t *testing.T, handler http.HandlerFunc) *stubServer {
+	stub := &stubServer{}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.hits.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
@@ -2,6 +2,8 @@ package ai
 
 import (
 	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/services"
+	"errors"
 	"fmt"
 
 	"github.com/sashabaranov/go-openai"
@@ -18,10 +20,33 @@ const (
 	PlatformMock       Platform = "mock"
 )
 
+// ErrPlatformUnavailable 请求指定的平台未配置
+var ErrPlatformUnavailable = errors.New("平台不可用")
+
+// PlatformHeader 响应头，记录实际提供服务的平台
+const PlatformHeader = "X-AI-Platform"
+
 // AIService AI服务
 type AIService struct {
-	clients map[Platform]*openai.Client
-	cfg     *config.Config
+	services.BaseService
+	clients   map[Platform]*openai.Client
+	endpoints map[Platform]platformEndpoint
+	cfg       *config.Config
+	signer    objectSigner
+	storage   imageStorage
+	perms     permissionChecker
+	mock      *mockPlatform
+}
+
+// permissionChecker 查询角色权限，用于判断是否不受用量配额限制
+type permissionChecker interface {
+	RoleHasPermission(role, permission string) bool
+}
+
+// platformEndpoint 平台的 OpenAI 兼容接口地址
+type platformEndpoint struct {
+	BaseURL string
+	APIKey  string
 }
 
 // getClient 获取指定平台的客户端
@@ -33,26 +58,11 @@ func (s *AIService) getClient(platform Platform) (*openai.Client, error) {
 
 	client, ok := s.clients[platform]
 	if !ok {
-		return nil, fmt.Errorf("platform %s not available", platform)
+		return nil, fmt.Errorf("%w: %s", ErrPlatformUnavailable, platform)
 	}
 	return client, nil
 }
 
-// getDefaultPlatform 获取默认平台（根据配置的API Key）
-func (s *AIService) getDefaultPlatform() Platform {
-	if s.cfg.SiliconAPIKey != "" {
-		return PlatformSilicon
-	}
-	if s.cfg.OpenRouterAPIKey != "" {
-		return PlatformOpenRouter
-	}
-	if s.cfg.DashscopeAPIKey != "" {
-		return PlatformDashScope
-	}
-	// 如果没有配置任何API Key，则使用 Mock 平台
-	return PlatformMock
-}
-
 func (s *AIService) getModelName(model string) string {
 	if model == "" {
 		return "gpt-3.5-turbo"
@@ -0,0 +1,230 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrQuotaExceeded = errors.New("AI 用量已超出配额")
+
+// 用量流水中的接口类型
+const (
+	EndpointChat            = "chat"
+	EndpointChatCompletions = "chat_completions"
+	EndpointImages          = "images"
+	EndpointImageEdits      = "image_edits"
+	EndpointImageVariations = "image_variations"
+)
+
+// usageMeter 记录一次 AI 调用的耗时与用量，调用结束后写入流水
+type usageMeter struct {
+	s     *AIService
+	entry models.AIUsageLog
+	start time.Time
+}
+
+func (s *AIService) startUsage(userID uint64, endpoint string, model string, stream bool) *usageMeter {
+	return &usageMeter{
+		s: s,
+		entry: models.AIUsageLog{
+			UserID:   userID,
+			Endpoint: endpoint,
+			Model:    model,
+			Stream:   stream,
+		},
+		start: time.Now(),
+	}
+}
+
+// finish 写入流水，写入失败只记录日志，不影响调用结果
+// 客户端中途断开时记录为 canceled，用量按已输出的部分计
+func (m *usageMeter) finish(platform Platform, usage models.Usage, callErr error) {
+	if m.s.DB == nil {
+		return
+	}
+
+	entry := m.entry
+	entry.Platform = string(platform)
+	entry.PromptTokens = usage.PromptTokens
+	entry.CompletionTokens = usage.CompletionTokens
+	entry.TotalTokens = usage.TotalTokens
+	if entry.TotalTokens == 0 {
+		entry.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	}
+	entry.LatencyMs = time.Since(m.start).Milliseconds()
+	entry.Status = "success"
+	if callErr != nil {
+		entry.Status = "error"
+		if errors.Is(callErr, context.Canceled) {
+			entry.Status = "canceled"
+		}
+		entry.Error = truncate(callErr.Error(), 255)
+	}
+
+	if err := m.s.DB.Create(&entry).Error; err != nil {
+		logrus.WithError(err).WithField("user_id", entry.UserID).Error("Failed to record AI usage")
+	}
+}
+
+// CheckQuota 检查用户当日和当月用量是否超出所属角色的配额
+func (s *AIService) CheckQuota(userID uint64) error {
+	if !config.AIQuotaEnabled || s.DB == nil {
+		return nil
+	}
+
+	quota, err := s.GetQuota(userID)
+	if err != nil {
+		return err
+	}
+
+	if quota.Daily.Remaining == 0 {
+		return fmt.Errorf("%w: 今日额度 %d tokens 已用完", ErrQuotaExceeded, quota.Daily.Limit)
+	}
+	if quota.Monthly.Remaining == 0 {
+		return fmt.Errorf("%w: 本月额度 %d tokens 已用完", ErrQuotaExceeded, quota.Monthly.Limit)
+	}
+	return nil
+}
+
+// GetQuota 获取用户当日和当月的配额使用情况
+func (s *AIService) GetQuota(userID uint64) (*models.AIQuotaResponse, error) {
+	role, err := s.userRole(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	quota, ok := config.AIRoleQuotas[role]
+	if !ok {
+		quota = config.AIRoleQuotas[models.RoleUser]
+	}
+	// 拥有 ai.unlimited 权限的角色不限用量
+	if s.perms != nil && s.perms.RoleHasPermission(role, models.PermAIUnlimited) {
+		quota = config.AIQuota{}
+	}
+
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+	dailyUsed, err := s.sumUsage(userID, dayStart)
+	if err != nil {
+		return nil, err
+	}
+	monthlyUsed, err := s.sumUsage(userID, monthStart)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AIQuotaResponse{
+		Role:    role,
+		Daily:   quotaStatus(quota.Daily, dailyUsed, dayStart.AddDate(0, 0, 1)),
+		Monthly: quotaStatus(quota.Monthly, monthlyUsed, monthStart.AddDate(0, 1, 0)),
+	}, nil
+}
+
+// userRole 获取用户角色，AI 接口都需要登录，用户不存在时返回错误
+func (s *AIService) userRole(userID uint64) (string, error) {
+	var user models.User
+	if err := s.DB.Select("role").Where("id = ?", userID).Take(&user).Error; err != nil {
+		return "", err
+	}
+	if user.Role == "" {
+		return models.RoleUser, nil
+	}
+	return user.Role, nil
+}
+
+func (s *AIService) sumUsage(userID uint64, since time.Time) (int64, error) {
+	var used int64
+	err := s.DB.Model(&models.AIUsageLog{}).
+		Select("COALESCE(SUM(total_tokens), 0)").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Scan(&used).Error
+	return used, err
+}
+
+func quotaStatus(limit, used int64, resetAt time.Time) models.AIQuotaStatus {
+	status := models.AIQuotaStatus{
+		Limit:     limit,
+		Used:      used,
+		Remaining: -1,
+		ResetAt:   resetAt,
+	}
+	if limit > 0 {
+		status.Remaining = max(limit-used, 0)
+	}
+	return status
+}
+
+// estimateTokens 粗略估算文本 token 数，用于上游未返回用量的场景
+func estimateTokens(text string) int {
+	return len(splitTokens(text))
+}
+
+// splitTokens 近似的分词：中日韩字符每字一个 token，其余连续字符每 4 个一个 token
+// Mock 平台按此切分流式数据块，使输出块数与估算的用量一致
+func splitTokens(text string) []string {
+	var tokens []string
+	start, count := -1, 0
+	for i, r := range text {
+		if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+			if start >= 0 {
+				tokens = append(tokens, text[start:i])
+				start, count = -1, 0
+			}
+			tokens = append(tokens, string(r))
+			continue
+		}
+
+		if start < 0 {
+			start = i
+		}
+		count++
+		if count == 4 {
+			tokens = append(tokens, text[start:i+utf8.RuneLen(r)])
+			start, count = -1, 0
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, text[start:])
+	}
+	return tokens
+}
+
+// estimateUsage 按请求消息和回复内容估算用量
+func estimateUsage(messages []openai.ChatCompletionMessage, reply string) models.Usage {
+	var prompt int
+	for _, msg := range messages {
+		prompt += estimateTokens(msg.Content)
+		for _, part := range msg.MultiContent {
+			prompt += estimateTokens(part.Text)
+		}
+	}
+	completion := estimateTokens(reply)
+
+	return models.Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	// 按字节截断时避免切断多字节字符
+	for maxLen > 0 && !utf8.RuneStart(s[maxLen]) {
+		maxLen--
+	}
+	return s[:maxLen]
+}
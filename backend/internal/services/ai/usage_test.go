@@ -0,0 +1,109 @@
+package ai
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, estimateTokens(""))
+	assert.Equal(t, 2, estimateTokens("你好"))
+	assert.Equal(t, 3, estimateTokens("hello world"))
+	assert.Equal(t, 3, estimateTokens("你好 hi!"))
+}
+
+func TestQuotaStatus(t *testing.T) {
+	reset := time.Now()
+	assert.Equal(t, int64(-1), quotaStatus(0, 100, reset).Remaining)
+	assert.Equal(t, int64(900), quotaStatus(1000, 100, reset).Remaining)
+	assert.Equal(t, int64(0), quotaStatus(1000, 1500, reset).Remaining)
+}
+
+func TestChatCompletionStream_HidesUsageUnlessRequested(t *testing.T) {
+	var captured map[string]any
+	server := newTestServer(t, captureHandler(t, &captured, func(w http.ResponseWriter, stream bool) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[{"index":0,"delta":{"content":"hi"},"finish_reason":"stop"}]}`+"\n\n")
+		fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","model":"stub-model","choices":[],"usage":{"prompt_tokens":3,"completion_tokens":1,"total_tokens":4}}`+"\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	s := newCaptureService(server)
+
+	req := stubRequest(true)
+	var buf bytes.Buffer
+	require.NoError(t, s.ChatCompletionStream(context.Background(), 0, PlatformSilicon, req, &buf))
+
+	// 上游始终返回用量，客户端未要求时不转发
+	assert.Equal(t, map[string]any{"include_usage": true}, captured["stream_options"])
+	assert.NotContains(t, buf.String(), `"usage"`)
+	assert.Contains(t, buf.String(), `"content":"hi"`)
+}
+
+func TestAIService_Quota(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "quotauser_" + timestamp,
+			Email:    "quotauser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		cfg := *testutil.TestConfig
+		cfg.SiliconAPIKey, cfg.OpenRouterAPIKey, cfg.DashscopeAPIKey = "", "", ""
+		s := NewAIService(&cfg, nil, nil)
+		defer s.DB.Where("user_id = ?", user.ID).Delete(&models.AIUsageLog{})
+
+		original := config.AIRoleQuotas[models.RoleUser]
+		config.AIRoleQuotas[models.RoleUser] = config.AIQuota{Daily: 50, Monthly: 1000}
+		defer func() {
+			config.AIRoleQuotas[models.RoleUser] = original
+		}()
+
+		quota, err := s.GetQuota(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RoleUser, quota.Role)
+		assert.Equal(t, int64(50), quota.Daily.Remaining)
+
+		// mock 平台的调用同样记录流水
+		_, err = s.Generate(context.Background(), user.ID, models.GenerateRequest{Prompt: "你好"})
+		require.NoError(t, err)
+
+		var logs []models.AIUsageLog
+		require.NoError(t, s.DB.Where("user_id = ?", user.ID).Find(&logs).Error)
+		require.Len(t, logs, 1)
+		assert.Equal(t, EndpointChat, logs[0].Endpoint)
+		assert.Equal(t, string(PlatformMock), logs[0].Platform)
+		assert.Equal(t, "success", logs[0].Status)
+		assert.Positive(t, logs[0].TotalTokens)
+
+		// 超出当日配额后拒绝请求
+		require.NoError(t, s.DB.Create(&models.AIUsageLog{UserID: user.ID, TotalTokens: 100, CreatedAt: time.Now()}).Error)
+		err = s.CheckQuota(user.ID)
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		_, err = s.ChatCompletion(context.Background(), user.ID, "", stubRequest(false))
+		assert.ErrorIs(t, err, ErrQuotaExceeded)
+
+		quota, err = s.GetQuota(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), quota.Daily.Remaining)
+		assert.Positive(t, quota.Monthly.Remaining)
+	})
+}
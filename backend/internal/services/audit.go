@@ -0,0 +1,26 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RecordAudit 写入审计记录，失败只记录日志，不影响业务
+func RecordAudit(db *gorm.DB, entry models.AuditLog) {
+	entry.UserAgent = truncateRunes(entry.UserAgent, 255)
+	entry.Detail = truncateRunes(entry.Detail, 255)
+
+	if err := db.Create(&entry).Error; err != nil {
+		logrus.WithError(err).WithField("action", entry.Action).Error("Failed to record audit log")
+	}
+}
+
+func truncateRunes(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	return string([]rune(s)[:maxLen])
+}
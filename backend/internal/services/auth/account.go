@@ -0,0 +1,113 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDeletionScheduled    = errors.New("账号已申请注销")
+	ErrDeletionNotScheduled = errors.New("账号未申请注销")
+	ErrReauthRequired       = errors.New("请重新登录后再申请注销")
+)
+
+// ScheduleAccountDeletion 用户申请注销账号，需要重新确认身份
+// 全部设备立即退出登录，宽限期内重新登录可以撤销，到期后由定时任务删除账号及全部数据
+func (s *AuthService) ScheduleAccountDeletion(ctx context.Context, userID uint64, sessionID string, req models.AccountDeletionRequest, client models.ClientInfo) (time.Time, error) {
+	var user models.User
+	if err := s.DB.Select("id", "password", "password_generated", "deletion_scheduled_at").First(&user, userID).Error; err != nil {
+		return time.Time{}, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, ErrDeletionScheduled
+	}
+	if err := s.reauthenticate(ctx, &user, sessionID, req, client); err != nil {
+		return time.Time{}, err
+	}
+
+	scheduledAt := time.Now().Add(config.AccountDeletionGracePeriod)
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// 最后一个管理员不能注销，避免到期删除后无人能再管理权限
+		if err := services.EnsureOtherAdministrator(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&user).Update("deletion_scheduled_at", scheduledAt).Error
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	services.RecordAudit(s.DB, models.AuditLog{
+		Action:    models.AuditAccountDeletion,
+		UserID:    userID,
+		ActorID:   userID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Detail:    "scheduled at " + scheduledAt.Format(time.RFC3339),
+	})
+
+	if err := s.LogoutAll(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+	return scheduledAt, nil
+}
+
+// reauthenticate 注销前重新确认身份，设置过密码的账号验证密码
+// 第三方登录创建的账号不知道密码，启用了两步验证时验证验证码，否则要求当前会话是刚刚登录的
+func (s *AuthService) reauthenticate(ctx context.Context, user *models.User, sessionID string, req models.AccountDeletionRequest, client models.ClientInfo) error {
+	if !user.PasswordGenerated {
+		ok, _, err := services.VerifyPassword(user.Password, req.Password)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrPasswordIncorrect
+		}
+		return nil
+	}
+
+	if req.Code != "" {
+		return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return s.verifyTwoFactorCode(tx, user.ID, req.Code, false, client)
+		})
+	}
+
+	loginAt, err := s.sessionCreatedAt(ctx, sessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return ErrReauthRequired
+	}
+	if err != nil {
+		return err
+	}
+	if time.Since(loginAt) > config.AccountReauthMaxAge {
+		return ErrReauthRequired
+	}
+	return nil
+}
+
+// CancelAccountDeletion 宽限期内撤销注销
+func (s *AuthService) CancelAccountDeletion(userID uint64, client models.ClientInfo) error {
+	result := s.DB.Model(&models.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Update("deletion_scheduled_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDeletionNotScheduled
+	}
+
+	services.RecordAudit(s.DB, models.AuditLog{
+		Action:    models.AuditDeletionCancel,
+		UserID:    userID,
+		ActorID:   userID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	})
+	return nil
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_AccountDeletion(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+		s := NewAuthService(testutil.TestConfig)
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "deleteuser_" + timestamp,
+			Email:    "deleteuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+			testutil.TestDB.Where("user_id = ?", user.ID).Delete(&models.AuditLog{})
+		}()
+
+		_, tokens, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, "", models.AccountDeletionRequest{Password: "Wrong123"}, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrPasswordIncorrect)
+		assert.ErrorIs(t, s.CancelAccountDeletion(user.ID, models.ClientInfo{}), ErrDeletionNotScheduled)
+
+		// 申请后全部设备退出登录
+		scheduledAt, err := s.ScheduleAccountDeletion(ctx, user.ID, "", models.AccountDeletionRequest{Password: "Password123"}, models.ClientInfo{})
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(config.AccountDeletionGracePeriod), scheduledAt, 5*time.Second)
+		_, err = s.ValidateToken(ctx, tokens.Token)
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, "", models.AccountDeletionRequest{Password: "Password123"}, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrDeletionScheduled)
+
+		// 宽限期内可以登录并撤销
+		_, _, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		require.NoError(t, s.CancelAccountDeletion(user.ID, models.ClientInfo{}))
+		found, err := userService.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.Nil(t, found.DeletionScheduledAt)
+	})
+}
+
+func TestAuthService_AccountDeletionWithoutPassword(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+		s := NewAuthService(testutil.TestConfig)
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "socialdelete_" + timestamp,
+			Email:    "socialdelete_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+			testutil.TestDB.Where("user_id = ?", user.ID).Delete(&models.AuditLog{})
+		}()
+		// 模拟第三方登录创建的账号
+		require.NoError(t, testutil.TestDB.Model(user).Update("password_generated", true).Error)
+
+		tokens, err := s.issueTokens(ctx, user.ID, "", false, models.ClientInfo{})
+		require.NoError(t, err)
+		claims, err := s.ValidateToken(ctx, tokens.Token)
+		require.NoError(t, err)
+
+		// 不验证随机生成的密码，没有会话或会话登录已久时需要重新登录
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, "", models.AccountDeletionRequest{Password: "Password123"}, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrReauthRequired)
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, claims.SessionID, models.AccountDeletionRequest{Code: "123456"}, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorNotEnabled)
+		loginAt := time.Now().Add(-config.AccountReauthMaxAge - time.Minute).Unix()
+		require.NoError(t, s.Redis.HSet(ctx, sessionKey(claims.SessionID), "created_at", loginAt).Err())
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, claims.SessionID, models.AccountDeletionRequest{}, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrReauthRequired)
+
+		// 刚登录的会话可以直接申请
+		require.NoError(t, s.Redis.HSet(ctx, sessionKey(claims.SessionID), "created_at", time.Now().Unix()).Err())
+		_, err = s.ScheduleAccountDeletion(ctx, user.ID, claims.SessionID, models.AccountDeletionRequest{}, models.ClientInfo{})
+		require.NoError(t, err)
+
+		// 重置密码后恢复为验证密码
+		require.NoError(t, userService.ResetPassword(strconv.FormatUint(user.ID, 10), "Password456"))
+		found, err := userService.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.False(t, found.PasswordGenerated)
+	})
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("API Key 不存在")
+	ErrAPIKeyInvalid  = errors.New("API Key 无效")
+	ErrAPIKeyLimit    = errors.New("API Key 数量已达上限")
+)
+
+// 明文中用于展示的前缀长度（含 sk-）
+const apiKeyDisplayLen = 10
+
+// IsAPIKey 判断 Bearer 凭证是否为 API Key
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, models.APIKeyPrefix)
+}
+
+// hashAPIKey API Key 本身是高熵随机串，用 SHA-256 即可，便于按哈希直接查找
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateAPIKey() (string, error) {
+	buf := make([]byte, config.APIKeyRandomByteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return models.APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CreateAPIKey 为用户生成新的 API Key，明文只在返回值中出现一次
+func (s *AuthService) CreateAPIKey(userID uint64, req models.APIKeyCreateRequest) (*models.APIKeyCreateResponse, error) {
+	var count int64
+	if err := s.DB.Model(&models.APIKey{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count >= int64(config.APIKeyMaxPerUser) {
+		return nil, fmt.Errorf("%w（%d）", ErrAPIKeyLimit, config.APIKeyMaxPerUser)
+	}
+
+	key, err := generateAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey := models.APIKey{
+		UserID:  userID,
+		Name:    req.Name,
+		Prefix:  key[:apiKeyDisplayLen],
+		KeyHash: hashAPIKey(key),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := s.DB.Create(&apiKey).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyCreateResponse{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys 获取用户的全部 API Key
+func (s *AuthService) ListAPIKeys(userID uint64) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := s.DB.Where("user_id = ?", userID).Order("id DESC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAPIKey 吊销（删除）用户的 API Key
+func (s *AuthService) RevokeAPIKey(userID uint64, keyID uint64) error {
+	result := s.DB.Where("id = ? AND user_id = ?", keyID, userID).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// ValidateAPIKey 校验 API Key，返回所属用户ID
+func (s *AuthService) ValidateAPIKey(key string) (uint64, error) {
+	if !IsAPIKey(key) {
+		return 0, ErrAPIKeyInvalid
+	}
+
+	var apiKey models.APIKey
+	err := s.DB.Preload("User").Where("key_hash = ?", hashAPIKey(key)).First(&apiKey).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, ErrAPIKeyInvalid
+		}
+		return 0, err
+	}
+
+	now := time.Now()
+	if apiKey.ExpiresAt != nil && now.After(*apiKey.ExpiresAt) {
+		return 0, ErrAPIKeyInvalid
+	}
+	if !apiKey.User.IsActive {
+		return 0, errors.New("用户已被禁用")
+	}
+
+	// 最近使用时间只做展示，按间隔更新即可
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) > config.APIKeyTouchInterval {
+		if err := s.DB.Model(&apiKey).Update("last_used_at", now).Error; err != nil {
+			logrus.WithError(err).WithField("api_key_id", apiKey.ID).Warn("Failed to update API key last used time")
+		}
+	}
+
+	return apiKey.UserID, nil
+}
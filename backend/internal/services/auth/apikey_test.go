@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, err := generateAPIKey()
+	require.NoError(t, err)
+	assert.True(t, IsAPIKey(key))
+	assert.Greater(t, len(key), 40)
+
+	other, err := generateAPIKey()
+	require.NoError(t, err)
+	assert.NotEqual(t, key, other)
+	assert.Len(t, hashAPIKey(key), 64)
+	assert.NotEqual(t, hashAPIKey(key), hashAPIKey(other))
+}
+
+func TestAuthService_APIKeys(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "apikeyuser_" + timestamp,
+			Email:    "apikeyuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+
+		created, err := s.CreateAPIKey(user.ID, models.APIKeyCreateRequest{Name: "sdk"})
+		require.NoError(t, err)
+		defer func() {
+			_ = s.RevokeAPIKey(user.ID, created.ID)
+		}()
+		assert.True(t, IsAPIKey(created.Key))
+		assert.Equal(t, created.Key[:apiKeyDisplayLen], created.Prefix)
+
+		// 只保存哈希
+		var stored models.APIKey
+		require.NoError(t, s.DB.First(&stored, created.ID).Error)
+		assert.Equal(t, hashAPIKey(created.Key), stored.KeyHash)
+		assert.NotContains(t, stored.KeyHash, created.Key)
+
+		userID, err := s.ValidateAPIKey(created.Key)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+
+		_, err = s.ValidateAPIKey(created.Key + "x")
+		assert.ErrorIs(t, err, ErrAPIKeyInvalid)
+
+		keys, err := s.ListAPIKeys(user.ID)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.NotNil(t, keys[0].LastUsedAt)
+
+		// 其他用户不能吊销
+		assert.ErrorIs(t, s.RevokeAPIKey(user.ID+1, created.ID), ErrAPIKeyNotFound)
+
+		require.NoError(t, s.RevokeAPIKey(user.ID, created.ID))
+		_, err = s.ValidateAPIKey(created.Key)
+		assert.ErrorIs(t, err, ErrAPIKeyInvalid)
+
+		// 过期的 Key 无效
+		expired, err := s.CreateAPIKey(user.ID, models.APIKeyCreateRequest{Name: "expired", ExpiresInDays: 1})
+		require.NoError(t, err)
+		defer func() {
+			_ = s.RevokeAPIKey(user.ID, expired.ID)
+		}()
+		require.NoError(t, s.DB.Model(&models.APIKey{}).Where("id = ?", expired.ID).
+			Update("expires_at", time.Now().Add(-time.Hour)).Error)
+		_, err = s.ValidateAPIKey(expired.Key)
+		assert.ErrorIs(t, err, ErrAPIKeyInvalid)
+	})
+}
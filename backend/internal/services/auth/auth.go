@@ -3,70 +3,87 @@ package auth
 import (
 	"ai-models-backend/internal/models"
 	"ai-models-backend/internal/services"
+	"context"
 	"errors"
+	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
-// Login 用户登录认证
-func (s *AuthService) Login(username, password string) (*models.User, string, error) {
-	var user models.User
+// Login 用户登录认证，每次登录创建一个新会话
+// 失败时只返回 ErrInvalidCredentials，连续失败后按账号和 IP 延迟或锁定，返回 LoginThrottledError
+// 用户启用了两步验证时返回 TwoFactorChallengeError，需要再调用 LoginTwoFactor
+func (s *AuthService) Login(ctx context.Context, username, password string, client models.ClientInfo) (*models.User, *models.AuthTokens, error) {
+	var user *models.User
 
 	// 根据用户名或邮箱查找用户
-	if err := s.DB.Where("username = ? OR email = ?", username, username).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", errors.New("用户不存在")
-		}
-		return nil, "", err
+	var found models.User
+	err := s.DB.Where("username = ? OR email = ?", username, username).First(&found).Error
+	if err == nil {
+		user = &found
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
 	}
 
-	// 检查用户是否激活
-	if !user.IsActive {
-		return nil, "", errors.New("用户已被禁用")
+	scopes := loginScopes(user, username, client)
+	if err := s.checkLoginAllowed(ctx, scopes); err != nil {
+		return nil, nil, err
 	}
 
-	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		return nil, "", errors.New("密码错误")
+	// 用户不存在时同样校验一次密码，响应时间与密码错误一致
+	encoded := dummyPasswordHash()
+	if user != nil {
+		encoded = user.Password
+	}
+	ok, needsRehash, err := services.VerifyPassword(encoded, password)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// 生成token
-	token, err := s.GenerateToken(user.ID)
+	// 禁用状态在密码正确后才检查，同样不暴露给调用方
+	if user == nil || !ok || !user.IsActive {
+		s.recordLoginFailure(ctx, scopes, user, client)
+		return nil, nil, ErrInvalidCredentials
+	}
+	s.clearLoginFailures(ctx, scopes[0])
+
+	// 哈希算法或参数已过时，趁有明文时升级，失败不影响登录
+	if needsRehash {
+		s.rehashPassword(user, password)
+	}
+
+	// 启用了两步验证时返回 TwoFactorChallengeError，验证码通过后才签发 token
+	tokens, err := s.completeLogin(ctx, user, client)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return &user, token, nil
+	return user, tokens, nil
 }
 
 // Register 用户注册
-func (s *AuthService) Register(req models.UserCreateRequest) (*models.User, string, error) {
+func (s *AuthService) Register(ctx context.Context, req models.UserCreateRequest, client models.ClientInfo) (*models.User, *models.AuthTokens, error) {
 	// 调用用户服务创建用户
 	userService := services.NewUserService(s.config)
 	user, err := userService.CreateUser(req)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
+	}
+
+	// 发送验证邮件失败不影响注册，用户可以稍后重新发送
+	if err := s.sendVerificationEmail(ctx, user); err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to send verification email")
 	}
 
 	// 生成token
-	token, err := s.GenerateToken(user.ID)
+	tokens, err := s.issueTokens(ctx, user.ID, "", false, client)
 	if err != nil {
-		return nil, "", err
+		return nil, nil, err
 	}
 
-	return user, token, nil
-}
-
-// Logout 退出登录 (将token添加到黑名单)
-func (s *AuthService) Logout(tokenString string) {
-	// 获取token的过期时间
-	claims, err := s.ValidateToken(tokenString)
-	if err == nil && claims != nil {
-		expiry := claims.ExpiresAt.Time
-		blacklist.Add(tokenString, expiry)
-	}
+	return user, tokens, nil
 }
 
 // CreateDefaultAdmin 创建默认管理员用户
@@ -91,20 +108,26 @@ func (s *AuthService) CreateDefaultAdmin() error {
 		return errors.New("无法获取默认管理员账号配置")
 	}
 
+	// 默认管理员沿用数据库密码，不强制密码策略，只做提醒
+	if err := services.ValidatePassword(password, username, email); err != nil {
+		logrus.Warnf("默认管理员密码不符合密码策略，请登录后修改: %v", err)
+	}
+
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	hashedPassword, err := services.HashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	// 创建默认管理员用户
+	// 创建默认管理员用户，本地邮箱无法接收验证邮件，直接视为已验证
+	now := time.Now()
 	admin := &models.User{
-		Username:      username,
-		Email:         email,
-		Password:      string(hashedPassword),
-		PlainPassword: password, // 根据配置决定是否存储
-		Role:          models.RoleAdmin,
-		IsActive:      true,
+		Username:        username,
+		Email:           email,
+		Password:        hashedPassword,
+		Role:            models.RoleAdmin,
+		IsActive:        true,
+		EmailVerifiedAt: &now,
 	}
 
 	// 保存到数据库
@@ -115,3 +138,39 @@ func (s *AuthService) CreateDefaultAdmin() error {
 	logrus.Infof("默认管理员用户已创建: %s", username)
 	return nil
 }
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// dummyPasswordHash 用户不存在时用于校验的哈希，按当前算法生成一次
+func dummyPasswordHash() string {
+	dummyHashOnce.Do(func() {
+		hashed, err := services.HashPassword("dummy-password-for-timing")
+		if err != nil {
+			logrus.WithError(err).Error("Failed to create dummy password hash")
+		}
+		dummyHash = hashed
+	})
+	return dummyHash
+}
+
+// rehashPassword 用当前配置的算法重新哈希密码
+// 只在哈希未被并发修改（如同时修改密码）时更新
+func (s *AuthService) rehashPassword(user *models.User, password string) {
+	hashed, err := services.HashPassword(password)
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Warn("Failed to rehash password")
+		return
+	}
+
+	err = s.DB.Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Update("password", hashed).Error
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Warn("Failed to save rehashed password")
+		return
+	}
+	user.Password = hashed
+}
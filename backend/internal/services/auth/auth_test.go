@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthService_LoginRehash(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "rehashuser_" + timestamp,
+			Email:    "rehashuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		// 模拟早期保存的 bcrypt 哈希
+		legacy, err := bcrypt.GenerateFromPassword([]byte("Password123"), bcrypt.DefaultCost)
+		require.NoError(t, err)
+		require.NoError(t, testutil.TestDB.Model(user).Update("password", string(legacy)).Error)
+
+		s := NewAuthService(testutil.TestConfig)
+
+		// 密码错误时不升级
+		_, _, err = s.Login(ctx, user.Username, "Password124", models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		stored, err := userService.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, string(legacy), stored.Password)
+
+		_, _, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		stored, err = userService.GetUserByID(user.ID)
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(stored.Password, "$argon2id$"))
+
+		// 升级后仍可用原密码登录
+		_, _, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+	})
+}
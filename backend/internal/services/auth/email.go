@@ -0,0 +1,304 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/mail"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmailTokenInvalid    = errors.New("链接无效或已过期")
+	ErrEmailAlreadyVerified = errors.New("邮箱已验证")
+	ErrMailTooFrequent      = errors.New("发送过于频繁，请稍后再试")
+)
+
+// 邮件链接令牌的用途，不同用途的令牌不能混用
+const (
+	emailTokenVerify = "verify_email"
+	emailTokenReset  = "reset_password"
+)
+
+// emailTokenPayload 邮件链接令牌的内容
+//
+// 令牌格式为 base64url(payload).base64url(HMAC-SHA256)，签名时额外混入用户的当前状态：
+// 验证邮箱混入邮箱和验证状态，重置密码混入密码哈希。令牌使用后状态随之改变，签名不再匹配，
+// 因此无需在服务端保存令牌即可保证只能使用一次，修改邮箱或密码也会让未使用的令牌失效
+type emailTokenPayload struct {
+	Purpose   string `json:"p"`
+	UserID    uint64 `json:"u"`
+	ExpiresAt int64  `json:"e"`
+}
+
+// emailTokenState 签名时混入的用户状态
+func emailTokenState(purpose string, user *models.User) string {
+	switch purpose {
+	case emailTokenVerify:
+		return user.Email + "|" + strconv.FormatBool(user.IsEmailVerified())
+	case emailTokenReset:
+		return user.Password
+	}
+	return ""
+}
+
+func (s *AuthService) emailTokenSecret() ([]byte, error) {
+	secret := s.config.EmailTokenSecret
+	if secret == "" {
+		secret = s.config.JWTSecret
+	}
+	if secret == "" {
+		return nil, errors.New("email token secret is not configured")
+	}
+	return []byte(secret), nil
+}
+
+func (s *AuthService) emailTokenMAC(payload []byte, state string) ([]byte, error) {
+	secret, err := s.emailTokenSecret()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	mac.Write([]byte{0})
+	mac.Write([]byte(state))
+	return mac.Sum(nil), nil
+}
+
+// signEmailToken 为用户签发邮件链接令牌
+func (s *AuthService) signEmailToken(purpose string, user *models.User, ttl time.Duration) (string, error) {
+	payload, err := json.Marshal(emailTokenPayload{
+		Purpose:   purpose,
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sig, err := s.emailTokenMAC(payload, emailTokenState(purpose, user))
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// verifyEmailToken 校验令牌并返回对应的用户，令牌无效、过期或已使用时返回 ErrEmailTokenInvalid
+func (s *AuthService) verifyEmailToken(purpose, token string) (*models.User, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrEmailTokenInvalid
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	var claims emailTokenPayload
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, ErrEmailTokenInvalid
+	}
+	if claims.Purpose != purpose || time.Now().Unix() > claims.ExpiresAt {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	var user models.User
+	if err := s.DB.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailTokenInvalid
+		}
+		return nil, err
+	}
+
+	expected, err := s.emailTokenMAC(payload, emailTokenState(purpose, &user))
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(sig, expected) {
+		return nil, ErrEmailTokenInvalid
+	}
+	return &user, nil
+}
+
+// SendVerificationEmail 重新发送邮箱验证邮件
+func (s *AuthService) SendVerificationEmail(ctx context.Context, userID uint64) error {
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
+		return err
+	}
+	if user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(ctx, &user)
+}
+
+func (s *AuthService) sendVerificationEmail(ctx context.Context, user *models.User) error {
+	if !s.acquireMailCooldown(ctx, emailTokenVerify, strconv.FormatUint(user.ID, 10), config.EmailResendInterval) {
+		return ErrMailTooFrequent
+	}
+
+	token, err := s.signEmailToken(emailTokenVerify, user, config.EmailVerifyTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	return s.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "请验证你的邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请打开以下链接完成邮箱验证，链接 %s 内有效：\n\n%s\n\n如果这不是你的操作，请忽略本邮件。\n",
+			user.Username, formatTTL(config.EmailVerifyTokenTTL), s.appLink("/verify-email", token)),
+	})
+}
+
+// VerifyEmail 使用邮件中的令牌完成邮箱验证
+func (s *AuthService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	user, err := s.verifyEmailToken(emailTokenVerify, token)
+	if err != nil {
+		return nil, err
+	}
+
+	// 条件更新，并发使用同一令牌时只有一次成功
+	now := time.Now()
+	result := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND email = ? AND email_verified_at IS NULL", user.ID, user.Email).
+		Update("email_verified_at", now)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrEmailTokenInvalid
+	}
+
+	user.EmailVerifiedAt = &now
+	return user, nil
+}
+
+// RequestPasswordReset 发送重置密码邮件
+// 邮箱未注册、用户已禁用或请求过于频繁时同样返回成功，不暴露邮箱是否存在
+func (s *AuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	var user models.User
+	if err := s.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+	if !s.acquireMailCooldown(ctx, emailTokenReset, strconv.FormatUint(user.ID, 10), config.PasswordResetInterval) {
+		return nil
+	}
+
+	token, err := s.signEmailToken(emailTokenReset, &user, config.PasswordResetTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	err = s.sendMail(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "重置密码",
+		Body: fmt.Sprintf("%s，你好：\n\n请打开以下链接重置密码，链接 %s 内有效，只能使用一次：\n\n%s\n\n如果这不是你的操作，请忽略本邮件，你的密码不会改变。\n",
+			user.Username, formatTTL(config.PasswordResetTokenTTL), s.appLink("/reset-password", token)),
+	})
+	if err != nil {
+		// 发送失败也不能让调用方区分邮箱是否存在
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to send password reset email")
+	}
+	return nil
+}
+
+// ResetPassword 使用邮件中的令牌重置密码，成功后退出所有设备
+func (s *AuthService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	user, err := s.verifyEmailToken(emailTokenReset, token)
+	if err != nil {
+		return err
+	}
+
+	if err := services.ValidatePassword(newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+	hashed, err := services.HashPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	// 能收到邮件说明邮箱属于该用户，顺便完成验证
+	updates := map[string]any{"password": hashed, "password_generated": false}
+	if !user.IsEmailVerified() {
+		updates["email_verified_at"] = time.Now()
+	}
+
+	// 条件更新，密码已被修改（令牌已使用）时不生效
+	result := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND password = ?", user.ID, user.Password).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrEmailTokenInvalid
+	}
+
+	// 密码可能已泄露，吊销全部登录
+	if err := s.LogoutAll(ctx, user.ID); err != nil {
+		logrus.WithError(err).WithField("user_id", user.ID).Error("Failed to logout sessions after password reset")
+	}
+	return nil
+}
+
+// acquireMailCooldown 限制同一用户的发信频率，没有 Redis 时不限制
+func (s *AuthService) acquireMailCooldown(ctx context.Context, purpose, id string, interval time.Duration) bool {
+	if s.Redis == nil {
+		return true
+	}
+	ok, err := s.Redis.SetNX(ctx, "auth:mail_cooldown:"+purpose+":"+id, 1, interval).Result()
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to check mail cooldown")
+		return true
+	}
+	return ok
+}
+
+func (s *AuthService) sendMail(ctx context.Context, msg mail.Message) error {
+	if s.mailer == nil {
+		return errors.New("mailer is not configured")
+	}
+	ctx, cancel := context.WithTimeout(ctx, config.MailSendTimeout)
+	defer cancel()
+	return s.mailer.Send(ctx, msg)
+}
+
+// appLink 生成前端页面链接
+func (s *AuthService) appLink(path, token string) string {
+	return strings.TrimRight(s.config.AppBaseURL, "/") + path + "?token=" + url.QueryEscape(token)
+}
+
+// formatTTL 以小时或分钟显示有效期
+func formatTTL(ttl time.Duration) string {
+	if ttl >= time.Hour && ttl%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", int(ttl.Hours()))
+	}
+	return fmt.Sprintf("%d 分钟", int(ttl.Minutes()))
+}
@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/mail"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mailTokenPattern = regexp.MustCompile(`token=(\S+)`)
+
+// lastMailToken 读取邮件目录中最新一封邮件里的令牌
+func lastMailToken(t *testing.T, dir string) string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[len(entries)-1].Name()))
+	require.NoError(t, err)
+	match := mailTokenPattern.FindStringSubmatch(string(data))
+	require.NotNil(t, match, "邮件中没有令牌")
+
+	token, err := url.QueryUnescape(match[1])
+	require.NoError(t, err)
+	return token
+}
+
+func TestEmailToken_Rejected(t *testing.T) {
+	s := &AuthService{config: &config.Config{JWTSecret: "secret"}}
+	user := &models.User{BaseModel: models.BaseModel{ID: 7}, Email: "a@example.com", Password: "hash"}
+
+	token, err := s.signEmailToken(emailTokenReset, user, time.Minute)
+	require.NoError(t, err)
+
+	// 用途不同的令牌不能混用，格式错误或过期的令牌在查询用户之前就被拒绝
+	_, err = s.verifyEmailToken(emailTokenVerify, token)
+	assert.ErrorIs(t, err, ErrEmailTokenInvalid)
+
+	expired, err := s.signEmailToken(emailTokenReset, user, -time.Minute)
+	require.NoError(t, err)
+	_, err = s.verifyEmailToken(emailTokenReset, expired)
+	assert.ErrorIs(t, err, ErrEmailTokenInvalid)
+
+	for _, bad := range []string{"", "no-dot", "!!!.???", base64.RawURLEncoding.EncodeToString([]byte("{")) + ".sig"} {
+		_, err = s.verifyEmailToken(emailTokenReset, bad)
+		assert.ErrorIs(t, err, ErrEmailTokenInvalid, bad)
+	}
+
+	// 签名混入了用户状态，状态改变后签名不同
+	payload, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	require.NoError(t, err)
+	var claims emailTokenPayload
+	require.NoError(t, json.Unmarshal(raw, &claims))
+	assert.Equal(t, uint64(7), claims.UserID)
+
+	before, err := s.emailTokenMAC(raw, emailTokenState(emailTokenReset, user))
+	require.NoError(t, err)
+	user.Password = "new-hash"
+	after, err := s.emailTokenMAC(raw, emailTokenState(emailTokenReset, user))
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	// 未配置密钥时不能签发
+	_, err = (&AuthService{config: &config.Config{}}).signEmailToken(emailTokenReset, user, time.Minute)
+	assert.Error(t, err)
+}
+
+func TestAuthService_VerifyEmail(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		s := NewAuthService(testutil.TestConfig)
+		mailDir := t.TempDir()
+		s.mailer = mail.NewFileMailer(mailDir, "no-reply@example.com")
+
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, _, err := s.Register(ctx, models.UserCreateRequest{
+			Username: "verifyuser_" + timestamp,
+			Email:    "verifyuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		}, models.ClientInfo{})
+		require.NoError(t, err)
+		userService := services.NewUserService(testutil.TestConfig)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+		assert.False(t, user.IsEmailVerified())
+
+		// 注册时已发送验证邮件，立即重新发送会被限流
+		token := lastMailToken(t, mailDir)
+		assert.ErrorIs(t, s.SendVerificationEmail(ctx, user.ID), ErrMailTooFrequent)
+
+		verified, err := s.VerifyEmail(ctx, token)
+		require.NoError(t, err)
+		assert.True(t, verified.IsEmailVerified())
+		ok, err := userService.IsEmailVerified(user.ID)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		// 令牌只能使用一次
+		_, err = s.VerifyEmail(ctx, token)
+		assert.ErrorIs(t, err, ErrEmailTokenInvalid)
+		assert.ErrorIs(t, s.SendVerificationEmail(ctx, user.ID), ErrEmailAlreadyVerified)
+
+		// 修改邮箱后需要重新验证
+		updated, err := userService.UpdateUser(user.ID, models.UserUpdateRequest{Email: "verifyuser2_" + timestamp + "@example.com"})
+		require.NoError(t, err)
+		assert.False(t, updated.IsEmailVerified())
+	})
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "resetuser_" + timestamp,
+			Email:    "resetuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+		mailDir := t.TempDir()
+		s.mailer = mail.NewFileMailer(mailDir, "no-reply@example.com")
+
+		_, session, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+
+		// 未注册的邮箱同样返回成功，但不发送邮件
+		require.NoError(t, s.RequestPasswordReset(ctx, "nobody_"+timestamp+"@example.com"))
+		entries, _ := os.ReadDir(mailDir)
+		assert.Empty(t, entries)
+
+		require.NoError(t, s.RequestPasswordReset(ctx, user.Email))
+		token := lastMailToken(t, mailDir)
+
+		// 不符合密码策略时令牌不会被消耗
+		assert.ErrorIs(t, s.ResetPassword(ctx, token, "weak"), services.ErrWeakPassword)
+		require.NoError(t, s.ResetPassword(ctx, token, "NewPassword456"))
+
+		// 令牌只能使用一次，旧登录全部失效，邮箱视为已验证
+		assert.ErrorIs(t, s.ResetPassword(ctx, token, "OtherPassword789"), ErrEmailTokenInvalid)
+		_, err = s.ValidateToken(ctx, session.Token)
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+
+		_, _, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		loggedIn, _, err := s.Login(ctx, user.Username, "NewPassword456", models.ClientInfo{})
+		require.NoError(t, err)
+		assert.True(t, loggedIn.IsEmailVerified())
+	})
+}
@@ -1,7 +1,8 @@
 package auth
 
 import (
-	"errors"
+	"ai-models-backend/internal/models"
+	"context"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -10,34 +11,50 @@ import (
 // JWTClaims JWT 声明
 // NOTE: 之类不需要设置 json tag，jwt内部使用 golang-jwt/jwt 转换为 user_id
 type JWTClaims struct {
-	UserID uint64
+	UserID    uint64
+	SessionID string // 所属登录会话，会话吊销后该会话签发的 token 全部失效
+	TwoFactor bool   // 该会话登录时通过了两步验证
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT token
-func (s *AuthService) GenerateToken(userID uint64) (string, error) {
+// GenerateToken 生成JWT access token
+func (s *AuthService) GenerateToken(userID uint64, sessionID string) (string, time.Time, error) {
+	return s.generateToken(userID, sessionID, false)
+}
+
+func (s *AuthService) generateToken(userID uint64, sessionID string, twoFactor bool) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.config.JWTExpiration)
 	claims := JWTClaims{
-		UserID: userID,
+		UserID:    userID,
+		SessionID: sessionID,
+		TwoFactor: twoFactor,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(s.config.JWTExpiration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "ai-models-backend",
 		},
 	}
 
+	if s.keys != nil {
+		signed, err := s.keys.sign(claims)
+		return signed, expiresAt, err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.getJWTSecret()))
+	signed, err := token.SignedString([]byte(s.getJWTSecret()))
+	return signed, expiresAt, err
 }
 
-// ValidateToken 验证JWT token
-func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
-	// 检查token是否在黑名单中
-	if blacklist.IsBlacklisted(tokenString) {
-		return nil, errors.New("token已失效")
-	}
-
+// ValidateToken 验证JWT token，并检查是否已被吊销
+// 配置了非对称密钥后只接受密钥集合签发的 token，切换时旧的 HS256 token 失效，客户端用 refresh token 换新即可
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (any, error) {
+		if s.keys != nil {
+			return s.keys.verificationKey(token)
+		}
+
 		// 方法类型必须是 HMAC
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid
@@ -50,22 +67,23 @@ func (s *AuthService) ValidateToken(tokenString string) (*JWTClaims, error) {
 	}
 
 	// token必须是指定的Claims类型
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrTokenMalformed
 	}
 
-	return nil, jwt.ErrTokenMalformed
+	if err := s.checkRevoked(ctx, claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
 }
 
-// RefreshToken 刷新token
-func (s *AuthService) RefreshToken(oldToken string) (string, error) {
-	claims, err := s.ValidateToken(oldToken)
-	if err != nil {
-		return "", err
+// JWKS 发布的验证公钥，未配置非对称密钥时为空集合
+func (s *AuthService) JWKS() models.JWKSet {
+	if s.keys == nil {
+		return models.JWKSet{Keys: []models.JWK{}}
 	}
-
-	// 生成新的token
-	return s.GenerateToken(claims.UserID)
+	return s.keys.JWKS()
 }
 
 // getJWTSecret 获取JWT密钥
@@ -0,0 +1,191 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 密钥文件后缀：私钥可签名也可验证，公钥只用于验证（轮换下来的旧密钥）
+const (
+	privateKeySuffix = ".pem"
+	publicKeySuffix  = ".pub.pem"
+)
+
+// signingKey 一个 JWT 密钥，kid 取自文件名
+type signingKey struct {
+	ID      string
+	Method  jwt.SigningMethod
+	Private crypto.Signer // 只有公钥时为空
+	Public  crypto.PublicKey
+}
+
+// KeySet JWT 密钥集合，用一个密钥签名，所有密钥都可以验证
+//
+// 轮换流程：放入新私钥并重启，新公钥通过 JWKS 发布；其他服务刷新 JWKS 后把 JWT_SIGNING_KID
+// 切到新密钥；旧 token 全部过期后删除旧私钥（或只保留 .pub.pem）
+type KeySet struct {
+	signing *signingKey
+	keys    map[string]*signingKey
+}
+
+// LoadKeySet 加载目录下的全部密钥，支持 RSA（RS256）和 Ed25519（EdDSA）
+// 文件名即 kid：<kid>.pem 为 PKCS#8/PKCS#1 私钥，<kid>.pub.pem 为 PKIX 公钥
+// signingKID 为空时使用文件名排序最后的私钥，按日期命名即可自动选用最新的密钥
+func LoadKeySet(dir string, signingKID string) (*KeySet, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := &KeySet{keys: make(map[string]*signingKey)}
+	var privateIDs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, privateKeySuffix) {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		kid := strings.TrimSuffix(strings.TrimSuffix(name, publicKeySuffix), privateKeySuffix)
+		key, err := parseKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: %w", name, err)
+		}
+
+		// 同一 kid 同时有私钥和公钥时以私钥为准
+		if existing, ok := ks.keys[kid]; ok && existing.Private != nil {
+			continue
+		}
+		ks.keys[kid] = key
+		if key.Private != nil {
+			privateIDs = append(privateIDs, kid)
+		}
+	}
+
+	if len(privateIDs) == 0 {
+		return nil, fmt.Errorf("no jwt private key in %s", dir)
+	}
+
+	if signingKID == "" {
+		sort.Strings(privateIDs)
+		signingKID = privateIDs[len(privateIDs)-1]
+	}
+	signing, ok := ks.keys[signingKID]
+	if !ok || signing.Private == nil {
+		return nil, fmt.Errorf("jwt signing key %s not found in %s", signingKID, dir)
+	}
+	ks.signing = signing
+
+	return ks, nil
+}
+
+// parseKey 解析 PEM 格式的私钥或公钥
+func parseKey(kid string, data []byte) (*signingKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &signingKey{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.Private, key.Public = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.Public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("unsupported key type %T, only RSA and Ed25519 are supported", parsed)
+	}
+	return key, nil
+}
+
+// SigningKeyID 当前签名密钥的 kid
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.ID
+}
+
+// sign 用当前签名密钥签发 token，header 中带上 kid
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.signing.Method, claims)
+	token.Header["kid"] = ks.signing.ID
+	return token.SignedString(ks.signing.Private)
+}
+
+// verificationKey 按 kid 查找验证密钥，算法必须与密钥一致
+func (ks *KeySet) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kid %q", jwt.ErrTokenUnverifiable, kid)
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.Public, nil
+}
+
+// JWKS 以 JWK Set 格式导出全部公钥
+func (ks *KeySet) JWKS() models.JWKSet {
+	set := models.JWKSet{Keys: make([]models.JWK, 0, len(ks.keys))}
+	for _, key := range ks.keys {
+		jwk := models.JWK{
+			Kid: key.ID,
+			Use: "sig",
+			Alg: key.Method.Alg(),
+		}
+		switch pub := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	// 输出顺序固定，便于缓存和比对
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+	return set
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, dir, name, pemType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: pemType, Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func writeRSAKey(t *testing.T, dir, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	writePEM(t, dir, kid+privateKeySuffix, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	return key
+}
+
+func writeEd25519Key(t *testing.T, dir, kid string) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, dir, kid+privateKeySuffix, "PRIVATE KEY", der)
+	return key
+}
+
+func newKeyedService(t *testing.T, dir, signingKID string) *AuthService {
+	keys, err := LoadKeySet(dir, signingKID)
+	require.NoError(t, err)
+	return &AuthService{
+		config: &config.Config{JWTSecret: "secret", JWTExpiration: time.Minute},
+		keys:   keys,
+	}
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2026-01-rsa")
+	writeEd25519Key(t, dir, "2026-06-ed25519")
+	ctx := context.Background()
+
+	// 默认使用文件名排序最后的私钥
+	s := newKeyedService(t, dir, "")
+	assert.Equal(t, "2026-06-ed25519", s.keys.SigningKeyID())
+
+	token, _, err := s.GenerateToken(7, "session")
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", parsed.Method.Alg())
+	assert.Equal(t, "2026-06-ed25519", parsed.Header["kid"])
+
+	claims, err := s.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), claims.UserID)
+
+	// 指定签名密钥
+	rs := newKeyedService(t, dir, "2026-01-rsa")
+	token, _, err = rs.GenerateToken(7, "session")
+	require.NoError(t, err)
+	parsed, _, err = jwt.NewParser().ParseUnverified(token, &JWTClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+
+	// 同一密钥集合的任意密钥签发的 token 都能验证
+	_, err = s.ValidateToken(ctx, token)
+	require.NoError(t, err)
+
+	// HS256 token 即使带上已知的 kid 也不接受
+	hs := jwt.NewWithClaims(jwt.SigningMethodHS256, JWTClaims{UserID: 7, SessionID: "session"})
+	hs.Header["kid"] = "2026-01-rsa"
+	hsToken, err := hs.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = s.ValidateToken(ctx, hsToken)
+	assert.ErrorIs(t, err, jwt.ErrSignatureInvalid)
+
+	// 未知 kid
+	other := t.TempDir()
+	writeRSAKey(t, other, "unknown")
+	token, _, err = newKeyedService(t, other, "").GenerateToken(7, "session")
+	require.NoError(t, err)
+	_, err = s.ValidateToken(ctx, token)
+	assert.ErrorIs(t, err, jwt.ErrTokenUnverifiable)
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	dir := t.TempDir()
+	oldKey := writeRSAKey(t, dir, "2026-01")
+	ctx := context.Background()
+
+	oldToken, _, err := newKeyedService(t, dir, "").GenerateToken(7, "session")
+	require.NoError(t, err)
+
+	// 新密钥上线，旧私钥只保留公钥
+	writeEd25519Key(t, dir, "2026-02")
+	require.NoError(t, os.Remove(filepath.Join(dir, "2026-01"+privateKeySuffix)))
+	der, err := x509.MarshalPKIXPublicKey(&oldKey.PublicKey)
+	require.NoError(t, err)
+	writePEM(t, dir, "2026-01"+publicKeySuffix, "PUBLIC KEY", der)
+
+	s := newKeyedService(t, dir, "")
+	assert.Equal(t, "2026-02", s.keys.SigningKeyID())
+	_, err = s.ValidateToken(ctx, oldToken)
+	require.NoError(t, err)
+
+	// 只有公钥的密钥不能用于签名
+	_, err = LoadKeySet(dir, "2026-01")
+	assert.ErrorContains(t, err, "signing key 2026-01 not found")
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey := writeRSAKey(t, dir, "a-rsa")
+	edKey := writeEd25519Key(t, dir, "b-ed25519")
+
+	s := newKeyedService(t, dir, "a-rsa")
+	set := s.JWKS()
+	require.Len(t, set.Keys, 2)
+
+	rsaJWK := set.Keys[0]
+	assert.Equal(t, "a-rsa", rsaJWK.Kid)
+	assert.Equal(t, "RSA", rsaJWK.Kty)
+	assert.Equal(t, "RS256", rsaJWK.Alg)
+	assert.Equal(t, "sig", rsaJWK.Use)
+	assert.Equal(t, "AQAB", rsaJWK.E)
+
+	edJWK := set.Keys[1]
+	assert.Equal(t, "OKP", edJWK.Kty)
+	assert.Equal(t, "Ed25519", edJWK.Crv)
+	assert.Equal(t, "EdDSA", edJWK.Alg)
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(edKey.Public().(ed25519.PublicKey)), edJWK.X)
+
+	// 其他服务只凭 JWKS 即可验证 token
+	n, err := base64.RawURLEncoding.DecodeString(rsaJWK.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(rsaJWK.E)
+	require.NoError(t, err)
+	pub := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+	assert.Equal(t, rsaKey.PublicKey.N, pub.N)
+
+	token, _, err := s.GenerateToken(7, "session")
+	require.NoError(t, err)
+	parsed, err := jwt.ParseWithClaims(token, &JWTClaims{}, func(token *jwt.Token) (any, error) {
+		return pub, nil
+	}, jwt.WithValidMethods([]string{rsaJWK.Alg}))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), parsed.Claims.(*JWTClaims).UserID)
+
+	// 未配置非对称密钥时为空集合
+	hs := &AuthService{config: &config.Config{JWTSecret: "secret"}}
+	assert.NotNil(t, hs.JWKS().Keys)
+	assert.Empty(t, hs.JWKS().Keys)
+}
+
+func TestLoadKeySet_Invalid(t *testing.T) {
+	_, err := LoadKeySet(t.TempDir(), "")
+	assert.ErrorContains(t, err, "no jwt private key")
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("not a key"), 0o600))
+	_, err = LoadKeySet(dir, "")
+	assert.ErrorContains(t, err, "invalid PEM data")
+
+	_, err = LoadKeySet(filepath.Join(dir, "missing"), "")
+	assert.Error(t, err)
+}
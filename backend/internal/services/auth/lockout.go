@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrInvalidCredentials 用户不存在、密码错误和用户被禁用都返回同一个错误，避免枚举用户名
+	ErrInvalidCredentials = errors.New("用户名或密码错误")
+	ErrLoginThrottled     = errors.New("登录尝试过于频繁")
+)
+
+// LoginThrottledError 登录被延迟或锁定，RetryAfter 后才能重试
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	seconds := int(math.Ceil(e.RetryAfter.Seconds()))
+	if seconds < 60 {
+		return fmt.Sprintf("%s，请 %d 秒后再试", ErrLoginThrottled.Error(), seconds)
+	}
+	return fmt.Sprintf("%s，请 %d 分钟后再试", ErrLoginThrottled.Error(), (seconds+59)/60)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// loginScope 一个失败计数的维度：账号或 IP
+//
+//	auth:login_fail:<scope>:<id>  连续失败次数，统计窗口内没有新的失败则过期
+//	auth:login_wait:<scope>:<id>  渐进延迟，存在期间拒绝登录
+//	auth:login_lock:<scope>:<id>  临时锁定，存在期间拒绝登录
+type loginScope struct {
+	name       string
+	id         string
+	delayAfter int
+	lockAfter  int
+}
+
+func (l loginScope) key(kind string) string {
+	return "auth:login_" + kind + ":" + l.name + ":" + l.id
+}
+
+// accountScope 按账号统计；用户不存在时按输入的用户名统计，行为与存在的账号一致，无法借此判断用户是否存在
+func accountScope(user *models.User, identifier string) loginScope {
+	id := "name:" + strings.ToLower(identifier)
+	if user != nil {
+		id = strconv.FormatUint(user.ID, 10)
+	}
+	return loginScope{name: "account", id: id, delayAfter: config.LoginAccountDelayAfter, lockAfter: config.LoginAccountLockAfter}
+}
+
+func ipScope(ip string) loginScope {
+	return loginScope{name: "ip", id: ip, delayAfter: config.LoginIPDelayAfter, lockAfter: config.LoginIPLockAfter}
+}
+
+// loginScopes 本次登录涉及的全部维度，没有 IP 时只按账号统计
+func loginScopes(user *models.User, identifier string, client models.ClientInfo) []loginScope {
+	scopes := []loginScope{accountScope(user, identifier)}
+	if client.IP != "" {
+		scopes = append(scopes, ipScope(client.IP))
+	}
+	return scopes
+}
+
+// loginDelay 第 failures 次失败后的等待时间，未达到阈值时为 0
+func loginDelay(failures, delayAfter int) time.Duration {
+	if failures < delayAfter {
+		return 0
+	}
+	delay := config.LoginDelayBase
+	for i := delayAfter; i < failures && delay < config.LoginDelayMax; i++ {
+		delay *= 2
+	}
+	return min(delay, config.LoginDelayMax)
+}
+
+// checkLoginAllowed 账号或 IP 处于延迟或锁定期间时返回 LoginThrottledError，没有 Redis 时不限制
+func (s *AuthService) checkLoginAllowed(ctx context.Context, scopes []loginScope) error {
+	if s.Redis == nil {
+		return nil
+	}
+
+	var retryAfter time.Duration
+	for _, scope := range scopes {
+		for _, kind := range []string{"lock", "wait"} {
+			ttl, err := s.Redis.PTTL(ctx, scope.key(kind)).Result()
+			if err != nil {
+				return err
+			}
+			retryAfter = max(retryAfter, ttl)
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordLoginFailure 累计失败次数，达到阈值后设置延迟或锁定，锁定时写入审计记录
+func (s *AuthService) recordLoginFailure(ctx context.Context, scopes []loginScope, user *models.User, client models.ClientInfo) {
+	if s.Redis == nil {
+		return
+	}
+
+	for _, scope := range scopes {
+		var incr *redis.IntCmd
+		_, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			incr = pipe.Incr(ctx, scope.key("fail"))
+			pipe.Expire(ctx, scope.key("fail"), config.LoginFailureWindow)
+			return nil
+		})
+		if err != nil {
+			logrus.WithError(err).Warn("Failed to record login failure")
+			continue
+		}
+		failures := int(incr.Val())
+
+		if failures >= scope.lockAfter {
+			// 锁定后重新计数，解锁后再次达到阈值才会再锁定
+			_, err := s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, scope.key("lock"), failures, config.LoginLockDuration)
+				pipe.Del(ctx, scope.key("fail"), scope.key("wait"))
+				return nil
+			})
+			if err != nil {
+				logrus.WithError(err).Warn("Failed to lock login")
+				continue
+			}
+
+			entry := models.AuditLog{
+				Action:    models.AuditLoginLockout,
+				IP:        client.IP,
+				UserAgent: client.UserAgent,
+				Detail:    fmt.Sprintf("%s %s locked for %s after %d failed attempts", scope.name, scope.id, config.LoginLockDuration, failures),
+			}
+			if user != nil && scope.name == "account" {
+				entry.UserID = user.ID
+			}
+			services.RecordAudit(s.DB, entry)
+			logrus.WithFields(logrus.Fields{"scope": scope.name, "id": scope.id, "ip": client.IP}).Warn("Login locked after repeated failures")
+			continue
+		}
+
+		if delay := loginDelay(failures, scope.delayAfter); delay > 0 {
+			if err := s.Redis.Set(ctx, scope.key("wait"), failures, delay).Err(); err != nil {
+				logrus.WithError(err).Warn("Failed to set login delay")
+			}
+		}
+	}
+}
+
+// clearLoginFailures 登录成功后清除账号的失败记录
+// IP 的计数不清除，否则攻击者可以穿插登录自己的账号来重置计数
+func (s *AuthService) clearLoginFailures(ctx context.Context, scope loginScope) {
+	if s.Redis == nil {
+		return
+	}
+	if err := s.Redis.Del(ctx, scope.key("fail"), scope.key("wait")).Err(); err != nil {
+		logrus.WithError(err).Warn("Failed to clear login failures")
+	}
+}
+
+// UnlockAccount 管理员解除账号的登录锁定和失败计数，同时清除按用户名和邮箱统计的记录
+func (s *AuthService) UnlockAccount(ctx context.Context, userID uint64, actor models.ClientInfo, actorID uint64) error {
+	if s.Redis == nil {
+		return ErrTokenStoreUnavailable
+	}
+
+	var user models.User
+	if err := s.DB.Select("id", "username", "email").First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	var keys []string
+	for _, scope := range []loginScope{
+		accountScope(&user, ""),
+		accountScope(nil, user.Username),
+		accountScope(nil, user.Email),
+	} {
+		keys = append(keys, scope.key("fail"), scope.key("wait"), scope.key("lock"))
+	}
+	if err := s.Redis.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+
+	services.RecordAudit(s.DB, models.AuditLog{
+		Action:    models.AuditAccountUnlock,
+		UserID:    user.ID,
+		ActorID:   actorID,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+	})
+	return nil
+}
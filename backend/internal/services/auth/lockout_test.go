@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), loginDelay(2, 3))
+	assert.Equal(t, config.LoginDelayBase, loginDelay(3, 3))
+	assert.Equal(t, 2*config.LoginDelayBase, loginDelay(4, 3))
+	assert.Equal(t, 4*config.LoginDelayBase, loginDelay(5, 3))
+	assert.Equal(t, config.LoginDelayMax, loginDelay(100, 3))
+
+	err := error(&LoginThrottledError{RetryAfter: 1500 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrLoginThrottled)
+	assert.Contains(t, err.Error(), "2 秒")
+	assert.Contains(t, (&LoginThrottledError{RetryAfter: 61 * time.Second}).Error(), "2 分钟")
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		// 缩短阈值和延迟，便于测试
+		oldDelayBase, oldAccountDelay, oldAccountLock := config.LoginDelayBase, config.LoginAccountDelayAfter, config.LoginAccountLockAfter
+		config.LoginDelayBase, config.LoginAccountDelayAfter, config.LoginAccountLockAfter = 50*time.Millisecond, 2, 4
+		defer func() {
+			config.LoginDelayBase, config.LoginAccountDelayAfter, config.LoginAccountLockAfter = oldDelayBase, oldAccountDelay, oldAccountLock
+		}()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "lockuser_" + timestamp,
+			Email:    "lockuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+			testutil.TestDB.Where("user_id = ?", user.ID).Delete(&models.AuditLog{})
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+		client := models.ClientInfo{IP: "203.0.113." + strconv.Itoa(int(time.Now().UnixNano()%250)), UserAgent: "lockout-test"}
+
+		// 用户不存在与密码错误返回同一个错误
+		_, _, err = s.Login(ctx, "nobody_"+timestamp, "Password123", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		_, _, err = s.Login(ctx, user.Username, "WrongPassword1", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		// 第二次失败后开始延迟，延迟期间即使密码正确也拒绝
+		_, _, err = s.Login(ctx, user.Username, "WrongPassword1", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		_, _, err = s.Login(ctx, user.Username, "Password123", client)
+		var throttled *LoginThrottledError
+		require.True(t, errors.As(err, &throttled))
+		assert.LessOrEqual(t, throttled.RetryAfter, config.LoginDelayBase)
+
+		// 用邮箱登录同样计入该账号
+		time.Sleep(config.LoginDelayBase)
+		_, _, err = s.Login(ctx, user.Email, "WrongPassword1", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+		time.Sleep(2 * config.LoginDelayBase)
+		_, _, err = s.Login(ctx, user.Username, "WrongPassword1", client)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+		// 达到阈值后锁定并写入审计记录
+		_, _, err = s.Login(ctx, user.Username, "Password123", client)
+		require.True(t, errors.As(err, &throttled))
+		assert.InDelta(t, config.LoginLockDuration.Seconds(), throttled.RetryAfter.Seconds(), 5)
+
+		var audit models.AuditLog
+		require.NoError(t, testutil.TestDB.Where("user_id = ? AND action = ?", user.ID, models.AuditLoginLockout).First(&audit).Error)
+		assert.Equal(t, client.IP, audit.IP)
+		assert.Equal(t, "lockout-test", audit.UserAgent)
+
+		// 管理员解锁后可以正常登录
+		require.NoError(t, s.UnlockAccount(ctx, user.ID, models.ClientInfo{IP: "127.0.0.1"}, 1))
+		_, _, err = s.Login(ctx, user.Username, "Password123", client)
+		require.NoError(t, err)
+
+		var unlock models.AuditLog
+		require.NoError(t, testutil.TestDB.Where("user_id = ? AND action = ?", user.ID, models.AuditAccountUnlock).First(&unlock).Error)
+		assert.Equal(t, uint64(1), unlock.ActorID)
+
+		// 被禁用的用户即使密码正确也只返回统一的错误
+		require.NoError(t, userService.DeactivateUser(user.ID))
+		_, _, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
@@ -0,0 +1,105 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const GitHubProviderName = "github"
+
+// GitHubProvider GitHub OAuth App 登录，GitHub 不支持 OIDC，用户信息从 REST API 获取
+type GitHubProvider struct {
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	client       *http.Client
+
+	// 接口地址，测试时替换为本地服务
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+}
+
+func NewGitHubProvider(clientID, clientSecret, redirectURL string, client *http.Client) *GitHubProvider {
+	return &GitHubProvider{
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		client:       client,
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		APIURL:       "https://api.github.com",
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return GitHubProviderName
+}
+
+// AuthCodeURL GitHub 没有 nonce，state 和 PKCE 已能防止授权码被替换
+func (p *GitHubProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	params := url.Values{
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {"read:user user:email"},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+		"allow_signup":          {"true"},
+	}
+	return appendQuery(p.AuthURL, params), nil
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	token, err := exchangeCode(ctx, p.client, p.TokenURL, url.Values{
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code_verifier": {codeVerifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var user struct {
+		ID        int64  `json:"id"`
+		Login     string `json:"login"`
+		Name      string `json:"name"`
+		AvatarURL string `json:"avatar_url"`
+	}
+	if err := getJSON(ctx, p.client, p.APIURL+"/user", token.AccessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: missing github user id", ErrExchangeFailed)
+	}
+
+	// 公开资料中的邮箱未必经过验证，使用邮箱列表中已验证的主邮箱
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := getJSON(ctx, p.client, p.APIURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider:  GitHubProviderName,
+		Subject:   strconv.FormatInt(user.ID, 10),
+		Username:  user.Login,
+		Name:      user.Name,
+		AvatarURL: user.AvatarURL,
+	}
+	for _, email := range emails {
+		if email.Primary && email.Verified {
+			identity.Email, identity.EmailVerified = email.Email, true
+			break
+		}
+	}
+	return identity, nil
+}
@@ -0,0 +1,166 @@
+package oauth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authorize 生成授权地址并模拟用户在 issuer 登录，返回 code 和 verifier
+func authorize(t *testing.T, issuer *testutil.OIDCIssuer, provider Provider, nonce string, user testutil.OIDCUser) (string, string) {
+	verifier, err := RandomString(32)
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", nonce, CodeChallenge(verifier))
+	require.NoError(t, err)
+	code, state := issuer.Authorize(t, authURL, user)
+	assert.Equal(t, "state-1", state)
+	return code, verifier
+}
+
+func TestOIDCProvider_Exchange(t *testing.T) {
+	issuer := testutil.NewOIDCIssuer(t)
+	provider := NewOIDCProvider("corp", issuer.URL+"/", issuer.ClientID, issuer.ClientSecret, "http://app/oauth/callback/corp", issuer.Client())
+	ctx := context.Background()
+	user := testutil.OIDCUser{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, Name: "Alice", PreferredUsername: "alice"}
+
+	code, verifier := authorize(t, issuer, provider, "nonce-1", user)
+	identity, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{
+		Provider:      "corp",
+		Subject:       "u-1",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Username:      "alice",
+		Name:          "Alice",
+	}, identity)
+
+	// 授权码只能使用一次
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	assert.ErrorIs(t, err, ErrExchangeFailed)
+
+	// PKCE verifier 不匹配
+	code, _ = authorize(t, issuer, provider, "nonce-1", user)
+	_, err = provider.Exchange(ctx, code, "wrong-verifier", "nonce-1")
+	assert.ErrorIs(t, err, ErrExchangeFailed)
+
+	// nonce 不匹配，防止 id_token 重放
+	code, verifier = authorize(t, issuer, provider, "nonce-1", user)
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-2")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+
+	// 签发给其他客户端或其他 issuer 的 id_token
+	for name, modify := range map[string]func(jwt.MapClaims){
+		"audience": func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+		"issuer":   func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+		"expired":  func(claims jwt.MapClaims) { claims["exp"] = 1 },
+	} {
+		issuer.ModifyClaims = modify
+		code, verifier = authorize(t, issuer, provider, "nonce-1", user)
+		_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+		assert.ErrorIs(t, err, ErrInvalidIDToken, name)
+	}
+	issuer.ModifyClaims = nil
+
+	// id_token 不含邮箱时从 userinfo 获取
+	issuer.ModifyClaims = func(claims jwt.MapClaims) {
+		delete(claims, "email")
+		delete(claims, "email_verified")
+	}
+	code, verifier = authorize(t, issuer, provider, "nonce-1", user)
+	identity, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", identity.Email)
+	assert.True(t, identity.EmailVerified)
+	issuer.ModifyClaims = nil
+}
+
+func TestOIDCProvider_KeyRotation(t *testing.T) {
+	interval := config.OIDCJWKSRefreshInterval
+	defer func() { config.OIDCJWKSRefreshInterval = interval }()
+
+	issuer := testutil.NewOIDCIssuer(t)
+	provider := NewOIDCProvider("oidc", issuer.URL, issuer.ClientID, issuer.ClientSecret, "http://app/cb", issuer.Client())
+	ctx := context.Background()
+	user := testutil.OIDCUser{Subject: "u-1"}
+
+	code, verifier := authorize(t, issuer, provider, "n", user)
+	_, err := provider.Exchange(ctx, code, verifier, "n")
+	require.NoError(t, err)
+
+	// 刚拉取过 JWKS 时不会因为未知 kid 立即重新拉取
+	config.OIDCJWKSRefreshInterval = time.Hour
+	issuer.RotateKey(t)
+	code, verifier = authorize(t, issuer, provider, "n", user)
+	_, err = provider.Exchange(ctx, code, verifier, "n")
+	assert.ErrorIs(t, err, ErrInvalidIDToken)
+
+	// 超过间隔后遇到新的 kid 重新拉取
+	config.OIDCJWKSRefreshInterval = 0
+	code, verifier = authorize(t, issuer, provider, "n", user)
+	_, err = provider.Exchange(ctx, code, verifier, "n")
+	require.NoError(t, err)
+}
+
+func TestGitHubProvider_Exchange(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		w.Header().Set("Content-Type", "application/json")
+		// GitHub 出错时同样返回 200
+		if r.PostForm.Get("code") != "good-code" || r.PostForm.Get("code_verifier") != "verifier" {
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "gh-token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/api/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gh-token", r.Header.Get("Authorization"))
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 42, "login": "octocat", "name": "The Octocat"})
+	})
+	mux.HandleFunc("/api/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "public@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider := NewGitHubProvider("gh-client", "gh-secret", "http://app/oauth/callback/github", server.Client())
+	provider.AuthURL = server.URL + "/login/oauth/authorize"
+	provider.TokenURL = server.URL + "/login/oauth/access_token"
+	provider.APIURL = server.URL + "/api"
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "", CodeChallenge("verifier"))
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "state-1", parsed.Query().Get("state"))
+	assert.Equal(t, CodeChallenge("verifier"), parsed.Query().Get("code_challenge"))
+
+	identity, err := provider.Exchange(context.Background(), "good-code", "verifier", "")
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{
+		Provider:      GitHubProviderName,
+		Subject:       "42",
+		Email:         "octocat@example.com",
+		EmailVerified: true,
+		Username:      "octocat",
+		Name:          "The Octocat",
+	}, identity)
+
+	_, err = provider.Exchange(context.Background(), "bad-code", "verifier", "")
+	assert.ErrorIs(t, err, ErrExchangeFailed)
+}
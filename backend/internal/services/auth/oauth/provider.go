@@ -0,0 +1,149 @@
+package oauth
+
+import (
+	"ai-models-backend/internal/config"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var (
+	ErrExchangeFailed = errors.New("第三方登录失败")
+	ErrInvalidIDToken = errors.New("id_token 无效")
+)
+
+// Identity 第三方账号信息
+type Identity struct {
+	Provider      string
+	Subject       string // 第三方账号的唯一ID，不会变化
+	Email         string
+	EmailVerified bool
+	Username      string // 第三方的登录名，用于生成本地用户名
+	Name          string
+	AvatarURL     string
+}
+
+// Provider OAuth2 授权码模式的第三方登录
+type Provider interface {
+	Name() string
+	// AuthCodeURL 生成跳转到第三方授权页的地址，codeChallenge 为 PKCE S256 challenge
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	// Exchange 用授权码换取第三方账号信息
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+// NewProviders 按配置创建已启用的第三方登录，回调地址为 OAuthRedirectURL/<name>
+func NewProviders(cfg *config.Config) map[string]Provider {
+	providers := make(map[string]Provider)
+	client := &http.Client{Timeout: config.OAuthHTTPTimeout}
+	redirectURL := func(name string) string {
+		return strings.TrimRight(cfg.OAuthRedirectURL, "/") + "/" + name
+	}
+
+	if cfg.GitHubClientID != "" {
+		providers[GitHubProviderName] = NewGitHubProvider(cfg.GitHubClientID, cfg.GitHubClientSecret, redirectURL(GitHubProviderName), client)
+	}
+	if cfg.OIDCIssuer != "" {
+		name := cfg.OIDCName
+		if name == "" {
+			name = "oidc"
+		}
+		providers[name] = NewOIDCProvider(name, cfg.OIDCIssuer, cfg.OIDCClientID, cfg.OIDCClientSecret, redirectURL(name), client)
+	}
+	return providers
+}
+
+// RandomString 生成 URL 安全的随机字符串，用于 state、nonce 和 PKCE verifier
+func RandomString(byteLength int) (string, error) {
+	buf := make([]byte, byteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// CodeChallenge PKCE S256：base64url(sha256(verifier))
+func CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// tokenResponse 令牌接口的响应，OIDC 额外返回 id_token
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeCode 用授权码请求令牌接口，client_secret 按 client_secret_post 方式提交
+func exchangeCode(ctx context.Context, client *http.Client, tokenURL string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var token tokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return nil, err
+	}
+	// GitHub 出错时也返回 200，错误放在 error 字段
+	if token.Error != "" {
+		return nil, fmt.Errorf("%w: %s %s", ErrExchangeFailed, token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("%w: empty access token", ErrExchangeFailed)
+	}
+	return &token, nil
+}
+
+// getJSON 带上 access token 请求用户信息接口
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return doJSON(client, req, out)
+}
+
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrExchangeFailed, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s %s returned %d: %s", ErrExchangeFailed, req.Method, req.URL.Path, resp.StatusCode, truncate(string(body), 200))
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%w: invalid response from %s: %v", ErrExchangeFailed, req.URL.Path, err)
+	}
+	return nil
+}
+
+func truncate(s string, maxLen int) string {
+	if len(s) <= maxLen {
+		return s
+	}
+	return s[:maxLen]
+}
@@ -4,6 +4,10 @@ import (
 	"ai-models-backend/internal/config"
 	"ai-models-backend/internal/database"
 	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/auth/oauth"
+	"ai-models-backend/internal/services/mail"
+
+	"github.com/sirupsen/logrus"
 )
 
 /**
@@ -13,12 +17,31 @@ import (
 type AuthService struct {
 	services.BaseService
 	config *config.Config
+	keys   *KeySet     // 非对称签名密钥，为空时使用 HS256
+	mailer mail.Mailer // 发送邮箱验证和重置密码邮件
+
+	providers map[string]oauth.Provider // 已启用的第三方登录，按名称索引
 }
 
 // NewAuthService 创建认证服务实例
 func NewAuthService(cfg *config.Config) *AuthService {
-	return &AuthService{
-		BaseService: services.BaseService{DB: database.DB},
+	s := &AuthService{
+		BaseService: services.BaseService{DB: database.DB, Redis: database.Redis},
 		config:      cfg,
+		mailer:      mail.New(cfg),
+		providers:   oauth.NewProviders(cfg),
 	}
+
+	if cfg.JWTKeysDir != "" {
+		keys, err := LoadKeySet(cfg.JWTKeysDir, cfg.JWTSigningKID)
+		if err != nil {
+			logrus.Fatalf("加载JWT密钥失败: %v", err)
+		}
+		s.keys = keys
+		logrus.Infof("JWT 使用非对称签名，签名密钥: %s", keys.SigningKeyID())
+	} else if cfg.IsProd {
+		logrus.Warn("JWT_KEYS_DIR 未配置，使用 HS256 共享密钥签名，其他服务无法通过 JWKS 验证 token")
+	}
+
+	return s
 }
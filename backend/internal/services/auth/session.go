@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+var ErrSessionNotFound = errors.New("会话不存在或已失效")
+
+// touchSessionScript 一次往返完成吊销检查和最近活跃时间更新
+// 返回 1 表示会话已吊销；会话已不存在时不写入，避免重新创建没有过期时间的 key
+var touchSessionScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return 1
+end
+local last = tonumber(redis.call("HGET", KEYS[2], "last_seen"))
+if last and tonumber(ARGV[1]) - last >= tonumber(ARGV[2]) then
+	redis.call("HSET", KEYS[2], "last_seen", ARGV[1])
+end
+return 0
+`)
+
+// ListSessions 列出用户当前有效的登录会话，最近活跃的在前
+func (s *AuthService) ListSessions(ctx context.Context, userID uint64, currentSessionID string) ([]models.UserSession, error) {
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+
+	sessionIDs, err := s.Redis.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	cmds := make([]*redis.MapStringStringCmd, len(sessionIDs))
+	_, err = s.Redis.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, sessionID := range sessionIDs {
+			cmds[i] = pipe.HGetAll(ctx, sessionKey(sessionID))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]models.UserSession, 0, len(sessionIDs))
+	var expired []any
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		// refresh token 过期后会话随之过期，集合中的ID顺带清理
+		if len(fields) == 0 {
+			expired = append(expired, sessionIDs[i])
+			continue
+		}
+		sessions = append(sessions, models.UserSession{
+			ID:         sessionIDs[i],
+			Device:     fields["device"],
+			UserAgent:  fields["user_agent"],
+			IP:         fields["ip"],
+			TwoFactor:  fields["two_factor"] == "1",
+			Current:    sessionIDs[i] == currentSessionID,
+			CreatedAt:  unixField(fields["created_at"]),
+			LastSeenAt: unixField(fields["last_seen"]),
+		})
+	}
+	if len(expired) > 0 {
+		s.Redis.SRem(ctx, userSessionsKey(userID), expired...)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// RevokeSession 吊销用户自己的某个会话，该设备上的 token 立即失效
+func (s *AuthService) RevokeSession(ctx context.Context, userID uint64, sessionID string) error {
+	if s.Redis == nil {
+		return ErrTokenStoreUnavailable
+	}
+
+	owner, err := s.Redis.HGet(ctx, sessionKey(sessionID), "user_id").Uint64()
+	if errors.Is(err, redis.Nil) {
+		return ErrSessionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	// 其他用户的会话同样视为不存在
+	if owner != userID {
+		return ErrSessionNotFound
+	}
+	return s.revokeSession(ctx, sessionID)
+}
+
+// sessionCreatedAt 会话的登录时间，刷新 token 不会改变
+func (s *AuthService) sessionCreatedAt(ctx context.Context, sessionID string) (time.Time, error) {
+	if s.Redis == nil {
+		return time.Time{}, ErrTokenStoreUnavailable
+	}
+	if sessionID == "" {
+		return time.Time{}, ErrSessionNotFound
+	}
+
+	value, err := s.Redis.HGet(ctx, sessionKey(sessionID), "created_at").Result()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, ErrSessionNotFound
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return unixField(value), nil
+}
+
+func unixField(value string) time.Time {
+	sec, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
+
+// deviceName 从 User-Agent 识别浏览器和操作系统，仅用于在会话列表中展示
+func deviceName(userAgent string) string {
+	var browser, system string
+
+	// Edge、Opera 的 User-Agent 同时包含 Chrome 和 Safari，需要先判断
+	switch {
+	case strings.Contains(userAgent, "Edg/"), strings.Contains(userAgent, "EdgiOS/"), strings.Contains(userAgent, "EdgA/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Chrome/"), strings.Contains(userAgent, "CriOS/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Firefox/"), strings.Contains(userAgent, "FxiOS/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	// iOS 的 User-Agent 中也有 Mac OS X，Android 的也有 Linux
+	switch {
+	case strings.Contains(userAgent, "iPhone"):
+		system = "iOS"
+	case strings.Contains(userAgent, "iPad"):
+		system = "iPadOS"
+	case strings.Contains(userAgent, "Android"):
+		system = "Android"
+	case strings.Contains(userAgent, "Windows"):
+		system = "Windows"
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		system = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		system = "Linux"
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "未知设备"
+	}
+}
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceName(t *testing.T) {
+	for userAgent, expected := range map[string]string{
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36":                   "Chrome on macOS",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0":           "Edge on Windows",
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1": "Safari on iOS",
+		"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36":                   "Chrome on Android",
+		"Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0":                                                                  "Firefox on Linux",
+		"curl/8.4.0": "未知设备",
+		"":           "未知设备",
+	} {
+		assert.Equal(t, expected, deviceName(userAgent), userAgent)
+	}
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "sessionuser_" + timestamp,
+			Email:    "sessionuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+
+		laptopClient := models.ClientInfo{
+			IP:        "203.0.113.10",
+			UserAgent: "Mozilla/5.0 (X11; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		}
+		_, laptop, err := s.Login(ctx, user.Username, "Password123", laptopClient)
+		require.NoError(t, err)
+		_, phone, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{IP: "198.51.100.7"})
+		require.NoError(t, err)
+
+		laptopClaims, err := s.ValidateToken(ctx, laptop.Token)
+		require.NoError(t, err)
+		phoneClaims, err := s.ValidateToken(ctx, phone.Token)
+		require.NoError(t, err)
+
+		sessions, err := s.ListSessions(ctx, user.ID, laptopClaims.SessionID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 2)
+		byID := map[string]models.UserSession{}
+		for _, session := range sessions {
+			byID[session.ID] = session
+		}
+		current := byID[laptopClaims.SessionID]
+		assert.True(t, current.Current)
+		assert.Equal(t, "Firefox on Linux", current.Device)
+		assert.Equal(t, laptopClient.UserAgent, current.UserAgent)
+		assert.Equal(t, laptopClient.IP, current.IP)
+		assert.WithinDuration(t, time.Now(), current.CreatedAt, 5*time.Second)
+		assert.WithinDuration(t, time.Now(), current.LastSeenAt, 5*time.Second)
+		assert.False(t, byID[phoneClaims.SessionID].Current)
+
+		// 刷新后设备信息保留
+		_, err = s.Refresh(ctx, laptop.RefreshToken)
+		require.NoError(t, err)
+		sessions, err = s.ListSessions(ctx, user.ID, "")
+		require.NoError(t, err)
+		for _, session := range sessions {
+			if session.ID == laptopClaims.SessionID {
+				assert.Equal(t, laptopClient.IP, session.IP)
+				assert.Equal(t, "Firefox on Linux", session.Device)
+			}
+		}
+
+		// 不能吊销其他用户的会话
+		assert.ErrorIs(t, s.RevokeSession(ctx, user.ID+1, phoneClaims.SessionID), ErrSessionNotFound)
+		assert.ErrorIs(t, s.RevokeSession(ctx, user.ID, "missing"), ErrSessionNotFound)
+
+		// 吊销手机上的会话，笔记本不受影响
+		require.NoError(t, s.RevokeSession(ctx, user.ID, phoneClaims.SessionID))
+		_, err = s.ValidateToken(ctx, phone.Token)
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+		_, err = s.Refresh(ctx, phone.RefreshToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		_, err = s.ValidateToken(ctx, laptop.Token)
+		require.NoError(t, err)
+
+		sessions, err = s.ListSessions(ctx, user.ID, laptopClaims.SessionID)
+		require.NoError(t, err)
+		require.Len(t, sessions, 1)
+		assert.Equal(t, laptopClaims.SessionID, sessions[0].ID)
+	})
+}
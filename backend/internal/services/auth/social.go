@@ -0,0 +1,361 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/auth/oauth"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOAuthProviderUnknown = errors.New("不支持的第三方登录")
+	ErrOAuthStateInvalid    = errors.New("登录已过期，请重新发起")
+	ErrOAuthEmailConflict   = errors.New("该邮箱已注册，请先使用密码登录后再绑定第三方账号")
+	ErrOAuthEmailRequired   = errors.New("第三方账号未提供邮箱，无法创建账号")
+	ErrIdentityLinked       = errors.New("该第三方账号已绑定其他用户，或已绑定同一平台的其他账号")
+	ErrIdentityNotFound     = errors.New("未绑定该第三方账号")
+	ErrIdentityLastLogin    = errors.New("邮箱未验证，解绑后将无法登录，请先验证邮箱")
+)
+
+// oauthState 跳转第三方授权时保存的状态，回调时按 state 取出并删除，只能使用一次
+type oauthState struct {
+	Provider string `json:"provider"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"` // PKCE code verifier
+	UserID   uint64 `json:"user_id"`  // 绑定第三方账号的用户，登录时为 0
+}
+
+func oauthStateKey(state string) string {
+	return "auth:oauth_state:" + state
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// OAuthProviders 已启用的第三方登录名称
+func (s *AuthService) OAuthProviders() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OAuthAuthorize 生成第三方授权地址，userID 不为 0 时表示为该用户绑定第三方账号
+func (s *AuthService) OAuthAuthorize(ctx context.Context, providerName string, userID uint64) (*models.OAuthAuthorizeResponse, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderUnknown
+	}
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+
+	var values [3]string
+	for i := range values {
+		value, err := oauth.RandomString(32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	state := values[0]
+	data, err := json.Marshal(oauthState{Provider: providerName, Nonce: values[1], Verifier: values[2], UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, values[1], oauth.CodeChallenge(values[2]))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Redis.Set(ctx, oauthStateKey(state), data, config.OAuthStateTTL).Err(); err != nil {
+		return nil, err
+	}
+
+	return &models.OAuthAuthorizeResponse{URL: authURL, State: state}, nil
+}
+
+// OAuthLogin 第三方登录回调
+// 已绑定的第三方账号直接登录；第三方验证过的邮箱与本地已验证的邮箱一致时自动绑定；
+// 邮箱未注册时自动创建账号；邮箱已注册但无法确认属于同一人时返回 ErrOAuthEmailConflict
+// 与密码登录一样，启用了两步验证时返回 TwoFactorChallengeError
+func (s *AuthService) OAuthLogin(ctx context.Context, providerName string, req models.OAuthCallbackRequest, client models.ClientInfo) (*models.User, *models.AuthTokens, error) {
+	identity, err := s.exchangeOAuth(ctx, providerName, req, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.findOrProvisionUser(ctx, identity, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	tokens, err := s.completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return user, tokens, nil
+}
+
+// LinkIdentity 为已登录用户绑定第三方账号，state 必须由同一用户发起
+func (s *AuthService) LinkIdentity(ctx context.Context, userID uint64, providerName string, req models.OAuthCallbackRequest, client models.ClientInfo) (*models.UserIdentity, error) {
+	identity, err := s.exchangeOAuth(ctx, providerName, req, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var linked *models.UserIdentity
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIdentity
+		err := tx.Where("provider = ? AND (subject = ? OR user_id = ?)", identity.Provider, identity.Subject, userID).First(&existing).Error
+		if err == nil {
+			if existing.UserID == userID && existing.Subject == identity.Subject {
+				linked = &existing
+				return nil
+			}
+			return ErrIdentityLinked
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		linked, err = s.createIdentity(tx, userID, identity, client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return linked, nil
+}
+
+// ListIdentities 获取用户绑定的第三方账号
+func (s *AuthService) ListIdentities(userID uint64) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := s.DB.Where("user_id = ?", userID).Order("provider").Find(&identities).Error
+	return identities, err
+}
+
+// UnlinkIdentity 解绑第三方账号
+// 自动创建的账号没有用户知道的密码，邮箱未验证时无法通过重置密码登录，此时不能解绑最后一个第三方账号
+func (s *AuthService) UnlinkIdentity(ctx context.Context, userID uint64, providerName string, client models.ClientInfo) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Select("id", "email_verified_at").First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		var identities []models.UserIdentity
+		if err := tx.Where("user_id = ?", userID).Find(&identities).Error; err != nil {
+			return err
+		}
+		var target *models.UserIdentity
+		for i := range identities {
+			if identities[i].Provider == providerName {
+				target = &identities[i]
+			}
+		}
+		if target == nil {
+			return ErrIdentityNotFound
+		}
+		if len(identities) == 1 && !user.IsEmailVerified() {
+			return ErrIdentityLastLogin
+		}
+
+		if err := tx.Delete(target).Error; err != nil {
+			return err
+		}
+		services.RecordAudit(tx, models.AuditLog{
+			Action:    models.AuditIdentityUnlink,
+			UserID:    userID,
+			ActorID:   userID,
+			IP:        client.IP,
+			UserAgent: client.UserAgent,
+			Detail:    providerName,
+		})
+		return nil
+	})
+}
+
+// exchangeOAuth 校验并消费 state，用授权码换取第三方账号信息
+func (s *AuthService) exchangeOAuth(ctx context.Context, providerName string, req models.OAuthCallbackRequest, userID uint64) (*oauth.Identity, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrOAuthProviderUnknown
+	}
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+
+	data, err := s.Redis.GetDel(ctx, oauthStateKey(req.State)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrOAuthStateInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	var state oauthState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, ErrOAuthStateInvalid
+	}
+	// 登录发起的 state 不能用于绑定，反之亦然
+	if state.Provider != providerName || state.UserID != userID {
+		return nil, ErrOAuthStateInvalid
+	}
+
+	identity, err := provider.Exchange(ctx, req.Code, state.Verifier, state.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	identity.Email = strings.TrimSpace(identity.Email)
+	return identity, nil
+}
+
+// findOrProvisionUser 查找第三方账号绑定的用户，没有时按邮箱绑定或创建用户
+func (s *AuthService) findOrProvisionUser(ctx context.Context, identity *oauth.Identity, client models.ClientInfo) (*models.User, error) {
+	var user models.User
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+		if err == nil {
+			return tx.First(&user, existing.UserID).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if identity.Email == "" {
+			return ErrOAuthEmailRequired
+		}
+
+		err = tx.Where("LOWER(email) = LOWER(?)", identity.Email).First(&user).Error
+		if err == nil {
+			// 双方都验证过邮箱才能确认是同一个人，否则可能被抢注邮箱的账号接管
+			if !identity.EmailVerified || !user.IsEmailVerified() {
+				return ErrOAuthEmailConflict
+			}
+			if tx.Where("user_id = ? AND provider = ?", user.ID, identity.Provider).First(&models.UserIdentity{}).Error == nil {
+				// 该用户已绑定同一第三方的其他账号
+				return ErrOAuthEmailConflict
+			}
+			_, err = s.createIdentity(tx, user.ID, identity, client)
+			return err
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err := s.provisionUser(tx, identity, &user); err != nil {
+			return err
+		}
+		_, err = s.createIdentity(tx, user.ID, identity, client)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// provisionUser 为首次登录的第三方账号创建用户，密码随机生成并标记，用户可通过重置密码设置
+func (s *AuthService) provisionUser(tx *gorm.DB, identity *oauth.Identity, user *models.User) error {
+	username, err := uniqueUsername(tx, identity)
+	if err != nil {
+		return err
+	}
+
+	randomPassword, err := oauth.RandomString(32)
+	if err != nil {
+		return err
+	}
+	hashed, err := services.HashPassword(randomPassword)
+	if err != nil {
+		return err
+	}
+
+	*user = models.User{
+		Username:          username,
+		Email:             identity.Email,
+		Password:          hashed,
+		PasswordGenerated: true,
+		Avatar:            identity.AvatarURL,
+		Role:              models.RoleUser,
+		IsActive:          true,
+	}
+	if identity.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return tx.Create(user).Error
+}
+
+func (s *AuthService) createIdentity(tx *gorm.DB, userID uint64, identity *oauth.Identity, client models.ClientInfo) (*models.UserIdentity, error) {
+	record := &models.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := tx.Create(record).Error; err != nil {
+		return nil, err
+	}
+
+	services.RecordAudit(tx, models.AuditLog{
+		Action:    models.AuditIdentityLink,
+		UserID:    userID,
+		ActorID:   userID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Detail:    identity.Provider,
+	})
+	return record, nil
+}
+
+// uniqueUsername 由第三方登录名或邮箱前缀生成未被占用的用户名
+func uniqueUsername(tx *gorm.DB, identity *oauth.Identity) (string, error) {
+	base := identity.Username
+	if base == "" {
+		base, _, _ = strings.Cut(identity.Email, "@")
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(base, "_"), "_-")
+	if len(base) > 40 {
+		base = base[:40]
+	}
+	if len(base) < 3 {
+		base = "user" + base
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		suffix, err := oauth.RandomString(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + strings.ToLower(usernameInvalidChars.ReplaceAllString(suffix, ""))
+	}
+
+	logrus.WithField("username", base).Warn("Failed to find an available username for oauth user")
+	return "", fmt.Errorf("用户名 %s 已被占用", base)
+}
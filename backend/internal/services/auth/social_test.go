@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/services/auth/oauth"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_OAuthLogin(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		issuer := testutil.NewOIDCIssuer(t)
+		s := NewAuthService(testutil.TestConfig)
+		s.providers = map[string]oauth.Provider{
+			"oidc": oauth.NewOIDCProvider("oidc", issuer.URL, issuer.ClientID, issuer.ClientSecret, "http://app/oauth/callback/oidc", issuer.Client()),
+		}
+		assert.Equal(t, []string{"oidc"}, s.OAuthProviders())
+
+		// 模拟浏览器跳转到 issuer 登录后回调
+		callback := func(userID uint64, user testutil.OIDCUser) models.OAuthCallbackRequest {
+			authorize, err := s.OAuthAuthorize(ctx, "oidc", userID)
+			require.NoError(t, err)
+			code, state := issuer.Authorize(t, authorize.URL, user)
+			assert.Equal(t, authorize.State, state)
+			return models.OAuthCallbackRequest{Code: code, State: state}
+		}
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		var cleanup []uint64
+		defer func() {
+			for _, id := range cleanup {
+				_ = userService.DeleteUser(id)
+				testutil.TestDB.Where("user_id = ?", id).Delete(&models.AuditLog{})
+			}
+		}()
+
+		_, err := s.OAuthAuthorize(ctx, "github", 0)
+		assert.ErrorIs(t, err, ErrOAuthProviderUnknown)
+
+		// 首次登录自动创建账号，第三方验证过的邮箱视为已验证
+		external := testutil.OIDCUser{
+			Subject:           "sub-" + timestamp,
+			Email:             "oidc_" + timestamp + "@example.com",
+			EmailVerified:     true,
+			PreferredUsername: "oidc user " + timestamp,
+		}
+		req := callback(0, external)
+		user, tokens, err := s.OAuthLogin(ctx, "oidc", req, models.ClientInfo{IP: "127.0.0.1"})
+		require.NoError(t, err)
+		cleanup = append(cleanup, user.ID)
+		assert.Equal(t, "oidc_user_"+timestamp, user.Username)
+		assert.Equal(t, external.Email, user.Email)
+		assert.True(t, user.IsEmailVerified())
+		assert.True(t, user.PasswordGenerated)
+		claims, err := s.ValidateToken(ctx, tokens.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+
+		// state 只能使用一次
+		_, _, err = s.OAuthLogin(ctx, "oidc", req, models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+
+		// 再次登录使用同一账号，即使第三方的邮箱已经改变
+		external.Email = "changed_" + timestamp + "@example.com"
+		again, _, err := s.OAuthLogin(ctx, "oidc", callback(0, external), models.ClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, again.ID)
+
+		// 本地邮箱未验证时不能自动绑定，避免抢注邮箱的账号被接管
+		local, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "oauthlocal_" + timestamp,
+			Email:    "oauthlocal_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		cleanup = append(cleanup, local.ID)
+		other := testutil.OIDCUser{Subject: "other-" + timestamp, Email: local.Email, EmailVerified: true}
+		_, _, err = s.OAuthLogin(ctx, "oidc", callback(0, other), models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrOAuthEmailConflict)
+
+		// 已登录用户主动绑定，登录发起的 state 不能用于绑定
+		_, err = s.LinkIdentity(ctx, local.ID, "oidc", callback(0, other), models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+		_, err = s.LinkIdentity(ctx, local.ID, "oidc", callback(local.ID, external), models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrIdentityLinked)
+		identity, err := s.LinkIdentity(ctx, local.ID, "oidc", callback(local.ID, other), models.ClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, local.ID, identity.UserID)
+
+		linked, _, err := s.OAuthLogin(ctx, "oidc", callback(0, other), models.ClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, local.ID, linked.ID)
+
+		identities, err := s.ListIdentities(local.ID)
+		require.NoError(t, err)
+		require.Len(t, identities, 1)
+
+		// 邮箱未验证时不能解绑唯一的第三方账号
+		assert.ErrorIs(t, s.UnlinkIdentity(ctx, local.ID, "oidc", models.ClientInfo{}), ErrIdentityLastLogin)
+		require.NoError(t, s.UnlinkIdentity(ctx, user.ID, "oidc", models.ClientInfo{}))
+		assert.ErrorIs(t, s.UnlinkIdentity(ctx, user.ID, "oidc", models.ClientInfo{}), ErrIdentityNotFound)
+
+		var audit models.AuditLog
+		require.NoError(t, testutil.TestDB.Where("user_id = ? AND action = ?", user.ID, models.AuditIdentityUnlink).First(&audit).Error)
+		assert.Equal(t, "oidc", audit.Detail)
+	})
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTokenRevoked          = errors.New("token已失效")
+	ErrRefreshTokenInvalid   = errors.New("refresh token 无效或已过期")
+	ErrRefreshTokenReused    = errors.New("refresh token 已被使用，该登录已失效，请重新登录")
+	ErrTokenStoreUnavailable = errors.New("Redis 未初始化，无法管理登录状态")
+)
+
+// 登录状态在 Redis 中的存储，每次登录是一个会话（设备）：
+//
+//	auth:session:<sid>          会话的用户ID、当前 refresh token 哈希、是否通过两步验证，以及登录设备和最近活跃时间，有效期同 refresh token
+//	auth:sessions:<uid>         用户的全部会话ID
+//	auth:refresh:<hash>         有效的 refresh token -> 会话ID
+//	auth:refresh_used:<hash>    已轮换的 refresh token -> 会话ID，再次出现说明 token 被盗用
+//	auth:revoked_session:<sid>  已吊销的会话，有效期同 access token，过期后该会话签发的 token 也都已过期
+func sessionKey(sessionID string) string {
+	return "auth:session:" + sessionID
+}
+
+func userSessionsKey(userID uint64) string {
+	return fmt.Sprintf("auth:sessions:%d", userID)
+}
+
+func refreshKey(hash string) string {
+	return "auth:refresh:" + hash
+}
+
+func usedRefreshKey(hash string) string {
+	return "auth:refresh_used:" + hash
+}
+
+func revokedSessionKey(sessionID string) string {
+	return "auth:revoked_session:" + sessionID
+}
+
+// hashToken refresh token 只保存哈希，Redis 泄露时无法直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomToken(byteLength int) (string, error) {
+	buf := make([]byte, byteLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// issueTokens 为会话签发新的 access token 和 refresh token，sessionID 为空时创建新会话
+// twoFactor 表示会话登录时通过了两步验证，刷新时沿用；client 只在创建会话时记录为登录设备
+func (s *AuthService) issueTokens(ctx context.Context, userID uint64, sessionID string, twoFactor bool, client models.ClientInfo) (*models.AuthTokens, error) {
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+
+	now := time.Now()
+	fields := []any{"user_id", userID, "two_factor", twoFactor, "last_seen", now.Unix()}
+	if sessionID == "" {
+		id, err := randomToken(config.SessionIDByteLength)
+		if err != nil {
+			return nil, err
+		}
+		sessionID = id
+		fields = append(fields,
+			"device", deviceName(client.UserAgent),
+			"user_agent", client.UserAgent,
+			"ip", client.IP,
+			"created_at", now.Unix(),
+		)
+	}
+
+	refreshToken, err := randomToken(config.RefreshTokenByteLength)
+	if err != nil {
+		return nil, err
+	}
+	hash := hashToken(refreshToken)
+	ttl := s.config.JWTRefreshExpiration
+
+	_, err = s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, refreshKey(hash), sessionID, ttl)
+		pipe.HSet(ctx, sessionKey(sessionID), append(fields, "refresh", hash)...)
+		pipe.Expire(ctx, sessionKey(sessionID), ttl)
+		pipe.SAdd(ctx, userSessionsKey(userID), sessionID)
+		pipe.Expire(ctx, userSessionsKey(userID), ttl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	token, expiresAt, err := s.generateToken(userID, sessionID, twoFactor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthTokens{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: now.Add(ttl),
+	}, nil
+}
+
+// Refresh 用 refresh token 换取新的令牌，旧的 refresh token 随即作废（轮换）
+// 已作废的 refresh token 再次出现说明被盗用，吊销整个会话
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error) {
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+
+	hash := hashToken(refreshToken)
+
+	// GETDEL 保证同一个 refresh token 只能成功使用一次
+	sessionID, err := s.Redis.GetDel(ctx, refreshKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, s.detectReuse(ctx, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Redis.Set(ctx, usedRefreshKey(hash), sessionID, s.config.JWTRefreshExpiration).Err(); err != nil {
+		return nil, err
+	}
+
+	session, err := s.Redis.HMGet(ctx, sessionKey(sessionID), "user_id", "two_factor").Result()
+	if err != nil {
+		return nil, err
+	}
+	rawUserID, _ := session[0].(string)
+	userID, err := strconv.ParseUint(rawUserID, 10, 64)
+	if err != nil {
+		return nil, ErrRefreshTokenInvalid
+	}
+	twoFactor, _ := session[1].(string)
+
+	// 用户被删除或禁用后不能继续刷新
+	var user models.User
+	if err := s.DB.Select("id", "is_active").First(&user, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Join(ErrRefreshTokenInvalid, s.revokeSession(ctx, sessionID))
+		}
+		return nil, err
+	}
+	if !user.IsActive {
+		if err := s.revokeSession(ctx, sessionID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("用户已被禁用")
+	}
+
+	return s.issueTokens(ctx, userID, sessionID, twoFactor == "1", models.ClientInfo{})
+}
+
+// detectReuse refresh token 不存在时，判断是过期还是已轮换后被重复使用
+func (s *AuthService) detectReuse(ctx context.Context, hash string) error {
+	sessionID, err := s.Redis.Get(ctx, usedRefreshKey(hash)).Result()
+	if errors.Is(err, redis.Nil) {
+		return ErrRefreshTokenInvalid
+	}
+	if err != nil {
+		return err
+	}
+
+	logrus.WithField("session_id", sessionID).Warn("Refresh token reused, revoking session")
+	if err := s.revokeSession(ctx, sessionID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout 退出当前会话，该会话的 access token 和 refresh token 立即失效
+func (s *AuthService) Logout(ctx context.Context, sessionID string) error {
+	if s.Redis == nil {
+		return ErrTokenStoreUnavailable
+	}
+	return s.revokeSession(ctx, sessionID)
+}
+
+// LogoutAll 退出用户的全部会话（所有设备）
+func (s *AuthService) LogoutAll(ctx context.Context, userID uint64) error {
+	if s.Redis == nil {
+		return ErrTokenStoreUnavailable
+	}
+
+	sessionIDs, err := s.Redis.SMembers(ctx, userSessionsKey(userID)).Result()
+	if err != nil {
+		return err
+	}
+	for _, sessionID := range sessionIDs {
+		if err := s.revokeSession(ctx, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// revokeSession 吊销会话：删除 refresh token，并在 access token 有效期内拒绝该会话的 token
+func (s *AuthService) revokeSession(ctx context.Context, sessionID string) error {
+	session, err := s.Redis.HGetAll(ctx, sessionKey(sessionID)).Result()
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, revokedSessionKey(sessionID), 1, s.config.JWTExpiration)
+		if hash := session["refresh"]; hash != "" {
+			pipe.Del(ctx, refreshKey(hash))
+		}
+		if userID, err := strconv.ParseUint(session["user_id"], 10, 64); err == nil {
+			pipe.SRem(ctx, userSessionsKey(userID), sessionID)
+		}
+		pipe.Del(ctx, sessionKey(sessionID))
+		return nil
+	})
+	return err
+}
+
+// checkRevoked 检查 token 所属会话是否已被吊销，未吊销时顺带更新会话的最近活跃时间
+func (s *AuthService) checkRevoked(ctx context.Context, claims *JWTClaims) error {
+	// 不属于任何会话的 token 无法吊销，不予接受
+	if claims.SessionID == "" {
+		return ErrTokenRevoked
+	}
+	if s.Redis == nil {
+		return nil
+	}
+
+	revoked, err := touchSessionScript.Run(ctx, s.Redis,
+		[]string{revokedSessionKey(claims.SessionID), sessionKey(claims.SessionID)},
+		time.Now().Unix(), int64(config.SessionTouchInterval/time.Second),
+	).Int()
+	if err != nil {
+		return err
+	}
+	if revoked == 1 {
+		return ErrTokenRevoked
+	}
+	return nil
+}
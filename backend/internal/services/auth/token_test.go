@@ -0,0 +1,145 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/database"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateToken_Claims(t *testing.T) {
+	s := &AuthService{config: &config.Config{JWTSecret: "secret", JWTExpiration: time.Minute}}
+	ctx := context.Background()
+
+	token, expiresAt, err := s.GenerateToken(42, "session-1")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), expiresAt, time.Second)
+
+	claims, err := s.ValidateToken(ctx, token)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), claims.UserID)
+	assert.Equal(t, "session-1", claims.SessionID)
+
+	// 不属于会话的 token 无法吊销，不接受
+	token, _, err = s.GenerateToken(42, "")
+	require.NoError(t, err)
+	_, err = s.ValidateToken(ctx, token)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	// 其他密钥签发的 token 无效
+	other := &AuthService{config: &config.Config{JWTSecret: "other", JWTExpiration: time.Minute}}
+	token, _, err = other.GenerateToken(42, "session-1")
+	require.NoError(t, err)
+	_, err = s.ValidateToken(ctx, token)
+	assert.ErrorIs(t, err, jwt.ErrSignatureInvalid)
+
+	// 没有 Redis 时无法签发 refresh token
+	_, err = s.Refresh(ctx, "anything")
+	assert.ErrorIs(t, err, ErrTokenStoreUnavailable)
+}
+
+func TestAuthService_RefreshRotation(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "refreshuser_" + timestamp,
+			Email:    "refreshuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+		require.NotNil(t, s.Redis)
+		assert.Equal(t, database.Redis, s.Redis)
+
+		_, first, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+
+		// 轮换：新的 refresh token 属于同一会话，旧的 refresh token 作废
+		second, err := s.Refresh(ctx, first.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.RefreshToken, second.RefreshToken)
+
+		firstClaims, err := s.ValidateToken(ctx, first.Token)
+		require.NoError(t, err)
+		secondClaims, err := s.ValidateToken(ctx, second.Token)
+		require.NoError(t, err)
+		assert.Equal(t, firstClaims.SessionID, secondClaims.SessionID)
+
+		// 重复使用旧的 refresh token，整个会话被吊销
+		_, err = s.Refresh(ctx, first.RefreshToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+		_, err = s.Refresh(ctx, second.RefreshToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		_, err = s.ValidateToken(ctx, second.Token)
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+
+		_, err = s.Refresh(ctx, "not-a-refresh-token")
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "logoutuser_" + timestamp,
+			Email:    "logoutuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		s := NewAuthService(testutil.TestConfig)
+
+		// 两台设备登录，退出其中一台不影响另一台
+		_, phone, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		_, laptop, err := s.Login(ctx, user.Email, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		_, tablet, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+
+		phoneClaims, err := s.ValidateToken(ctx, phone.Token)
+		require.NoError(t, err)
+		require.NoError(t, s.Logout(ctx, phoneClaims.SessionID))
+
+		_, err = s.ValidateToken(ctx, phone.Token)
+		assert.ErrorIs(t, err, ErrTokenRevoked)
+		_, err = s.Refresh(ctx, phone.RefreshToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		_, err = s.ValidateToken(ctx, laptop.Token)
+		require.NoError(t, err)
+
+		// 退出所有设备
+		require.NoError(t, s.LogoutAll(ctx, user.ID))
+		for _, tokens := range []*models.AuthTokens{laptop, tablet} {
+			_, err = s.ValidateToken(ctx, tokens.Token)
+			assert.ErrorIs(t, err, ErrTokenRevoked)
+			_, err = s.Refresh(ctx, tokens.RefreshToken)
+			assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA1 测试向量，取后 6 位
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	for unix, expected := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := totpCode(secret, totpStep(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+
+	// 允许前后一个时间步的误差，已使用的时间步不能再用
+	now := time.Unix(1234567890, 0)
+	current := totpStep(now)
+	previous, err := totpCode(secret, current-1)
+	require.NoError(t, err)
+	step, ok := matchTOTP(secret, previous, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, current-1, step)
+	_, ok = matchTOTP(secret, previous, now, current-1)
+	assert.False(t, ok)
+
+	tooOld, err := totpCode(secret, current-2)
+	require.NoError(t, err)
+	_, ok = matchTOTP(secret, tooOld, now, 0)
+	assert.False(t, ok)
+	_, ok = matchTOTP(secret, "12345", now, 0)
+	assert.False(t, ok)
+
+	uri, err := url.Parse(totpProvisioningURI("AI Models", "alice", secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/AI Models:alice", uri.Path)
+	assert.Equal(t, secret, uri.Query().Get("secret"))
+	assert.Equal(t, "AI Models", uri.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := newRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+	assert.Regexp(t, `^[0-9a-f]{5}-[0-9a-f]{5}$`, codes[0])
+
+	// 输入时忽略大小写、空格和连字符
+	assert.Equal(t, hashes[0], hashRecoveryCode(codes[0]))
+	assert.Equal(t, hashes[0], hashRecoveryCode(" "+strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))+" "))
+	assert.NotEqual(t, hashes[0], hashes[1])
+}
@@ -0,0 +1,375 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrTwoFactorRequired         = errors.New("需要两步验证")
+	ErrTwoFactorCodeInvalid      = errors.New("验证码错误")
+	ErrTwoFactorChallengeInvalid = errors.New("验证已过期，请重新登录")
+	ErrTwoFactorAlreadyEnabled   = errors.New("已启用两步验证")
+	ErrTwoFactorNotEnabled       = errors.New("未启用两步验证")
+	ErrTwoFactorSetupRequired    = errors.New("请先绑定身份验证器")
+	ErrTwoFactorEnforced         = errors.New("当前角色要求两步验证，不能关闭")
+	ErrPasswordIncorrect         = errors.New("密码错误")
+)
+
+// TwoFactorChallengeError 密码验证通过但需要两步验证，携带换取 token 用的 challenge token
+type TwoFactorChallengeError struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+func (e *TwoFactorChallengeError) Error() string {
+	return ErrTwoFactorRequired.Error()
+}
+
+func (e *TwoFactorChallengeError) Unwrap() error {
+	return ErrTwoFactorRequired
+}
+
+// twoFactorChallengeKey 登录第二步的 challenge，只保存哈希：
+//
+//	auth:2fa_challenge:<hash>  user_id 和已尝试次数，有效期 config.TwoFactorChallengeTTL
+func twoFactorChallengeKey(token string) string {
+	return "auth:2fa_challenge:" + hashToken(token)
+}
+
+// GetTwoFactorStatus 获取用户的两步验证状态
+func (s *AuthService) GetTwoFactorStatus(userID uint64) (*models.TwoFactorStatus, error) {
+	var user models.User
+	if err := s.DB.Select("id", "role").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	required, err := s.roleRequiresTwoFactor(user.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.TwoFactorStatus{Required: required}
+	record, err := s.twoFactor(s.DB, userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if record != nil && record.IsEnabled() {
+		status.Enabled = true
+		status.EnabledAt = record.EnabledAt
+		status.RecoveryCodesRemaining = len(record.RecoveryCodes)
+	}
+	return status, nil
+}
+
+// SetupTwoFactor 生成新的 TOTP 密钥，需要用 EnableTwoFactor 输入一次验证码确认后才启用
+func (s *AuthService) SetupTwoFactor(userID uint64) (*models.TwoFactorSetupResponse, error) {
+	var user models.User
+	if err := s.DB.Select("id", "username").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	secret, err := newTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := s.twoFactor(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if record != nil && record.IsEnabled() {
+			return ErrTwoFactorAlreadyEnabled
+		}
+		return tx.Save(&models.UserTwoFactor{UserID: userID, Secret: secret}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: totpProvisioningURI(config.TwoFactorIssuer, user.Username, secret),
+	}, nil
+}
+
+// EnableTwoFactor 用身份验证器生成的验证码确认绑定，启用后返回恢复码
+// 当前会话不会变为已通过两步验证，需要重新登录
+func (s *AuthService) EnableTwoFactor(userID uint64, code string, client models.ClientInfo) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes(config.TwoFactorRecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		record, err := s.twoFactor(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTwoFactorSetupRequired
+		}
+		if err != nil {
+			return err
+		}
+		if record.IsEnabled() {
+			return ErrTwoFactorAlreadyEnabled
+		}
+
+		step, ok := matchTOTP(record.Secret, normalizeTwoFactorCode(code), time.Now(), record.LastUsedStep)
+		if !ok {
+			return ErrTwoFactorCodeInvalid
+		}
+
+		now := time.Now()
+		record.EnabledAt = &now
+		record.LastUsedStep = step
+		record.RecoveryCodes = hashes
+		if err := tx.Save(record).Error; err != nil {
+			return err
+		}
+
+		s.recordTwoFactorAudit(tx, models.AuditTwoFactorEnable, userID, client, userID, "")
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableTwoFactor 关闭两步验证，需要密码和验证码（或恢复码），角色要求两步验证时不能关闭
+func (s *AuthService) DisableTwoFactor(userID uint64, req models.TwoFactorDisableRequest, client models.ClientInfo) error {
+	var user models.User
+	if err := s.DB.Select("id", "role", "password").First(&user, userID).Error; err != nil {
+		return err
+	}
+	ok, _, err := services.VerifyPassword(user.Password, req.Password)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrPasswordIncorrect
+	}
+
+	required, err := s.roleRequiresTwoFactor(user.Role)
+	if err != nil {
+		return err
+	}
+	if required {
+		return ErrTwoFactorEnforced
+	}
+
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.verifyTwoFactorCode(tx, userID, req.Code, true, client); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.UserTwoFactor{}, userID).Error; err != nil {
+			return err
+		}
+		s.recordTwoFactorAudit(tx, models.AuditTwoFactorDisable, userID, client, userID, "")
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部失效，只接受身份验证器的验证码
+func (s *AuthService) RegenerateRecoveryCodes(userID uint64, code string, client models.ClientInfo) ([]string, error) {
+	codes, hashes, err := newRecoveryCodes(config.TwoFactorRecoveryCodes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.verifyTwoFactorCode(tx, userID, code, false, client); err != nil {
+			return err
+		}
+		return tx.Model(&models.UserTwoFactor{UserID: userID}).
+			Select("recovery_codes").
+			Updates(&models.UserTwoFactor{RecoveryCodes: hashes}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// ResetTwoFactor 管理员为丢失身份验证器的用户关闭两步验证
+func (s *AuthService) ResetTwoFactor(userID uint64, actor models.ClientInfo, actorID uint64) error {
+	result := s.DB.Delete(&models.UserTwoFactor{}, userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTwoFactorNotEnabled
+	}
+
+	s.recordTwoFactorAudit(s.DB, models.AuditTwoFactorDisable, userID, actor, actorID, "reset by admin")
+	return nil
+}
+
+// LoginTwoFactor 登录第二步，用 challenge token 和验证码（或恢复码）换取 token
+// 同一 challenge 错误次数过多后失效，需要重新输入密码
+func (s *AuthService) LoginTwoFactor(ctx context.Context, challengeToken, code string, client models.ClientInfo) (*models.User, *models.AuthTokens, error) {
+	if s.Redis == nil {
+		return nil, nil, ErrTokenStoreUnavailable
+	}
+
+	key := twoFactorChallengeKey(challengeToken)
+	userID, err := s.Redis.HGet(ctx, key, "user_id").Uint64()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil, ErrTwoFactorChallengeInvalid
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.Redis.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	if attempts > int64(config.TwoFactorChallengeAttempts) {
+		s.Redis.Del(ctx, key)
+		return nil, nil, ErrTwoFactorChallengeInvalid
+	}
+
+	err = s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return s.verifyTwoFactorCode(tx, userID, code, true, client)
+	})
+	if err != nil {
+		if attempts == int64(config.TwoFactorChallengeAttempts) {
+			s.Redis.Del(ctx, key)
+		}
+		return nil, nil, err
+	}
+
+	// 并发提交同一 challenge 时只有一个能成功
+	deleted, err := s.Redis.Del(ctx, key).Result()
+	if err != nil {
+		return nil, nil, err
+	}
+	if deleted == 0 {
+		return nil, nil, ErrTwoFactorChallengeInvalid
+	}
+
+	var user models.User
+	if err := s.DB.First(&user, userID).Error; err != nil {
+		return nil, nil, err
+	}
+	if !user.IsActive {
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	tokens, err := s.issueTokens(ctx, user.ID, "", true, client)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &user, tokens, nil
+}
+
+// completeLogin 第一步认证通过后，未启用两步验证时直接签发 token，否则返回 TwoFactorChallengeError
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, client models.ClientInfo) (*models.AuthTokens, error) {
+	record, err := s.twoFactor(s.DB.WithContext(ctx), user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if record == nil || !record.IsEnabled() {
+		return s.issueTokens(ctx, user.ID, "", false, client)
+	}
+
+	if s.Redis == nil {
+		return nil, ErrTokenStoreUnavailable
+	}
+	token, err := randomToken(config.RefreshTokenByteLength)
+	if err != nil {
+		return nil, err
+	}
+	key := twoFactorChallengeKey(token)
+	_, err = s.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, "user_id", user.ID, "attempts", 0)
+		pipe.Expire(ctx, key, config.TwoFactorChallengeTTL)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &TwoFactorChallengeError{Token: token, ExpiresAt: time.Now().Add(config.TwoFactorChallengeTTL)}
+}
+
+// verifyTwoFactorCode 校验验证码，allowRecovery 时也接受恢复码，需要在事务中调用
+// 验证码对应的时间步使用后不能再次使用，恢复码使用后删除
+func (s *AuthService) verifyTwoFactorCode(tx *gorm.DB, userID uint64, code string, allowRecovery bool, client models.ClientInfo) error {
+	record, err := s.twoFactor(tx.Clauses(clause.Locking{Strength: "UPDATE"}), userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrTwoFactorNotEnabled
+	}
+	if err != nil {
+		return err
+	}
+	if !record.IsEnabled() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = normalizeTwoFactorCode(code)
+	if step, ok := matchTOTP(record.Secret, code, time.Now(), record.LastUsedStep); ok {
+		return tx.Model(record).Update("last_used_step", step).Error
+	}
+
+	if !allowRecovery {
+		return ErrTwoFactorCodeInvalid
+	}
+	index := slices.Index(record.RecoveryCodes, hashRecoveryCode(code))
+	if index < 0 {
+		return ErrTwoFactorCodeInvalid
+	}
+	remaining := slices.Delete(slices.Clone(record.RecoveryCodes), index, index+1)
+	err = tx.Model(record).Select("recovery_codes").Updates(&models.UserTwoFactor{RecoveryCodes: remaining}).Error
+	if err != nil {
+		return err
+	}
+
+	s.recordTwoFactorAudit(tx, models.AuditRecoveryCodeUsed, userID, client, userID, fmt.Sprintf("%d remaining", len(remaining)))
+	return nil
+}
+
+func (s *AuthService) twoFactor(db *gorm.DB, userID uint64) (*models.UserTwoFactor, error) {
+	var record models.UserTwoFactor
+	if err := db.First(&record, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// roleRequiresTwoFactor 角色是否要求两步验证，角色不存在时不要求
+func (s *AuthService) roleRequiresTwoFactor(roleName string) (bool, error) {
+	var required []bool
+	err := s.DB.Model(&models.Role{}).Where("name = ?", roleName).Pluck("require_two_factor", &required).Error
+	if err != nil {
+		return false, err
+	}
+	return len(required) > 0 && required[0], nil
+}
+
+func (s *AuthService) recordTwoFactorAudit(db *gorm.DB, action string, userID uint64, actor models.ClientInfo, actorID uint64, detail string) {
+	services.RecordAudit(db, models.AuditLog{
+		Action:    action,
+		UserID:    userID,
+		ActorID:   actorID,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+		Detail:    detail,
+	})
+}
+
+// normalizeTwoFactorCode 去掉用户输入时常带的空格
+func normalizeTwoFactorCode(code string) string {
+	return strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+}
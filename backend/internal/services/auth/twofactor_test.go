@@ -0,0 +1,139 @@
+package auth
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/services"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthService_TwoFactor(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		ctx := context.Background()
+		s := NewAuthService(testutil.TestConfig)
+
+		userService := services.NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "totpuser_" + timestamp,
+			Email:    "totpuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+			testutil.TestDB.Where("user_id = ?", user.ID).Delete(&models.AuditLog{})
+		}()
+
+		// 身份验证器在指定时间步显示的验证码
+		codeAt := func(secret string, offset int64) string {
+			code, err := totpCode(secret, totpStep(time.Now())+offset)
+			require.NoError(t, err)
+			return code
+		}
+		login := func() *TwoFactorChallengeError {
+			_, _, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+			var challenge *TwoFactorChallengeError
+			require.True(t, errors.As(err, &challenge), "expected two-factor challenge, got %v", err)
+			return challenge
+		}
+
+		_, err = s.EnableTwoFactor(user.ID, "123456", models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorSetupRequired)
+
+		setup, err := s.SetupTwoFactor(user.ID)
+		require.NoError(t, err)
+		assert.Contains(t, setup.ProvisioningURI, "secret="+setup.Secret)
+
+		// 确认绑定前登录不需要验证码
+		_, tokens, err := s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		require.NotNil(t, tokens)
+
+		_, err = s.EnableTwoFactor(user.ID, "000000", models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorCodeInvalid)
+		recoveryCodes, err := s.EnableTwoFactor(user.ID, codeAt(setup.Secret, 0), models.ClientInfo{})
+		require.NoError(t, err)
+		assert.Len(t, recoveryCodes, config.TwoFactorRecoveryCodes)
+		_, err = s.SetupTwoFactor(user.ID)
+		assert.ErrorIs(t, err, ErrTwoFactorAlreadyEnabled)
+
+		// 启用后密码登录只返回 challenge，确认绑定时用过的验证码不能再次使用
+		challenge := login()
+		_, _, err = s.LoginTwoFactor(ctx, challenge.Token, codeAt(setup.Secret, 0), models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorCodeInvalid)
+		loggedIn, tokens, err := s.LoginTwoFactor(ctx, challenge.Token, codeAt(setup.Secret, 1), models.ClientInfo{})
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, loggedIn.ID)
+		claims, err := s.ValidateToken(ctx, tokens.Token)
+		require.NoError(t, err)
+		assert.True(t, claims.TwoFactor)
+
+		// 刷新后仍然是通过两步验证的会话
+		refreshed, err := s.Refresh(ctx, tokens.RefreshToken)
+		require.NoError(t, err)
+		claims, err = s.ValidateToken(ctx, refreshed.Token)
+		require.NoError(t, err)
+		assert.True(t, claims.TwoFactor)
+
+		// challenge 只能使用一次
+		_, _, err = s.LoginTwoFactor(ctx, challenge.Token, recoveryCodes[0], models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorChallengeInvalid)
+
+		// 恢复码只能使用一次
+		_, _, err = s.LoginTwoFactor(ctx, login().Token, recoveryCodes[0], models.ClientInfo{})
+		require.NoError(t, err)
+		_, _, err = s.LoginTwoFactor(ctx, login().Token, recoveryCodes[0], models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorCodeInvalid)
+		status, err := s.GetTwoFactorStatus(user.ID)
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, config.TwoFactorRecoveryCodes-1, status.RecoveryCodesRemaining)
+
+		// 错误次数过多后 challenge 失效，即使之后输入正确的恢复码
+		challenge = login()
+		for i := 0; i < config.TwoFactorChallengeAttempts; i++ {
+			_, _, err = s.LoginTwoFactor(ctx, challenge.Token, "000000", models.ClientInfo{})
+			assert.ErrorIs(t, err, ErrTwoFactorCodeInvalid)
+		}
+		_, _, err = s.LoginTwoFactor(ctx, challenge.Token, recoveryCodes[1], models.ClientInfo{})
+		assert.ErrorIs(t, err, ErrTwoFactorChallengeInvalid)
+
+		// 角色要求两步验证时不能关闭
+		rbac := services.NewRBACService()
+		roleName := "totp_" + timestamp[len(timestamp)-8:]
+		_, err = rbac.SaveRole(roleName, models.RoleRequest{Permissions: []string{models.PermAdminAccess}}, models.ClientInfo{}, 0)
+		require.NoError(t, err)
+		defer func() { _ = rbac.DeleteRole(roleName, models.ClientInfo{}, 0) }()
+		_, err = rbac.SetTwoFactorRequired(roleName, true, models.ClientInfo{}, 0)
+		require.NoError(t, err)
+		require.NoError(t, rbac.AssignRole(user.ID, roleName, models.ClientInfo{}, 0))
+
+		assert.ErrorIs(t, rbac.Authorize(user.ID, false, models.PermAdminAccess), services.ErrRoleTwoFactorRequired)
+		assert.NoError(t, rbac.Authorize(user.ID, true, models.PermAdminAccess))
+		assert.ErrorIs(t, rbac.Authorize(user.ID, true, models.PermUsersWrite), services.ErrPermissionDenied)
+
+		disable := models.TwoFactorDisableRequest{Password: "Password123", Code: recoveryCodes[2]}
+		assert.ErrorIs(t, s.DisableTwoFactor(user.ID, disable, models.ClientInfo{}), ErrTwoFactorEnforced)
+		require.NoError(t, rbac.AssignRole(user.ID, models.RoleUser, models.ClientInfo{}, 0))
+
+		assert.ErrorIs(t, s.DisableTwoFactor(user.ID, models.TwoFactorDisableRequest{Password: "Wrong123", Code: recoveryCodes[2]}, models.ClientInfo{}), ErrPasswordIncorrect)
+		require.NoError(t, s.DisableTwoFactor(user.ID, disable, models.ClientInfo{}))
+		_, tokens, err = s.Login(ctx, user.Username, "Password123", models.ClientInfo{})
+		require.NoError(t, err)
+		require.NotNil(t, tokens)
+		assert.ErrorIs(t, s.ResetTwoFactor(user.ID, models.ClientInfo{}, 1), ErrTwoFactorNotEnabled)
+
+		var audit models.AuditLog
+		require.NoError(t, testutil.TestDB.Where("user_id = ? AND action = ?", user.ID, models.AuditRecoveryCodeUsed).First(&audit).Error)
+	})
+}
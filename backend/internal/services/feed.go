@@ -33,34 +33,40 @@ func NewFeedService(db *gorm.DB, userService *UserService) *FeedService {
 
 // GetFeedPosts 获取信息流帖子列表（支持评论预载）
 func (s *FeedService) GetFeedPosts(params models.FeedQueryParams) ([]models.FeedPostResponseItem, string, bool, error) {
-	var posts []models.FeedPost
+	// 热度排序读取热门排行
+	if params.Sort == "hot" {
+		return s.getHotFeedPosts(params)
+	}
 
-	query := s.DB.Model(&models.FeedPost{})
+	var posts []models.FeedPost
 
-	// 计算排序方式
+	// 计算排序方式，cursorValue 取出每条帖子的排序字段值用于生成游标
+	var sort, column string
+	var cursorValue func(models.FeedPost) int64
+	switch params.Sort {
+	case "like":
+		sort, column = cursorPostsLike, "like_count"
+		cursorValue = func(p models.FeedPost) int64 { return int64(p.LikeCount) }
+	case "comment":
+		sort, column = cursorPostsComment, "comment_count"
+		cursorValue = func(p models.FeedPost) int64 { return int64(p.CommentCount) }
+	default: // "time"
+		sort, column = cursorPostsTime, "created_at"
+		cursorValue = func(p models.FeedPost) int64 { return p.CreatedAt.UnixMicro() }
+	}
+	query := s.DB.Model(&models.FeedPost{}).Order(column + " DESC, id DESC")
+
+	// 有cursor分页时使用游标中记录的排序字段值，计数在翻页之间变化也不会重复或遗漏
 	if params.AfterID != "" {
-		// 有cursor分页，设置排序和cursor条件
-		switch params.Sort {
-		case "like":
-			query = query.Order("like_count DESC, id DESC").Where("(like_count < (SELECT like_count FROM feed_posts WHERE id = ?) OR (like_count = (SELECT like_count FROM feed_posts WHERE id = ?) AND id < ?))",
-				params.AfterID, params.AfterID, params.AfterID)
-		case "comment":
-			query = query.Order("comment_count DESC, id DESC").Where("(comment_count < (SELECT comment_count FROM feed_posts WHERE id = ?) OR (comment_count = (SELECT comment_count FROM feed_posts WHERE id = ?) AND id < ?))",
-				params.AfterID, params.AfterID, params.AfterID)
-		default: // "time"
-			query = query.Order("created_at DESC, id DESC").Where("(created_at < (SELECT created_at FROM feed_posts WHERE id = ?) OR (created_at = (SELECT created_at FROM feed_posts WHERE id = ?) AND id < ?))",
-				params.AfterID, params.AfterID, params.AfterID)
+		cursor, err := s.decodeCursor(params.AfterID, sort, 0)
+		if err != nil {
+			return nil, "", false, err
 		}
-	} else {
-		// 无cursor分页，只设置排序
-		switch params.Sort {
-		case "like":
-			query = query.Order("like_count DESC, id DESC")
-		case "comment":
-			query = query.Order("comment_count DESC, id DESC")
-		default: // "time"
-			query = query.Order("created_at DESC, id DESC")
+		var value any = cursor.Value
+		if sort == cursorPostsTime {
+			value = cursor.Time()
 		}
+		query = query.Where("("+column+" < ? OR ("+column+" = ? AND id < ?))", value, value, cursor.ID)
 	}
 
 	// 查询数据，多查一条判断是否还有更多
@@ -72,7 +78,7 @@ func (s *FeedService) GetFeedPosts(params models.FeedQueryParams) ([]models.Feed
 	// 4. 权限错误 - 数据库用户没有查询权限
 	// 5. 表不存在错误 - 查询的表不存在
 	// 6. 字段映射错误 - struct字段与数据库字段映射失败
-	if err := query.Limit(params.Limit + 1).Find(&posts).Error; err != nil {
+	if err := query.Preload("Media", orderFeedMedia).Limit(params.Limit + 1).Find(&posts).Error; err != nil {
 		return nil, "", false, err
 	}
 
@@ -85,10 +91,19 @@ func (s *FeedService) GetFeedPosts(params models.FeedQueryParams) ([]models.Feed
 	// 生成下一页游标
 	var nextCursor string
 	if hasMore && len(posts) > 0 {
-		nextCursor = fmt.Sprintf("%d", posts[len(posts)-1].ID)
+		last := posts[len(posts)-1]
+		token, err := s.encodeCursor(feedCursor{Sort: sort, Value: cursorValue(last), ID: last.ID})
+		if err != nil {
+			return nil, "", false, err
+		}
+		nextCursor = token
 	}
 
-	// 构建响应项目列表
+	return s.buildPostResponseItems(posts, params.CommentCount), nextCursor, hasMore, nil
+}
+
+// buildPostResponseItems 构建帖子响应项目列表，commentCount 大于0时预载评论
+func (s *FeedService) buildPostResponseItems(posts []models.FeedPost, commentCount int) []models.FeedPostResponseItem {
 	responseItems := make([]models.FeedPostResponseItem, len(posts))
 	for i, post := range posts {
 		item := models.FeedPostResponseItem{
@@ -96,8 +111,8 @@ func (s *FeedService) GetFeedPosts(params models.FeedQueryParams) ([]models.Feed
 		}
 
 		// 根据参数决定是否预载评论
-		if params.CommentCount > 0 {
-			comments, err := s.getCommentsFromCache(fmt.Sprintf("%d", post.ID), params.CommentCount)
+		if commentCount > 0 {
+			comments, err := s.getCommentsFromCache(fmt.Sprintf("%d", post.ID), commentCount)
 			if err == nil && len(comments) > 0 {
 				item.PreloadedComments = comments
 				item.CommentPreviewCount = len(comments)
@@ -115,11 +130,23 @@ func (s *FeedService) GetFeedPosts(params models.FeedQueryParams) ([]models.Feed
 		responseItems[i] = item
 	}
 
-	return responseItems, nextCursor, hasMore, nil
+	return responseItems
 }
 
 // CreateFeedPost 创建信息流帖子
 func (s *FeedService) CreateFeedPost(userID uint64, req models.CreateFeedPostRequest) (*models.FeedPost, error) {
+	if req.Content == "" && req.ImageURL == "" && len(req.Media) == 0 {
+		return nil, ErrFeedEmptyPost
+	}
+	usable, err := uploadedFeedMedia(s.DB, userID, req.Media)
+	if err != nil {
+		return nil, err
+	}
+	media, err := s.buildFeedMedia(req.Media, usable)
+	if err != nil {
+		return nil, err
+	}
+
 	// 获取用户信息
 	var user models.User
 	if err := s.DB.First(&user, userID).Error; err != nil {
@@ -134,6 +161,7 @@ func (s *FeedService) CreateFeedPost(userID uint64, req models.CreateFeedPostReq
 		Status:             user.Status,
 		Content:            req.Content,
 		ImageURL:           req.ImageURL,
+		Media:              media,
 		LikeCount:          0,
 		CommentCount:       0,
 		UserProfileVersion: user.ProfileVersion,
@@ -143,6 +171,12 @@ func (s *FeedService) CreateFeedPost(userID uint64, req models.CreateFeedPostReq
 		return nil, err
 	}
 
+	// 异步推送到粉丝的时间线，推送失败不影响发帖
+	if s.Redis != nil {
+		go s.fanOutPost(post.UserID, post.ID)
+	}
+	s.refreshHotScore(post.ID)
+
 	return post, nil
 }
 
@@ -154,9 +188,9 @@ func (s *FeedService) GetFeedPostByID(postID string) (*models.FeedPost, error) {
 	}
 
 	var post models.FeedPost
-	if err := s.DB.Where("id = ?", postIDUint).First(&post).Error; err != nil {
+	if err := s.DB.Preload("Media", orderFeedMedia).Where("id = ?", postIDUint).First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, ErrFeedPostNotFound
 		}
 		return nil, err
 	}
@@ -174,7 +208,7 @@ func (s *FeedService) SetFeedPostLike(userID uint64, postID string, isLike bool)
 	var post models.FeedPost
 	if err := s.DB.Where("id = ?", postIDUint).First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, ErrFeedPostNotFound
 		}
 		return nil, err
 	}
@@ -217,6 +251,8 @@ func (s *FeedService) SetFeedPostLike(userID uint64, postID string, isLike bool)
 		return nil, txErr
 	}
 
+	s.refreshHotScore(postIDUint)
+
 	return result, nil
 }
 
@@ -225,22 +261,27 @@ func (s *FeedService) GetFeedComments(params models.CommentQueryParams) ([]model
 	var comments []models.FeedComment
 	var total int64
 
-	// 统计总评论数
-	if err := s.DB.Model(&models.FeedComment{}).Where("post_id = ?", params.PostID).Count(&total).Error; err != nil {
+	postID, err := s.ParseStringToUint64(params.PostID)
+	if err != nil {
+		return nil, "", false, 0, err
+	}
+
+	// 只列出顶层评论（包括仍有回复的已删除评论），回复通过回复列表获取
+	if err := s.DB.Model(&models.FeedComment{}).Where("post_id = ? AND parent_id = 0", params.PostID).Count(&total).Error; err != nil {
 		return nil, "", false, 0, err
 	}
 
-	query := s.DB.Model(&models.FeedComment{}).Where("post_id = ?", params.PostID).Order("created_at DESC, id DESC")
+	query := s.DB.Model(&models.FeedComment{}).Where("post_id = ? AND parent_id = 0", params.PostID).Order("created_at DESC, id DESC")
 
-	// Cursor分页处理
+	// Cursor分页处理，游标只能用于生成它的帖子
 	if params.AfterID != "" {
-		var lastComment models.FeedComment
-		if err := s.DB.Where("id = ?", params.AfterID).First(&lastComment).Error; err != nil {
-			return nil, "", false, 0, fmt.Errorf("invalid cursor: %w", err)
+		cursor, err := s.decodeCursor(params.AfterID, cursorComments, postID)
+		if err != nil {
+			return nil, "", false, 0, err
 		}
 
 		query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
-			lastComment.CreatedAt, lastComment.CreatedAt, lastComment.ID)
+			cursor.Time(), cursor.Time(), cursor.ID)
 	}
 
 	// 查询数据，多查一条判断是否还有更多
@@ -257,7 +298,12 @@ func (s *FeedService) GetFeedComments(params models.CommentQueryParams) ([]model
 	// 生成下一页游标
 	var nextCursor string
 	if hasMore && len(comments) > 0 {
-		nextCursor = fmt.Sprintf("%d", comments[len(comments)-1].ID)
+		last := comments[len(comments)-1]
+		token, err := s.encodeCursor(feedCursor{Sort: cursorComments, Scope: postID, Value: last.CreatedAt.UnixMicro(), ID: last.ID})
+		if err != nil {
+			return nil, "", false, 0, err
+		}
+		nextCursor = token
 	}
 
 	return comments, nextCursor, hasMore, total, nil
@@ -274,7 +320,7 @@ func (s *FeedService) CreateFeedComment(userID uint64, postID string, req models
 	var post models.FeedPost
 	if err := s.DB.Where("id = ?", postIDUint).First(&post).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("post not found")
+			return nil, ErrFeedPostNotFound
 		}
 		return nil, err
 	}
@@ -293,17 +339,37 @@ func (s *FeedService) CreateFeedComment(userID uint64, postID string, req models
 		Avatar:             user.Avatar,
 		Content:            req.Content,
 		ReplyTo:            req.ReplyTo,
+		ParentID:           req.ParentID,
 		LikeCount:          0,
 		UserProfileVersion: user.ProfileVersion,
 	}
 
 	// 事务处理：创建评论并更新帖子评论数
 	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		// 先锁定帖子，与删除评论、删除帖子的加锁顺序一致
+		if err := lockFeedPost(tx, post.ID, &post); err != nil {
+			return err
+		}
+
+		// 回复评论时锁定被回复的评论，避免与删除并发
+		if comment.ParentID != 0 {
+			if err := attachToParent(tx, comment); err != nil {
+				return err
+			}
+		}
+
 		// 创建评论
 		if err := tx.Create(comment).Error; err != nil {
 			return err
 		}
 
+		if comment.ParentID != 0 {
+			if err := tx.Model(&models.FeedComment{}).Where("id = ?", comment.ParentID).
+				UpdateColumn("reply_count", gorm.Expr("reply_count + 1")).Error; err != nil {
+				return err
+			}
+		}
+
 		// 增加帖子评论数
 		if err := tx.Model(&post).UpdateColumn("comment_count", gorm.Expr("comment_count + 1")).Error; err != nil {
 			return err
@@ -316,8 +382,9 @@ func (s *FeedService) CreateFeedComment(userID uint64, postID string, req models
 		return nil, err
 	}
 
-	// 异步清理缓存
-	go s.invalidateCommentCache(postID)
+	// 同步清理缓存，返回后再请求信息流能看到新评论
+	s.invalidateCommentCache(postID)
+	s.refreshHotScore(post.ID)
 
 	return comment, nil
 }
@@ -329,14 +396,17 @@ func (s *FeedService) SetFeedCommentLike(userID uint64, commentID string, isLike
 		return nil, err
 	}
 
-	// 检查评论是否存在
+	// 检查评论是否存在，已删除的占位评论不能点赞
 	var comment models.FeedComment
 	if err := s.DB.Where("id = ?", commentIDUint).First(&comment).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("comment not found")
+			return nil, ErrFeedCommentNotFound
 		}
 		return nil, err
 	}
+	if comment.Deleted {
+		return nil, ErrFeedCommentNotFound
+	}
 
 	// 检查用户是否已点赞
 	var existingLike models.FeedCommentLike
@@ -346,6 +416,10 @@ func (s *FeedService) SetFeedCommentLike(userID uint64, commentID string, isLike
 
 	// 使用事务处理点赞状态设置
 	txErr := s.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定评论，避免与删除评论并发时给刚清零点赞数的占位评论计数
+		if lockErr := lockFeedComment(tx, commentIDUint, &comment); lockErr != nil {
+			return lockErr
+		}
 		if isLike {
 			// 要点赞
 			if err == nil {
@@ -445,9 +519,22 @@ func (s *FeedService) CleanFeedOrphanData() error {
 		return err
 	}
 
-	// 删除用户不存在的评论
+	// 删除帖子不存在的媒体附件，OSS文件不再引用，一并删除
+	var orphanMedia []models.FeedMedia
+	subQuery8 := s.DB.Table("feed_posts").Select("id").Where("feed_posts.id = feed_media.post_id")
+	if err := s.DB.Where("NOT EXISTS (?)", subQuery8).Find(&orphanMedia).Error; err != nil {
+		return err
+	}
+	if len(orphanMedia) > 0 {
+		if err := s.DB.Delete(&orphanMedia).Error; err != nil {
+			return err
+		}
+		s.deleteMediaFilesAsync(orphanMedia)
+	}
+
+	// 删除用户不存在的评论，已删除的占位评论不再关联用户，需要保留
 	subQuery2 := s.DB.Table("users").Select("id").Where("users.id = feed_comments.user_id")
-	if err := s.DB.Where("NOT EXISTS (?)", subQuery2).Delete(&models.FeedComment{}).Error; err != nil {
+	if err := s.DB.Where("deleted = ? AND NOT EXISTS (?)", false, subQuery2).Delete(&models.FeedComment{}).Error; err != nil {
 		return err
 	}
 
@@ -520,7 +607,7 @@ func (s *FeedService) getTopCommentsFromDB(postID string, count int) ([]models.F
 	var comments []models.FeedComment
 	
 	err := s.DB.Model(&models.FeedComment{}).
-		Where("post_id = ?", postID).
+		Where("post_id = ? AND parent_id = 0 AND deleted = ?", postID, false).
 		Order("created_at DESC, id DESC").
 		Limit(count).
 		Find(&comments).Error
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFeedPostNotFound    = errors.New("post not found")
+	ErrFeedCommentNotFound = errors.New("comment not found")
+	ErrFeedForbidden       = errors.New("只能修改自己发布的内容")
+	ErrFeedParentMismatch  = errors.New("回复的评论不属于该帖子")
+)
+
+// attachToParent 锁定被回复的评论并设置楼层信息，被回复的评论必须属于同一帖子且未删除
+func attachToParent(tx *gorm.DB, comment *models.FeedComment) error {
+	var parent models.FeedComment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parent, comment.ParentID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrFeedCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if parent.Deleted {
+		return ErrFeedCommentNotFound
+	}
+	if parent.PostID != comment.PostID {
+		return ErrFeedParentMismatch
+	}
+
+	comment.RootID = parent.RootID
+	if comment.RootID == 0 {
+		comment.RootID = parent.ID
+	}
+	comment.ReplyTo = parent.Username
+	return nil
+}
+
+// GetFeedCommentReplies 获取评论的直接回复，按时间正序，支持cursor分页
+func (s *FeedService) GetFeedCommentReplies(commentID string, params models.ReplyQueryParams) ([]models.FeedComment, string, bool, int64, error) {
+	commentIDUint, err := s.ParseStringToUint64(commentID)
+	if err != nil {
+		return nil, "", false, 0, err
+	}
+
+	var parent models.FeedComment
+	if err := s.DB.First(&parent, commentIDUint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", false, 0, ErrFeedCommentNotFound
+		}
+		return nil, "", false, 0, err
+	}
+
+	query := s.DB.Model(&models.FeedComment{}).Where("parent_id = ?", parent.ID).Order("created_at ASC, id ASC")
+
+	// Cursor分页处理，游标只能用于生成它的评论
+	if params.AfterID != "" {
+		cursor, err := s.decodeCursor(params.AfterID, cursorReplies, parent.ID)
+		if err != nil {
+			return nil, "", false, 0, err
+		}
+
+		query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))",
+			cursor.Time(), cursor.Time(), cursor.ID)
+	}
+
+	// 多查一条判断是否还有更多
+	var replies []models.FeedComment
+	if err := query.Limit(params.Limit + 1).Find(&replies).Error; err != nil {
+		return nil, "", false, 0, err
+	}
+
+	hasMore := len(replies) > params.Limit
+	if hasMore {
+		replies = replies[:params.Limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(replies) > 0 {
+		last := replies[len(replies)-1]
+		token, err := s.encodeCursor(feedCursor{Sort: cursorReplies, Scope: parent.ID, Value: last.CreatedAt.UnixMicro(), ID: last.ID})
+		if err != nil {
+			return nil, "", false, 0, err
+		}
+		nextCursor = token
+	}
+
+	return replies, nextCursor, hasMore, int64(parent.ReplyCount), nil
+}
+
+// EditFeedComment 作者编辑评论内容，记录编辑时间
+func (s *FeedService) EditFeedComment(userID uint64, commentID string, req models.UpdateFeedCommentRequest) (*models.FeedComment, error) {
+	commentIDUint, err := s.ParseStringToUint64(commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment models.FeedComment
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockFeedComment(tx, commentIDUint, &comment); err != nil {
+			return err
+		}
+		// 管理员也不能修改他人的评论内容，只能删除
+		if comment.UserID != userID {
+			return ErrFeedForbidden
+		}
+
+		now := time.Now()
+		comment.Content = req.Content
+		comment.EditedAt = &now
+		return tx.Model(&comment).Select("content", "edited_at").Updates(&comment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateCommentCache(strconv.FormatUint(comment.PostID, 10))
+	return &comment, nil
+}
+
+// DeleteFeedComment 删除评论，作者或拥有 feed.moderate 权限的用户可以删除
+// 有回复的评论保留占位，没有回复的直接删除
+func (s *FeedService) DeleteFeedComment(userID uint64, commentID string, moderator bool) error {
+	commentIDUint, err := s.ParseStringToUint64(commentID)
+	if err != nil {
+		return err
+	}
+
+	var comment models.FeedComment
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockCommentWithPost(tx, commentIDUint, &comment); err != nil {
+			return err
+		}
+		if comment.UserID != userID && !moderator {
+			return ErrFeedForbidden
+		}
+		return removeFeedComment(tx, &comment)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCommentCache(strconv.FormatUint(comment.PostID, 10))
+	s.refreshHotScore(comment.PostID)
+	return nil
+}
+
+// lockFeedComment 锁定未删除的评论
+func lockFeedComment(tx *gorm.DB, id uint64, comment *models.FeedComment) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(comment, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrFeedCommentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if comment.Deleted {
+		return ErrFeedCommentNotFound
+	}
+	return nil
+}
+
+// lockCommentWithPost 先锁定评论所属的帖子再锁定评论
+// 增删评论的事务都按 帖子 → 评论 → 被回复的评论 的顺序加锁，与删除帖子时先锁帖子再删除评论一致，避免死锁
+func lockCommentWithPost(tx *gorm.DB, id uint64, comment *models.FeedComment) error {
+	var postID uint64
+	if err := tx.Model(&models.FeedComment{}).Where("id = ?", id).Pluck("post_id", &postID).Error; err != nil {
+		return err
+	}
+	if postID == 0 {
+		return ErrFeedCommentNotFound
+	}
+
+	var post models.FeedPost
+	if err := lockFeedPost(tx, postID, &post); err != nil {
+		// 帖子已删除时评论也已一并删除
+		if errors.Is(err, ErrFeedPostNotFound) {
+			return ErrFeedCommentNotFound
+		}
+		return err
+	}
+	return lockFeedComment(tx, id, comment)
+}
+
+// removeFeedComment 删除已锁定的评论，调用方需已通过 lockCommentWithPost 锁定帖子，帖子评论数只统计未删除的评论
+// 有回复时清空内容和作者保留占位，否则连同点赞直接删除
+func removeFeedComment(tx *gorm.DB, comment *models.FeedComment) error {
+	if err := tx.Model(&models.FeedPost{}).Where("id = ?", comment.PostID).
+		UpdateColumn("comment_count", gorm.Expr("comment_count - 1")).Error; err != nil {
+		return err
+	}
+
+	if comment.ReplyCount == 0 {
+		return pruneFeedComment(tx, comment)
+	}
+
+	if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.FeedCommentLike{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(comment).Updates(map[string]any{
+		"deleted":    true,
+		"content":    "",
+		"user_id":    0,
+		"username":   "",
+		"avatar":     "",
+		"like_count": 0,
+	}).Error
+}
+
+// pruneFeedComment 彻底删除没有回复的评论，被回复的评论回复数减一
+// 被回复的评论是占位且已没有回复时一并删除，依次向上
+func pruneFeedComment(tx *gorm.DB, comment *models.FeedComment) error {
+	for {
+		if err := tx.Where("comment_id = ?", comment.ID).Delete(&models.FeedCommentLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.FeedComment{}, comment.ID).Error; err != nil {
+			return err
+		}
+		if comment.ParentID == 0 {
+			return nil
+		}
+
+		var parent models.FeedComment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&parent, comment.ParentID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !parent.Deleted || parent.ReplyCount > 1 {
+			return tx.Model(&parent).UpdateColumn("reply_count", gorm.Expr("reply_count - 1")).Error
+		}
+		comment = &parent
+	}
+}
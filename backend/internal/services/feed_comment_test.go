@@ -0,0 +1,111 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedService_CommentReplies(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		author, err := userService.CreateUser(getTestUser1("_reply"))
+		require.NoError(t, err)
+		replier, err := userService.CreateUser(getTestUser2("_reply"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(author.ID)
+			_ = userService.DeleteUser(replier.ID)
+		}()
+
+		post, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "thread"})
+		require.NoError(t, err)
+		postID := strconv.FormatUint(post.ID, 10)
+		otherPost, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "other"})
+		require.NoError(t, err)
+
+		root, err := feedService.CreateFeedComment(author.ID, postID, models.CreateFeedCommentRequest{Content: "root"})
+		require.NoError(t, err)
+		rootID := strconv.FormatUint(root.ID, 10)
+
+		// 回复记录楼层和被回复的用户
+		reply, err := feedService.CreateFeedComment(replier.ID, postID, models.CreateFeedCommentRequest{Content: "reply", ParentID: root.ID})
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, reply.RootID)
+		assert.Equal(t, author.Username, reply.ReplyTo)
+		nested, err := feedService.CreateFeedComment(author.ID, postID, models.CreateFeedCommentRequest{Content: "nested", ParentID: reply.ID})
+		require.NoError(t, err)
+		assert.Equal(t, root.ID, nested.RootID)
+		assert.Equal(t, replier.Username, nested.ReplyTo)
+		second, err := feedService.CreateFeedComment(replier.ID, postID, models.CreateFeedCommentRequest{Content: "second", ParentID: root.ID})
+		require.NoError(t, err)
+
+		_, err = feedService.CreateFeedComment(replier.ID, strconv.FormatUint(otherPost.ID, 10), models.CreateFeedCommentRequest{Content: "x", ParentID: root.ID})
+		assert.ErrorIs(t, err, ErrFeedParentMismatch)
+
+		// 帖子评论列表只包含顶层评论，评论数包含全部回复
+		comments, _, _, total, err := feedService.GetFeedComments(models.CommentQueryParams{PostID: postID, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		require.Len(t, comments, 1)
+		assert.Equal(t, 2, comments[0].ReplyCount)
+		detail, err := feedService.GetFeedPostByID(postID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, detail.CommentCount)
+
+		// 回复按时间正序分页
+		replies, nextCursor, hasMore, total, err := feedService.GetFeedCommentReplies(rootID, models.ReplyQueryParams{Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), total)
+		assert.True(t, hasMore)
+		require.Len(t, replies, 1)
+		assert.Equal(t, reply.ID, replies[0].ID)
+		replies, _, hasMore, _, err = feedService.GetFeedCommentReplies(rootID, models.ReplyQueryParams{AfterID: nextCursor, Limit: 1})
+		require.NoError(t, err)
+		assert.False(t, hasMore)
+		require.Len(t, replies, 1)
+		assert.Equal(t, second.ID, replies[0].ID)
+
+		// 只有作者可以编辑
+		replyID := strconv.FormatUint(reply.ID, 10)
+		_, err = feedService.EditFeedComment(author.ID, replyID, models.UpdateFeedCommentRequest{Content: "hijack"})
+		assert.ErrorIs(t, err, ErrFeedForbidden)
+		edited, err := feedService.EditFeedComment(replier.ID, replyID, models.UpdateFeedCommentRequest{Content: "edited"})
+		require.NoError(t, err)
+		assert.Equal(t, "edited", edited.Content)
+		assert.NotNil(t, edited.EditedAt)
+
+		// 有回复的评论删除后保留占位
+		assert.ErrorIs(t, feedService.DeleteFeedComment(author.ID, replyID, false), ErrFeedForbidden)
+		require.NoError(t, feedService.DeleteFeedComment(replier.ID, replyID, false))
+		var tombstone models.FeedComment
+		require.NoError(t, testutil.TestDB.First(&tombstone, reply.ID).Error)
+		assert.True(t, tombstone.Deleted)
+		assert.Empty(t, tombstone.Content)
+		assert.Zero(t, tombstone.UserID)
+		_, err = feedService.EditFeedComment(replier.ID, replyID, models.UpdateFeedCommentRequest{Content: "again"})
+		assert.ErrorIs(t, err, ErrFeedCommentNotFound)
+		_, err = feedService.SetFeedCommentLike(author.ID, replyID, true)
+		assert.ErrorIs(t, err, ErrFeedCommentNotFound)
+
+		// 删除占位下最后一条回复时占位一并删除
+		require.NoError(t, feedService.DeleteFeedComment(author.ID, strconv.FormatUint(nested.ID, 10), false))
+		var count int64
+		require.NoError(t, testutil.TestDB.Model(&models.FeedComment{}).Where("id IN ?", []uint64{reply.ID, nested.ID}).Count(&count).Error)
+		assert.Zero(t, count)
+		require.NoError(t, testutil.TestDB.First(root, root.ID).Error)
+		assert.Equal(t, 1, root.ReplyCount)
+
+		// 管理员可以删除他人的评论
+		require.NoError(t, feedService.DeleteFeedComment(author.ID, strconv.FormatUint(second.ID, 10), true))
+		detail, err = feedService.GetFeedPostByID(postID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, detail.CommentCount)
+	})
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("分页游标无效")
+
+// 分页游标对应的列表，不同列表或排序方式的游标不能混用
+const (
+	cursorPostsTime    = "posts:time"
+	cursorPostsLike    = "posts:like"
+	cursorPostsComment = "posts:comment"
+	cursorPostsHot     = "posts:hot"
+	cursorComments     = "comments"
+	cursorReplies      = "replies"
+	cursorTimeline     = "timeline"
+	cursorFollowers    = "followers"
+	cursorFollowing    = "following"
+)
+
+// feedCursor 分页游标内容，记录上一页最后一条的排序字段值和ID，翻页时不再回查该记录
+type feedCursor struct {
+	Sort  string  `json:"s"`
+	Scope uint64  `json:"p,omitempty"` // 所属对象ID，例如评论所在的帖子，防止游标用于其他对象的列表
+	Value int64   `json:"v,omitempty"` // 排序字段的值，时间为微秒时间戳
+	Score float64 `json:"f,omitempty"` // 热度排序的热度值
+	ID    uint64  `json:"id"`
+}
+
+// Time 时间排序的游标值
+func (c feedCursor) Time() time.Time {
+	return time.UnixMicro(c.Value)
+}
+
+func (s *FeedService) cursorSecret() ([]byte, error) {
+	if s.userService == nil || s.userService.config == nil {
+		return nil, errors.New("feed cursor secret is not configured")
+	}
+	secret := s.userService.config.FeedCursorSecret
+	if secret == "" {
+		secret = s.userService.config.JWTSecret
+	}
+	if secret == "" {
+		return nil, errors.New("feed cursor secret is not configured")
+	}
+	return []byte(secret), nil
+}
+
+func (s *FeedService) cursorMAC(payload []byte) ([]byte, error) {
+	secret, err := s.cursorSecret()
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+// encodeCursor 生成签名的分页游标，客户端只能原样传回
+func (s *FeedService) encodeCursor(cursor feedCursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	sig, err := s.cursorMAC(payload)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// decodeCursor 校验分页游标，签名错误或与当前列表的排序方式、所属对象不一致时返回 ErrInvalidCursor
+func (s *FeedService) decodeCursor(token, sort string, scope uint64) (*feedCursor, error) {
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	expected, err := s.cursorMAC(payload)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(sig, expected) {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor feedCursor
+	if err := json.Unmarshal(payload, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if cursor.Sort != sort || cursor.Scope != scope || cursor.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
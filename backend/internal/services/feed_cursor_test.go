@@ -0,0 +1,109 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedService_Cursor(t *testing.T) {
+	s := &FeedService{userService: &UserService{config: &config.Config{JWTSecret: "secret"}}}
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC)
+	token, err := s.encodeCursor(feedCursor{Sort: cursorComments, Scope: 7, Value: createdAt.UnixMicro(), ID: 42})
+	require.NoError(t, err)
+
+	cursor, err := s.decodeCursor(token, cursorComments, 7)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), cursor.ID)
+	assert.True(t, createdAt.Equal(cursor.Time()))
+
+	// 用于其他帖子或其他列表
+	_, err = s.decodeCursor(token, cursorComments, 8)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = s.decodeCursor(token, cursorReplies, 7)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	// 篡改内容或签名
+	payload, sig, _ := strings.Cut(token, ".")
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"comments","p":7,"id":1}`)) + "." + sig
+	_, err = s.decodeCursor(forged, cursorComments, 7)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	_, err = s.decodeCursor(payload+".AAAA", cursorComments, 7)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+	for _, bad := range []string{"", "42", "!!.!!", payload} {
+		_, err = s.decodeCursor(bad, cursorComments, 7)
+		assert.ErrorIs(t, err, ErrInvalidCursor, bad)
+	}
+
+	// 更换密钥后旧游标失效
+	other := &FeedService{userService: &UserService{config: &config.Config{JWTSecret: "secret", FeedCursorSecret: "other"}}}
+	_, err = other.decodeCursor(token, cursorComments, 7)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestFeedService_CursorPagination(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		user, err := userService.CreateUser(getTestUser1("_cursor"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+		}()
+
+		post, err := feedService.CreateFeedPost(user.ID, models.CreateFeedPostRequest{Content: "cursor"})
+		require.NoError(t, err)
+		postID := strconv.FormatUint(post.ID, 10)
+		otherPost, err := feedService.CreateFeedPost(user.ID, models.CreateFeedPostRequest{Content: "other"})
+		require.NoError(t, err)
+		var created []uint64
+		for i := 0; i < 3; i++ {
+			comment, err := feedService.CreateFeedComment(user.ID, postID, models.CreateFeedCommentRequest{Content: "comment " + strconv.Itoa(i)})
+			require.NoError(t, err)
+			created = append(created, comment.ID)
+		}
+
+		// 评论按时间倒序翻页，不重复不遗漏
+		var seen []uint64
+		params := models.CommentQueryParams{PostID: postID, Limit: 2}
+		for {
+			comments, nextCursor, hasMore, _, err := feedService.GetFeedComments(params)
+			require.NoError(t, err)
+			for _, comment := range comments {
+				seen = append(seen, comment.ID)
+			}
+			if !hasMore {
+				break
+			}
+			params.AfterID = nextCursor
+		}
+		assert.Equal(t, []uint64{created[2], created[1], created[0]}, seen)
+
+		// 游标不能用于其他帖子
+		_, cursor, _, _, err := feedService.GetFeedComments(models.CommentQueryParams{PostID: postID, Limit: 1})
+		require.NoError(t, err)
+		_, _, _, _, err = feedService.GetFeedComments(models.CommentQueryParams{PostID: strconv.FormatUint(otherPost.ID, 10), AfterID: cursor, Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+
+		// 帖子游标记录排序字段的值，不能用于其他排序方式
+		_, cursor, hasMore, err := feedService.GetFeedPosts(models.FeedQueryParams{Sort: "comment", Limit: 1})
+		require.NoError(t, err)
+		require.True(t, hasMore)
+		_, _, _, err = feedService.GetFeedPosts(models.FeedQueryParams{Sort: "like", AfterID: cursor, Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+		_, _, _, err = feedService.GetFeedPosts(models.FeedQueryParams{Sort: "comment", AfterID: cursor, Limit: 1})
+		assert.NoError(t, err)
+		_, _, _, err = feedService.GetFeedPosts(models.FeedQueryParams{Sort: "time", AfterID: postID, Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
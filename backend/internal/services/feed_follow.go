@@ -0,0 +1,160 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFollowSelf       = errors.New("不能关注自己")
+	ErrFollowUserAbsent = errors.New("用户不存在")
+)
+
+// Follow 关注用户，重复关注不报错，返回关注状态是否改变
+func (s *FeedService) Follow(followerID, followeeID uint64) (bool, error) {
+	if followerID == followeeID {
+		return false, ErrFollowSelf
+	}
+	if err := s.DB.Select("id").First(&models.User{}, followeeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, ErrFollowUserAbsent
+		}
+		return false, err
+	}
+
+	result := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserFollow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.invalidateTimeline(followerID)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Unfollow 取消关注，未关注时不报错，返回关注状态是否改变
+func (s *FeedService) Unfollow(followerID, followeeID uint64) (bool, error) {
+	result := s.DB.Where("follower_id = ? AND followee_id = ?", followerID, followeeID).Delete(&models.UserFollow{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		s.invalidateTimeline(followerID)
+		invalidateDemotedTimelines(s.DB, s.Redis, []uint64{followeeID})
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetFollowers 获取用户的粉丝列表，按关注时间倒序
+func (s *FeedService) GetFollowers(userID string, params models.FollowQueryParams) (*models.FollowListResponse, error) {
+	return s.getFollowList(userID, params, cursorFollowers, "followee_id", "follower_id")
+}
+
+// GetFollowing 获取用户关注的人，按关注时间倒序
+func (s *FeedService) GetFollowing(userID string, params models.FollowQueryParams) (*models.FollowListResponse, error) {
+	return s.getFollowList(userID, params, cursorFollowing, "follower_id", "followee_id")
+}
+
+// getFollowList ownerColumn 为列表所属用户所在的列，userColumn 为列表中用户所在的列
+func (s *FeedService) getFollowList(userID string, params models.FollowQueryParams, sort, ownerColumn, userColumn string) (*models.FollowListResponse, error) {
+	userIDUint, err := s.ParseStringToUint64(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var total int64
+	if err := s.DB.Model(&models.UserFollow{}).Where(ownerColumn+" = ?", userIDUint).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	query := s.DB.Table("user_follows").
+		Select("users.id, users.username, users.avatar, users.status, user_follows.created_at AS followed_at, user_follows.id AS follow_id").
+		Joins(fmt.Sprintf("JOIN users ON users.id = user_follows.%s", userColumn)).
+		Where("user_follows."+ownerColumn+" = ?", userIDUint).
+		Order("user_follows.id DESC")
+
+	// Cursor分页处理，关注记录ID随关注时间递增
+	if params.AfterID != "" {
+		cursor, err := s.decodeCursor(params.AfterID, sort, userIDUint)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("user_follows.id < ?", cursor.ID)
+	}
+
+	// 多查一条判断是否还有更多
+	var users []models.FollowUser
+	if err := query.Limit(params.Limit + 1).Scan(&users).Error; err != nil {
+		return nil, err
+	}
+
+	hasMore := len(users) > params.Limit
+	if hasMore {
+		users = users[:params.Limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(users) > 0 {
+		token, err := s.encodeCursor(feedCursor{Sort: sort, Scope: userIDUint, ID: users[len(users)-1].FollowID})
+		if err != nil {
+			return nil, err
+		}
+		nextCursor = token
+	}
+	if users == nil {
+		users = []models.FollowUser{}
+	}
+
+	return &models.FollowListResponse{
+		Users:      users,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+		Total:      total,
+	}, nil
+}
+
+// GetFollowStats 获取用户的关注数和粉丝数，以及与当前用户的关注关系
+func (s *FeedService) GetFollowStats(userID string, viewerID uint64) (*models.FollowStats, error) {
+	userIDUint, err := s.ParseStringToUint64(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.Select("id").First(&models.User{}, userIDUint).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFollowUserAbsent
+		}
+		return nil, err
+	}
+
+	var stats models.FollowStats
+	if err := s.DB.Model(&models.UserFollow{}).Where("followee_id = ?", userIDUint).Count(&stats.FollowerCount).Error; err != nil {
+		return nil, err
+	}
+	if err := s.DB.Model(&models.UserFollow{}).Where("follower_id = ?", userIDUint).Count(&stats.FollowingCount).Error; err != nil {
+		return nil, err
+	}
+
+	if viewerID != 0 && viewerID != userIDUint {
+		var relations []models.UserFollow
+		if err := s.DB.Where("(follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)",
+			viewerID, userIDUint, userIDUint, viewerID).Find(&relations).Error; err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			if relation.FollowerID == viewerID {
+				stats.IsFollowing = true
+			} else {
+				stats.IsFollowedBy = true
+			}
+		}
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeedService_Follow(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		alice, err := userService.CreateUser(getTestUser1("_follow"))
+		require.NoError(t, err)
+		bob, err := userService.CreateUser(getTestUser2("_follow"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(alice.ID)
+			_ = userService.DeleteUser(bob.ID)
+		}()
+		aliceID := strconv.FormatUint(alice.ID, 10)
+		bobID := strconv.FormatUint(bob.ID, 10)
+
+		_, err = feedService.Follow(alice.ID, alice.ID)
+		assert.ErrorIs(t, err, ErrFollowSelf)
+		_, err = feedService.Follow(alice.ID, 999999999)
+		assert.ErrorIs(t, err, ErrFollowUserAbsent)
+
+		// 重复关注不改变状态
+		changed, err := feedService.Follow(alice.ID, bob.ID)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		changed, err = feedService.Follow(alice.ID, bob.ID)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		_, err = feedService.Follow(bob.ID, alice.ID)
+		require.NoError(t, err)
+
+		stats, err := feedService.GetFollowStats(bobID, alice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), stats.FollowerCount)
+		assert.Equal(t, int64(1), stats.FollowingCount)
+		assert.True(t, stats.IsFollowing)
+		assert.True(t, stats.IsFollowedBy)
+
+		followers, err := feedService.GetFollowers(bobID, models.FollowQueryParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), followers.Total)
+		require.Len(t, followers.Users, 1)
+		assert.Equal(t, alice.ID, followers.Users[0].ID)
+		assert.Equal(t, alice.Username, followers.Users[0].Username)
+		following, err := feedService.GetFollowing(aliceID, models.FollowQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, following.Users, 1)
+		assert.Equal(t, bob.ID, following.Users[0].ID)
+
+		changed, err = feedService.Unfollow(alice.ID, bob.ID)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		changed, err = feedService.Unfollow(alice.ID, bob.ID)
+		require.NoError(t, err)
+		assert.False(t, changed)
+		stats, err = feedService.GetFollowStats(bobID, alice.ID)
+		require.NoError(t, err)
+		assert.Zero(t, stats.FollowerCount)
+		assert.False(t, stats.IsFollowing)
+	})
+}
+
+func TestFeedService_Timeline(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+		ctx := context.Background()
+
+		reader, err := userService.CreateUser(getTestUser1("_timeline"))
+		require.NoError(t, err)
+		author, err := userService.CreateUser(getTestUser2("_timeline"))
+		require.NoError(t, err)
+		stranger, err := userService.CreateUser(getTestUser2("_stranger"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(reader.ID)
+			_ = userService.DeleteUser(author.ID)
+			_ = userService.DeleteUser(stranger.ID)
+		}()
+
+		_, err = feedService.CreateFeedPost(stranger.ID, models.CreateFeedPostRequest{Content: "stranger"})
+		require.NoError(t, err)
+		own, err := feedService.CreateFeedPost(reader.ID, models.CreateFeedPostRequest{Content: "own"})
+		require.NoError(t, err)
+		first, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "first"})
+		require.NoError(t, err)
+
+		// 关注前只有自己的帖子
+		posts, _, _, err := feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		assert.Equal(t, own.ID, posts[0].ID)
+
+		// 关注后重建缓存，包含关注之前的帖子
+		_, err = feedService.Follow(reader.ID, author.ID)
+		require.NoError(t, err)
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 2)
+		assert.Equal(t, first.ID, posts[0].ID)
+
+		// 新帖子推送到粉丝的时间线缓存
+		second, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "second"})
+		require.NoError(t, err)
+		key := timelineKey(reader.ID)
+		assert.Eventually(t, func() bool {
+			_, err := feedService.Redis.ZScore(ctx, key, strconv.FormatUint(second.ID, 10)).Result()
+			return err == nil
+		}, 2*time.Second, 20*time.Millisecond)
+
+		// 分页合并缓存和数据库中的帖子
+		posts, cursor, hasMore, err := feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 2})
+		require.NoError(t, err)
+		assert.True(t, hasMore)
+		require.Len(t, posts, 2)
+		assert.Equal(t, second.ID, posts[0].ID)
+		assert.Equal(t, first.ID, posts[1].ID)
+		posts, _, hasMore, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{AfterID: cursor, Limit: 2})
+		require.NoError(t, err)
+		assert.False(t, hasMore)
+		require.Len(t, posts, 1)
+		assert.Equal(t, own.ID, posts[0].ID)
+
+		// 粉丝数达到阈值的用户不推送，读取时从数据库查询
+		threshold := config.FeedFanoutFollowerThreshold
+		config.FeedFanoutFollowerThreshold = 1
+		defer func() { config.FeedFanoutFollowerThreshold = threshold }()
+		third, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "third"})
+		require.NoError(t, err)
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 4)
+		assert.Equal(t, third.ID, posts[0].ID)
+
+		// 已删除的帖子和取消关注的用户的帖子不再出现
+		require.NoError(t, feedService.DeleteFeedPost(author.ID, strconv.FormatUint(second.ID, 10), false))
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, posts, 3)
+		_, err = feedService.Unfollow(reader.ID, author.ID)
+		require.NoError(t, err)
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		assert.Equal(t, own.ID, posts[0].ID)
+	})
+}
+
+func TestFeedService_TimelineFanoutDemotion(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		threshold := config.FeedFanoutFollowerThreshold
+		config.FeedFanoutFollowerThreshold = 2
+		defer func() { config.FeedFanoutFollowerThreshold = threshold }()
+
+		reader, err := userService.CreateUser(getTestUser1("_demote"))
+		require.NoError(t, err)
+		author, err := userService.CreateUser(getTestUser2("_demote"))
+		require.NoError(t, err)
+		other, err := userService.CreateUser(getTestUser2("_demote_other"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(reader.ID)
+			_ = userService.DeleteUser(author.ID)
+			_ = userService.DeleteUser(other.ID)
+		}()
+
+		// 作者有两个粉丝，达到阈值，读者的时间线缓存不包含作者
+		_, err = feedService.Follow(reader.ID, author.ID)
+		require.NoError(t, err)
+		_, err = feedService.Follow(other.ID, author.ID)
+		require.NoError(t, err)
+		posts, _, _, err := feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, posts)
+
+		// 拉模式期间发布的帖子不推送，读取时从数据库查询
+		pulled, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "pulled"})
+		require.NoError(t, err)
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+
+		// 粉丝数降到阈值以下改为推模式后，之前未推送的帖子仍然可见
+		_, err = feedService.Unfollow(other.ID, author.ID)
+		require.NoError(t, err)
+		posts, _, _, err = feedService.GetTimeline(reader.ID, models.TimelineQueryParams{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, posts, 1)
+		assert.Equal(t, pulled.ID, posts[0].ID)
+	})
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// hotScoreScript 热门排行存在时更新帖子热度并裁剪到最大长度
+// 排行不存在时不写入，避免只有部分帖子的排行被当作完整结果，等读取时或定时任务重建
+var hotScoreScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[2])
+redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -(tonumber(ARGV[3]) + 1))
+return 1
+`)
+
+// hotEntry 热门排行中的一条记录
+type hotEntry struct {
+	ID    uint64
+	Score float64
+}
+
+// hotScore 计算帖子热度（Reddit 算法），只与互动数和发布时间有关，不随当前时间变化
+// 互动数每多10倍热度加1，发布时间每晚 FeedHotDecaySeconds 热度加1，因此旧帖子的热度自然被新帖子超过
+func hotScore(likes, comments int, createdAt time.Time) float64 {
+	points := likes + config.FeedHotCommentWeight*comments
+	return math.Log10(float64(max(points, 1))) + float64(createdAt.Unix())/float64(config.FeedHotDecaySeconds)
+}
+
+// sortHotEntries 按热度倒序排列，热度相同时按ID倒序
+func sortHotEntries(entries []hotEntry) {
+	slices.SortFunc(entries, func(a, b hotEntry) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.ID, a.ID)
+	})
+}
+
+// after 是否排在游标之后
+func (e hotEntry) after(cursor *feedCursor) bool {
+	return cursor == nil || e.Score < cursor.Score || (e.Score == cursor.Score && e.ID < cursor.ID)
+}
+
+// getHotFeedPosts 按热度获取帖子列表，游标记录上一页最后一条的热度和ID
+func (s *FeedService) getHotFeedPosts(params models.FeedQueryParams) ([]models.FeedPostResponseItem, string, bool, error) {
+	var cursor *feedCursor
+	if params.AfterID != "" {
+		decoded, err := s.decodeCursor(params.AfterID, cursorPostsHot, 0)
+		if err != nil {
+			return nil, "", false, err
+		}
+		cursor = decoded
+	}
+
+	// 多取一条判断是否还有更多
+	entries, err := s.hotRanking(cursor, params.Limit+1)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	hasMore := len(entries) > params.Limit
+	if hasMore {
+		entries = entries[:params.Limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(entries) > 0 {
+		last := entries[len(entries)-1]
+		token, err := s.encodeCursor(feedCursor{Sort: cursorPostsHot, Score: last.Score, ID: last.ID})
+		if err != nil {
+			return nil, "", false, err
+		}
+		nextCursor = token
+	}
+
+	// 按排行顺序返回，排行中已删除的帖子跳过
+	var posts []models.FeedPost
+	if len(entries) > 0 {
+		ids := make([]uint64, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		var found []models.FeedPost
+		if err := s.DB.Preload("Media", orderFeedMedia).Where("id IN ?", ids).Find(&found).Error; err != nil {
+			return nil, "", false, err
+		}
+		byID := make(map[uint64]models.FeedPost, len(found))
+		for _, post := range found {
+			byID[post.ID] = post
+		}
+		for _, id := range ids {
+			if post, ok := byID[id]; ok {
+				posts = append(posts, post)
+			}
+		}
+	}
+
+	return s.buildPostResponseItems(posts, params.CommentCount), nextCursor, hasMore, nil
+}
+
+// hotRanking 获取排在游标之后的 count 条热门帖子，优先读取 Redis 排行，Redis 不可用时从数据库计算
+func (s *FeedService) hotRanking(cursor *feedCursor, count int) ([]hotEntry, error) {
+	if s.Redis != nil {
+		entries, err := s.readHotRanking(context.Background(), cursor, count)
+		if err == nil {
+			return entries, nil
+		}
+		logrus.WithError(err).Warn("读取热门排行失败，从数据库计算")
+	}
+
+	entries, err := s.computeHotRanking()
+	if err != nil {
+		return nil, err
+	}
+	start := slices.IndexFunc(entries, func(e hotEntry) bool { return e.after(cursor) })
+	if start < 0 {
+		return nil, nil
+	}
+	entries = entries[start:]
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries, nil
+}
+
+// readHotRanking 读取 Redis 热门排行中排在游标之后的帖子，排行不存在时从数据库重建
+func (s *FeedService) readHotRanking(ctx context.Context, cursor *feedCursor, count int) ([]hotEntry, error) {
+	exists, err := s.Redis.Exists(ctx, config.FeedHotKey).Result()
+	if err != nil {
+		return nil, err
+	}
+	if exists == 0 {
+		if _, err := s.RebuildHotRanking(); err != nil {
+			return nil, err
+		}
+	}
+
+	// 有序集合中热度相同的成员按字符串排序，与ID顺序不一致
+	// 因此热度等于游标的成员单独取出按ID过滤，再取热度更低的成员
+	maxScore := "+inf"
+	var tiesCmd *redis.ZSliceCmd
+	pipe := s.Redis.Pipeline()
+	if cursor != nil {
+		score := strconv.FormatFloat(cursor.Score, 'g', -1, 64)
+		maxScore = "(" + score
+		tiesCmd = pipe.ZRangeByScoreWithScores(ctx, config.FeedHotKey, &redis.ZRangeBy{Min: score, Max: score})
+	}
+	rangeCmd := pipe.ZRevRangeByScoreWithScores(ctx, config.FeedHotKey, &redis.ZRangeBy{Max: maxScore, Min: "-inf", Count: int64(count)})
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	members := rangeCmd.Val()
+	if tiesCmd != nil {
+		members = append(tiesCmd.Val(), members...)
+	}
+	entries := make([]hotEntry, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(fmt.Sprint(member.Member), 10, 64)
+		if err != nil {
+			continue
+		}
+		entry := hotEntry{ID: id, Score: member.Score}
+		if entry.after(cursor) {
+			entries = append(entries, entry)
+		}
+	}
+	sortHotEntries(entries)
+	if len(entries) > count {
+		entries = entries[:count]
+	}
+	return entries, nil
+}
+
+// computeHotRanking 从数据库计算热度最高的 FeedHotMaxLen 条帖子
+// 数据库只负责筛选，写入排行的热度统一由 hotScore 计算，保证与增量更新的结果一致
+func (s *FeedService) computeHotRanking() ([]hotEntry, error) {
+	var posts []models.FeedPost
+	order := fmt.Sprintf("LOG(GREATEST(like_count + %d * comment_count, 1)) + EXTRACT(EPOCH FROM created_at) / %d DESC, id DESC",
+		config.FeedHotCommentWeight, config.FeedHotDecaySeconds)
+	if err := s.DB.Select("id", "like_count", "comment_count", "created_at").
+		Order(order).Limit(config.FeedHotMaxLen).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+
+	entries := make([]hotEntry, len(posts))
+	for i, post := range posts {
+		entries[i] = hotEntry{ID: post.ID, Score: hotScore(post.LikeCount, post.CommentCount, post.CreatedAt)}
+	}
+	sortHotEntries(entries)
+	return entries, nil
+}
+
+// RebuildHotRanking 从数据库重建 Redis 热门排行，先写入临时键再替换，重建期间读取不受影响
+// 修正增量更新遗漏的变化，例如重建过程中的点赞、注销账号删除的帖子
+func (s *FeedService) RebuildHotRanking() (int, error) {
+	if s.Redis == nil {
+		return 0, nil
+	}
+
+	entries, err := s.computeHotRanking()
+	if err != nil {
+		return 0, err
+	}
+
+	ctx := context.Background()
+	if len(entries) == 0 {
+		return 0, s.Redis.Del(ctx, config.FeedHotKey).Err()
+	}
+
+	members := make([]redis.Z, len(entries))
+	for i, entry := range entries {
+		members[i] = redis.Z{Score: entry.Score, Member: strconv.FormatUint(entry.ID, 10)}
+	}
+	tmpKey := config.FeedHotKey + ":rebuild"
+	pipe := s.Redis.TxPipeline()
+	pipe.Del(ctx, tmpKey)
+	pipe.ZAdd(ctx, tmpKey, members...)
+	pipe.Rename(ctx, tmpKey, config.FeedHotKey)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return len(entries), nil
+}
+
+// refreshHotScore 点赞数或评论数变化后按数据库中的最新计数更新帖子热度，更新失败时等待定时重建
+func (s *FeedService) refreshHotScore(postID uint64) {
+	if s.Redis == nil {
+		return
+	}
+	logger := logrus.WithField("post_id", postID)
+
+	var post models.FeedPost
+	if err := s.DB.Select("id", "like_count", "comment_count", "created_at").Where("id = ?", postID).Find(&post).Error; err != nil {
+		logger.WithError(err).Warn("更新帖子热度失败")
+		return
+	}
+	if post.ID == 0 {
+		s.removeHotScore(postID)
+		return
+	}
+
+	score := hotScore(post.LikeCount, post.CommentCount, post.CreatedAt)
+	member := strconv.FormatUint(postID, 10)
+	if err := hotScoreScript.Run(context.Background(), s.Redis, []string{config.FeedHotKey}, score, member, config.FeedHotMaxLen).Err(); err != nil {
+		logger.WithError(err).Warn("更新帖子热度失败")
+	}
+}
+
+// removeHotScore 帖子删除后从热门排行移除
+func (s *FeedService) removeHotScore(postID uint64) {
+	if s.Redis == nil {
+		return
+	}
+	if err := s.Redis.ZRem(context.Background(), config.FeedHotKey, strconv.FormatUint(postID, 10)).Err(); err != nil {
+		logrus.WithError(err).WithField("post_id", postID).Warn("移除帖子热度失败")
+	}
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHotScore(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	decay := time.Duration(config.FeedHotDecaySeconds) * time.Second
+
+	// 同一时间发布，互动越多热度越高，评论按权重计入
+	assert.Greater(t, hotScore(10, 0, now), hotScore(5, 0, now))
+	assert.Equal(t, hotScore(config.FeedHotCommentWeight, 0, now), hotScore(0, 1, now))
+	assert.Equal(t, hotScore(0, 0, now), hotScore(1, 0, now))
+
+	// 晚发布一个衰减周期的帖子只需要十分之一的互动
+	assert.InDelta(t, hotScore(100, 0, now), hotScore(10, 0, now.Add(decay)), 1e-9)
+	// 旧的热门帖子最终被新帖子超过
+	assert.Less(t, hotScore(1000, 100, now), hotScore(1, 0, now.Add(4*decay)))
+
+	entries := []hotEntry{{ID: 1, Score: 1}, {ID: 3, Score: 2}, {ID: 2, Score: 2}, {ID: 4, Score: 1}}
+	sortHotEntries(entries)
+	assert.Equal(t, []hotEntry{{ID: 3, Score: 2}, {ID: 2, Score: 2}, {ID: 4, Score: 1}, {ID: 1, Score: 1}}, entries)
+	cursor := &feedCursor{Score: 2, ID: 2}
+	assert.False(t, entries[0].after(cursor))
+	assert.False(t, entries[1].after(cursor))
+	assert.True(t, entries[2].after(cursor))
+}
+
+func TestFeedService_HotRanking(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		testutil.SetupTestRedis(t)
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+		ctx := context.Background()
+
+		author, err := userService.CreateUser(getTestUser1("_hot"))
+		require.NoError(t, err)
+		fan, err := userService.CreateUser(getTestUser2("_hot"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(author.ID)
+			_ = userService.DeleteUser(fan.ID)
+			_ = feedService.Redis.Del(ctx, config.FeedHotKey).Err()
+		}()
+
+		// 旧帖子互动多，但发布时间早了多个衰减周期
+		old, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "old"})
+		require.NoError(t, err)
+		oldID := strconv.FormatUint(old.ID, 10)
+		decay := time.Duration(config.FeedHotDecaySeconds) * time.Second
+		require.NoError(t, feedService.DB.Model(old).UpdateColumns(map[string]any{
+			"created_at": time.Now().Add(-3 * decay),
+			"like_count": 100,
+		}).Error)
+		quiet, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "quiet"})
+		require.NoError(t, err)
+		fresh, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "fresh"})
+		require.NoError(t, err)
+		freshID := strconv.FormatUint(fresh.ID, 10)
+
+		_, err = feedService.RebuildHotRanking()
+		require.NoError(t, err)
+
+		// 新帖子的点赞和评论实时更新热度
+		_, err = feedService.SetFeedPostLike(fan.ID, freshID, true)
+		require.NoError(t, err)
+		_, err = feedService.CreateFeedComment(fan.ID, freshID, models.CreateFeedCommentRequest{Content: "nice"})
+		require.NoError(t, err)
+		score, err := feedService.Redis.ZScore(ctx, config.FeedHotKey, freshID).Result()
+		require.NoError(t, err)
+		assert.InDelta(t, hotScore(1, 1, fresh.CreatedAt), score, 1e-9)
+
+		// 分页不重复不遗漏，新帖子排在旧的热门帖子前面
+		var seen []uint64
+		params := models.FeedQueryParams{Sort: "hot", Limit: 2}
+		for {
+			posts, nextCursor, hasMore, err := feedService.GetFeedPosts(params)
+			require.NoError(t, err)
+			for _, post := range posts {
+				seen = append(seen, post.ID)
+			}
+			if !hasMore {
+				break
+			}
+			params.AfterID = nextCursor
+		}
+		unique := slices.Clone(seen)
+		slices.Sort(unique)
+		assert.Len(t, slices.Compact(unique), len(seen))
+		freshAt, quietAt, oldAt := slices.Index(seen, fresh.ID), slices.Index(seen, quiet.ID), slices.Index(seen, old.ID)
+		require.True(t, freshAt >= 0 && quietAt >= 0 && oldAt >= 0)
+		assert.Less(t, freshAt, quietAt)
+		assert.Less(t, quietAt, oldAt)
+
+		// 删除的帖子移出排行
+		require.NoError(t, feedService.DeleteFeedPost(author.ID, oldID, false))
+		_, err = feedService.Redis.ZScore(ctx, config.FeedHotKey, oldID).Result()
+		assert.Error(t, err)
+
+		// 排行不存在时读取前重建
+		require.NoError(t, feedService.Redis.Del(ctx, config.FeedHotKey).Err())
+		posts, _, _, err := feedService.GetFeedPosts(models.FeedQueryParams{Sort: "hot", Limit: 10})
+		require.NoError(t, err)
+		assert.NotEmpty(t, posts)
+		exists, err := feedService.Redis.Exists(ctx, config.FeedHotKey).Result()
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), exists)
+
+		// 热度游标不能用于其他排序方式
+		_, cursor, hasMore, err := feedService.GetFeedPosts(models.FeedQueryParams{Sort: "hot", Limit: 1})
+		require.NoError(t, err)
+		require.True(t, hasMore)
+		_, _, _, err = feedService.GetFeedPosts(models.FeedQueryParams{Sort: "like", AfterID: cursor, Limit: 1})
+		assert.ErrorIs(t, err, ErrInvalidCursor)
+	})
+}
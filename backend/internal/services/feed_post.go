@@ -0,0 +1,264 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrFeedEmptyPost    = errors.New("内容和图片不能同时为空")
+	ErrFeedInvalidMedia = errors.New("媒体附件无效")
+
+	ErrFeedMediaUnavailable = errors.New("OSS 未配置，无法上传图片")
+)
+
+// orderFeedMedia 预加载媒体附件时按帖子内顺序排列
+func orderFeedMedia(db *gorm.DB) *gorm.DB {
+	return db.Order("position, id")
+}
+
+// feedMediaPrefix 用户上传帖子图片的OSS目录
+func feedMediaPrefix(userID uint64) string {
+	return config.FeedMediaKeyPrefix + strconv.FormatUint(userID, 10) + "/"
+}
+
+// SignFeedMediaUpload 申请上传帖子图片，对象键由服务端在该用户的目录下生成并记录，发帖时只能使用记录过的对象键
+func (s *FeedService) SignFeedMediaUpload(userID uint64, req models.FeedMediaUploadRequest) (*models.SignResponse, error) {
+	if s.userService == nil || s.userService.ossService == nil {
+		return nil, ErrFeedMediaUnavailable
+	}
+	ossService := s.userService.ossService
+	if err := ossService.ValidateCfg(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrFeedMediaUnavailable, err)
+	}
+
+	key := feedMediaPrefix(userID) + ossService.HashifyName(req.FileName)
+	signedURL, err := ossService.SignToUpload(key, req.FileType)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.DB.Create(&models.FeedUpload{UserID: userID, OssKey: key}).Error; err != nil {
+		return nil, err
+	}
+
+	return &models.SignResponse{SignedURL: signedURL, ObjectKey: key}, nil
+}
+
+// uploadedFeedMedia 返回媒体附件中该用户申请上传过的OSS对象键
+func uploadedFeedMedia(db *gorm.DB, userID uint64, items []models.FeedMediaRequest) (map[string]bool, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = item.OssKey
+	}
+	var uploaded []string
+	if err := db.Model(&models.FeedUpload{}).
+		Where("user_id = ? AND oss_key IN ?", userID, keys).
+		Pluck("oss_key", &uploaded).Error; err != nil {
+		return nil, err
+	}
+
+	usable := make(map[string]bool, len(uploaded))
+	for _, key := range uploaded {
+		usable[key] = true
+	}
+	return usable, nil
+}
+
+// buildFeedMedia 校验媒体附件并生成记录，OSS对象键不能重复
+// usable 为该用户可以使用的对象键：自己申请上传过的图片，编辑时还包括帖子已有的附件
+func (s *FeedService) buildFeedMedia(items []models.FeedMediaRequest, usable map[string]bool) ([]models.FeedMedia, error) {
+	media := make([]models.FeedMedia, len(items))
+	seen := make(map[string]bool, len(items))
+	for i, item := range items {
+		key := item.OssKey
+		if !usable[key] {
+			return nil, fmt.Errorf("%w: media[%d].oss_key must be an image uploaded via /api/feed/media/upload-url", ErrFeedInvalidMedia, i)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: media[%d].oss_key is duplicated", ErrFeedInvalidMedia, i)
+		}
+		seen[key] = true
+
+		media[i] = models.FeedMedia{
+			Position: i,
+			OssKey:   key,
+			Width:    item.Width,
+			Height:   item.Height,
+			Blurhash: item.Blurhash,
+		}
+		if s.userService != nil && s.userService.ossService != nil {
+			url, err := s.userService.ossService.GetFileURL(key)
+			if err != nil {
+				return nil, err
+			}
+			media[i].URL = url
+		}
+	}
+	return media, nil
+}
+
+// EditFeedPost 作者编辑帖子，整体替换文字、图片和媒体附件，不再引用的OSS文件随后删除
+func (s *FeedService) EditFeedPost(userID uint64, postID string, req models.UpdateFeedPostRequest) (*models.FeedPost, error) {
+	postIDUint, err := s.ParseStringToUint64(postID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Content == "" && req.ImageURL == "" && len(req.Media) == 0 {
+		return nil, ErrFeedEmptyPost
+	}
+
+	var post models.FeedPost
+	var media, removed []models.FeedMedia
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockFeedPost(tx, postIDUint, &post); err != nil {
+			return err
+		}
+		// 管理员也不能修改他人的帖子内容，只能删除
+		if post.UserID != userID {
+			return ErrFeedForbidden
+		}
+
+		var old []models.FeedMedia
+		if err := tx.Where("post_id = ?", post.ID).Find(&old).Error; err != nil {
+			return err
+		}
+		usable, err := uploadedFeedMedia(tx, userID, req.Media)
+		if err != nil {
+			return err
+		}
+		if usable == nil {
+			usable = make(map[string]bool, len(old))
+		}
+		for _, m := range old {
+			usable[m.OssKey] = true
+		}
+		media, err = s.buildFeedMedia(req.Media, usable)
+		if err != nil {
+			return err
+		}
+
+		kept := make(map[string]bool, len(media))
+		for _, m := range media {
+			kept[m.OssKey] = true
+		}
+		for _, m := range old {
+			if !kept[m.OssKey] {
+				removed = append(removed, m)
+			}
+		}
+
+		if err := tx.Where("post_id = ?", post.ID).Delete(&models.FeedMedia{}).Error; err != nil {
+			return err
+		}
+		for i := range media {
+			media[i].PostID = post.ID
+		}
+		if len(media) > 0 {
+			if err := tx.Create(&media).Error; err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		post.Content = req.Content
+		post.ImageURL = req.ImageURL
+		post.EditedAt = &now
+		return tx.Model(&post).Select("content", "image_url", "edited_at").Updates(&post).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	post.Media = media
+	s.deleteMediaFilesAsync(removed)
+	return &post, nil
+}
+
+// DeleteFeedPost 删除帖子及其评论、点赞和媒体附件，作者或拥有 feed.moderate 权限的用户可以删除
+func (s *FeedService) DeleteFeedPost(userID uint64, postID string, moderator bool) error {
+	postIDUint, err := s.ParseStringToUint64(postID)
+	if err != nil {
+		return err
+	}
+
+	var media []models.FeedMedia
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		var post models.FeedPost
+		if err := lockFeedPost(tx, postIDUint, &post); err != nil {
+			return err
+		}
+		if post.UserID != userID && !moderator {
+			return ErrFeedForbidden
+		}
+
+		media, err = deleteFeedPosts(tx, []uint64{post.ID})
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.invalidateCommentCache(strconv.FormatUint(postIDUint, 10))
+	s.removeHotScore(postIDUint)
+	s.deleteMediaFilesAsync(media)
+	return nil
+}
+
+// lockFeedPost 锁定帖子
+func lockFeedPost(tx *gorm.DB, id uint64, post *models.FeedPost) error {
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(post, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrFeedPostNotFound
+	}
+	return err
+}
+
+// deleteFeedPosts 删除帖子及其评论、评论点赞、帖子点赞和媒体附件，返回被删除的媒体附件，提交后由调用方删除OSS文件
+func deleteFeedPosts(tx *gorm.DB, postIDs []uint64) ([]models.FeedMedia, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	var media []models.FeedMedia
+	if err := tx.Where("post_id IN ?", postIDs).Find(&media).Error; err != nil {
+		return nil, err
+	}
+
+	comments := tx.Model(&models.FeedComment{}).Select("id").Where("post_id IN ?", postIDs)
+	steps := []func() error{
+		func() error { return tx.Where("comment_id IN (?)", comments).Delete(&models.FeedCommentLike{}).Error },
+		func() error { return tx.Where("post_id IN ?", postIDs).Delete(&models.FeedComment{}).Error },
+		func() error { return tx.Where("post_id IN ?", postIDs).Delete(&models.PostLike{}).Error },
+		func() error { return tx.Where("post_id IN ?", postIDs).Delete(&models.FeedMedia{}).Error },
+		func() error { return tx.Delete(&models.FeedPost{}, postIDs).Error },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return nil, err
+		}
+	}
+	return media, nil
+}
+
+// deleteMediaFilesAsync 异步删除不再引用的媒体附件OSS文件，仍被其他帖子或头像引用的文件保留
+func (s *FeedService) deleteMediaFilesAsync(media []models.FeedMedia) {
+	if len(media) == 0 || s.userService == nil {
+		return
+	}
+	go func() {
+		for _, m := range media {
+			s.userService.deleteOSSFileAsync(m.OssKey)
+		}
+	}()
+}
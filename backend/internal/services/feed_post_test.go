@@ -0,0 +1,209 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeedMedia(userID uint64, name string) models.FeedMediaRequest {
+	return models.FeedMediaRequest{OssKey: feedMediaPrefix(userID) + name, Width: 640, Height: 480, Blurhash: "LEHV6nWB2yk8pyo0adR*.7kCMdnj"}
+}
+
+// recordTestUploads 记录用户申请上传过的图片，测试环境没有配置OSS，不经过签名直接写入
+func recordTestUploads(t *testing.T, userID uint64, items ...models.FeedMediaRequest) {
+	for _, item := range items {
+		require.NoError(t, testutil.TestDB.Create(&models.FeedUpload{UserID: userID, OssKey: item.OssKey}).Error)
+	}
+}
+
+func TestFeedPostRequest_Validation(t *testing.T) {
+	valid := models.CreateFeedPostRequest{Content: "hello", Media: []models.FeedMediaRequest{testFeedMedia(1, "a.jpg")}}
+	assert.NoError(t, binding.Validator.ValidateStruct(&valid))
+
+	tooLong := models.CreateFeedPostRequest{Content: strings.Repeat("长", 2001)}
+	assert.Error(t, binding.Validator.ValidateStruct(&tooLong))
+
+	badURL := models.CreateFeedPostRequest{ImageURL: "not a url"}
+	assert.Error(t, binding.Validator.ValidateStruct(&badURL))
+
+	tooMany := models.CreateFeedPostRequest{}
+	for i := 0; i < 10; i++ {
+		tooMany.Media = append(tooMany.Media, testFeedMedia(1, strconv.Itoa(i)+".jpg"))
+	}
+	assert.Error(t, binding.Validator.ValidateStruct(&tooMany))
+
+	noSize := models.CreateFeedPostRequest{Media: []models.FeedMediaRequest{{OssKey: "assets/images/a.jpg"}}}
+	assert.Error(t, binding.Validator.ValidateStruct(&noSize))
+
+	// 只能申请上传图片
+	assert.NoError(t, binding.Validator.ValidateStruct(&models.FeedMediaUploadRequest{FileName: "a.jpg", FileType: "image/jpeg"}))
+	assert.Error(t, binding.Validator.ValidateStruct(&models.FeedMediaUploadRequest{FileName: "a.html", FileType: "text/html"}))
+
+	// 取消点赞传 false 也是合法请求，未传时报错
+	unlike := false
+	assert.NoError(t, binding.Validator.ValidateStruct(&models.SetFeedPostLikeRequest{IsLike: &unlike}))
+	assert.NoError(t, binding.Validator.ValidateStruct(&models.SetFeedCommentLikeRequest{IsLike: &unlike}))
+	assert.Error(t, binding.Validator.ValidateStruct(&models.SetFeedPostLikeRequest{}))
+}
+
+func TestFeedService_BuildFeedMedia(t *testing.T) {
+	s := &FeedService{}
+	usable := map[string]bool{feedMediaPrefix(1) + "a.jpg": true, feedMediaPrefix(1) + "b.jpg": true}
+
+	media, err := s.buildFeedMedia([]models.FeedMediaRequest{testFeedMedia(1, "a.jpg"), testFeedMedia(1, "b.jpg")}, usable)
+	require.NoError(t, err)
+	require.Len(t, media, 2)
+	assert.Equal(t, 1, media[1].Position)
+	assert.Equal(t, "assets/images/feed/1/b.jpg", media[1].OssKey)
+
+	// 只能使用申请上传过的对象键，自己目录下没有申请过的也不行
+	for _, key := range []string{
+		"assets/files/a.pdf", "assets/images/a.jpg", "assets/images/feed/1/", "assets/images/feed/2/a.jpg",
+		"assets/images/feed/1/../2/a.jpg", "assets/images/feed/12/a.jpg", "https://example.com/a.jpg",
+		feedMediaPrefix(1) + "c.jpg",
+	} {
+		_, err := s.buildFeedMedia([]models.FeedMediaRequest{{OssKey: key, Width: 1, Height: 1}}, usable)
+		assert.ErrorIs(t, err, ErrFeedInvalidMedia, key)
+	}
+	_, err = s.buildFeedMedia([]models.FeedMediaRequest{testFeedMedia(1, "a.jpg"), testFeedMedia(1, "a.jpg")}, usable)
+	assert.ErrorIs(t, err, ErrFeedInvalidMedia)
+
+	// 编辑时可以保留帖子已有的附件
+	_, err = s.buildFeedMedia([]models.FeedMediaRequest{{OssKey: "assets/images/a.jpg", Width: 1, Height: 1}}, map[string]bool{"assets/images/a.jpg": true})
+	assert.NoError(t, err)
+}
+
+func TestFeedService_EditAndDeletePost(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		author, err := userService.CreateUser(getTestUser1("_editpost"))
+		require.NoError(t, err)
+		other, err := userService.CreateUser(getTestUser2("_editpost"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(author.ID)
+			_ = userService.DeleteUser(other.ID)
+		}()
+
+		_, err = feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{})
+		assert.ErrorIs(t, err, ErrFeedEmptyPost)
+
+		recordTestUploads(t, author.ID, testFeedMedia(author.ID, "first.jpg"), testFeedMedia(author.ID, "second.jpg"))
+		post, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{
+			Media: []models.FeedMediaRequest{testFeedMedia(author.ID, "first.jpg"), testFeedMedia(author.ID, "second.jpg")},
+		})
+		require.NoError(t, err)
+		postID := strconv.FormatUint(post.ID, 10)
+
+		detail, err := feedService.GetFeedPostByID(postID)
+		require.NoError(t, err)
+		require.Len(t, detail.Media, 2)
+		assert.Equal(t, feedMediaPrefix(author.ID)+"first.jpg", detail.Media[0].OssKey)
+		assert.Equal(t, 640, detail.Media[0].Width)
+
+		// 只有作者可以编辑，媒体附件整体替换
+		_, err = feedService.EditFeedPost(other.ID, postID, models.UpdateFeedPostRequest{Content: "hijack"})
+		assert.ErrorIs(t, err, ErrFeedForbidden)
+		edited, err := feedService.EditFeedPost(author.ID, postID, models.UpdateFeedPostRequest{
+			Content: "edited",
+			Media:   []models.FeedMediaRequest{testFeedMedia(author.ID, "second.jpg")},
+		})
+		require.NoError(t, err)
+		assert.NotNil(t, edited.EditedAt)
+		detail, err = feedService.GetFeedPostByID(postID)
+		require.NoError(t, err)
+		assert.Equal(t, "edited", detail.Content)
+		require.Len(t, detail.Media, 1)
+		assert.Equal(t, feedMediaPrefix(author.ID)+"second.jpg", detail.Media[0].OssKey)
+		assert.Equal(t, 0, detail.Media[0].Position)
+
+		// 删除帖子时评论、点赞和媒体附件一并删除
+		_, err = feedService.SetFeedPostLike(other.ID, postID, true)
+		require.NoError(t, err)
+		comment, err := feedService.CreateFeedComment(other.ID, postID, models.CreateFeedCommentRequest{Content: "nice"})
+		require.NoError(t, err)
+		_, err = feedService.SetFeedCommentLike(author.ID, strconv.FormatUint(comment.ID, 10), true)
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, feedService.DeleteFeedPost(other.ID, postID, false), ErrFeedForbidden)
+		require.NoError(t, feedService.DeleteFeedPost(author.ID, postID, false))
+		_, err = feedService.GetFeedPostByID(postID)
+		assert.ErrorIs(t, err, ErrFeedPostNotFound)
+		for _, query := range []struct {
+			model any
+			where string
+			id    uint64
+		}{
+			{&models.FeedComment{}, "post_id = ?", post.ID},
+			{&models.PostLike{}, "post_id = ?", post.ID},
+			{&models.FeedMedia{}, "post_id = ?", post.ID},
+			{&models.FeedCommentLike{}, "comment_id = ?", comment.ID},
+		} {
+			var count int64
+			require.NoError(t, testutil.TestDB.Model(query.model).Where(query.where, query.id).Count(&count).Error)
+			assert.Zero(t, count, "%T", query.model)
+		}
+
+		// 管理员可以删除他人的帖子
+		moderated, err := feedService.CreateFeedPost(author.ID, models.CreateFeedPostRequest{Content: "moderated"})
+		require.NoError(t, err)
+		require.NoError(t, feedService.DeleteFeedPost(other.ID, strconv.FormatUint(moderated.ID, 10), true))
+	})
+}
+
+func TestFeedService_MediaOwnership(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		feedService := NewFeedService(testutil.TestDB, userService)
+
+		alice, err := userService.CreateUser(getTestUser1("_media"))
+		require.NoError(t, err)
+		bob, err := userService.CreateUser(getTestUser2("_media"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(alice.ID)
+			_ = userService.DeleteUser(bob.ID)
+		}()
+
+		// 没有申请上传过的对象键不能使用，即使位于自己的目录下
+		photo := testFeedMedia(alice.ID, "photo.jpg")
+		_, err = feedService.CreateFeedPost(alice.ID, models.CreateFeedPostRequest{Media: []models.FeedMediaRequest{photo}})
+		assert.ErrorIs(t, err, ErrFeedInvalidMedia)
+		recordTestUploads(t, alice.ID, photo)
+		post, err := feedService.CreateFeedPost(alice.ID, models.CreateFeedPostRequest{Media: []models.FeedMediaRequest{photo}})
+		require.NoError(t, err)
+
+		// 不能引用他人申请上传的图片
+		_, err = feedService.CreateFeedPost(bob.ID, models.CreateFeedPostRequest{Media: []models.FeedMediaRequest{photo}})
+		assert.ErrorIs(t, err, ErrFeedInvalidMedia)
+		bobPost, err := feedService.CreateFeedPost(bob.ID, models.CreateFeedPostRequest{Content: "bob"})
+		require.NoError(t, err)
+		bobPostID := strconv.FormatUint(bobPost.ID, 10)
+		_, err = feedService.EditFeedPost(bob.ID, bobPostID, models.UpdateFeedPostRequest{Media: []models.FeedMediaRequest{photo}})
+		assert.ErrorIs(t, err, ErrFeedInvalidMedia)
+
+		// 头像引用了他人的图片后删除帖子，文件仍被引用，不会被删除
+		_, err = userService.UpdateUser(bob.ID, models.UserUpdateRequest{AvatarOssKey: photo.OssKey})
+		require.NoError(t, err)
+		require.NoError(t, feedService.DeleteFeedPost(bob.ID, bobPostID, false))
+		require.NoError(t, feedService.DeleteFeedPost(alice.ID, strconv.FormatUint(post.ID, 10), false))
+		referenced, err := userService.ossKeyReferenced(photo.OssKey)
+		require.NoError(t, err)
+		assert.True(t, referenced)
+
+		_, err = userService.UpdateUser(bob.ID, models.UserUpdateRequest{AvatarOssKey: feedMediaPrefix(bob.ID) + "avatar.jpg"})
+		require.NoError(t, err)
+		referenced, err = userService.ossKeyReferenced(photo.OssKey)
+		require.NoError(t, err)
+		assert.False(t, referenced)
+	})
+}
@@ -37,6 +37,18 @@ func (m *FeedSyncManager) StartSyncTasks() error {
 		return err
 	}
 
+	// 每小时删除注销宽限期已过的账号
+	_, err = m.cron.AddFunc("@every 1h", m.purgeDeletedAccounts)
+	if err != nil {
+		return err
+	}
+
+	// 每10分钟重建热门排行，修正增量更新遗漏的变化
+	_, err = m.cron.AddFunc("@every 10m", m.rebuildHotRanking)
+	if err != nil {
+		return err
+	}
+
 	// 每天凌晨2点执行全量数据清理
 	_, err = m.cron.AddFunc("0 2 * * *", m.fullDataCleanup)
 	if err != nil {
@@ -91,6 +103,30 @@ func (m *FeedSyncManager) cleanOrphanData() {
 	logrus.Info("Orphan data cleanup completed")
 }
 
+// purgeDeletedAccounts 删除注销宽限期已过的账号及其全部数据
+func (m *FeedSyncManager) purgeDeletedAccounts() {
+	purged, err := m.userService.PurgeScheduledDeletions()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to purge deleted accounts")
+		return
+	}
+
+	if purged > 0 {
+		logrus.WithField("count", purged).Info("Deleted accounts purged")
+	}
+}
+
+// rebuildHotRanking 重建热门排行
+func (m *FeedSyncManager) rebuildHotRanking() {
+	count, err := m.feedService.RebuildHotRanking()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to rebuild hot ranking")
+		return
+	}
+
+	logrus.WithField("count", count).Debug("Hot ranking rebuilt")
+}
+
 // fullDataCleanup 全量数据清理
 func (m *FeedSyncManager) fullDataCleanup() {
 	logrus.Info("Starting full data cleanup task")
@@ -16,7 +16,7 @@ func getTestUser1(suffix string) models.UserCreateRequest {
 	return models.UserCreateRequest{
 		Username: "feeduser1" + suffix + "_" + timestamp,
 		Email:    "feeduser1" + suffix + "_" + timestamp + "@example.com",
-		Password: "password123",
+		Password: "Password123",
 	}
 }
 
@@ -25,7 +25,7 @@ func getTestUser2(suffix string) models.UserCreateRequest {
 	return models.UserCreateRequest{
 		Username: "feeduser2" + suffix + "_" + timestamp,
 		Email:    "feeduser2" + suffix + "_" + timestamp + "@example.com",
-		Password: "password123",
+		Password: "Password123",
 	}
 }
 
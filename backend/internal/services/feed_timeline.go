@@ -0,0 +1,323 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/models"
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// timelineMarker 时间线缓存包含推模式用户的全部帖子时保留的占位成员，分数为0
+// 缓存裁剪时分数最低的占位成员最先被移除，此后更早的帖子需要从数据库读取
+const timelineMarker = "0"
+
+// fanOutScript 时间线缓存存在时写入帖子并裁剪到最大长度
+// 缓存不存在时不写入，避免只有部分帖子的缓存被当作完整结果，等读取时从数据库重建
+var fanOutScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[1])
+redis.call("ZREMRANGEBYRANK", KEYS[1], 0, -(tonumber(ARGV[2]) + 1))
+return 1
+`)
+
+func timelineKey(userID uint64) string {
+	return config.FeedTimelineKeyPrefix + strconv.FormatUint(userID, 10)
+}
+
+// GetTimeline 获取关注的人和自己发布的帖子，按发布顺序倒序，游标与帖子列表同样签名，只能由本人使用
+// 粉丝数少的用户的帖子从 Redis 时间线读取，粉丝数多的用户和自己的帖子从数据库读取，合并后分页
+func (s *FeedService) GetTimeline(userID uint64, params models.TimelineQueryParams) ([]models.FeedPostResponseItem, string, bool, error) {
+	var afterID uint64
+	if params.AfterID != "" {
+		cursor, err := s.decodeCursor(params.AfterID, cursorTimeline, userID)
+		if err != nil {
+			return nil, "", false, err
+		}
+		afterID = cursor.ID
+	}
+
+	var followees []uint64
+	if err := s.DB.Model(&models.UserFollow{}).Where("follower_id = ?", userID).Pluck("followee_id", &followees).Error; err != nil {
+		return nil, "", false, err
+	}
+	large, small, err := s.splitByFanout(followees)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	// 多取一条判断是否还有更多
+	count := params.Limit + 1
+	pullUsers := append([]uint64{userID}, large...)
+	var candidates []uint64
+	if s.Redis != nil {
+		pushed, complete, err := s.readTimelineCache(context.Background(), userID, small, afterID, count)
+		if err != nil {
+			logrus.WithError(err).WithField("user_id", userID).Warn("读取时间线缓存失败，从数据库读取")
+			pullUsers = append(pullUsers, small...)
+		} else {
+			candidates = pushed
+			// 缓存已裁剪且不够一页时，更早的帖子只能从数据库读取
+			if len(pushed) < count && !complete {
+				pullUsers = append(pullUsers, small...)
+			}
+		}
+	} else {
+		pullUsers = append(pullUsers, small...)
+	}
+
+	pulled, err := s.latestPostIDs(pullUsers, afterID, count)
+	if err != nil {
+		return nil, "", false, err
+	}
+	candidates = append(candidates, pulled...)
+
+	// 帖子ID随发布时间递增，按ID倒序合并去重
+	slices.Sort(candidates)
+	slices.Reverse(candidates)
+	candidates = slices.Compact(candidates)
+
+	hasMore := len(candidates) > params.Limit
+	if hasMore {
+		candidates = candidates[:params.Limit]
+	}
+
+	var nextCursor string
+	if hasMore && len(candidates) > 0 {
+		token, err := s.encodeCursor(feedCursor{Sort: cursorTimeline, Scope: userID, ID: candidates[len(candidates)-1]})
+		if err != nil {
+			return nil, "", false, err
+		}
+		nextCursor = token
+	}
+
+	// 缓存中可能有已删除的帖子或已取消关注的用户的帖子，查询时过滤
+	var posts []models.FeedPost
+	if len(candidates) > 0 {
+		authors := append([]uint64{userID}, followees...)
+		if err := s.DB.Preload("Media", orderFeedMedia).
+			Where("id IN ? AND user_id IN ?", candidates, authors).
+			Order("id DESC").Find(&posts).Error; err != nil {
+			return nil, "", false, err
+		}
+	}
+
+	return s.buildPostResponseItems(posts, params.CommentCount), nextCursor, hasMore, nil
+}
+
+// splitByFanout 按粉丝数把用户分为拉模式（粉丝数达到阈值）和推模式两组
+func (s *FeedService) splitByFanout(userIDs []uint64) (large, small []uint64, err error) {
+	if len(userIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	err = s.DB.Model(&models.UserFollow{}).
+		Where("followee_id IN ?", userIDs).
+		Group("followee_id").
+		Having("COUNT(*) >= ?", config.FeedFanoutFollowerThreshold).
+		Pluck("followee_id", &large).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, id := range userIDs {
+		if !slices.Contains(large, id) {
+			small = append(small, id)
+		}
+	}
+	return large, small, nil
+}
+
+// latestPostIDs 从数据库读取指定用户发布的、ID小于 afterID 的最新帖子ID，afterID 为0表示从最新开始
+func (s *FeedService) latestPostIDs(userIDs []uint64, afterID uint64, count int) ([]uint64, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	query := s.DB.Model(&models.FeedPost{}).Where("user_id IN ?", userIDs)
+	if afterID != 0 {
+		query = query.Where("id < ?", afterID)
+	}
+
+	var ids []uint64
+	err := query.Order("id DESC").Limit(count).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// readTimelineCache 读取时间线缓存中早于 afterID 的帖子ID，缓存不存在时从数据库重建
+// complete 表示缓存包含推模式用户的全部帖子，没有被裁剪
+func (s *FeedService) readTimelineCache(ctx context.Context, userID uint64, small []uint64, afterID uint64, count int) ([]uint64, bool, error) {
+	key := timelineKey(userID)
+
+	exists, err := s.Redis.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if exists == 0 {
+		if err := s.rebuildTimeline(ctx, key, small); err != nil {
+			return nil, false, err
+		}
+	}
+
+	maxScore := "+inf"
+	if afterID != 0 {
+		maxScore = "(" + strconv.FormatUint(afterID, 10)
+	}
+
+	pipe := s.Redis.Pipeline()
+	rangeCmd := pipe.ZRevRangeByScore(ctx, key, &redis.ZRangeBy{Max: maxScore, Min: "(0", Count: int64(count)})
+	markerCmd := pipe.ZScore(ctx, key, timelineMarker)
+	pipe.Expire(ctx, key, config.FeedTimelineTTL)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, false, err
+	}
+
+	members, err := rangeCmd.Result()
+	if err != nil {
+		return nil, false, err
+	}
+	ids := make([]uint64, 0, len(members))
+	for _, member := range members {
+		id, err := strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, markerCmd.Err() == nil, nil
+}
+
+// rebuildTimeline 从数据库加载推模式用户的最新帖子写入时间线缓存
+func (s *FeedService) rebuildTimeline(ctx context.Context, key string, small []uint64) error {
+	ids, err := s.latestPostIDs(small, 0, config.FeedTimelineMaxLen)
+	if err != nil {
+		return err
+	}
+
+	members := make([]redis.Z, 0, len(ids)+1)
+	for _, id := range ids {
+		members = append(members, redis.Z{Score: float64(id), Member: strconv.FormatUint(id, 10)})
+	}
+	if len(ids) < config.FeedTimelineMaxLen {
+		members = append(members, redis.Z{Score: 0, Member: timelineMarker})
+	}
+
+	pipe := s.Redis.TxPipeline()
+	pipe.ZAdd(ctx, key, members...)
+	pipe.Expire(ctx, key, config.FeedTimelineTTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// fanOutPost 把帖子写入粉丝的时间线缓存，粉丝数达到阈值的用户不推送，由粉丝读取时查询
+func (s *FeedService) fanOutPost(authorID, postID uint64) {
+	ctx := context.Background()
+	logger := logrus.WithFields(logrus.Fields{"user_id": authorID, "post_id": postID})
+
+	var followers int64
+	if err := s.DB.Model(&models.UserFollow{}).Where("followee_id = ?", authorID).Count(&followers).Error; err != nil {
+		logger.WithError(err).Warn("推送时间线失败")
+		return
+	}
+	if followers >= int64(config.FeedFanoutFollowerThreshold) {
+		return
+	}
+
+	member := strconv.FormatUint(postID, 10)
+	var lastID uint64
+	for {
+		var follows []models.UserFollow
+		if err := s.DB.Select("id", "follower_id").
+			Where("followee_id = ? AND id > ?", authorID, lastID).
+			Order("id").Limit(config.FeedFanoutBatchSize).
+			Find(&follows).Error; err != nil {
+			logger.WithError(err).Warn("推送时间线失败")
+			return
+		}
+		if len(follows) == 0 {
+			return
+		}
+
+		pipe := s.Redis.Pipeline()
+		for _, follow := range follows {
+			fanOutScript.Eval(ctx, pipe, []string{timelineKey(follow.FollowerID)}, member, config.FeedTimelineMaxLen)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.WithError(err).Warn("推送时间线失败")
+			return
+		}
+
+		if len(follows) < config.FeedFanoutBatchSize {
+			return
+		}
+		lastID = follows[len(follows)-1].ID
+	}
+}
+
+// invalidateDemotedTimelines 粉丝减少后，粉丝数刚降到推模式阈值以下的用户删除其粉丝的时间线缓存
+// 粉丝数达到阈值期间发布的帖子没有推送，标记完整的缓存不会再从数据库读取这些帖子，删除后读取时重建
+// 每次每个用户只减少一个粉丝，粉丝数正好比阈值少一即为刚降到阈值以下
+func invalidateDemotedTimelines(db *gorm.DB, rdb *redis.Client, followeeIDs []uint64) {
+	if rdb == nil || len(followeeIDs) == 0 {
+		return
+	}
+
+	var demoted []uint64
+	if err := db.Model(&models.UserFollow{}).
+		Where("followee_id IN ?", followeeIDs).
+		Group("followee_id").
+		Having("COUNT(*) = ?", config.FeedFanoutFollowerThreshold-1).
+		Pluck("followee_id", &demoted).Error; err != nil {
+		logrus.WithError(err).Warn("检查推模式阈值失败")
+		return
+	}
+
+	ctx := context.Background()
+	for _, followeeID := range demoted {
+		logger := logrus.WithField("user_id", followeeID)
+		var lastID uint64
+		for {
+			var follows []models.UserFollow
+			if err := db.Select("id", "follower_id").
+				Where("followee_id = ? AND id > ?", followeeID, lastID).
+				Order("id").Limit(config.FeedFanoutBatchSize).
+				Find(&follows).Error; err != nil {
+				logger.WithError(err).Warn("删除粉丝时间线缓存失败")
+				break
+			}
+			if len(follows) == 0 {
+				break
+			}
+
+			keys := make([]string, len(follows))
+			for i, follow := range follows {
+				keys[i] = timelineKey(follow.FollowerID)
+			}
+			if err := rdb.Del(ctx, keys...).Err(); err != nil {
+				logger.WithError(err).Warn("删除粉丝时间线缓存失败")
+				break
+			}
+
+			if len(follows) < config.FeedFanoutBatchSize {
+				break
+			}
+			lastID = follows[len(follows)-1].ID
+		}
+	}
+}
+
+// invalidateTimeline 关注关系变化后删除时间线缓存，下次读取时重建
+func (s *FeedService) invalidateTimeline(userID uint64) {
+	if s.Redis == nil {
+		return
+	}
+	if err := s.Redis.Del(context.Background(), timelineKey(userID)).Err(); err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Warn("删除时间线缓存失败")
+	}
+}
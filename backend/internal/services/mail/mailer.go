@@ -0,0 +1,69 @@
+package mail
+
+import (
+	"ai-models-backend/internal/config"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	netmail "net/mail"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送，生产环境使用 SMTP，开发和测试环境写入文件或日志
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New 根据配置创建 Mailer：配置了 SMTP_HOST 时使用 SMTP，否则写入 MAIL_DIR，都未配置时输出到日志
+func New(cfg *config.Config) Mailer {
+	if cfg.SMTPHost != "" {
+		logrus.Infof("邮件通过 SMTP 发送: %s:%s", cfg.SMTPHost, cfg.SMTPPort)
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
+
+	if cfg.IsProd {
+		logrus.Warn("SMTP_HOST 未配置，邮件不会真正发出")
+	}
+	if cfg.MailDir != "" {
+		return NewFileMailer(cfg.MailDir, cfg.MailFrom)
+	}
+	return NewLogMailer(cfg.MailFrom)
+}
+
+// render 生成 RFC 5322 格式的邮件内容
+func render(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(msg.Body)
+	return buf.Bytes()
+}
+
+// validateMessage 收件人必须是合法地址，标题不能包含换行，防止注入邮件头
+func validateMessage(msg Message) error {
+	if _, err := netmail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	if strings.ContainsAny(msg.To+msg.Subject, "\r\n") {
+		return errors.New("invalid mail header")
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package mail
+
+import (
+	"ai-models-backend/internal/config"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	m := NewFileMailer(dir, "AI Models <no-reply@example.com>")
+	ctx := context.Background()
+
+	require.NoError(t, m.Send(ctx, Message{To: "alice@example.com", Subject: "请验证你的邮箱", Body: "link: http://localhost/verify"}))
+	require.NoError(t, m.Send(ctx, Message{To: "bob@example.com", Subject: "second", Body: "body"}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	content := string(data)
+	assert.Contains(t, content, "From: AI Models <no-reply@example.com>\r\n")
+	assert.Contains(t, content, "To: alice@example.com\r\n")
+	// 非 ASCII 标题需要编码
+	assert.Contains(t, content, "Subject: =?utf-8?b?")
+	assert.True(t, strings.HasSuffix(content, "\r\n\r\nlink: http://localhost/verify"))
+}
+
+func TestValidateMessage(t *testing.T) {
+	assert.NoError(t, validateMessage(Message{To: "alice@example.com", Subject: "hi"}))
+	assert.Error(t, validateMessage(Message{To: "not-an-address", Subject: "hi"}))
+	assert.Error(t, validateMessage(Message{To: "alice@example.com", Subject: "hi\r\nBcc: eve@example.com"}))
+
+	// 发送前校验，不合法的邮件不落盘
+	dir := t.TempDir()
+	err := NewFileMailer(dir, "no-reply@example.com").Send(context.Background(), Message{To: "bad", Subject: "hi"})
+	assert.Error(t, err)
+	entries, _ := os.ReadDir(dir)
+	assert.Empty(t, entries)
+}
+
+func TestNew(t *testing.T) {
+	assert.IsType(t, &SMTPMailer{}, New(&config.Config{SMTPHost: "smtp.example.com"}))
+	assert.IsType(t, &FileMailer{}, New(&config.Config{MailDir: t.TempDir()}))
+	assert.IsType(t, &LogMailer{}, New(&config.Config{}))
+}
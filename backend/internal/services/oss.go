@@ -359,6 +359,12 @@ func genBase36String(length int) string {
 	return string(result)
 }
 
+// ReservedObjectKey 对象键是否位于由业务接口管理的目录下，通用OSS接口不能写入或删除
+// 帖子图片只能通过信息流接口申请上传，避免覆盖或删除他人已发布的图片
+func ReservedObjectKey(objectKey string) bool {
+	return strings.HasPrefix(objectKey, config.FeedMediaKeyPrefix)
+}
+
 // ValidateCfg 检查OSS配置是否完整
 func (s *OSSService) ValidateCfg() error {
 	if s.config.OSSAccessKeyID == "" {
@@ -17,6 +17,12 @@ const (
 	upPrefix = "test/"
 )
 
+func TestReservedObjectKey(t *testing.T) {
+	assert.True(t, ReservedObjectKey(feedMediaPrefix(1)+"a.jpg"))
+	assert.False(t, ReservedObjectKey("assets/images/a.jpg"))
+	assert.False(t, ReservedObjectKey(objKey))
+}
+
 func TestOSSService_Simple(t *testing.T) {
 	testutil.RunWithEnv(t, func(t *testing.T) {
 		cfg := testutil.TestConfig
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/pkg/utils"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrWeakPassword        = errors.New("密码强度不足")
+	ErrUnknownPasswordHash = errors.New("无法识别的密码哈希格式")
+)
+
+// PasswordHasher 密码哈希算法，新增算法时实现该接口并加入 passwordHashers
+type PasswordHasher interface {
+	Name() string
+	Hash(password string) (string, error)
+	// Matches 判断哈希是否由该算法生成
+	Matches(encoded string) bool
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash 哈希参数是否与当前配置不同
+	NeedsRehash(encoded string) bool
+}
+
+// passwordHashers 当前支持的全部算法，按配置创建，参数变化后立即生效
+func passwordHashers() []PasswordHasher {
+	return []PasswordHasher{
+		&Argon2idHasher{
+			Time:    config.PasswordArgon2Time,
+			Memory:  config.PasswordArgon2Memory,
+			Threads: config.PasswordArgon2Threads,
+			KeyLen:  config.PasswordArgon2KeyLen,
+			SaltLen: config.PasswordArgon2SaltLen,
+		},
+		&BcryptHasher{Cost: config.PasswordBcryptCost},
+	}
+}
+
+// currentPasswordHasher 新密码使用的算法
+func currentPasswordHasher() (PasswordHasher, error) {
+	for _, hasher := range passwordHashers() {
+		if hasher.Name() == config.PasswordHashAlgorithm {
+			return hasher, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported password hash algorithm %q", config.PasswordHashAlgorithm)
+}
+
+// HashPassword 使用当前配置的算法生成密码哈希
+func HashPassword(password string) (string, error) {
+	hasher, err := currentPasswordHasher()
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(password)
+}
+
+// VerifyPassword 校验密码，needsRehash 表示哈希的算法或参数已过时，应在校验通过后重新哈希
+func VerifyPassword(encoded, password string) (ok bool, needsRehash bool, err error) {
+	current, err := currentPasswordHasher()
+	if err != nil {
+		return false, false, err
+	}
+
+	for _, hasher := range passwordHashers() {
+		if !hasher.Matches(encoded) {
+			continue
+		}
+		ok, err := hasher.Verify(encoded, password)
+		if err != nil || !ok {
+			return false, false, err
+		}
+		return true, hasher.Name() != current.Name() || hasher.NeedsRehash(encoded), nil
+	}
+	return false, false, ErrUnknownPasswordHash
+}
+
+// ValidatePassword 密码策略：长度限制，且包含大小写字母和数字，不能与用户名或邮箱相同
+func ValidatePassword(password string, identities ...string) error {
+	if len(password) < config.PasswordMinLength || len(password) > config.PasswordMaxLength {
+		return fmt.Errorf("%w：长度需在 %d 到 %d 个字符之间", ErrWeakPassword, config.PasswordMinLength, config.PasswordMaxLength)
+	}
+	if !utils.IsValidPassword(password) {
+		return fmt.Errorf("%w：需同时包含大写字母、小写字母和数字", ErrWeakPassword)
+	}
+	for _, identity := range identities {
+		if identity != "" && strings.EqualFold(password, identity) {
+			return fmt.Errorf("%w：不能与用户名或邮箱相同", ErrWeakPassword)
+		}
+	}
+	return nil
+}
+
+// BcryptHasher bcrypt 算法，兼容早期保存的哈希
+type BcryptHasher struct {
+	Cost int
+}
+
+func (h *BcryptHasher) Name() string {
+	return "bcrypt"
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	return string(hashed), err
+}
+
+func (h *BcryptHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
+
+// Argon2idHasher argon2id 算法，哈希使用 PHC 字符串格式：
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+	KeyLen  uint32
+	SaltLen int
+}
+
+type argon2idHash struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	key     []byte
+}
+
+func (h *Argon2idHasher) Name() string {
+	return "argon2id"
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Time, h.Memory, h.Threads, h.KeyLen)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.Memory, h.Time, h.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *Argon2idHasher) Matches(encoded string) bool {
+	return strings.HasPrefix(encoded, "$argon2id$")
+}
+
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	parsed, err := parseArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	// 按哈希中记录的参数计算，参数调整后旧哈希仍可校验
+	key := argon2.IDKey([]byte(password), parsed.salt, parsed.time, parsed.memory, parsed.threads, uint32(len(parsed.key)))
+	return subtle.ConstantTimeCompare(key, parsed.key) == 1, nil
+}
+
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	parsed, err := parseArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return parsed.memory != h.Memory || parsed.time != h.Time || parsed.threads != h.Threads ||
+		uint32(len(parsed.key)) != h.KeyLen || len(parsed.salt) != h.SaltLen
+}
+
+func parseArgon2id(encoded string) (*argon2idHash, error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, ErrUnknownPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return nil, fmt.Errorf("invalid argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	var parsed argon2idHash
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &parsed.memory, &parsed.time, &parsed.threads); err != nil {
+		return nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	var err error
+	if parsed.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	if parsed.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return nil, fmt.Errorf("invalid argon2id key: %w", err)
+	}
+	return &parsed, nil
+}
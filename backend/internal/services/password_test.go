@@ -0,0 +1,124 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// withPasswordConfig 临时修改密码哈希配置，使用较小的参数加快测试
+func withPasswordConfig(t *testing.T, algorithm string, bcryptCost int, argon2Memory uint32) {
+	algo, cost, memory, iterations := config.PasswordHashAlgorithm, config.PasswordBcryptCost, config.PasswordArgon2Memory, config.PasswordArgon2Time
+	config.PasswordHashAlgorithm = algorithm
+	config.PasswordBcryptCost = bcryptCost
+	config.PasswordArgon2Memory = argon2Memory
+	config.PasswordArgon2Time = 1
+	t.Cleanup(func() {
+		config.PasswordHashAlgorithm, config.PasswordBcryptCost, config.PasswordArgon2Memory, config.PasswordArgon2Time = algo, cost, memory, iterations
+	})
+}
+
+func TestPassword_Argon2id(t *testing.T) {
+	withPasswordConfig(t, "argon2id", bcrypt.MinCost, 1024)
+
+	hashed, err := HashPassword("Secret123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hashed, "$argon2id$v=19$m=1024,t=1,p=2$"))
+
+	other, err := HashPassword("Secret123")
+	require.NoError(t, err)
+	assert.NotEqual(t, hashed, other, "每次哈希使用不同的盐")
+
+	ok, needsRehash, err := VerifyPassword(hashed, "Secret123")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	ok, _, err = VerifyPassword(hashed, "Secret124")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 调整参数后旧哈希仍能校验，但需要重新哈希
+	config.PasswordArgon2Memory = 2048
+	ok, needsRehash, err = VerifyPassword(hashed, "Secret123")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+}
+
+func TestPassword_BcryptMigration(t *testing.T) {
+	withPasswordConfig(t, "argon2id", bcrypt.MinCost, 1024)
+
+	// 早期保存的 bcrypt 哈希
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	ok, needsRehash, err := VerifyPassword(string(legacy), "Secret123")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, needsRehash, "算法不同需要升级")
+
+	ok, needsRehash, err = VerifyPassword(string(legacy), "wrong")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+
+	// 继续使用 bcrypt 时，只有 cost 变化才需要升级
+	config.PasswordHashAlgorithm = "bcrypt"
+	_, needsRehash, err = VerifyPassword(string(legacy), "Secret123")
+	require.NoError(t, err)
+	assert.False(t, needsRehash)
+
+	config.PasswordBcryptCost = bcrypt.MinCost + 1
+	_, needsRehash, err = VerifyPassword(string(legacy), "Secret123")
+	require.NoError(t, err)
+	assert.True(t, needsRehash)
+
+	hashed, err := HashPassword("Secret123")
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hashed))
+	require.NoError(t, err)
+	assert.Equal(t, bcrypt.MinCost+1, cost)
+}
+
+func TestPassword_Invalid(t *testing.T) {
+	withPasswordConfig(t, "argon2id", bcrypt.MinCost, 1024)
+
+	_, _, err := VerifyPassword("Secret123", "Secret123")
+	assert.ErrorIs(t, err, ErrUnknownPasswordHash, "明文不是合法的哈希")
+
+	_, _, err = VerifyPassword("$argon2id$v=19$m=x$salt$key", "Secret123")
+	assert.Error(t, err)
+
+	config.PasswordHashAlgorithm = "md5"
+	_, err = HashPassword("Secret123")
+	assert.Error(t, err)
+}
+
+func TestValidatePassword(t *testing.T) {
+	cases := []struct {
+		password string
+		valid    bool
+	}{
+		{"Secret123", true},
+		{"Sec123", false},                  // 太短
+		{"secret123", false},               // 缺少大写字母
+		{"SECRET123", false},               // 缺少小写字母
+		{"SecretPass", false},              // 缺少数字
+		{"Alice2024", false},               // 与用户名相同
+		{strings.Repeat("Aa1", 30), false}, // 太长
+	}
+
+	for _, c := range cases {
+		err := ValidatePassword(c.password, "alice2024", "alice@example.com")
+		if c.valid {
+			assert.NoError(t, err, c.password)
+		} else {
+			assert.ErrorIs(t, err, ErrWeakPassword, c.password)
+		}
+	}
+}
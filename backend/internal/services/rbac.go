@@ -0,0 +1,351 @@
+package services
+
+import (
+	"ai-models-backend/internal/config"
+	"ai-models-backend/internal/database"
+	"ai-models-backend/internal/models"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRoleNotFound      = errors.New("角色不存在")
+	ErrInvalidRole       = errors.New("角色无效")
+	ErrRoleBuiltIn       = errors.New("内置角色不能删除或修改")
+	ErrRoleInUse         = errors.New("仍有用户属于该角色")
+	ErrLastAdministrator = errors.New("至少需要保留一个管理员")
+
+	ErrPermissionDenied      = errors.New("没有权限")
+	ErrRoleTwoFactorRequired = errors.New("当前角色要求两步验证，请启用两步验证后重新登录")
+)
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{1,31}$`)
+
+// builtInRoles 内置角色，启动时自动创建；admin 拥有全部权限，user 为注册用户的默认角色
+var builtInRoles = []models.Role{
+	{Name: models.RoleAdmin, Description: "管理员", Permissions: []string{models.PermAll}, BuiltIn: true},
+	{Name: models.RoleUser, Description: "普通用户", Permissions: []string{}, BuiltIn: true},
+}
+
+/**
+ * RBACService 角色权限服务
+ * 角色保存在数据库，按 config.RBACCacheTTL 缓存在内存，修改后本实例立即生效
+ */
+type RBACService struct {
+	BaseService
+
+	mu       sync.RWMutex
+	roles    map[string]*models.Role
+	loadedAt time.Time
+}
+
+func NewRBACService() *RBACService {
+	return &RBACService{
+		BaseService: BaseService{DB: database.DB},
+	}
+}
+
+// EnsureBuiltInRoles 创建缺失的内置角色，admin 的权限始终重置为全部权限
+func (s *RBACService) EnsureBuiltInRoles() error {
+	for _, role := range builtInRoles {
+		err := s.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&role).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	err := s.DB.Model(&models.Role{}).Where("name = ?", models.RoleAdmin).
+		Select("permissions", "built_in").
+		Updates(&models.Role{Permissions: []string{models.PermAll}, BuiltIn: true}).Error
+	if err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+// ListRoles 获取全部角色
+func (s *RBACService) ListRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := s.DB.Order("name").Find(&roles).Error
+	return roles, err
+}
+
+// ListPermissions 获取全部可分配的权限
+func (s *RBACService) ListPermissions() []models.PermissionInfo {
+	permissions := make([]models.PermissionInfo, 0, len(models.Permissions))
+	for name, description := range models.Permissions {
+		permissions = append(permissions, models.PermissionInfo{Name: name, Description: description})
+	}
+	sort.Slice(permissions, func(i, j int) bool {
+		return permissions[i].Name < permissions[j].Name
+	})
+	return permissions
+}
+
+// SaveRole 创建角色或更新角色的说明和权限
+func (s *RBACService) SaveRole(name string, req models.RoleRequest, actor models.ClientInfo, actorID uint64) (*models.Role, error) {
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w：名称只能包含小写字母、数字、下划线和连字符", ErrInvalidRole)
+	}
+	if name == models.RoleAdmin {
+		return nil, ErrRoleBuiltIn
+	}
+
+	permissions := make([]string, 0, len(req.Permissions))
+	seen := make(map[string]bool)
+	for _, permission := range req.Permissions {
+		if _, ok := models.Permissions[permission]; !ok {
+			return nil, fmt.Errorf("%w：未知的权限 %s", ErrInvalidRole, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			permissions = append(permissions, permission)
+		}
+	}
+	sort.Strings(permissions)
+
+	role := models.Role{Name: name}
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&role, "name = ?", name).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		role.Description = req.Description
+		role.Permissions = permissions
+		return tx.Save(&role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	s.recordAudit(models.AuditRoleUpdate, 0, actor, actorID, fmt.Sprintf("save role %s: %s", name, strings.Join(permissions, ",")))
+	return &role, nil
+}
+
+// DeleteRole 删除自定义角色，仍有用户属于该角色时不能删除
+func (s *RBACService) DeleteRole(name string, actor models.ClientInfo, actorID uint64) error {
+	var role models.Role
+	if err := s.DB.First(&role, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRoleNotFound
+		}
+		return err
+	}
+	if role.BuiltIn {
+		return ErrRoleBuiltIn
+	}
+	if s.ExistsByCondition(&models.User{}, map[string]any{"role": name}) {
+		return ErrRoleInUse
+	}
+
+	if err := s.DB.Delete(&role).Error; err != nil {
+		return err
+	}
+	s.invalidate()
+	s.recordAudit(models.AuditRoleUpdate, 0, actor, actorID, "delete role "+name)
+	return nil
+}
+
+// SetTwoFactorRequired 设置角色的用户是否必须通过两步验证登录，内置角色也可以设置
+// 已登录但未通过两步验证的会话立即失去该角色的权限，用户启用两步验证并重新登录后恢复
+func (s *RBACService) SetTwoFactorRequired(name string, required bool, actor models.ClientInfo, actorID uint64) (*models.Role, error) {
+	var role models.Role
+	if err := s.DB.First(&role, "name = ?", name).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.DB.Model(&role).Update("require_two_factor", required).Error; err != nil {
+		return nil, err
+	}
+	role.RequireTwoFactor = required
+
+	s.invalidate()
+	s.recordAudit(models.AuditRoleUpdate, 0, actor, actorID, fmt.Sprintf("role %s require_two_factor=%t", name, required))
+	return &role, nil
+}
+
+// AssignRole 修改用户的角色
+func (s *RBACService) AssignRole(userID uint64, roleName string, actor models.ClientInfo, actorID uint64) error {
+	if _, err := s.role(roleName); err != nil {
+		return err
+	}
+
+	var previous string
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		// 撤销管理员时至少保留一个，避免无人能再管理权限
+		if roleName != models.RoleAdmin {
+			if err := EnsureOtherAdministrator(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "role").First(&user, userID).Error; err != nil {
+			return err
+		}
+		previous = user.Role
+
+		return tx.Model(&user).Update("role", roleName).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	s.recordAudit(models.AuditRoleAssign, userID, actor, actorID, fmt.Sprintf("role %s -> %s", previous, roleName))
+	return nil
+}
+
+// EnsureOtherAdministrator 用户是管理员时，确认撤销、禁用或删除该用户后仍有其他可用的管理员（已启用且未申请注销）
+// 锁住全部管理员，并发操作时不会同时通过检查；需在锁定用户之前调用，与其他事务的加锁顺序保持一致
+func EnsureOtherAdministrator(tx *gorm.DB, userID uint64) error {
+	var adminIDs []uint64
+	err := tx.Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ?", models.RoleAdmin).
+		Order("id").
+		Pluck("id", &adminIDs).Error
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(adminIDs, userID) {
+		return nil
+	}
+
+	var others int64
+	err = tx.Model(&models.User{}).
+		Where("id IN ? AND id <> ? AND is_active = ? AND deletion_scheduled_at IS NULL", adminIDs, userID, true).
+		Count(&others).Error
+	if err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastAdministrator
+	}
+	return nil
+}
+
+// HasPermissions 用户是否拥有全部指定的权限
+func (s *RBACService) HasPermissions(userID uint64, permissions ...string) (bool, error) {
+	var roleName string
+	err := s.DB.Model(&models.User{}).Select("role").Where("id = ?", userID).Scan(&roleName).Error
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if !s.RoleHasPermission(roleName, permission) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Authorize 检查用户是否拥有全部指定的权限，角色要求两步验证时 twoFactor 表示当前会话是否通过了两步验证
+// 没有权限时返回 ErrPermissionDenied，缺少两步验证时返回 ErrRoleTwoFactorRequired
+func (s *RBACService) Authorize(userID uint64, twoFactor bool, permissions ...string) error {
+	var roleName string
+	err := s.DB.Model(&models.User{}).Select("role").Where("id = ?", userID).Scan(&roleName).Error
+	if err != nil {
+		return err
+	}
+
+	role, err := s.role(roleName)
+	if errors.Is(err, ErrRoleNotFound) {
+		return ErrPermissionDenied
+	}
+	if err != nil {
+		return err
+	}
+	for _, permission := range permissions {
+		if !role.HasPermission(permission) {
+			return ErrPermissionDenied
+		}
+	}
+
+	if role.RequireTwoFactor && !twoFactor {
+		return ErrRoleTwoFactorRequired
+	}
+	return nil
+}
+
+// RoleHasPermission 角色是否拥有指定权限，角色不存在时没有任何权限
+func (s *RBACService) RoleHasPermission(roleName, permission string) bool {
+	role, err := s.role(roleName)
+	if err != nil {
+		if !errors.Is(err, ErrRoleNotFound) {
+			logrus.WithError(err).WithField("role", roleName).Error("Failed to load role")
+		}
+		return false
+	}
+	return role.HasPermission(permission)
+}
+
+// role 从缓存中获取角色，缓存过期时重新加载
+func (s *RBACService) role(name string) (*models.Role, error) {
+	s.mu.RLock()
+	roles, loadedAt := s.roles, s.loadedAt
+	s.mu.RUnlock()
+
+	if roles == nil || time.Since(loadedAt) > config.RBACCacheTTL {
+		var err error
+		if roles, err = s.loadRoles(); err != nil {
+			return nil, err
+		}
+	}
+
+	role, ok := roles[name]
+	if !ok {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (s *RBACService) loadRoles() (map[string]*models.Role, error) {
+	var list []models.Role
+	if err := s.DB.Find(&list).Error; err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string]*models.Role, len(list))
+	for i := range list {
+		roles[list[i].Name] = &list[i]
+	}
+
+	s.mu.Lock()
+	s.roles, s.loadedAt = roles, time.Now()
+	s.mu.Unlock()
+	return roles, nil
+}
+
+func (s *RBACService) recordAudit(action string, userID uint64, actor models.ClientInfo, actorID uint64, detail string) {
+	RecordAudit(s.DB, models.AuditLog{
+		Action:    action,
+		UserID:    userID,
+		ActorID:   actorID,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+		Detail:    detail,
+	})
+}
+
+func (s *RBACService) invalidate() {
+	s.mu.Lock()
+	s.roles = nil
+	s.mu.Unlock()
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"ai-models-backend/internal/models"
+	"ai-models-backend/internal/testutil"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRole_HasPermission(t *testing.T) {
+	admin := models.Role{Permissions: []string{models.PermAll}}
+	assert.True(t, admin.HasPermission(models.PermUsersWrite))
+	assert.True(t, admin.HasPermission("anything.new"))
+
+	moderator := models.Role{Permissions: []string{models.PermAdminAccess, models.PermFeedModerate}}
+	assert.True(t, moderator.HasPermission(models.PermFeedModerate))
+	assert.False(t, moderator.HasPermission(models.PermUsersWrite))
+
+	assert.False(t, (&models.Role{}).HasPermission(models.PermAdminAccess))
+}
+
+func TestRBACService_Roles(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		rbac := NewRBACService()
+		require.NoError(t, rbac.EnsureBuiltInRoles())
+		// 可重复执行
+		require.NoError(t, rbac.EnsureBuiltInRoles())
+
+		userService := NewUserService(testutil.TestConfig)
+		timestamp := strconv.FormatInt(time.Now().UnixNano(), 10)
+		user, err := userService.CreateUser(models.UserCreateRequest{
+			Username: "rbacuser_" + timestamp,
+			Email:    "rbacuser_" + timestamp + "@example.com",
+			Password: "Password123",
+		})
+		require.NoError(t, err)
+		roleName := "mod_" + timestamp[len(timestamp)-8:]
+		defer func() {
+			_ = userService.DeleteUser(user.ID)
+			_ = rbac.DeleteRole(roleName, models.ClientInfo{}, 0)
+			testutil.TestDB.Where("user_id = ?", user.ID).Delete(&models.AuditLog{})
+		}()
+
+		// 注册用户默认没有任何管理权限
+		allowed, err := rbac.HasPermissions(user.ID, models.PermAdminAccess)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		// 权限必须是已定义的，admin 角色不能修改
+		_, err = rbac.SaveRole(roleName, models.RoleRequest{Permissions: []string{"feed.destroy"}}, models.ClientInfo{}, 0)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		_, err = rbac.SaveRole("Bad Name", models.RoleRequest{Permissions: []string{}}, models.ClientInfo{}, 0)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		_, err = rbac.SaveRole(models.RoleAdmin, models.RoleRequest{Permissions: []string{}}, models.ClientInfo{}, 0)
+		assert.ErrorIs(t, err, ErrRoleBuiltIn)
+
+		role, err := rbac.SaveRole(roleName, models.RoleRequest{
+			Description: "版主",
+			Permissions: []string{models.PermFeedModerate, models.PermAdminAccess, models.PermFeedModerate},
+		}, models.ClientInfo{IP: "127.0.0.1"}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{models.PermAdminAccess, models.PermFeedModerate}, role.Permissions)
+
+		assert.ErrorIs(t, rbac.AssignRole(user.ID, "no_such_role", models.ClientInfo{}, 1), ErrRoleNotFound)
+		require.NoError(t, rbac.AssignRole(user.ID, roleName, models.ClientInfo{}, 1))
+
+		allowed, err = rbac.HasPermissions(user.ID, models.PermAdminAccess, models.PermFeedModerate)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		allowed, err = rbac.HasPermissions(user.ID, models.PermAdminAccess, models.PermUsersWrite)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		var audit models.AuditLog
+		require.NoError(t, testutil.TestDB.Where("user_id = ? AND action = ?", user.ID, models.AuditRoleAssign).First(&audit).Error)
+		assert.Equal(t, "role user -> "+roleName, audit.Detail)
+
+		// 修改角色权限后立即生效
+		_, err = rbac.SaveRole(roleName, models.RoleRequest{Permissions: []string{models.PermAdminAccess}}, models.ClientInfo{}, 1)
+		require.NoError(t, err)
+		allowed, err = rbac.HasPermissions(user.ID, models.PermFeedModerate)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+
+		// 仍有用户的角色和内置角色不能删除
+		assert.ErrorIs(t, rbac.DeleteRole(roleName, models.ClientInfo{}, 1), ErrRoleInUse)
+		assert.ErrorIs(t, rbac.DeleteRole(models.RoleUser, models.ClientInfo{}, 1), ErrRoleBuiltIn)
+		require.NoError(t, rbac.AssignRole(user.ID, models.RoleUser, models.ClientInfo{}, 1))
+		require.NoError(t, rbac.DeleteRole(roleName, models.ClientInfo{}, 1))
+		assert.ErrorIs(t, rbac.DeleteRole(roleName, models.ClientInfo{}, 1), ErrRoleNotFound)
+	})
+}
+
+func TestEnsureOtherAdministrator(t *testing.T) {
+	testutil.RunWithTestDB(t, func(t *testing.T) {
+		userService := NewUserService(testutil.TestConfig)
+		admin, err := userService.CreateUser(getTestUser1("_lastadmin"))
+		require.NoError(t, err)
+		other, err := userService.CreateUser(getTestUser2("_lastadmin"))
+		require.NoError(t, err)
+		defer func() {
+			_ = userService.DeleteUser(admin.ID)
+			_ = userService.DeleteUser(other.ID)
+		}()
+
+		// 在事务中把其他管理员降级，检查结束后回滚
+		errRollback := errors.New("rollback")
+		err = testutil.TestDB.Transaction(func(tx *gorm.DB) error {
+			require.NoError(t, tx.Model(&models.User{}).Where("role = ?", models.RoleAdmin).Update("role", models.RoleUser).Error)
+			require.NoError(t, tx.Model(admin).Update("role", models.RoleAdmin).Error)
+
+			assert.ErrorIs(t, EnsureOtherAdministrator(tx, admin.ID), ErrLastAdministrator)
+			// 不是管理员的用户不受限制
+			assert.NoError(t, EnsureOtherAdministrator(tx, other.ID))
+
+			// 已禁用或已申请注销的管理员不算
+			require.NoError(t, tx.Model(other).Updates(map[string]any{"role": models.RoleAdmin, "is_active": false}).Error)
+			assert.ErrorIs(t, EnsureOtherAdministrator(tx, admin.ID), ErrLastAdministrator)
+			require.NoError(t, tx.Model(other).Updates(map[string]any{"is_active": true, "deletion_scheduled_at": time.Now()}).Error)
+			assert.ErrorIs(t, EnsureOtherAdministrator(tx, admin.ID), ErrLastAdministrator)
+
+			require.NoError(t, tx.Model(other).Update("deletion_scheduled_at", nil).Error)
+			assert.NoError(t, EnsureOtherAdministrator(tx, admin.ID))
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+	})
+}
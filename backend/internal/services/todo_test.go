@@ -17,7 +17,7 @@ func TestTodoService_CRUD(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser",
 			Email:    "test@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -81,7 +81,7 @@ func TestTodoService_InvalidRequests(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser2",
 			Email:    "test2@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -123,7 +123,7 @@ func TestTodoService_ToggleComplete(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser3",
 			Email:    "test3@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -164,7 +164,7 @@ func TestTodoService_PositionAndStats(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser4",
 			Email:    "test4@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -237,7 +237,7 @@ func TestTodoService_PositionUpdate(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser5",
 			Email:    "test5@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -317,7 +317,7 @@ func TestTodoService_DueDate(t *testing.T) {
 		userReq := models.UserCreateRequest{
 			Username: "testuser6",
 			Email:    "test6@example.com",
-			Password: "password123",
+			Password: "Password123",
 		}
 		user, err := userService.CreateUser(userReq)
 		require.NoError(t, err)
@@ -8,7 +8,6 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -24,7 +23,7 @@ type UserService struct {
 
 func NewUserService(cfg *config.Config) *UserService {
 	return &UserService{
-		BaseService: BaseService{DB: database.DB},
+		BaseService: BaseService{DB: database.DB, Redis: database.Redis},
 		config:      cfg,
 		ossService:  NewOSSService(cfg),
 	}
@@ -47,8 +46,13 @@ func (s *UserService) CreateUser(req models.UserCreateRequest) (*models.User, er
 		return nil, errors.New("邮箱已存在")
 	}
 
+	// 检查密码强度
+	if err := ValidatePassword(req.Password, req.Username, req.Email); err != nil {
+		return nil, err
+	}
+
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := HashPassword(req.Password)
 	if err != nil {
 		return nil, err
 	}
@@ -56,16 +60,11 @@ func (s *UserService) CreateUser(req models.UserCreateRequest) (*models.User, er
 	user := &models.User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Role:     models.RoleUser, // 默认角色为普通用户
 		IsActive: true,
 	}
 
-	// 根据配置决定是否存储明文密码
-	if config.UserStorePlainPassword {
-		user.PlainPassword = req.Password
-	}
-
 	// 保存到数据库
 	if err := s.DB.Create(user).Error; err != nil {
 		return nil, err
@@ -109,7 +108,9 @@ func (s *UserService) UpdateUser(id uint64, req models.UserUpdateRequest) (*mode
 		if s.ExistsByConditionExcludeID(&models.User{}, map[string]any{"email": req.Email}, id) {
 			return nil, errors.New("邮箱已存在")
 		}
+		// 新邮箱需要重新验证
 		user.Email = req.Email
+		user.EmailVerifiedAt = nil
 	}
 
 	// 更新头像
@@ -118,11 +119,10 @@ func (s *UserService) UpdateUser(id uint64, req models.UserUpdateRequest) (*mode
 	}
 
 	// 更新头像OSS密钥
+	var oldOssKey string
 	if req.AvatarOssKey != "" {
-		// 如果更换了新的OSS key，异步删除旧的文件
-		oldOssKey := user.AvatarOssKey
-		if oldOssKey != "" && oldOssKey != req.AvatarOssKey {
-			go s.deleteOSSFileAsync(oldOssKey)
+		if user.AvatarOssKey != req.AvatarOssKey {
+			oldOssKey = user.AvatarOssKey
 		}
 		user.AvatarOssKey = req.AvatarOssKey
 	}
@@ -147,6 +147,11 @@ func (s *UserService) UpdateUser(id uint64, req models.UserUpdateRequest) (*mode
 		return nil, err
 	}
 
+	// 更换了新的OSS key，保存后异步删除旧的文件
+	if oldOssKey != "" {
+		go s.deleteOSSFileAsync(oldOssKey)
+	}
+
 	return &user, nil
 }
 
@@ -176,23 +181,8 @@ func (s *UserService) GetUsers(page, limit int) (*models.PaginationResponse, err
 
 // DeleteUser 删除用户
 func (s *UserService) DeleteUser(id uint64) error {
-	// 先获取用户信息，检查是否有OSS key需要删除
-	user, err := s.GetUserByID(id)
-	if err != nil {
-		return err
-	}
-
-	// 删除用户记录
-	if err := s.HardDelete(&models.User{}, id); err != nil {
-		return err
-	}
-
-	// 如果用户有头像OSS key，异步静默删除OSS上的文件
-	if user.AvatarOssKey != "" {
-		go s.deleteOSSFileAsync(user.AvatarOssKey)
-	}
-
-	return nil
+	// 用户的全部数据一并删除，不再等待定时清理孤儿数据
+	return s.PurgeUser(id, "deleted by admin")
 }
 
 // ActivateUser 启用用户
@@ -200,9 +190,21 @@ func (s *UserService) ActivateUser(id uint64) error {
 	return s.UpdateField(&models.User{}, id, "is_active", true)
 }
 
-// DeactivateUser 禁用用户
+// DeactivateUser 禁用用户，不能禁用最后一个管理员
 func (s *UserService) DeactivateUser(id uint64) error {
-	return s.UpdateField(&models.User{}, id, "is_active", false)
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := EnsureOtherAdministrator(tx, id); err != nil {
+			return err
+		}
+		result := tx.Model(&models.User{}).Where("id = ?", id).Update("is_active", false)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("记录不存在")
+		}
+		return nil
+	})
 }
 
 // ChangePassword 修改用户密码
@@ -217,30 +219,27 @@ func (s *UserService) ChangePassword(userID uint64, req models.ChangePasswordReq
 	}
 
 	// 验证旧密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
-		return errors.New("原密码错误")
-	}
-
-	// 加密新密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	ok, _, err := VerifyPassword(user.Password, req.OldPassword)
 	if err != nil {
 		return err
 	}
-
-	// 更新密码
-	updates := map[string]any{
-		"password": string(hashedPassword),
+	if !ok {
+		return errors.New("原密码错误")
 	}
 
-	if config.UserStorePlainPassword {
-		updates["plain_password"] = req.NewPassword
+	// 检查新密码强度
+	if err := ValidatePassword(req.NewPassword, user.Username, user.Email); err != nil {
+		return err
 	}
 
-	if err := s.DB.Model(&user).Updates(updates).Error; err != nil {
+	// 加密新密码
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
 		return err
 	}
 
-	return nil
+	// 更新密码
+	return s.DB.Model(&user).Updates(map[string]any{"password": hashedPassword, "password_generated": false}).Error
 }
 
 // GetUserCount 获取用户总数
@@ -259,32 +258,36 @@ func (s *UserService) GetAdminUserCount() (int, error) {
 
 // ResetPassword 重置用户密码
 func (s *UserService) ResetPassword(userID string, newPassword string) error {
-	// 加密新密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	if err != nil {
+	var user models.User
+	if err := s.DB.Select("id", "username", "email").Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("用户不存在")
+		}
 		return err
 	}
 
-	// 更新数据库中的密码
-	updateData := map[string]interface{}{
-		"password": string(hashedPassword),
+	// 检查新密码强度
+	if err := ValidatePassword(newPassword, user.Username, user.Email); err != nil {
+		return err
 	}
 
-	// 根据配置决定是否更新明文密码
-	if config.UserStorePlainPassword {
-		updateData["plain_password"] = newPassword
+	// 加密新密码
+	hashedPassword, err := HashPassword(newPassword)
+	if err != nil {
+		return err
 	}
 
-	return s.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updateData).Error
+	// 更新数据库中的密码
+	return s.DB.Model(&user).Updates(map[string]any{"password": hashedPassword, "password_generated": false}).Error
 }
 
-// IsAdmin 检查用户是否为管理员
-func (s *UserService) IsAdmin(userID uint64) (bool, error) {
+// IsEmailVerified 检查用户邮箱是否已验证
+func (s *UserService) IsEmailVerified(userID uint64) (bool, error) {
 	user, err := s.GetUserByID(userID)
 	if err != nil {
 		return false, err
 	}
-	return user.IsAdmin(), nil
+	return user.IsEmailVerified(), nil
 }
 
 // GetRecentlyUpdatedUsers 获取最近更新的用户列表
@@ -294,13 +297,38 @@ func (s *UserService) GetRecentlyUpdatedUsers(since time.Time) ([]models.User, e
 	return users, err
 }
 
-// deleteOSSFileAsync 异步静默删除OSS文件
+// ossKeyReferenced OSS文件是否仍被帖子媒体附件或用户头像引用
+func (s *UserService) ossKeyReferenced(ossKey string) (bool, error) {
+	var count int64
+	if err := s.DB.Model(&models.FeedMedia{}).Where("oss_key = ?", ossKey).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+	if err := s.DB.Model(&models.User{}).Where("avatar_oss_key = ?", ossKey).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// deleteOSSFileAsync 异步静默删除OSS文件，文件仍被引用或无法确认时不删除
 func (s *UserService) deleteOSSFileAsync(ossKey string) {
 	if s.ossService == nil {
 		logrus.Error("OSS服务未初始化，无法删除文件")
 		return
 	}
 
+	referenced, err := s.ossKeyReferenced(ossKey)
+	if err != nil {
+		logrus.WithError(err).WithField("ossKey", ossKey).Warn("检查OSS文件引用失败，跳过删除")
+		return
+	}
+	if referenced {
+		logrus.WithField("ossKey", ossKey).Info("OSS文件仍被引用，跳过删除")
+		return
+	}
+
 	if err := s.ossService.DeleteFile(ossKey); err != nil {
 		// 静默处理错误，只记录日志
 		logrus.WithFields(logrus.Fields{
@@ -7,24 +7,23 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
 	userA = models.UserCreateRequest{
 		Username: "userA",
 		Email:    "a@example.com",
-		Password: "passwordA",
+		Password: "PasswordA1",
 	}
 	userB = models.UserCreateRequest{
 		Username: "userA", // 用户名重复
 		Email:    "b@example.com",
-		Password: "passwordB",
+		Password: "PasswordB1",
 	}
 	userC = models.UserCreateRequest{
 		Username: "userC",
 		Email:    "a@example.com", // 邮箱重复
-		Password: "passwordC",
+		Password: "PasswordC1",
 	}
 )
 
@@ -41,7 +40,9 @@ func TestUserService_CRUD(t *testing.T) {
 		assert.True(t, a.IsActive)
 		assert.NotEmpty(t, a.Password)
 		assert.NotEqual(t, userA.Password, a.Password)
-		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(a.Password), []byte(userA.Password)))
+		ok, _, err := VerifyPassword(a.Password, userA.Password)
+		require.NoError(t, err)
+		assert.True(t, ok)
 
 		// 查询A
 		a2, err := userService.GetUserByID(a.ID)
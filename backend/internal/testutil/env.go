@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -56,6 +57,15 @@ func LoadTestEnv(t *testing.T) *config.Config {
 		PostgresDB:       os.Getenv("POSTGRES_DB"),
 		JWTSecret:        os.Getenv("JWT_SECRET"),
 
+		// Redis配置，与数据库同样不能用docker内域名
+		RedisHost:     postgresHost,
+		RedisPort:     os.Getenv("REDIS_PORT"),
+		RedisPassword: os.Getenv("REDIS_PASSWORD"),
+
+		// 令牌有效期
+		JWTExpiration:        15 * time.Minute,
+		JWTRefreshExpiration: 24 * time.Hour,
+
 		// OSS配置
 		OSSAccessKeyID:     os.Getenv("OSS_ACCESS_KEY_ID"),
 		OSSAccessKeySecret: os.Getenv("OSS_ACCESS_KEY_SECRET"),
@@ -0,0 +1,228 @@
+package testutil
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCUser 模拟 issuer 中登录的用户
+type OIDCUser struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+type oidcGrant struct {
+	user          OIDCUser
+	nonce         string
+	codeChallenge string
+	redirectURI   string
+}
+
+/**
+ * OIDCIssuer 本地模拟的 OIDC issuer，提供 discovery、JWKS、令牌和 userinfo 接口
+ * 授权页由 Authorize 代替浏览器完成，令牌接口校验 client secret、redirect_uri 和 PKCE
+ */
+type OIDCIssuer struct {
+	*httptest.Server
+	ClientID     string
+	ClientSecret string
+
+	// ModifyClaims 签发 id_token 前修改 claims，用于构造异常的 id_token
+	ModifyClaims func(claims jwt.MapClaims)
+
+	mu     sync.Mutex
+	key    *rsa.PrivateKey
+	kid    string
+	codes  map[string]oidcGrant
+	tokens map[string]OIDCUser
+	serial int
+}
+
+// NewOIDCIssuer 启动模拟 issuer，测试结束时自动关闭
+func NewOIDCIssuer(t *testing.T) *OIDCIssuer {
+	issuer := &OIDCIssuer{
+		ClientID:     "test-client",
+		ClientSecret: "test-secret",
+		codes:        make(map[string]oidcGrant),
+		tokens:       make(map[string]OIDCUser),
+	}
+	issuer.RotateKey(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/jwks", issuer.handleJWKS)
+	mux.HandleFunc("/token", issuer.handleToken)
+	mux.HandleFunc("/userinfo", issuer.handleUserinfo)
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// RotateKey 更换签名密钥，之后签发的 id_token 使用新的 kid
+func (i *OIDCIssuer) RotateKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("生成 RSA 密钥失败: %v", err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.serial++
+	i.key, i.kid = key, "key-"+strconv.Itoa(i.serial)
+}
+
+// Authorize 模拟用户在授权页登录并同意授权，返回回调中的 code 和 state
+func (i *OIDCIssuer) Authorize(t *testing.T, authURL string, user OIDCUser) (code, state string) {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("授权地址无效: %v", err)
+	}
+	query := parsed.Query()
+	if query.Get("client_id") != i.ClientID || query.Get("response_type") != "code" {
+		t.Fatalf("授权请求参数错误: %s", authURL)
+	}
+	if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		t.Fatalf("授权请求缺少 PKCE: %s", authURL)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.serial++
+	code = "code-" + strconv.Itoa(i.serial)
+	i.codes[code] = oidcGrant{
+		user:          user,
+		nonce:         query.Get("nonce"),
+		codeChallenge: query.Get("code_challenge"),
+		redirectURI:   query.Get("redirect_uri"),
+	}
+	return code, query.Get("state")
+}
+
+func (i *OIDCIssuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 i.URL,
+		"authorization_endpoint": i.URL + "/authorize",
+		"token_endpoint":         i.URL + "/token",
+		"userinfo_endpoint":      i.URL + "/userinfo",
+		"jwks_uri":               i.URL + "/jwks",
+	})
+}
+
+func (i *OIDCIssuer) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": i.kid,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(i.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(i.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (i *OIDCIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+	if r.PostForm.Get("client_id") != i.ClientID || r.PostForm.Get("client_secret") != i.ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	code := r.PostForm.Get("code")
+	grant, ok := i.codes[code]
+	delete(i.codes, code)
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || grant.redirectURI != r.PostForm.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != grant.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   i.URL,
+		"aud":   i.ClientID,
+		"sub":   grant.user.Subject,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+		"nonce": grant.nonce,
+	}
+	if grant.user.Email != "" {
+		claims["email"] = grant.user.Email
+		claims["email_verified"] = grant.user.EmailVerified
+	}
+	if grant.user.Name != "" {
+		claims["name"] = grant.user.Name
+	}
+	if grant.user.PreferredUsername != "" {
+		claims["preferred_username"] = grant.user.PreferredUsername
+	}
+	if i.ModifyClaims != nil {
+		i.ModifyClaims(claims)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.kid
+	idToken, err := token.SignedString(i.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	accessToken := "access-" + code
+	i.tokens[accessToken] = grant.user
+	writeJSON(w, http.StatusOK, map[string]string{
+		"access_token": accessToken,
+		"token_type":   "Bearer",
+		"id_token":     idToken,
+	})
+}
+
+func (i *OIDCIssuer) handleUserinfo(w http.ResponseWriter, r *http.Request) {
+	i.mu.Lock()
+	user, ok := i.tokens[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+	i.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_token"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"sub":                user.Subject,
+		"email":              user.Email,
+		"email_verified":     user.EmailVerified,
+		"name":               user.Name,
+		"preferred_username": user.PreferredUsername,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -76,6 +76,16 @@ func RunWithTestDB(t *testing.T, testFunc func(t *testing.T)) {
 	testFunc(t)
 }
 
+// SetupTestRedis 连接测试 Redis，需要在 RunWithTestDB 或 RunWithEnv 中调用
+func SetupTestRedis(t *testing.T) {
+	if err := database.InitializeRedis(TestConfig); err != nil {
+		t.Fatalf("初始化测试Redis失败: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = database.CloseRedis()
+	})
+}
+
 // RunWithEnv 只加载环境变量，不连接数据库，适用于不依赖数据库的测试
 func RunWithEnv(t *testing.T, testFunc func(t *testing.T)) {
 	TestConfig = LoadTestEnv(t)